@@ -0,0 +1,299 @@
+package authtoken
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/en9inerd/go-pkgs/httpjson"
+)
+
+func mustKey(k *Key, err error) *Key {
+	if err != nil {
+		panic(err)
+	}
+	return k
+}
+
+func testKeys(t *testing.T) []*Key {
+	t.Helper()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ec256, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	ec521, _ := ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+
+	return []*Key{
+		mustKey(NewHMACKey("hs256", HS256, []byte("secret"))),
+		mustKey(NewHMACKey("hs512", HS512, []byte("secret"))),
+		mustKey(NewRSAKey("rs256", RS256, rsaKey)),
+		mustKey(NewRSAKey("rs384", RS384, rsaKey)),
+		mustKey(NewECDSAKey("es256", ES256, ec256)),
+		mustKey(NewECDSAKey("es512", ES512, ec521)),
+	}
+}
+
+func TestSignVerify_AllAlgorithms(t *testing.T) {
+	for _, key := range testKeys(t) {
+		t.Run(string(key.Algorithm), func(t *testing.T) {
+			token, err := Sign(Claims{Subject: "user-1", ExpiresAt: time.Now().Add(time.Minute)}, key)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			v := NewVerifier(VerifierConfig{Keys: NewKeySet(key.Public())})
+			claims, err := v.Verify(context.Background(), token)
+			if err != nil {
+				t.Fatalf("Verify: %v", err)
+			}
+			if claims.Subject != "user-1" {
+				t.Errorf("Subject = %q", claims.Subject)
+			}
+		})
+	}
+}
+
+func TestVerify_TamperedSignature(t *testing.T) {
+	key := mustKey(NewHMACKey("k", HS256, []byte("secret")))
+	token, _ := Sign(Claims{Subject: "a"}, key)
+
+	parts := strings.Split(token, ".")
+	forged, _ := Sign(Claims{Subject: "admin"}, key)
+	parts[1] = strings.Split(forged, ".")[1]
+
+	v := NewVerifier(VerifierConfig{Keys: NewKeySet(key)})
+	if _, err := v.Verify(context.Background(), strings.Join(parts, ".")); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("expected ErrInvalidSignature, got %v", err)
+	}
+}
+
+func TestVerify_AlgorithmConfusion(t *testing.T) {
+	ec, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	esKey := mustKey(NewECDSAKey("k", ES256, ec))
+	hsKey := mustKey(NewHMACKey("k", HS256, []byte("attacker")))
+
+	token, _ := Sign(Claims{Subject: "x"}, hsKey)
+
+	v := NewVerifier(VerifierConfig{Keys: NewKeySet(esKey.Public())})
+	if _, err := v.Verify(context.Background(), token); !errors.Is(err, ErrUnsupportedAlgorithm) {
+		t.Errorf("expected ErrUnsupportedAlgorithm, got %v", err)
+	}
+}
+
+func TestVerify_RegisteredClaims(t *testing.T) {
+	key := mustKey(NewHMACKey("k", HS256, []byte("secret")))
+	now := time.Now()
+
+	tests := []struct {
+		name   string
+		claims Claims
+		cfg    VerifierConfig
+		want   error
+	}{
+		{"expired", Claims{ExpiresAt: now.Add(-time.Minute)}, VerifierConfig{}, ErrExpired},
+		{"leeway", Claims{ExpiresAt: now.Add(-time.Second)}, VerifierConfig{Leeway: time.Minute}, nil},
+		{"not yet valid", Claims{NotBefore: now.Add(time.Hour)}, VerifierConfig{}, ErrNotYetValid},
+		{"issuer", Claims{Issuer: "other"}, VerifierConfig{Issuer: "me"}, ErrInvalidIssuer},
+		{"audience", Claims{Audience: Audience{"a", "b"}}, VerifierConfig{Audience: "c"}, ErrInvalidAudience},
+		{"audience ok", Claims{Audience: Audience{"a", "b"}}, VerifierConfig{Audience: "b"}, nil},
+		{"algorithm not allowed", Claims{}, VerifierConfig{Algorithms: []Algorithm{RS256}}, ErrUnsupportedAlgorithm},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, _ := Sign(tt.claims, key)
+			tt.cfg.Keys = NewKeySet(key)
+			_, err := NewVerifier(tt.cfg).Verify(context.Background(), token)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestClaims_JSONRoundTrip(t *testing.T) {
+	exp := time.Unix(1700000000, 0)
+	in := Claims{Subject: "s", Audience: Audience{"api"}, ExpiresAt: exp}
+	in.Set("role", "admin")
+	in.Set("sub", "ignored")
+
+	key := mustKey(NewHMACKey("k", HS256, []byte("secret")))
+	token, _ := Sign(in, key)
+
+	v := NewVerifier(VerifierConfig{Keys: NewKeySet(key), Now: func() time.Time { return exp.Add(-time.Second) }})
+	out, err := v.Verify(context.Background(), token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.Subject != "s" || !out.ExpiresAt.Equal(exp) || len(out.Audience) != 1 {
+		t.Errorf("unexpected claims: %+v", out)
+	}
+	if role, _ := out.Get("role"); role != "admin" {
+		t.Errorf("role = %v", role)
+	}
+	if _, ok := out.Get("sub"); ok {
+		t.Error("registered claim leaked into Extra")
+	}
+}
+
+func TestKeySet_Rotate(t *testing.T) {
+	k1 := mustKey(NewHMACKey("k1", HS256, []byte("one")))
+	k2 := mustKey(NewHMACKey("k2", HS256, []byte("two")))
+	ks := NewKeySet(k1)
+	v := NewVerifier(VerifierConfig{Keys: ks})
+
+	old, _ := ks.Sign(Claims{Subject: "old"})
+	ks.Rotate(k2)
+	fresh, _ := ks.Sign(Claims{Subject: "new"})
+
+	for _, tok := range []string{old, fresh} {
+		if _, err := v.Verify(context.Background(), tok); err != nil {
+			t.Errorf("Verify after rotate: %v", err)
+		}
+	}
+
+	ks.Remove("k1")
+	if _, err := v.Verify(context.Background(), old); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("expected ErrUnknownKey for removed key, got %v", err)
+	}
+}
+
+func TestJWKS_RoundTrip(t *testing.T) {
+	ks := NewKeySet(testKeys(t)...)
+	set := ks.JWKS()
+
+	// HMAC keys must never be published
+	for _, j := range set.Keys {
+		if strings.HasPrefix(j.KeyID, "hs") {
+			t.Errorf("HMAC key %q published", j.KeyID)
+		}
+	}
+	if len(set.Keys) != 4 {
+		t.Fatalf("expected 4 public keys, got %d", len(set.Keys))
+	}
+
+	for _, j := range set.Keys {
+		pub, err := j.Key()
+		if err != nil {
+			t.Fatalf("JWK.Key(%s): %v", j.KeyID, err)
+		}
+		signer, _ := ks.LookupKey(context.Background(), j.KeyID)
+		token, _ := Sign(Claims{Subject: "x"}, signer)
+		v := NewVerifier(VerifierConfig{Keys: NewKeySet(pub)})
+		if _, err := v.Verify(context.Background(), token); err != nil {
+			t.Errorf("verify with parsed %s: %v", j.KeyID, err)
+		}
+	}
+}
+
+func TestRemoteJWKS_RefreshOnUnknownKid(t *testing.T) {
+	ec1, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	ec2, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	k1 := mustKey(NewECDSAKey("k1", ES256, ec1))
+	k2 := mustKey(NewECDSAKey("k2", ES256, ec2))
+	issuer := NewKeySet(k1)
+
+	var fetches atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		httpjson.WriteJSON(w, issuer.JWKS())
+	}))
+	defer srv.Close()
+
+	remote := NewRemoteJWKS(srv.URL, RemoteJWKSConfig{MinRefreshInterval: time.Nanosecond})
+	v := NewVerifier(VerifierConfig{Keys: remote})
+
+	tok1, _ := issuer.Sign(Claims{Subject: "a"})
+	if _, err := v.Verify(context.Background(), tok1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := v.Verify(context.Background(), tok1); err != nil {
+		t.Fatal(err)
+	}
+	if fetches.Load() != 1 {
+		t.Errorf("expected cached keys, got %d fetches", fetches.Load())
+	}
+
+	issuer.Rotate(k2)
+	tok2, _ := issuer.Sign(Claims{Subject: "b"})
+	if _, err := v.Verify(context.Background(), tok2); err != nil {
+		t.Fatalf("verify after issuer rotation: %v", err)
+	}
+	if fetches.Load() != 2 {
+		t.Errorf("expected refetch for unknown kid, got %d fetches", fetches.Load())
+	}
+}
+
+func TestRemoteJWKS_FailingEndpoint(t *testing.T) {
+	var fetches atomic.Int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		<-release
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	remote := NewRemoteJWKS(srv.URL, RemoteJWKSConfig{})
+
+	// concurrent lookups share the fetch in progress
+	var wg sync.WaitGroup
+	for i := range 10 {
+		wg.Go(func() {
+			if _, err := remote.LookupKey(context.Background(), fmt.Sprintf("kid-%d", i)); err == nil {
+				t.Error("lookup succeeded against a failing endpoint")
+			}
+		})
+	}
+	for fetches.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	// later lookups within MinRefreshInterval do not fetch again
+	for i := range 10 {
+		if _, err := remote.LookupKey(context.Background(), fmt.Sprintf("other-%d", i)); err == nil {
+			t.Error("lookup succeeded against a failing endpoint")
+		}
+	}
+	if n := fetches.Load(); n != 1 {
+		t.Errorf("got %d fetches, want 1", n)
+	}
+}
+
+func TestVerifyRequestAndContext(t *testing.T) {
+	key := mustKey(NewHMACKey("k", HS256, []byte("secret")))
+	v := NewVerifier(VerifierConfig{Keys: NewKeySet(key)})
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	if _, err := v.VerifyRequest(r); !errors.Is(err, ErrMissingToken) {
+		t.Errorf("expected ErrMissingToken, got %v", err)
+	}
+
+	token, _ := Sign(Claims{Subject: "u"}, key)
+	r.Header.Set("Authorization", "bearer "+token)
+	claims, err := v.VerifyRequest(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := NewContext(context.Background(), claims)
+	got, ok := FromContext(ctx)
+	if !ok || got.Subject != "u" {
+		t.Errorf("FromContext = %+v, %v", got, ok)
+	}
+}
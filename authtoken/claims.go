@@ -0,0 +1,148 @@
+package authtoken
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"time"
+)
+
+// registeredClaims lists claim names mapped to Claims fields.
+var registeredClaims = []string{"iss", "sub", "aud", "exp", "nbf", "iat", "jti"}
+
+// Audience is the aud claim. It is encoded as a string when it has a single
+// value and as an array otherwise, and accepts both forms when decoding.
+type Audience []string
+
+// MarshalJSON implements json.Marshaler.
+func (a Audience) MarshalJSON() ([]byte, error) {
+	if len(a) == 1 {
+		return json.Marshal(a[0])
+	}
+	return json.Marshal([]string(a))
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (a *Audience) UnmarshalJSON(b []byte) error {
+	var single string
+	if err := json.Unmarshal(b, &single); err == nil {
+		*a = Audience{single}
+		return nil
+	}
+	var multi []string
+	if err := json.Unmarshal(b, &multi); err != nil {
+		return fmt.Errorf("aud must be a string or array of strings: %w", err)
+	}
+	*a = multi
+	return nil
+}
+
+// Claims holds the registered JWT claims plus any custom claims.
+// Zero-valued fields are omitted from the encoded token.
+type Claims struct {
+	Issuer    string
+	Subject   string
+	Audience  Audience
+	ExpiresAt time.Time
+	NotBefore time.Time
+	IssuedAt  time.Time
+	ID        string
+
+	// Extra holds custom (private) claims.
+	// Keys that collide with registered claim names are ignored when encoding.
+	Extra map[string]any
+}
+
+// MarshalJSON implements json.Marshaler.
+func (c Claims) MarshalJSON() ([]byte, error) {
+	m := make(map[string]any, len(c.Extra)+len(registeredClaims))
+	maps.Copy(m, c.Extra)
+	for _, name := range registeredClaims {
+		delete(m, name)
+	}
+
+	setString := func(name, v string) {
+		if v != "" {
+			m[name] = v
+		}
+	}
+	setTime := func(name string, t time.Time) {
+		if !t.IsZero() {
+			m[name] = t.Unix()
+		}
+	}
+
+	setString("iss", c.Issuer)
+	setString("sub", c.Subject)
+	setString("jti", c.ID)
+	if len(c.Audience) > 0 {
+		m["aud"] = c.Audience
+	}
+	setTime("exp", c.ExpiresAt)
+	setTime("nbf", c.NotBefore)
+	setTime("iat", c.IssuedAt)
+
+	return json.Marshal(m)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (c *Claims) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		Issuer    string   `json:"iss"`
+		Subject   string   `json:"sub"`
+		Audience  Audience `json:"aud"`
+		ExpiresAt *float64 `json:"exp"`
+		NotBefore *float64 `json:"nbf"`
+		IssuedAt  *float64 `json:"iat"`
+		ID        string   `json:"jti"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	var extra map[string]any
+	if err := json.Unmarshal(b, &extra); err != nil {
+		return err
+	}
+	for _, name := range registeredClaims {
+		delete(extra, name)
+	}
+
+	*c = Claims{
+		Issuer:    raw.Issuer,
+		Subject:   raw.Subject,
+		Audience:  raw.Audience,
+		ExpiresAt: numericDate(raw.ExpiresAt),
+		NotBefore: numericDate(raw.NotBefore),
+		IssuedAt:  numericDate(raw.IssuedAt),
+		ID:        raw.ID,
+	}
+	if len(extra) > 0 {
+		c.Extra = extra
+	}
+	return nil
+}
+
+// Get returns a custom claim by name.
+func (c *Claims) Get(name string) (any, bool) {
+	v, ok := c.Extra[name]
+	return v, ok
+}
+
+// Set stores a custom claim.
+func (c *Claims) Set(name string, value any) {
+	if c.Extra == nil {
+		c.Extra = make(map[string]any)
+	}
+	c.Extra[name] = value
+}
+
+// numericDate converts seconds since the epoch to a time.Time.
+func numericDate(v *float64) time.Time {
+	if v == nil {
+		return time.Time{}
+	}
+	sec := int64(*v)
+	nsec := int64((*v - float64(sec)) * 1e9)
+	return time.Unix(sec, nsec)
+}
@@ -0,0 +1,336 @@
+package authtoken
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/en9inerd/go-pkgs/httpclient"
+	"github.com/en9inerd/go-pkgs/httpjson"
+)
+
+// JWK is a JSON Web Key holding public key material.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid,omitempty"`
+	Algorithm string `json:"alg,omitempty"`
+	Use       string `json:"use,omitempty"`
+
+	// RSA parameters
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+
+	// EC parameters
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+	Y     string `json:"y,omitempty"`
+}
+
+// JWKS is a JSON Web Key Set document.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWK returns the public JSON Web Key representation of k.
+// HMAC keys cannot be published and return an error.
+func (k *Key) JWK() (JWK, error) {
+	jwk := JWK{KeyID: k.ID, Algorithm: string(k.Algorithm), Use: "sig"}
+
+	switch pub := k.public.(type) {
+	case *rsa.PublicKey:
+		jwk.KeyType = "RSA"
+		jwk.N = encodeSegment(pub.N.Bytes())
+		jwk.E = encodeSegment(big.NewInt(int64(pub.E)).Bytes())
+	case *ecdsa.PublicKey:
+		size := curveByteSize(pub.Curve)
+		ecdh, err := pub.Bytes()
+		if err != nil {
+			return JWK{}, fmt.Errorf("authtoken: encode key %q: %w", k.ID, err)
+		}
+		// uncompressed point: 0x04 || X || Y
+		jwk.KeyType = "EC"
+		jwk.Curve = pub.Curve.Params().Name
+		jwk.X = encodeSegment(ecdh[1 : 1+size])
+		jwk.Y = encodeSegment(ecdh[1+size:])
+	default:
+		return JWK{}, fmt.Errorf("authtoken: key %q has no public representation", k.ID)
+	}
+	return jwk, nil
+}
+
+// Key converts the JWK into a verification key.
+func (j JWK) Key() (*Key, error) {
+	alg := Algorithm(j.Algorithm)
+
+	switch j.KeyType {
+	case "RSA":
+		n, err := decodeSegment(j.N)
+		if err != nil {
+			return nil, fmt.Errorf("authtoken: jwk %q: invalid n: %w", j.KeyID, err)
+		}
+		e, err := decodeSegment(j.E)
+		if err != nil {
+			return nil, fmt.Errorf("authtoken: jwk %q: invalid e: %w", j.KeyID, err)
+		}
+		if alg == "" {
+			alg = RS256
+		}
+		pub := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+		return NewPublicKey(j.KeyID, alg, pub)
+	case "EC":
+		var curve elliptic.Curve
+		switch j.Curve {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("authtoken: jwk %q: unsupported curve %q", j.KeyID, j.Curve)
+		}
+		x, err := decodeSegment(j.X)
+		if err != nil {
+			return nil, fmt.Errorf("authtoken: jwk %q: invalid x: %w", j.KeyID, err)
+		}
+		y, err := decodeSegment(j.Y)
+		if err != nil {
+			return nil, fmt.Errorf("authtoken: jwk %q: invalid y: %w", j.KeyID, err)
+		}
+		size := curveByteSize(curve)
+		point := make([]byte, 1+2*size)
+		point[0] = 4
+		new(big.Int).SetBytes(x).FillBytes(point[1 : 1+size])
+		new(big.Int).SetBytes(y).FillBytes(point[1+size:])
+		pub, err := ecdsa.ParseUncompressedPublicKey(curve, point)
+		if err != nil {
+			return nil, fmt.Errorf("authtoken: jwk %q: %w", j.KeyID, err)
+		}
+		if alg == "" {
+			alg = map[string]Algorithm{"P-256": ES256, "P-384": ES384, "P-521": ES512}[j.Curve]
+		}
+		return NewPublicKey(j.KeyID, alg, pub)
+	}
+	return nil, fmt.Errorf("authtoken: jwk %q: unsupported key type %q", j.KeyID, j.KeyType)
+}
+
+// ParseJWKS parses a JWKS document. Keys that are not meant for signatures
+// or use unsupported key types are skipped.
+func ParseJWKS(data []byte) ([]*Key, error) {
+	var set JWKS
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("authtoken: decode jwks: %w", err)
+	}
+	return set.keys(), nil
+}
+
+func (s JWKS) keys() []*Key {
+	keys := make([]*Key, 0, len(s.Keys))
+	for _, j := range s.Keys {
+		if j.Use != "" && j.Use != "sig" {
+			continue
+		}
+		if k, err := j.Key(); err == nil {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// JWKS returns the public keys of the set as a JWKS document, ordered by key ID.
+// HMAC keys are never published.
+func (ks *KeySet) JWKS() JWKS {
+	keys := ks.Keys()
+	slices.SortFunc(keys, func(a, b *Key) int { return strings.Compare(a.ID, b.ID) })
+
+	set := JWKS{Keys: make([]JWK, 0, len(keys))}
+	for _, k := range keys {
+		if jwk, err := k.JWK(); err == nil {
+			set.Keys = append(set.Keys, jwk)
+		}
+	}
+	return set
+}
+
+// JWKSHandler returns an http.Handler that serves the public keys of ks,
+// typically mounted at "/.well-known/jwks.json".
+func JWKSHandler(ks *KeySet) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		httpjson.WriteJSON(w, ks.JWKS())
+	})
+}
+
+// RemoteJWKSConfig configures a RemoteJWKS.
+type RemoteJWKSConfig struct {
+	// Client is the HTTP client used to fetch the key set.
+	// If nil, httpclient.New() is used.
+	Client *httpclient.Client
+
+	// RefreshInterval is how long fetched keys are cached.
+	// Default: 1 hour
+	RefreshInterval time.Duration
+
+	// MinRefreshInterval limits how often an unknown key ID can trigger a refetch,
+	// so tokens with random kids cannot be used to flood the JWKS endpoint.
+	// Default: 1 minute
+	MinRefreshInterval time.Duration
+}
+
+// RemoteJWKS is a KeySource backed by a remote JWKS endpoint.
+// Keys are cached and refreshed periodically or when an unknown key ID is seen,
+// which picks up key rotation on the issuer side. Concurrent lookups share one
+// fetch, and a failed fetch is not retried within MinRefreshInterval, so a
+// failing endpoint is not flooded either.
+type RemoteJWKS struct {
+	url    string
+	cfg    RemoteJWKSConfig
+	client *httpclient.Client
+
+	mu          sync.Mutex
+	keys        map[string]*Key
+	fetchedAt   time.Time // last successful fetch
+	attemptedAt time.Time // last fetch, successful or not
+	lastErr     error     // error of the last fetch
+	inflight    *jwksFetch
+}
+
+// jwksFetch is a fetch shared by concurrent callers; err is set before done
+// is closed.
+type jwksFetch struct {
+	done chan struct{}
+	err  error
+}
+
+// NewRemoteJWKS creates a key source that fetches keys from url.
+func NewRemoteJWKS(url string, cfg RemoteJWKSConfig) *RemoteJWKS {
+	if cfg.Client == nil {
+		cfg.Client = httpclient.New()
+	}
+	if cfg.RefreshInterval == 0 {
+		cfg.RefreshInterval = time.Hour
+	}
+	if cfg.MinRefreshInterval == 0 {
+		cfg.MinRefreshInterval = time.Minute
+	}
+	return &RemoteJWKS{url: url, cfg: cfg, client: cfg.Client}
+}
+
+// LookupKey implements KeySource.
+func (r *RemoteJWKS) LookupKey(ctx context.Context, kid string) (*Key, error) {
+	r.mu.Lock()
+	due := r.keys == nil || time.Since(r.fetchedAt) > r.cfg.RefreshInterval
+	r.mu.Unlock()
+
+	if due {
+		// stale keys are still used while the endpoint fails
+		if err := r.fetch(ctx, true); err != nil && !r.loaded() {
+			return nil, err
+		}
+	}
+
+	if key, ok := r.lookup(kid); ok {
+		return key, nil
+	}
+
+	// unknown kid: the issuer may have rotated keys
+	if err := r.fetch(ctx, true); err != nil {
+		return nil, err
+	}
+	if key, ok := r.lookup(kid); ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnknownKey, kid)
+}
+
+// Refresh fetches the key set immediately, or waits for a fetch already in
+// progress.
+func (r *RemoteJWKS) Refresh(ctx context.Context) error {
+	return r.fetch(ctx, false)
+}
+
+func (r *RemoteJWKS) lookup(kid string) (*Key, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if kid == "" {
+		// only unambiguous when the set holds a single key
+		if len(r.keys) == 1 {
+			for _, k := range r.keys {
+				return k, true
+			}
+		}
+		return nil, false
+	}
+	k, ok := r.keys[kid]
+	return k, ok
+}
+
+// loaded reports whether a key set was ever fetched.
+func (r *RemoteJWKS) loaded() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.keys != nil
+}
+
+// fetch refreshes the key set, joining a fetch already in progress. If
+// throttled and the last fetch was less than MinRefreshInterval ago, it
+// returns that fetch's error instead of fetching again. The request runs
+// outside the lock and is not canceled with ctx, so other callers waiting
+// for it are not failed by one caller's cancellation.
+func (r *RemoteJWKS) fetch(ctx context.Context, throttled bool) error {
+	r.mu.Lock()
+	f := r.inflight
+	if f == nil {
+		if throttled && time.Since(r.attemptedAt) < r.cfg.MinRefreshInterval {
+			err := r.lastErr
+			r.mu.Unlock()
+			return err
+		}
+		f = &jwksFetch{done: make(chan struct{})}
+		r.inflight = f
+		go r.download(context.WithoutCancel(ctx), f)
+	}
+	r.mu.Unlock()
+
+	select {
+	case <-f.done:
+		return f.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// download fetches the key set and completes f.
+func (r *RemoteJWKS) download(ctx context.Context, f *jwksFetch) {
+	var set JWKS
+	err := r.client.GetJSON(ctx, r.url, &set)
+	if err != nil {
+		err = fmt.Errorf("authtoken: fetch jwks: %w", err)
+	}
+
+	r.mu.Lock()
+	r.attemptedAt = time.Now()
+	r.lastErr = err
+	if err == nil {
+		keys := make(map[string]*Key)
+		for _, k := range set.keys() {
+			keys[k.ID] = k
+		}
+		r.keys = keys
+		r.fetchedAt = r.attemptedAt
+	}
+	r.inflight = nil
+	r.mu.Unlock()
+
+	f.err = err
+	close(f.done)
+}
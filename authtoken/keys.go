@@ -0,0 +1,318 @@
+package authtoken
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	_ "crypto/sha256" // register SHA-256 for crypto.Hash
+	_ "crypto/sha512" // register SHA-384/512 for crypto.Hash
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+)
+
+// Algorithm is a JWS signing algorithm.
+type Algorithm string
+
+// Supported signing algorithms.
+const (
+	HS256 Algorithm = "HS256"
+	HS384 Algorithm = "HS384"
+	HS512 Algorithm = "HS512"
+	RS256 Algorithm = "RS256"
+	RS384 Algorithm = "RS384"
+	RS512 Algorithm = "RS512"
+	ES256 Algorithm = "ES256"
+	ES384 Algorithm = "ES384"
+	ES512 Algorithm = "ES512"
+)
+
+// hash returns the hash function used by the algorithm.
+func (a Algorithm) hash() crypto.Hash {
+	switch a {
+	case HS256, RS256, ES256:
+		return crypto.SHA256
+	case HS384, RS384, ES384:
+		return crypto.SHA384
+	case HS512, RS512, ES512:
+		return crypto.SHA512
+	}
+	return 0
+}
+
+// family returns the key type prefix ("HS", "RS" or "ES").
+func (a Algorithm) family() string {
+	if len(a) < 2 {
+		return ""
+	}
+	return string(a[:2])
+}
+
+func (a Algorithm) valid() bool { return a.hash() != 0 }
+
+// curve returns the elliptic curve required by an ES algorithm.
+func (a Algorithm) curve() elliptic.Curve {
+	switch a {
+	case ES256:
+		return elliptic.P256()
+	case ES384:
+		return elliptic.P384()
+	case ES512:
+		return elliptic.P521()
+	}
+	return nil
+}
+
+// Key is a signing or verification key bound to an algorithm.
+// Verification-only keys have no private material.
+type Key struct {
+	// ID is the key identifier written to the kid header.
+	ID string
+
+	// Algorithm is the signing algorithm used with this key.
+	Algorithm Algorithm
+
+	secret  []byte
+	private crypto.Signer
+	public  crypto.PublicKey
+}
+
+// NewHMACKey creates a symmetric key for HS256, HS384 or HS512.
+func NewHMACKey(id string, alg Algorithm, secret []byte) (*Key, error) {
+	if alg.family() != "HS" || !alg.valid() {
+		return nil, fmt.Errorf("%w: %q is not an HMAC algorithm", ErrUnsupportedAlgorithm, alg)
+	}
+	if len(secret) == 0 {
+		return nil, errors.New("authtoken: empty HMAC secret")
+	}
+	return &Key{ID: id, Algorithm: alg, secret: secret}, nil
+}
+
+// NewRSAKey creates a signing key for RS256, RS384 or RS512.
+func NewRSAKey(id string, alg Algorithm, priv *rsa.PrivateKey) (*Key, error) {
+	if alg.family() != "RS" || !alg.valid() {
+		return nil, fmt.Errorf("%w: %q is not an RSA algorithm", ErrUnsupportedAlgorithm, alg)
+	}
+	return &Key{ID: id, Algorithm: alg, private: priv, public: &priv.PublicKey}, nil
+}
+
+// NewECDSAKey creates a signing key for ES256, ES384 or ES512.
+// The key's curve must match the algorithm.
+func NewECDSAKey(id string, alg Algorithm, priv *ecdsa.PrivateKey) (*Key, error) {
+	if alg.family() != "ES" || !alg.valid() {
+		return nil, fmt.Errorf("%w: %q is not an ECDSA algorithm", ErrUnsupportedAlgorithm, alg)
+	}
+	if priv.Curve != alg.curve() {
+		return nil, fmt.Errorf("authtoken: curve %s does not match %s", priv.Curve.Params().Name, alg)
+	}
+	return &Key{ID: id, Algorithm: alg, private: priv, public: &priv.PublicKey}, nil
+}
+
+// NewPublicKey creates a verification-only key from an *rsa.PublicKey or *ecdsa.PublicKey.
+func NewPublicKey(id string, alg Algorithm, pub crypto.PublicKey) (*Key, error) {
+	switch p := pub.(type) {
+	case *rsa.PublicKey:
+		if alg.family() != "RS" || !alg.valid() {
+			return nil, fmt.Errorf("%w: %q is not an RSA algorithm", ErrUnsupportedAlgorithm, alg)
+		}
+	case *ecdsa.PublicKey:
+		if alg.family() != "ES" || !alg.valid() {
+			return nil, fmt.Errorf("%w: %q is not an ECDSA algorithm", ErrUnsupportedAlgorithm, alg)
+		}
+		if p.Curve != alg.curve() {
+			return nil, fmt.Errorf("authtoken: curve %s does not match %s", p.Curve.Params().Name, alg)
+		}
+	default:
+		return nil, fmt.Errorf("authtoken: unsupported public key type %T", pub)
+	}
+	return &Key{ID: id, Algorithm: alg, public: pub}, nil
+}
+
+// Public returns a verification-only copy of the key.
+// For HMAC keys the secret is shared, so the key itself is returned.
+func (k *Key) Public() *Key {
+	if k.secret != nil {
+		return k
+	}
+	return &Key{ID: k.ID, Algorithm: k.Algorithm, public: k.public}
+}
+
+// CanSign reports whether the key holds private material.
+func (k *Key) CanSign() bool {
+	return k.secret != nil || k.private != nil
+}
+
+func (k *Key) digest(data []byte) []byte {
+	h := k.Algorithm.hash().New()
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+func (k *Key) sign(data []byte) ([]byte, error) {
+	switch k.Algorithm.family() {
+	case "HS":
+		if k.secret == nil {
+			return nil, fmt.Errorf("authtoken: key %q cannot sign", k.ID)
+		}
+		mac := hmac.New(k.Algorithm.hash().New, k.secret)
+		mac.Write(data)
+		return mac.Sum(nil), nil
+	case "RS":
+		priv, ok := k.private.(*rsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("authtoken: key %q cannot sign", k.ID)
+		}
+		return rsa.SignPKCS1v15(rand.Reader, priv, k.Algorithm.hash(), k.digest(data))
+	case "ES":
+		priv, ok := k.private.(*ecdsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("authtoken: key %q cannot sign", k.ID)
+		}
+		r, s, err := ecdsa.Sign(rand.Reader, priv, k.digest(data))
+		if err != nil {
+			return nil, err
+		}
+		// JWS uses the fixed-size R || S encoding rather than ASN.1.
+		size := curveByteSize(priv.Curve)
+		sig := make([]byte, 2*size)
+		r.FillBytes(sig[:size])
+		s.FillBytes(sig[size:])
+		return sig, nil
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnsupportedAlgorithm, k.Algorithm)
+}
+
+func (k *Key) verify(data, sig []byte) error {
+	switch k.Algorithm.family() {
+	case "HS":
+		mac := hmac.New(k.Algorithm.hash().New, k.secret)
+		mac.Write(data)
+		if !hmac.Equal(sig, mac.Sum(nil)) {
+			return ErrInvalidSignature
+		}
+		return nil
+	case "RS":
+		pub, ok := k.public.(*rsa.PublicKey)
+		if !ok {
+			return ErrInvalidSignature
+		}
+		if err := rsa.VerifyPKCS1v15(pub, k.Algorithm.hash(), k.digest(data), sig); err != nil {
+			return ErrInvalidSignature
+		}
+		return nil
+	case "ES":
+		pub, ok := k.public.(*ecdsa.PublicKey)
+		if !ok {
+			return ErrInvalidSignature
+		}
+		size := curveByteSize(pub.Curve)
+		if len(sig) != 2*size {
+			return ErrInvalidSignature
+		}
+		r := new(big.Int).SetBytes(sig[:size])
+		s := new(big.Int).SetBytes(sig[size:])
+		if !ecdsa.Verify(pub, k.digest(data), r, s) {
+			return ErrInvalidSignature
+		}
+		return nil
+	}
+	return fmt.Errorf("%w: %q", ErrUnsupportedAlgorithm, k.Algorithm)
+}
+
+func curveByteSize(c elliptic.Curve) int {
+	return (c.Params().BitSize + 7) / 8
+}
+
+// KeySet is a rotatable collection of keys.
+// The active key signs new tokens; all keys in the set are used for verification.
+// KeySet is safe for concurrent use.
+type KeySet struct {
+	mu     sync.RWMutex
+	keys   map[string]*Key
+	active string
+}
+
+// NewKeySet creates a key set. The first key becomes the active signing key.
+func NewKeySet(keys ...*Key) *KeySet {
+	ks := &KeySet{keys: make(map[string]*Key)}
+	for _, k := range keys {
+		ks.Add(k)
+	}
+	if len(keys) > 0 {
+		ks.active = keys[0].ID
+	}
+	return ks
+}
+
+// Add adds a key to the set without making it active.
+func (ks *KeySet) Add(key *Key) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	ks.keys[key.ID] = key
+}
+
+// Rotate adds key to the set and makes it the active signing key.
+// Previously active keys stay available for verifying tokens they issued.
+func (ks *KeySet) Rotate(key *Key) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	ks.keys[key.ID] = key
+	ks.active = key.ID
+}
+
+// Remove deletes a key from the set. Removing the active key leaves the set
+// without a signing key until Rotate is called.
+func (ks *KeySet) Remove(kid string) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	delete(ks.keys, kid)
+	if ks.active == kid {
+		ks.active = ""
+	}
+}
+
+// Active returns the current signing key, or nil if there is none.
+func (ks *KeySet) Active() *Key {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	return ks.keys[ks.active]
+}
+
+// Sign signs claims with the active key.
+func (ks *KeySet) Sign(claims Claims) (string, error) {
+	key := ks.Active()
+	if key == nil {
+		return "", fmt.Errorf("%w: no active signing key", ErrUnknownKey)
+	}
+	return Sign(claims, key)
+}
+
+// LookupKey implements KeySource. An empty kid resolves to the active key.
+func (ks *KeySet) LookupKey(_ context.Context, kid string) (*Key, error) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	if kid == "" {
+		kid = ks.active
+	}
+	key, ok := ks.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, kid)
+	}
+	return key, nil
+}
+
+// Keys returns a snapshot of all keys in the set.
+func (ks *KeySet) Keys() []*Key {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	keys := make([]*Key, 0, len(ks.keys))
+	for _, k := range ks.keys {
+		keys = append(keys, k)
+	}
+	return keys
+}
@@ -0,0 +1,185 @@
+// Package conditional evaluates HTTP conditional requests (If-Match,
+// If-None-Match, If-Modified-Since, If-Unmodified-Since) following RFC 9110
+// section 13. It is shared by middleware.ETag and httpjson.WriteJSONWithETag
+// so both layers apply the same semantics.
+//
+//	etag := conditional.StrongETag(body)
+//	if conditional.Check(w, r, etag, modTime) {
+//		return // 304 Not Modified or 412 Precondition Failed already written
+//	}
+//	w.Write(body)
+package conditional
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"iter"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// StrongETag returns a quoted strong entity tag derived from data.
+func StrongETag(data []byte) string {
+	sum := sha256.Sum256(data)
+	return `"` + base64.RawURLEncoding.EncodeToString(sum[:16]) + `"`
+}
+
+// WeakETag returns a quoted weak entity tag derived from data.
+func WeakETag(data []byte) string {
+	return "W/" + StrongETag(data)
+}
+
+// StrongMatch reports whether two entity tags match using the strong
+// comparison function: both must be strong and have identical opaque tags.
+func StrongMatch(a, b string) bool {
+	return !isWeak(a) && !isWeak(b) && a == b
+}
+
+// WeakMatch reports whether two entity tags match using the weak comparison
+// function: their opaque tags are identical, ignoring weakness.
+func WeakMatch(a, b string) bool {
+	return strings.TrimPrefix(a, "W/") == strings.TrimPrefix(b, "W/")
+}
+
+func isWeak(etag string) bool { return strings.HasPrefix(etag, "W/") }
+
+// Evaluate applies the preconditions in r to a representation with the given
+// entity tag and modification time, in the order defined by RFC 9110
+// section 13.2.2. Either may be empty/zero if unknown.
+//
+// It returns 0 when the request should proceed normally,
+// http.StatusNotModified for a GET or HEAD whose cached copy is current, or
+// http.StatusPreconditionFailed when a precondition does not hold.
+func Evaluate(r *http.Request, etag string, lastModified time.Time) int {
+	// Step 1: If-Match, strong comparison
+	if im := r.Header.Get("If-Match"); im != "" {
+		if !matchesAny(im, etag, StrongMatch) {
+			return http.StatusPreconditionFailed
+		}
+	} else if ius, ok := parseTime(r.Header.Get("If-Unmodified-Since")); ok && !lastModified.IsZero() {
+		// Step 2: If-Unmodified-Since, only when If-Match is absent
+		if lastModified.Truncate(time.Second).After(ius) {
+			return http.StatusPreconditionFailed
+		}
+	}
+
+	safe := r.Method == http.MethodGet || r.Method == http.MethodHead
+
+	// Step 3: If-None-Match, weak comparison
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		if matchesAny(inm, etag, WeakMatch) {
+			if safe {
+				return http.StatusNotModified
+			}
+			return http.StatusPreconditionFailed
+		}
+		return 0
+	}
+
+	// Step 4: If-Modified-Since, only for GET/HEAD without If-None-Match
+	if ims, ok := parseTime(r.Header.Get("If-Modified-Since")); ok && safe && !lastModified.IsZero() {
+		if !lastModified.Truncate(time.Second).After(ims) {
+			return http.StatusNotModified
+		}
+	}
+	return 0
+}
+
+// Check sets the ETag and Last-Modified response headers (when non-empty),
+// evaluates the request preconditions and, if the request should not
+// proceed, writes a 304 or 412 response. It reports whether a response was
+// written, in which case the caller must not write a body.
+func Check(w http.ResponseWriter, r *http.Request, etag string, lastModified time.Time) bool {
+	h := w.Header()
+	if etag != "" {
+		h.Set("ETag", etag)
+	}
+	if !lastModified.IsZero() {
+		h.Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	switch status := Evaluate(r, etag, lastModified); status {
+	case http.StatusNotModified:
+		WriteNotModified(w)
+		return true
+	case http.StatusPreconditionFailed:
+		w.WriteHeader(status)
+		return true
+	}
+	return false
+}
+
+// WriteNotModified writes a 304 response, removing headers that describe
+// the omitted content as RFC 9110 section 15.4.5 recommends.
+func WriteNotModified(w http.ResponseWriter) {
+	h := w.Header()
+	h.Del("Content-Type")
+	h.Del("Content-Length")
+	h.Del("Content-Encoding")
+	if h.Get("ETag") != "" {
+		h.Del("Last-Modified")
+	}
+	w.WriteHeader(http.StatusNotModified)
+}
+
+// matchesAny reports whether etag matches the If-Match/If-None-Match list
+// header using match. "*" matches any existing representation.
+func matchesAny(header, etag string, match func(a, b string) bool) bool {
+	if strings.TrimSpace(header) == "*" {
+		return etag != ""
+	}
+	if etag == "" {
+		return false
+	}
+	for candidate := range etags(header) {
+		if match(candidate, etag) {
+			return true
+		}
+	}
+	return false
+}
+
+// etags yields the entity tags in a comma-separated list. Tags are scanned
+// by their quotes because an opaque tag may itself contain commas.
+func etags(header string) iter.Seq[string] {
+	return func(yield func(string) bool) {
+		s := header
+		for {
+			s = strings.TrimLeft(s, " \t,")
+			if s == "" {
+				return
+			}
+			start := 0
+			if strings.HasPrefix(s, "W/") {
+				start = 2
+			}
+			if len(s) <= start || s[start] != '"' {
+				// malformed entry: skip to the next comma
+				i := strings.IndexByte(s, ',')
+				if i < 0 {
+					return
+				}
+				s = s[i:]
+				continue
+			}
+			end := strings.IndexByte(s[start+1:], '"')
+			if end < 0 {
+				return
+			}
+			end += start + 2
+			if !yield(s[:end]) {
+				return
+			}
+			s = s[end:]
+		}
+	}
+}
+
+func parseTime(value string) (time.Time, bool) {
+	if value == "" {
+		return time.Time{}, false
+	}
+	t, err := http.ParseTime(value)
+	return t, err == nil
+}
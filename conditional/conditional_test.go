@@ -0,0 +1,108 @@
+package conditional
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		a, b         string
+		strong, weak bool
+	}{
+		{`"1"`, `"1"`, true, true},
+		{`W/"1"`, `W/"1"`, false, true},
+		{`W/"1"`, `"1"`, false, true},
+		{`W/"1"`, `W/"2"`, false, false},
+		{`"1"`, `"2"`, false, false},
+	}
+	for _, tt := range tests {
+		if got := StrongMatch(tt.a, tt.b); got != tt.strong {
+			t.Errorf("StrongMatch(%s, %s) = %v, want %v", tt.a, tt.b, got, tt.strong)
+		}
+		if got := WeakMatch(tt.a, tt.b); got != tt.weak {
+			t.Errorf("WeakMatch(%s, %s) = %v, want %v", tt.a, tt.b, got, tt.weak)
+		}
+	}
+}
+
+func TestETags(t *testing.T) {
+	got := slices.Collect(etags(`"a", W/"b,c" ,bogus, "d"`))
+	want := []string{`"a"`, `W/"b,c"`, `"d"`}
+	if !slices.Equal(got, want) {
+		t.Errorf("etags = %q, want %q", got, want)
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	modified := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	before := modified.Add(-time.Hour).Format(http.TimeFormat)
+	after := modified.Add(time.Hour).Format(http.TimeFormat)
+
+	tests := []struct {
+		name    string
+		method  string
+		headers map[string]string
+		etag    string
+		want    int
+	}{
+		{"no preconditions", "GET", nil, `"x"`, 0},
+		{"if-none-match hit", "GET", map[string]string{"If-None-Match": `"y", W/"x"`}, `"x"`, http.StatusNotModified},
+		{"if-none-match miss", "GET", map[string]string{"If-None-Match": `"y"`}, `"x"`, 0},
+		{"if-none-match star", "GET", map[string]string{"If-None-Match": "*"}, `"x"`, http.StatusNotModified},
+		{"if-none-match on PUT", "PUT", map[string]string{"If-None-Match": `"x"`}, `"x"`, http.StatusPreconditionFailed},
+		{"if-match weak fails", "PUT", map[string]string{"If-Match": `W/"x"`}, `W/"x"`, http.StatusPreconditionFailed},
+		{"if-match strong ok", "PUT", map[string]string{"If-Match": `"x"`}, `"x"`, 0},
+		{"if-match star without representation", "PUT", map[string]string{"If-Match": "*"}, "", http.StatusPreconditionFailed},
+		{"if-unmodified-since fails", "PUT", map[string]string{"If-Unmodified-Since": before}, "", http.StatusPreconditionFailed},
+		{"if-match takes precedence over if-unmodified-since", "PUT",
+			map[string]string{"If-Match": `"x"`, "If-Unmodified-Since": before}, `"x"`, 0},
+		{"if-modified-since not modified", "GET", map[string]string{"If-Modified-Since": after}, "", http.StatusNotModified},
+		{"if-modified-since modified", "GET", map[string]string{"If-Modified-Since": before}, "", 0},
+		{"if-none-match takes precedence over if-modified-since", "GET",
+			map[string]string{"If-None-Match": `"y"`, "If-Modified-Since": after}, `"x"`, 0},
+		{"if-modified-since ignored for POST", "POST", map[string]string{"If-Modified-Since": after}, "", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, "/", nil)
+			for k, v := range tt.headers {
+				r.Header.Set(k, v)
+			}
+			if got := Evaluate(r, tt.etag, modified); got != tt.want {
+				t.Errorf("Evaluate = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	etag := StrongETag([]byte("body"))
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("If-None-Match", etag)
+	w := httptest.NewRecorder()
+	w.Header().Set("Content-Type", "application/json")
+
+	if !Check(w, r, etag, time.Time{}) {
+		t.Fatal("expected response to be written")
+	}
+	if w.Code != http.StatusNotModified {
+		t.Errorf("status = %d, want 304", w.Code)
+	}
+	if w.Header().Get("ETag") != etag {
+		t.Errorf("ETag = %q", w.Header().Get("ETag"))
+	}
+	if w.Header().Get("Content-Type") != "" {
+		t.Error("Content-Type should be removed from 304")
+	}
+
+	w = httptest.NewRecorder()
+	if Check(w, httptest.NewRequest("GET", "/", nil), etag, time.Time{}) {
+		t.Error("expected request to proceed")
+	}
+}
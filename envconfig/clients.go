@@ -0,0 +1,173 @@
+package envconfig
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/en9inerd/go-pkgs/httpclient"
+	"github.com/en9inerd/go-pkgs/longpoll"
+	"github.com/en9inerd/go-pkgs/retry"
+	"github.com/en9inerd/go-pkgs/validator"
+)
+
+// LongPoll builds a longpoll.Config from variables with the given prefix.
+// See (*Source).LongPoll for the variable names.
+func LongPoll(prefix string) (longpoll.Config, error) {
+	s := New(prefix)
+	cfg := s.LongPoll()
+	return cfg, s.Err()
+}
+
+// HTTPClient builds an httpclient.Config from variables with the given prefix.
+// See (*Source).HTTPClient for the variable names.
+func HTTPClient(prefix string) (httpclient.Config, error) {
+	s := New(prefix)
+	cfg := s.HTTPClient()
+	return cfg, s.Err()
+}
+
+// Retry builds a retry.Strategy from variables with the given prefix.
+// See (*Source).Retry for the variable names.
+func Retry(prefix string) (*retry.Strategy, error) {
+	s := New(prefix)
+	strategy := s.Retry()
+	return strategy, s.Err()
+}
+
+// Server reads HTTP server timeouts from variables with the given prefix.
+// See (*Source).Server for the variable names.
+func Server(prefix string) (ServerTimeouts, error) {
+	s := New(prefix)
+	t := s.Server()
+	return t, s.Err()
+}
+
+// LongPoll reads a longpoll.Config from:
+//
+//	POLL_TIMEOUT     duration > 0
+//	RETRY_DELAY      duration >= 0
+//	MAX_RETRIES      integer >= -1 (default -1, unlimited)
+//	MAX_RETRY_AFTER  duration >= 0
+//	METHOD           GET or POST
+//	HEADERS          KEY=VALUE,...
+//	BACKOFF_*        a retry strategy, see Retry; enables Backoff when
+//	                 BACKOFF_INITIAL_DELAY is set
+func (s *Source) LongPoll() longpoll.Config {
+	cfg := longpoll.Config{
+		PollTimeout:   s.Duration("POLL_TIMEOUT", 0),
+		RetryDelay:    s.Duration("RETRY_DELAY", 0),
+		MaxRetries:    s.Int("MAX_RETRIES", -1),
+		MaxRetryAfter: s.Duration("MAX_RETRY_AFTER", 0),
+		Method:        strings.ToUpper(s.String("METHOD", "")),
+		Headers:       s.Map("HEADERS", nil),
+	}
+
+	s.Check(!s.IsSet("POLL_TIMEOUT") || cfg.PollTimeout > 0, "POLL_TIMEOUT", "must be positive")
+	s.Check(validator.MinDuration(cfg.RetryDelay, 0), "RETRY_DELAY", "must not be negative")
+	s.Check(validator.MinInt(cfg.MaxRetries, -1), "MAX_RETRIES", "must be -1 (unlimited) or greater")
+	s.Check(validator.MinDuration(cfg.MaxRetryAfter, 0), "MAX_RETRY_AFTER", "must not be negative")
+	s.Check(cfg.Method == "" || validator.PermittedValue(cfg.Method, http.MethodGet, http.MethodPost),
+		"METHOD", "must be GET or POST")
+
+	if backoff := s.Sub("BACKOFF"); backoff.IsSet("INITIAL_DELAY") {
+		cfg.Backoff = backoff.Retry()
+	}
+	return cfg
+}
+
+// HTTPClient reads an httpclient.Config from:
+//
+//	TIMEOUT   duration > 0
+//	BASE_URL  absolute http(s) URL
+//	HEADERS   KEY=VALUE,...
+func (s *Source) HTTPClient() httpclient.Config {
+	cfg := httpclient.Config{
+		Timeout: s.Duration("TIMEOUT", 0),
+		BaseURL: s.String("BASE_URL", ""),
+		Headers: s.Map("HEADERS", nil),
+	}
+
+	s.Check(!s.IsSet("TIMEOUT") || cfg.Timeout > 0, "TIMEOUT", "must be positive")
+	if cfg.BaseURL != "" {
+		u, err := url.Parse(cfg.BaseURL)
+		s.Check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "",
+			"BASE_URL", "must be an absolute http or https URL")
+	}
+	return cfg
+}
+
+// Retry reads a retry.Strategy, starting from retry.DefaultStrategy, from:
+//
+//	MAX_ATTEMPTS   integer >= 1
+//	INITIAL_DELAY  duration >= 0
+//	MAX_DELAY      duration >= INITIAL_DELAY
+//	MULTIPLIER     number >= 1
+//	JITTER         boolean
+func (s *Source) Retry() *retry.Strategy {
+	strategy := retry.DefaultStrategy()
+	strategy.MaxAttempts = s.Int("MAX_ATTEMPTS", strategy.MaxAttempts)
+	strategy.InitialDelay = s.Duration("INITIAL_DELAY", strategy.InitialDelay)
+	strategy.MaxDelay = s.Duration("MAX_DELAY", strategy.MaxDelay)
+	strategy.Multiplier = s.Float("MULTIPLIER", strategy.Multiplier)
+	strategy.Jitter = s.Bool("JITTER", strategy.Jitter)
+
+	s.Check(validator.MinInt(strategy.MaxAttempts, 1), "MAX_ATTEMPTS", "must be at least 1")
+	s.Check(validator.MinDuration(strategy.InitialDelay, 0), "INITIAL_DELAY", "must not be negative")
+	s.Check(validator.MinDuration(strategy.MaxDelay, strategy.InitialDelay), "MAX_DELAY", "must not be less than INITIAL_DELAY")
+	s.Check(validator.MinFloat(strategy.Multiplier, 1), "MULTIPLIER", "must be at least 1")
+	return strategy
+}
+
+// ServerTimeouts holds http.Server timeouts plus a graceful shutdown timeout.
+type ServerTimeouts struct {
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	ShutdownTimeout   time.Duration
+}
+
+// DefaultServerTimeouts returns conservative timeouts for public-facing servers.
+func DefaultServerTimeouts() ServerTimeouts {
+	return ServerTimeouts{
+		ReadTimeout:       15 * time.Second,
+		ReadHeaderTimeout: 5 * time.Second,
+		WriteTimeout:      15 * time.Second,
+		IdleTimeout:       60 * time.Second,
+		ShutdownTimeout:   10 * time.Second,
+	}
+}
+
+// Apply sets the timeouts on srv.
+func (t ServerTimeouts) Apply(srv *http.Server) {
+	srv.ReadTimeout = t.ReadTimeout
+	srv.ReadHeaderTimeout = t.ReadHeaderTimeout
+	srv.WriteTimeout = t.WriteTimeout
+	srv.IdleTimeout = t.IdleTimeout
+}
+
+// Server reads ServerTimeouts, starting from DefaultServerTimeouts, from
+// READ_TIMEOUT, READ_HEADER_TIMEOUT, WRITE_TIMEOUT, IDLE_TIMEOUT and
+// SHUTDOWN_TIMEOUT. All must be non-negative; zero disables a timeout as
+// it does for http.Server.
+func (s *Source) Server() ServerTimeouts {
+	t := DefaultServerTimeouts()
+	t.ReadTimeout = s.Duration("READ_TIMEOUT", t.ReadTimeout)
+	t.ReadHeaderTimeout = s.Duration("READ_HEADER_TIMEOUT", t.ReadHeaderTimeout)
+	t.WriteTimeout = s.Duration("WRITE_TIMEOUT", t.WriteTimeout)
+	t.IdleTimeout = s.Duration("IDLE_TIMEOUT", t.IdleTimeout)
+	t.ShutdownTimeout = s.Duration("SHUTDOWN_TIMEOUT", t.ShutdownTimeout)
+
+	for name, d := range map[string]time.Duration{
+		"READ_TIMEOUT":        t.ReadTimeout,
+		"READ_HEADER_TIMEOUT": t.ReadHeaderTimeout,
+		"WRITE_TIMEOUT":       t.WriteTimeout,
+		"IDLE_TIMEOUT":        t.IdleTimeout,
+		"SHUTDOWN_TIMEOUT":    t.ShutdownTimeout,
+	} {
+		s.Check(validator.MinDuration(d, 0), name, "must not be negative")
+	}
+	return t
+}
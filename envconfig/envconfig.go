@@ -0,0 +1,194 @@
+// Package envconfig builds configuration for the clients and servers in this
+// module from prefixed environment variables, so deployments can tune
+// timeouts and retry behavior without recompiling.
+//
+// Each variable name is the prefix, an underscore, and the setting name,
+// e.g. with prefix "TELEGRAM" the poll timeout is read from
+// TELEGRAM_POLL_TIMEOUT. Unset variables keep the package defaults.
+// Values are validated with the validator package and all problems are
+// reported together in a single *Error:
+//
+//	cfg, err := envconfig.LongPoll("TELEGRAM")
+//	if err != nil {
+//		log.Fatal(err) // e.g. TELEGRAM_POLL_TIMEOUT: must be a duration such as 30s
+//	}
+//	client := longpoll.NewWithConfig(cfg)
+//
+// Durations use time.ParseDuration syntax ("500ms", "30s", "1m").
+// Header maps use comma-separated KEY=VALUE pairs.
+package envconfig
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/en9inerd/go-pkgs/validator"
+)
+
+// Error reports invalid environment variables, keyed by variable name.
+type Error struct {
+	validator.Validator
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	keys := slices.Sorted(maps.Keys(e.FieldErrors))
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, k+": "+strings.Join(e.FieldErrors[k], "; "))
+	}
+	return "envconfig: invalid environment: " + strings.Join(parts, ", ")
+}
+
+// Source reads prefixed variables and collects validation errors.
+// Accessors return the default when a variable is unset or invalid;
+// call Err once all values have been read.
+type Source struct {
+	prefix string
+	lookup func(string) (string, bool)
+	v      *validator.Validator
+}
+
+// New creates a Source reading from the process environment.
+func New(prefix string) *Source {
+	return NewWithLookup(prefix, os.LookupEnv)
+}
+
+// NewWithLookup creates a Source that reads variables with lookup,
+// which has the signature of os.LookupEnv.
+func NewWithLookup(prefix string, lookup func(string) (string, bool)) *Source {
+	return &Source{prefix: prefix, lookup: lookup, v: &validator.Validator{}}
+}
+
+// Sub returns a Source for nested settings under name, sharing the same
+// lookup and error collection. Sub("BACKOFF") of "APP" reads APP_BACKOFF_*.
+func (s *Source) Sub(name string) *Source {
+	return &Source{prefix: s.Key(name), lookup: s.lookup, v: s.v}
+}
+
+// Key returns the full variable name for name.
+func (s *Source) Key(name string) string {
+	if s.prefix == "" {
+		return name
+	}
+	return s.prefix + "_" + name
+}
+
+// Err returns an *Error describing every invalid variable read so far,
+// or nil if all were valid.
+func (s *Source) Err() error {
+	if s.v.Valid() {
+		return nil
+	}
+	return &Error{Validator: *s.v}
+}
+
+// Check records message for the variable name if ok is false.
+func (s *Source) Check(ok bool, name, message string) {
+	s.v.CheckField(ok, s.Key(name), message)
+}
+
+// IsSet reports whether the variable name is set.
+func (s *Source) IsSet(name string) bool {
+	_, ok := s.get(name)
+	return ok
+}
+
+func (s *Source) get(name string) (string, bool) {
+	value, ok := s.lookup(s.Key(name))
+	if !ok {
+		return "", false
+	}
+	return strings.TrimSpace(value), true
+}
+
+// String returns the variable name, or def if it is unset.
+func (s *Source) String(name, def string) string {
+	if value, ok := s.get(name); ok {
+		return value
+	}
+	return def
+}
+
+// Int returns the variable name parsed as an integer, or def.
+func (s *Source) Int(name string, def int) int {
+	value, ok := s.get(name)
+	if !ok {
+		return def
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		s.Check(false, name, "must be an integer")
+		return def
+	}
+	return n
+}
+
+// Float returns the variable name parsed as a float, or def.
+func (s *Source) Float(name string, def float64) float64 {
+	value, ok := s.get(name)
+	if !ok {
+		return def
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		s.Check(false, name, "must be a number")
+		return def
+	}
+	return f
+}
+
+// Bool returns the variable name parsed with strconv.ParseBool, or def.
+func (s *Source) Bool(name string, def bool) bool {
+	value, ok := s.get(name)
+	if !ok {
+		return def
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		s.Check(false, name, "must be a boolean")
+		return def
+	}
+	return b
+}
+
+// Duration returns the variable name parsed with time.ParseDuration, or def.
+func (s *Source) Duration(name string, def time.Duration) time.Duration {
+	value, ok := s.get(name)
+	if !ok {
+		return def
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		s.Check(false, name, "must be a duration such as 30s")
+		return def
+	}
+	return d
+}
+
+// Map returns the variable name parsed as comma-separated KEY=VALUE pairs, or def.
+func (s *Source) Map(name string, def map[string]string) map[string]string {
+	value, ok := s.get(name)
+	if !ok {
+		return def
+	}
+	m := make(map[string]string)
+	for pair := range strings.SplitSeq(value, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		k, v, found := strings.Cut(pair, "=")
+		k = strings.TrimSpace(k)
+		if !found || k == "" {
+			s.Check(false, name, fmt.Sprintf("invalid pair %q, want KEY=VALUE", pair))
+			continue
+		}
+		m[k] = strings.TrimSpace(v)
+	}
+	return m
+}
@@ -0,0 +1,151 @@
+package envconfig
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func mapLookup(env map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		v, ok := env[key]
+		return v, ok
+	}
+}
+
+func TestSource_LongPoll(t *testing.T) {
+	s := NewWithLookup("TG", mapLookup(map[string]string{
+		"TG_POLL_TIMEOUT":          "50s",
+		"TG_RETRY_DELAY":           "2s",
+		"TG_METHOD":                "post",
+		"TG_HEADERS":               "X-A=1, X-B = two",
+		"TG_BACKOFF_INITIAL_DELAY": "100ms",
+		"TG_BACKOFF_MAX_DELAY":     "5s",
+	}))
+
+	cfg := s.LongPoll()
+	if err := s.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.PollTimeout != 50*time.Second || cfg.RetryDelay != 2*time.Second {
+		t.Errorf("timeouts = %v/%v", cfg.PollTimeout, cfg.RetryDelay)
+	}
+	if cfg.MaxRetries != -1 {
+		t.Errorf("MaxRetries = %d, want -1", cfg.MaxRetries)
+	}
+	if cfg.Method != http.MethodPost {
+		t.Errorf("Method = %q", cfg.Method)
+	}
+	if cfg.Headers["X-A"] != "1" || cfg.Headers["X-B"] != "two" {
+		t.Errorf("Headers = %v", cfg.Headers)
+	}
+	if cfg.Backoff == nil || cfg.Backoff.InitialDelay != 100*time.Millisecond || cfg.Backoff.MaxDelay != 5*time.Second {
+		t.Errorf("Backoff = %+v", cfg.Backoff)
+	}
+}
+
+func TestSource_Unset(t *testing.T) {
+	s := NewWithLookup("APP", mapLookup(nil))
+
+	lp := s.LongPoll()
+	hc := s.HTTPClient()
+	srv := s.Server()
+	if err := s.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if lp.PollTimeout != 0 || lp.Backoff != nil {
+		t.Errorf("expected zero values to keep package defaults, got %+v", lp)
+	}
+	if hc.Timeout != 0 || hc.BaseURL != "" {
+		t.Errorf("unexpected httpclient config %+v", hc)
+	}
+	if srv != DefaultServerTimeouts() {
+		t.Errorf("Server() = %+v, want defaults", srv)
+	}
+}
+
+func TestSource_CollectsErrors(t *testing.T) {
+	s := NewWithLookup("APP", mapLookup(map[string]string{
+		"APP_POLL_TIMEOUT":       "soon",
+		"APP_MAX_RETRIES":        "-5",
+		"APP_METHOD":             "DELETE",
+		"APP_BASE_URL":           "/relative",
+		"APP_RETRY_MULTIPLIER":   "0.5",
+		"APP_RETRY_MAX_ATTEMPTS": "x",
+	}))
+
+	s.LongPoll()
+	s.HTTPClient()
+	s.Sub("RETRY").Retry()
+
+	err := s.Err()
+	var envErr *Error
+	if !errors.As(err, &envErr) {
+		t.Fatalf("expected *Error, got %v", err)
+	}
+	for _, key := range []string{
+		"APP_POLL_TIMEOUT", "APP_MAX_RETRIES", "APP_METHOD", "APP_BASE_URL",
+		"APP_RETRY_MULTIPLIER", "APP_RETRY_MAX_ATTEMPTS",
+	} {
+		if len(envErr.FieldErrors[key]) == 0 {
+			t.Errorf("expected error for %s", key)
+		}
+		if !strings.Contains(err.Error(), key) {
+			t.Errorf("error message missing %s: %v", key, err)
+		}
+	}
+}
+
+func TestSource_Retry(t *testing.T) {
+	s := NewWithLookup("", mapLookup(map[string]string{
+		"MAX_ATTEMPTS":  "5",
+		"INITIAL_DELAY": "1s",
+		"MAX_DELAY":     "500ms",
+		"JITTER":        "false",
+	}))
+
+	strategy := s.Retry()
+	if strategy.MaxAttempts != 5 || strategy.Jitter {
+		t.Errorf("strategy = %+v", strategy)
+	}
+	if strategy.RetryableErrors == nil {
+		t.Error("expected RetryableErrors from the default strategy")
+	}
+
+	var envErr *Error
+	if !errors.As(s.Err(), &envErr) || len(envErr.FieldErrors["MAX_DELAY"]) == 0 {
+		t.Errorf("expected MAX_DELAY error, got %v", s.Err())
+	}
+}
+
+func TestServerTimeouts_Apply(t *testing.T) {
+	s := NewWithLookup("HTTP", mapLookup(map[string]string{"HTTP_WRITE_TIMEOUT": "0s"}))
+	timeouts := s.Server()
+	if err := s.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	srv := &http.Server{}
+	timeouts.Apply(srv)
+	if srv.WriteTimeout != 0 {
+		t.Errorf("WriteTimeout = %v, want 0", srv.WriteTimeout)
+	}
+	if srv.ReadHeaderTimeout != 5*time.Second {
+		t.Errorf("ReadHeaderTimeout = %v, want 5s", srv.ReadHeaderTimeout)
+	}
+}
+
+func TestLongPoll_FromEnvironment(t *testing.T) {
+	t.Setenv("SVC_POLL_TIMEOUT", "10s")
+
+	cfg, err := LongPoll("SVC")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.PollTimeout != 10*time.Second {
+		t.Errorf("PollTimeout = %v, want 10s", cfg.PollTimeout)
+	}
+}
@@ -2,6 +2,13 @@ module github.com/en9inerd/go-pkgs
 
 go 1.26.1
 
-require golang.org/x/term v0.42.0
+require (
+	golang.org/x/crypto v0.57.0
+	golang.org/x/term v0.46.0
+)
 
-require golang.org/x/sys v0.43.0 // indirect
+require (
+	golang.org/x/net v0.58.0 // indirect
+	golang.org/x/sys v0.48.0 // indirect
+	golang.org/x/text v0.42.0 // indirect
+)
@@ -0,0 +1,98 @@
+package httpclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// RequestSummary is a sanitized description of an outgoing request, passed
+// to audit hooks. Its fields are stable so audit sinks can store it directly
+// instead of parsing log lines.
+type RequestSummary struct {
+	// Method is the HTTP method.
+	Method string `json:"method"`
+
+	// URL is the request URL with user info removed and query values redacted.
+	URL string `json:"url"`
+
+	// Attempt is the 1-based attempt number of this request.
+	Attempt int `json:"attempt"`
+
+	// RequestBytes is the request body size, or -1 if unknown.
+	RequestBytes int64 `json:"requestBytes"`
+}
+
+// ResponseSummary describes the outcome of a request for audit hooks.
+type ResponseSummary struct {
+	RequestSummary
+
+	// StatusCode is the response status, or 0 if the request failed.
+	StatusCode int `json:"statusCode"`
+
+	// Duration is the time until the response headers were received.
+	Duration time.Duration `json:"duration"`
+
+	// ResponseBytes is the number of response body bytes read by the caller.
+	ResponseBytes int64 `json:"responseBytes"`
+
+	// Error is the transport error message, empty on success.
+	Error string `json:"error,omitempty"`
+}
+
+// SanitizeURL returns u as a string without user info and with every query
+// value replaced by "REDACTED", so credentials passed in URLs never reach
+// audit sinks.
+func SanitizeURL(u *url.URL) string {
+	clean := *u
+	clean.User = nil
+	if clean.RawQuery != "" {
+		q := clean.Query()
+		for k, vs := range q {
+			for i := range vs {
+				vs[i] = "REDACTED"
+			}
+			q[k] = vs
+		}
+		clean.RawQuery = q.Encode()
+	}
+	return clean.String()
+}
+
+// summarize builds the request summary for req.
+func summarize(req *http.Request, attempt int) RequestSummary {
+	size := req.ContentLength
+	if req.Body == nil || req.Body == http.NoBody {
+		size = 0
+	}
+	return RequestSummary{
+		Method:       req.Method,
+		URL:          SanitizeURL(req.URL),
+		Attempt:      attempt,
+		RequestBytes: size,
+	}
+}
+
+// auditBody reports the response summary once the caller closes the body.
+type auditBody struct {
+	io.ReadCloser
+	ctx     context.Context
+	summary ResponseSummary
+	hook    func(context.Context, ResponseSummary)
+	once    sync.Once
+}
+
+func (b *auditBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.summary.ResponseBytes += int64(n)
+	return n, err
+}
+
+func (b *auditBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(func() { b.hook(b.ctx, b.summary) })
+	return err
+}
@@ -0,0 +1,91 @@
+package httpclient
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// tokenRefreshKey marks the context of a token source call made after a 401
+type tokenRefreshKey struct{}
+
+// TokenRefresh reports whether a token source is being called with ctx
+// because the server rejected the previous token with 401 Unauthorized. A
+// source caching its token should fetch a new one then.
+func TokenRefresh(ctx context.Context) bool {
+	refresh, _ := ctx.Value(tokenRefreshKey{}).(bool)
+	return refresh
+}
+
+// WithBearerTokenSource sets a source of bearer tokens consulted for every
+// request without an Authorization header. If the server responds with 401
+// Unauthorized, the source is consulted again with a context for which
+// TokenRefresh reports true, and the request is sent once more with the new
+// token, provided its body can be replayed as for Do with retries. It takes
+// precedence over WithBasicAuth.
+//
+//	c.WithBearerTokenSource(func(ctx context.Context) (string, error) {
+//		return tokens.Get(ctx, httpclient.TokenRefresh(ctx))
+//	})
+func (c *Client) WithBearerTokenSource(source func(ctx context.Context) (string, error)) *Client {
+	c.tokenSource = source
+	return c
+}
+
+// WithBasicAuth sets HTTP basic authentication for every request without an
+// Authorization header
+func (c *Client) WithBasicAuth(username, password string) *Client {
+	c.basicAuth = url.UserPassword(username, password)
+	return c
+}
+
+// authenticates reports whether the client adds credentials to req
+func (c *Client) authenticates(req *http.Request) bool {
+	return (c.tokenSource != nil || c.basicAuth != nil) && req.Header.Get("Authorization") == ""
+}
+
+// doAuth sends req with the client's credentials, refreshing a bearer token
+// the server rejected once
+func (c *Client) doAuth(ctx context.Context, req *http.Request) (*http.Response, error) {
+	if c.tokenSource == nil {
+		r := req.Clone(ctx)
+		password, _ := c.basicAuth.Password()
+		r.SetBasicAuth(c.basicAuth.Username(), password)
+		return c.dispatch(ctx, r)
+	}
+
+	r, err := c.withToken(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.dispatch(ctx, r)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized || !replayable(req) {
+		return resp, err
+	}
+	// drain a bounded amount so the connection can be reused
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	resp.Body.Close()
+
+	if r, err = c.withToken(context.WithValue(ctx, tokenRefreshKey{}, true), req); err != nil {
+		return nil, err
+	}
+	if req.GetBody != nil {
+		if r.Body, err = req.GetBody(); err != nil {
+			return nil, fmt.Errorf("replay body: %w", err)
+		}
+	}
+	return c.dispatch(ctx, r)
+}
+
+// withToken returns a copy of req carrying a token from the token source
+func (c *Client) withToken(ctx context.Context, req *http.Request) (*http.Request, error) {
+	token, err := c.tokenSource(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("token source: %w", err)
+	}
+	r := req.Clone(req.Context())
+	r.Header.Set("Authorization", "Bearer "+token)
+	return r, nil
+}
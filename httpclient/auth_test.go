@@ -0,0 +1,92 @@
+package httpclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBearerTokenSource_RefreshesOn401(t *testing.T) {
+	var bodies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(b))
+		if r.Header.Get("Authorization") != "Bearer fresh" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer srv.Close()
+
+	var calls, refreshes int
+	c := New().WithBaseURL(srv.URL).WithBearerTokenSource(func(ctx context.Context) (string, error) {
+		calls++
+		if TokenRefresh(ctx) {
+			refreshes++
+			return "fresh", nil
+		}
+		return "expired", nil
+	})
+
+	resp, err := c.Post(context.Background(), "/", map[string]int{"n": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("status = %d, want 200", resp.StatusCode)
+	}
+	if calls != 2 || refreshes != 1 {
+		t.Errorf("token source calls = %d, refreshes = %d, want 2 and 1", calls, refreshes)
+	}
+	if len(bodies) != 2 || bodies[1] != bodies[0] {
+		t.Errorf("request bodies = %q, want the same body twice", bodies)
+	}
+}
+
+func TestBearerTokenSource_FailsAfterOneRefresh(t *testing.T) {
+	var hits int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	c := New().WithBaseURL(srv.URL).WithBearerTokenSource(func(ctx context.Context) (string, error) {
+		return "token", nil
+	})
+	if err := c.GetJSON(context.Background(), "/", nil); err == nil {
+		t.Fatal("expected error for 401")
+	}
+	if hits != 2 {
+		t.Errorf("server hits = %d, want 2", hits)
+	}
+}
+
+func TestWithBasicAuth(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, _ := r.BasicAuth()
+		w.Write([]byte(user + ":" + pass))
+	}))
+	defer srv.Close()
+
+	c := New().WithBaseURL(srv.URL).WithBasicAuth("client", "secret")
+	for _, tc := range []struct {
+		opts []RequestOption
+		want string
+	}{
+		{nil, "client:secret"},
+		{[]RequestOption{WithBasicAuth("override", "pw")}, "override:pw"},
+	} {
+		resp, err := c.Get(context.Background(), "/", tc.opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if string(got) != tc.want {
+			t.Errorf("credentials = %q, want %q", got, tc.want)
+		}
+	}
+}
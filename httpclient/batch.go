@@ -0,0 +1,104 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// BatchItem is one request of a Batch.
+type BatchItem struct {
+	// Method is the HTTP method. Default: GET
+	Method string
+
+	// Path is the request path, relative to the client's base URL.
+	Path string
+
+	// Body, if not nil, is sent as JSON.
+	Body any
+
+	// Target, if not nil, receives the decoded JSON response.
+	Target any
+
+	// Options apply to this request only.
+	Options []RequestOption
+}
+
+// BatchResult is the outcome of the BatchItem with the same index.
+type BatchResult struct {
+	// StatusCode is the response status, or 0 if no response was received.
+	StatusCode int
+
+	// Err is the error of the request or of decoding its response; error
+	// responses are *httperrors.APIError as for GetJSON.
+	Err error
+}
+
+// BatchResults are the results of a Batch, in the order of its items.
+type BatchResults []BatchResult
+
+// Err joins the errors of the failed items, each prefixed with the item
+// index, or returns nil if all succeeded.
+func (rs BatchResults) Err() error {
+	var errs []error
+	for i, r := range rs {
+		if r.Err != nil {
+			errs = append(errs, fmt.Errorf("batch item %d: %w", i, r.Err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Batch executes items with at most concurrency requests in flight (at
+// least one) and returns their results in the order of items. Items not
+// started when ctx is done fail with the context's error.
+//
+//	users := make([]User, len(ids))
+//	items := make([]httpclient.BatchItem, len(ids))
+//	for i, id := range ids {
+//		items[i] = httpclient.BatchItem{Path: "/users/" + id, Target: &users[i]}
+//	}
+//	if err := c.Batch(ctx, items, 8).Err(); err != nil {
+//		return err
+//	}
+func (c *Client) Batch(ctx context.Context, items []BatchItem, concurrency int) BatchResults {
+	results := make(BatchResults, len(items))
+	sem := make(chan struct{}, max(concurrency, 1))
+	var wg sync.WaitGroup
+	for i, item := range items {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			results[i].Err = ctx.Err()
+			continue
+		}
+		wg.Go(func() {
+			defer func() { <-sem }()
+			results[i] = c.batchItem(ctx, item)
+		})
+	}
+	wg.Wait()
+	return results
+}
+
+// batchItem executes one item of a batch.
+func (c *Client) batchItem(ctx context.Context, item BatchItem) BatchResult {
+	method := item.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	resp, err := c.NewRequest().
+		Method(method).
+		Path(item.Path).
+		Body(item.Body).
+		Options(item.Options...).
+		Send(ctx)
+	if err != nil {
+		return BatchResult{Err: err}
+	}
+	defer resp.Body.Close()
+
+	return BatchResult{StatusCode: resp.StatusCode, Err: c.decodeJSON(resp, item.Target)}
+}
@@ -0,0 +1,84 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/en9inerd/go-pkgs/httperrors"
+)
+
+func TestBatch(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		if r.URL.Path == "/items/3" {
+			http.Error(w, `{"message":"not found"}`, http.StatusNotFound)
+			return
+		}
+		fmt.Fprintf(w, `{"path":%q,"method":%q}`, r.URL.Path, r.Method)
+	}))
+	defer srv.Close()
+	c := New().WithBaseURL(srv.URL)
+
+	type echo struct{ Path, Method string }
+	out := make([]echo, 6)
+	items := make([]BatchItem, len(out))
+	for i := range items {
+		items[i] = BatchItem{Path: fmt.Sprintf("/items/%d", i), Target: &out[i]}
+	}
+	items[5].Method = http.MethodPost
+	items[5].Body = map[string]int{"n": 5}
+
+	results := c.Batch(context.Background(), items, 2)
+	if len(results) != len(items) {
+		t.Fatalf("got %d results, want %d", len(results), len(items))
+	}
+	if p := peak.Load(); p > 2 {
+		t.Errorf("peak concurrency = %d, want at most 2", p)
+	}
+	for i, r := range results {
+		if i == 3 {
+			continue
+		}
+		if r.Err != nil || r.StatusCode != http.StatusOK || out[i].Path != items[i].Path {
+			t.Errorf("item %d: %+v, decoded %+v", i, r, out[i])
+		}
+	}
+	if out[5].Method != http.MethodPost {
+		t.Errorf("item 5 method = %q, want POST", out[5].Method)
+	}
+
+	var apiErr *httperrors.APIError
+	if !errors.As(results[3].Err, &apiErr) || results[3].StatusCode != http.StatusNotFound {
+		t.Errorf("item 3: %+v, want a 404 APIError", results[3])
+	}
+	if err := results.Err(); err == nil || !strings.Contains(err.Error(), "batch item 3") {
+		t.Errorf("Err() = %v, want the error of item 3", err)
+	}
+}
+
+func TestBatch_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	results := New().Batch(ctx, []BatchItem{{Path: "http://127.0.0.1:0/"}, {Path: "http://127.0.0.1:0/"}}, 1)
+	for i, r := range results {
+		if !errors.Is(r.Err, context.Canceled) {
+			t.Errorf("item %d err = %v, want context.Canceled", i, r.Err)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package httpclient
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned, wrapped, for requests rejected without being
+// sent because the circuit breaker of their target is open.
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// BreakerState is the state of a circuit breaker.
+type BreakerState int
+
+const (
+	// BreakerClosed lets requests through while counting failures.
+	BreakerClosed BreakerState = iota
+
+	// BreakerOpen rejects requests with ErrCircuitOpen.
+	BreakerOpen
+
+	// BreakerHalfOpen lets a limited number of probe requests through to
+	// decide whether the target has recovered.
+	BreakerHalfOpen
+)
+
+// String returns the state name.
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// BreakerConfig configures the circuit breakers of a client. Each request
+// host gets its own breaker.
+type BreakerConfig struct {
+	// FailureRate is the fraction of failed requests within Window that
+	// opens the breaker. Default: 0.5
+	FailureRate float64
+
+	// MinRequests is how many requests Window must hold before FailureRate
+	// is evaluated, so a single early failure does not open the breaker.
+	// Default: 10
+	MinRequests int
+
+	// Window is the period over which requests are counted. Default: 1m
+	Window time.Duration
+
+	// OpenTimeout is how long the breaker stays open before letting probe
+	// requests through. Default: 30s
+	OpenTimeout time.Duration
+
+	// HalfOpenProbes is how many probe requests are let through while half
+	// open. The breaker closes once all succeed and opens again on the first
+	// failure. Default: 1
+	HalfOpenProbes int
+
+	// IsFailure reports whether a request outcome counts as a failure.
+	// Default: a transport error or a 5xx response.
+	IsFailure func(resp *http.Response, err error) bool
+
+	// OnStateChange, if set, is called when the breaker of target changes
+	// state.
+	OnStateChange func(target string, from, to BreakerState)
+}
+
+// withDefaults returns cfg with the documented defaults filled in.
+func (cfg BreakerConfig) withDefaults() BreakerConfig {
+	if cfg.FailureRate <= 0 {
+		cfg.FailureRate = 0.5
+	}
+	if cfg.MinRequests <= 0 {
+		cfg.MinRequests = 10
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = time.Minute
+	}
+	if cfg.OpenTimeout <= 0 {
+		cfg.OpenTimeout = 30 * time.Second
+	}
+	if cfg.HalfOpenProbes <= 0 {
+		cfg.HalfOpenProbes = 1
+	}
+	if cfg.IsFailure == nil {
+		cfg.IsFailure = func(resp *http.Response, err error) bool {
+			return err != nil || resp.StatusCode >= 500
+		}
+	}
+	return cfg
+}
+
+// breakers holds one breaker per target.
+type breakers struct {
+	cfg BreakerConfig
+
+	mu      sync.Mutex
+	targets map[string]*breaker
+}
+
+func newBreakers(cfg BreakerConfig) *breakers {
+	return &breakers{cfg: cfg.withDefaults(), targets: make(map[string]*breaker)}
+}
+
+// get returns the breaker of target, creating it if needed.
+func (bs *breakers) get(target string) *breaker {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	b, ok := bs.targets[target]
+	if !ok {
+		b = &breaker{cfg: &bs.cfg, target: target, windowStart: time.Now()}
+		bs.targets[target] = b
+	}
+	return b
+}
+
+// breaker is the circuit breaker of one target.
+type breaker struct {
+	cfg    *BreakerConfig
+	target string
+
+	mu          sync.Mutex
+	state       BreakerState
+	openedAt    time.Time
+	windowStart time.Time
+	requests    int
+	failures    int
+	probes      int // probes let through while half open
+	successes   int // probes that succeeded
+}
+
+// allow reports whether a request may be sent, returning ErrCircuitOpen if
+// not. A nil result must be followed by a call to done.
+func (b *breaker) allow() error {
+	b.mu.Lock()
+	from := b.state
+	now := time.Now()
+	if b.state == BreakerOpen && now.Sub(b.openedAt) >= b.cfg.OpenTimeout {
+		b.state, b.probes, b.successes = BreakerHalfOpen, 0, 0
+	}
+
+	var err error
+	switch b.state {
+	case BreakerOpen:
+		err = ErrCircuitOpen
+	case BreakerHalfOpen:
+		if b.probes >= b.cfg.HalfOpenProbes {
+			err = ErrCircuitOpen
+		} else {
+			b.probes++
+		}
+	}
+	to := b.state
+	b.mu.Unlock()
+
+	b.changed(from, to)
+	return err
+}
+
+// done records the outcome of a request allowed by allow. Requests whose
+// outcome says nothing about the target, such as those canceled by the
+// caller, are passed with counted false.
+func (b *breaker) done(failed, counted bool) {
+	b.mu.Lock()
+	from := b.state
+	now := time.Now()
+	switch b.state {
+	case BreakerClosed:
+		if !counted {
+			break
+		}
+		if now.Sub(b.windowStart) >= b.cfg.Window {
+			b.windowStart, b.requests, b.failures = now, 0, 0
+		}
+		b.requests++
+		if failed {
+			b.failures++
+		}
+		if b.requests >= b.cfg.MinRequests && float64(b.failures) >= b.cfg.FailureRate*float64(b.requests) {
+			b.state, b.openedAt = BreakerOpen, now
+		}
+	case BreakerHalfOpen:
+		switch {
+		case !counted:
+			b.probes-- // let another probe through
+		case failed:
+			b.state, b.openedAt = BreakerOpen, now
+		default:
+			b.successes++
+			if b.successes >= b.cfg.HalfOpenProbes {
+				b.state = BreakerClosed
+				b.windowStart, b.requests, b.failures = now, 0, 0
+			}
+		}
+	}
+	to := b.state
+	b.mu.Unlock()
+
+	b.changed(from, to)
+}
+
+// changed reports a state change to the OnStateChange callback.
+func (b *breaker) changed(from, to BreakerState) {
+	if from != to && b.cfg.OnStateChange != nil {
+		b.cfg.OnStateChange(b.target, from, to)
+	}
+}
@@ -0,0 +1,89 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDo_CircuitBreaker(t *testing.T) {
+	var healthy atomic.Bool
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	var mu sync.Mutex
+	var changes []string
+	c := NewWithConfig(Config{
+		BaseURL: srv.URL,
+		CircuitBreaker: &BreakerConfig{
+			MinRequests: 2,
+			OpenTimeout: 50 * time.Millisecond,
+			OnStateChange: func(target string, from, to BreakerState) {
+				mu.Lock()
+				defer mu.Unlock()
+				changes = append(changes, from.String()+"->"+to.String())
+			},
+		},
+	})
+	get := func() error {
+		resp, err := c.Get(context.Background(), "/")
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+
+	for range 2 {
+		if err := get(); err != nil {
+			t.Fatalf("request while closed: %v", err)
+		}
+	}
+	if err := get(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("err = %v, want ErrCircuitOpen", err)
+	}
+	if n := hits.Load(); n != 2 {
+		t.Errorf("server hits = %d, want 2", n)
+	}
+
+	// a failed probe opens the breaker again
+	time.Sleep(60 * time.Millisecond)
+	if err := get(); err != nil {
+		t.Fatalf("probe: %v", err)
+	}
+	if err := get(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("err after failed probe = %v, want ErrCircuitOpen", err)
+	}
+
+	// a successful probe closes it
+	healthy.Store(true)
+	time.Sleep(60 * time.Millisecond)
+	for range 3 {
+		if err := get(); err != nil {
+			t.Fatalf("request after recovery: %v", err)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := []string{"closed->open", "open->half-open", "half-open->open", "open->half-open", "half-open->closed"}
+	if len(changes) != len(want) {
+		t.Fatalf("state changes = %v, want %v", changes, want)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Errorf("state changes = %v, want %v", changes, want)
+			break
+		}
+	}
+}
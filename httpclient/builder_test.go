@@ -0,0 +1,71 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequestBuilder(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var in map[string]string
+		json.NewDecoder(r.Body).Decode(&in)
+		json.NewEncoder(w).Encode(map[string]string{
+			"method": r.Method,
+			"path":   r.URL.Path,
+			"q":      r.URL.Query().Get("q"),
+			"header": r.Header.Get("X-Test"),
+			"type":   r.Header.Get("Content-Type"),
+			"name":   in["name"],
+		})
+	}))
+	defer srv.Close()
+	c := New().WithBaseURL(srv.URL)
+
+	var out map[string]string
+	err := c.NewRequest().
+		Method("post").
+		Path("/users").
+		Query("q", "a&b").
+		Header("X-Test", "yes").
+		Body(map[string]string{"name": "ann"}).
+		Do(context.Background(), &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"method": "POST", "path": "/users", "q": "a&b", "header": "yes", "type": "application/json", "name": "ann"}
+	for k, v := range want {
+		if out[k] != v {
+			t.Errorf("%s = %q, want %q", k, out[k], v)
+		}
+	}
+
+	err = c.NewRequest().Method(http.MethodPut).Path("/raw").
+		BodyReader(strings.NewReader(`{"name":"bob"}`), "application/merge-patch+json").
+		Do(context.Background(), &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out["type"] != "application/merge-patch+json" || out["name"] != "bob" {
+		t.Errorf("raw body request = %v", out)
+	}
+}
+
+func TestRequestBuilder_Validation(t *testing.T) {
+	c := New()
+	err := c.NewRequest().Method(http.MethodGet).Body("x").Do(context.Background(), nil)
+	if err == nil {
+		t.Fatal("expected validation error")
+	}
+	for _, want := range []string{"path is required", "GET request must not have a body"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("err = %q, want it to mention %q", err, want)
+		}
+	}
+	if err := c.NewRequest().Path("/x").Do(context.Background(), nil); err == nil || !strings.Contains(err.Error(), "method is required") {
+		t.Errorf("err = %v, want method is required", err)
+	}
+}
@@ -0,0 +1,306 @@
+package httpclient
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxCachedBody is the largest response body the cache stores; larger
+// responses are passed through uncached.
+const maxCachedBody = 1 << 20
+
+// CachedResponse is a response kept by a CacheStore.
+type CachedResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+
+	// Expires is when the response stops being fresh and must be
+	// revalidated with the server before reuse.
+	Expires time.Time
+
+	// Vary holds the request header values named by the response's Vary
+	// header; the response only serves requests with the same values.
+	Vary map[string]string
+}
+
+// CacheStore stores cached responses by key. Implementations must be safe
+// for concurrent use.
+type CacheStore interface {
+	Get(key string) (*CachedResponse, bool)
+	Set(key string, resp *CachedResponse)
+	Delete(key string)
+}
+
+// MemoryCache is an in-memory CacheStore that evicts the least recently
+// used response once it holds maxEntries.
+type MemoryCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	order      *list.List
+	entries    map[string]*list.Element
+}
+
+type memoryEntry struct {
+	key  string
+	resp *CachedResponse
+}
+
+// NewMemoryCache creates a MemoryCache holding up to maxEntries responses.
+// A maxEntries of 0 or less means no limit.
+func NewMemoryCache(maxEntries int) *MemoryCache {
+	return &MemoryCache{
+		maxEntries: maxEntries,
+		order:      list.New(),
+		entries:    make(map[string]*list.Element),
+	}
+}
+
+// Get returns the response stored under key.
+func (m *MemoryCache) Get(key string) (*CachedResponse, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.entries[key]
+	if !ok {
+		return nil, false
+	}
+	m.order.MoveToFront(e)
+	return e.Value.(*memoryEntry).resp, true
+}
+
+// Set stores resp under key, evicting the least recently used response if
+// the cache is full.
+func (m *MemoryCache) Set(key string, resp *CachedResponse) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if e, ok := m.entries[key]; ok {
+		e.Value.(*memoryEntry).resp = resp
+		m.order.MoveToFront(e)
+		return
+	}
+	m.entries[key] = m.order.PushFront(&memoryEntry{key: key, resp: resp})
+	if m.maxEntries > 0 && m.order.Len() > m.maxEntries {
+		oldest := m.order.Back()
+		m.order.Remove(oldest)
+		delete(m.entries, oldest.Value.(*memoryEntry).key)
+	}
+}
+
+// Delete removes the response stored under key.
+func (m *MemoryCache) Delete(key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if e, ok := m.entries[key]; ok {
+		m.order.Remove(e)
+		delete(m.entries, key)
+	}
+}
+
+// Len returns the number of stored responses.
+func (m *MemoryCache) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.order.Len()
+}
+
+// doCached serves a GET request from the cache when the stored response is
+// fresh, revalidates it with the server when it is stale, and stores
+// cacheable responses otherwise.
+func (c *Client) doCached(ctx context.Context, req *http.Request) (*http.Response, error) {
+	c.setHeaders(req) // before matching Vary
+	key := req.URL.String()
+	url := SanitizeURL(req.URL)
+	noCache := hasDirective(req.Header.Get("Cache-Control"), "no-cache")
+
+	entry, ok := c.cache.Get(key)
+	if ok && !entry.matches(req) {
+		entry, ok = nil, false
+	}
+	if ok && !noCache && time.Now().Before(entry.Expires) {
+		c.cacheResult(ctx, "hit", url)
+		return entry.response(req), nil
+	}
+
+	if ok {
+		req = req.Clone(req.Context())
+		if etag := entry.Header.Get("ETag"); etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		if lm := entry.Header.Get("Last-Modified"); lm != "" {
+			req.Header.Set("If-Modified-Since", lm)
+		}
+	}
+
+	resp, err := c.do(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if ok && resp.StatusCode == http.StatusNotModified {
+		resp.Body.Close()
+		refreshed := *entry
+		refreshed.Header = entry.Header.Clone()
+		for _, h := range []string{"Cache-Control", "Expires", "Date", "ETag", "Age"} {
+			if v := resp.Header.Values(h); len(v) > 0 {
+				refreshed.Header[h] = v
+			}
+		}
+		refreshed.Expires = expiry(refreshed.Header, time.Now())
+		c.cache.Set(key, &refreshed)
+		c.cacheResult(ctx, "revalidated", url)
+		return refreshed.response(req), nil
+	}
+
+	c.cacheResult(ctx, "miss", url)
+	if !cacheable(resp) {
+		if ok {
+			c.cache.Delete(key)
+		}
+		return resp, nil
+	}
+	return c.store(key, req, resp)
+}
+
+// store reads the body of resp into the cache under key, unless it is too
+// large, and returns resp with its body still readable.
+func (c *Client) store(key string, req *http.Request, resp *http.Response) (*http.Response, error) {
+	if resp.ContentLength > maxCachedBody {
+		return resp, nil
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxCachedBody+1))
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	if len(body) > maxCachedBody {
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+		return resp, nil
+	}
+	resp.Body.Close()
+
+	entry := &CachedResponse{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header.Clone(),
+		Body:       body,
+		Expires:    expiry(resp.Header, time.Now()),
+	}
+	if vary := resp.Header.Values("Vary"); len(vary) > 0 {
+		entry.Vary = make(map[string]string)
+		for _, v := range vary {
+			for name := range strings.SplitSeq(v, ",") {
+				name = http.CanonicalHeaderKey(strings.TrimSpace(name))
+				entry.Vary[name] = req.Header.Get(name)
+			}
+		}
+	}
+	c.cache.Set(key, entry)
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return resp, nil
+}
+
+// cacheResult logs and counts a cache lookup outcome.
+func (c *Client) cacheResult(ctx context.Context, result, url string) {
+	if c.logger != nil {
+		c.logger.DebugContext(ctx, "http cache "+result, "url", url)
+	}
+	c.obs.Count("http_client_cache_total", "result", result)
+}
+
+// matches reports whether the request headers named by Vary are the ones
+// the response was stored for.
+func (e *CachedResponse) matches(req *http.Request) bool {
+	for name, value := range e.Vary {
+		if req.Header.Get(name) != value {
+			return false
+		}
+	}
+	return true
+}
+
+// response returns a new http.Response for req with the cached content.
+func (e *CachedResponse) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        strconv.Itoa(e.StatusCode) + " " + http.StatusText(e.StatusCode),
+		StatusCode:    e.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        e.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
+
+// cacheable reports whether resp may be stored: a 200 response without
+// no-store or Vary: *, that is fresh for a while or can be revalidated.
+func cacheable(resp *http.Response) bool {
+	if resp.StatusCode != http.StatusOK {
+		return false
+	}
+	cc := resp.Header.Get("Cache-Control")
+	if hasDirective(cc, "no-store") || resp.Header.Get("Vary") == "*" {
+		return false
+	}
+	return resp.Header.Get("ETag") != "" || resp.Header.Get("Last-Modified") != "" ||
+		expiry(resp.Header, time.Now()).After(time.Now())
+}
+
+// expiry returns when a response with header, received at now, stops being
+// fresh: max-age minus Age, else the Expires header, else now. no-cache
+// makes it stale immediately.
+func expiry(header http.Header, now time.Time) time.Time {
+	cc := header.Get("Cache-Control")
+	if hasDirective(cc, "no-cache") {
+		return now
+	}
+	if v, ok := directiveValue(cc, "max-age"); ok {
+		secs, err := strconv.Atoi(v)
+		if err != nil {
+			return now
+		}
+		age, _ := strconv.Atoi(header.Get("Age"))
+		return now.Add(time.Duration(secs-age) * time.Second)
+	}
+	if v := header.Get("Expires"); v != "" {
+		if t, err := http.ParseTime(v); err == nil {
+			if date, err := http.ParseTime(header.Get("Date")); err == nil {
+				return now.Add(t.Sub(date))
+			}
+			return t
+		}
+	}
+	return now
+}
+
+// hasDirective reports whether the Cache-Control value cc has directive.
+func hasDirective(cc, directive string) bool {
+	for d := range strings.SplitSeq(cc, ",") {
+		name, _, _ := strings.Cut(strings.TrimSpace(d), "=")
+		if strings.EqualFold(name, directive) {
+			return true
+		}
+	}
+	return false
+}
+
+// directiveValue returns the value of directive in the Cache-Control value cc.
+func directiveValue(cc, directive string) (string, bool) {
+	for d := range strings.SplitSeq(cc, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(d), "=")
+		if ok && strings.EqualFold(name, directive) {
+			return strings.Trim(value, `"`), true
+		}
+	}
+	return "", false
+}
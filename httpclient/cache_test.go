@@ -0,0 +1,103 @@
+package httpclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/en9inerd/go-pkgs/observability/observabilitytest"
+)
+
+func TestDo_Cache(t *testing.T) {
+	var requests, conditional int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch r.URL.Path {
+		case "/fresh":
+			w.Header().Set("Cache-Control", "max-age=60")
+			w.Write([]byte("fresh"))
+		case "/etag":
+			w.Header().Set("Cache-Control", "no-cache")
+			w.Header().Set("ETag", `"v1"`)
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				conditional++
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Write([]byte("tagged"))
+		case "/nostore":
+			w.Header().Set("Cache-Control", "no-store")
+			w.Write([]byte("private"))
+		}
+	}))
+	defer srv.Close()
+
+	obs, metrics, _ := observabilitytest.New()
+	c := NewWithConfig(Config{BaseURL: srv.URL, Cache: NewMemoryCache(10), Observability: obs})
+	get := func(path string) string {
+		t.Helper()
+		resp, err := c.Get(context.Background(), path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		b, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("GET %s status = %d", path, resp.StatusCode)
+		}
+		return string(b)
+	}
+
+	for range 3 {
+		if got := get("/fresh"); got != "fresh" {
+			t.Errorf("GET /fresh = %q", got)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("fresh response fetched %d times, want 1", requests)
+	}
+
+	requests = 0
+	for range 3 {
+		if got := get("/etag"); got != "tagged" {
+			t.Errorf("GET /etag = %q", got)
+		}
+	}
+	if requests != 3 || conditional != 2 {
+		t.Errorf("etag: %d requests, %d conditional, want 3 and 2", requests, conditional)
+	}
+
+	requests = 0
+	get("/nostore")
+	get("/nostore")
+	if requests != 2 {
+		t.Errorf("no-store response fetched %d times, want 2", requests)
+	}
+
+	for result, want := range map[string]float64{"hit": 2, "revalidated": 2, "miss": 4} {
+		if v := metrics.Value("http_client_cache_total", "result", result); v != want {
+			t.Errorf("http_client_cache_total{result=%s} = %v, want %v", result, v, want)
+		}
+	}
+}
+
+func TestMemoryCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	m := NewMemoryCache(2)
+	m.Set("a", &CachedResponse{})
+	m.Set("b", &CachedResponse{})
+	m.Get("a")
+	m.Set("c", &CachedResponse{})
+
+	if _, ok := m.Get("b"); ok {
+		t.Error("least recently used entry was not evicted")
+	}
+	if _, ok := m.Get("a"); !ok {
+		t.Error("recently used entry was evicted")
+	}
+	m.Delete("a")
+	if m.Len() != 1 {
+		t.Errorf("Len = %d, want 1", m.Len())
+	}
+}
@@ -6,11 +6,16 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"log/slog"
 	"maps"
 	"net/http"
+	"net/url"
+	"strconv"
 	"time"
+
+	"github.com/en9inerd/go-pkgs/observability"
+	"github.com/en9inerd/go-pkgs/ratelimit"
+	"github.com/en9inerd/go-pkgs/retry"
 )
 
 // Client wraps http.Client with additional utilities
@@ -19,21 +24,187 @@ type Client struct {
 	logger     *slog.Logger
 	baseURL    string
 	headers    map[string]string
+	obs        *observability.Config
+	retry      *retry.Strategy
+	cache      CacheStore
+
+	propagate       bool
+	maxRetryAfter   time.Duration
+	idempotencyKeys bool
+
+	limiter      ratelimit.Limiter
+	hostLimiters *HostLimiters
+	breakers     *breakers
+
+	tokenSource func(context.Context) (string, error)
+	basicAuth   *url.Userinfo
+
+	metrics Metrics
+
+	compressMin int
+
+	errorParser ErrorParser
+
+	hedgeDelay time.Duration
+	maxHedges  int
+
+	beforeRequest func(context.Context, RequestSummary)
+	afterResponse func(context.Context, ResponseSummary)
 }
 
 // Config holds client configuration
 type Config struct {
+	// Timeout limits each request, including reading the response body. A
+	// request made with the WithTimeout or WithDeadline option uses that
+	// instead; see DialTimeout to limit connecting separately.
+	// Default: 30s
 	Timeout time.Duration
+
 	BaseURL string
 	Headers map[string]string
 	Logger  *slog.Logger
+
+	// CookieJar, if set, stores cookies set by responses and sends them with
+	// later requests, for session-based APIs. See NewFileJar for a jar that
+	// survives restarts. Default: no cookies are kept.
+	CookieJar http.CookieJar
+
+	// MaxRedirects limits how many redirects a request follows before it
+	// fails with ErrTooManyRedirects. A negative value follows none: the
+	// 3xx response is returned as is. Default: 10
+	MaxRedirects int
+
+	// ForwardAuthorization keeps the Authorization header on redirects to
+	// other hosts. By default Authorization and Cookie headers are removed
+	// as soon as a redirect leaves the scheme and host of the original
+	// request, so credentials do not leak to another origin. Cookies from
+	// CookieJar follow its own domain rules either way.
+	ForwardAuthorization bool
+
+	// OnRedirect, if set, is called before each redirect is followed, with
+	// the next request and the requests made so far, oldest first. It may
+	// modify the next request's headers. Returning an error stops the
+	// request with that error; returning http.ErrUseLastResponse returns
+	// the 3xx response instead.
+	OnRedirect func(req *http.Request, via []*http.Request) error
+
+	// ProxyURL, if set, routes all requests through this proxy instead of
+	// the one from the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment.
+	ProxyURL *url.URL
+
+	// MaxIdleConns limits idle keep-alive connections across all hosts.
+	// Default: 100
+	MaxIdleConns int
+
+	// MaxIdleConnsPerHost limits idle keep-alive connections per host; raise
+	// it for clients sending many concurrent requests to one service.
+	// Default: 2
+	MaxIdleConnsPerHost int
+
+	// IdleConnTimeout is how long an idle connection is kept. Default: 90s
+	IdleConnTimeout time.Duration
+
+	// DialTimeout limits establishing a TCP connection. Default: 30s
+	DialTimeout time.Duration
+
+	// TLSHandshakeTimeout limits the TLS handshake. Default: 10s
+	TLSHandshakeTimeout time.Duration
+
+	// Observability receives request spans and metrics.
+	// Its Logger is used when Logger is nil.
+	Observability *observability.Config
+
+	// PropagateTrace adds the trace context of each request's span to its
+	// headers, e.g. as W3C traceparent, so the server continues the trace.
+	// It requires an Observability tracer implementing
+	// observability.Propagator.
+	PropagateTrace bool
+
+	// RetryStrategy, if set, retries requests that fail in transport or get
+	// a 5xx or 429 response, with the strategy's backoff. Requests are
+	// retried regardless of method, so only enable it for APIs whose
+	// non-idempotent endpoints tolerate replays. Only the strategy's
+	// backoff settings and RetryableErrors are used; attempts are reported
+	// through the client's Observability. Default: no retries.
+	RetryStrategy *retry.Strategy
+
+	// MaxRetryAfter caps how long a retry waits for a response that sets
+	// Retry-After, or an exhausted quota in its rate limit headers, in place
+	// of the strategy's backoff. Zero means no cap; the context still
+	// bounds the wait.
+	MaxRetryAfter time.Duration
+
+	// IdempotencyKeys attaches a random Idempotency-Key header to POST and
+	// PATCH requests that have none. A request keeps its key across retries
+	// and token refreshes, so an API honoring the header applies it once.
+	// See WithIdempotencyKey to choose the key per request.
+	IdempotencyKeys bool
+
+	// Cache, if set, caches GET responses following their Cache-Control,
+	// Expires, ETag and Last-Modified headers: fresh responses are served
+	// without a request, stale ones are revalidated with a conditional
+	// request. Bodies over 1 MiB are not cached. See NewMemoryCache.
+	// Default: no caching.
+	Cache CacheStore
+
+	// Limiter, if set, is waited on before every request is sent, including
+	// retries, to stay within a quota shared by all hosts. Cached responses
+	// do not consume it. Default: no limit.
+	Limiter ratelimit.Limiter
+
+	// HostLimiters, if set, is waited on before every request is sent, with
+	// a separate limiter per request host. See NewHostLimiters.
+	// Default: no limit.
+	HostLimiters *HostLimiters
+
+	// CircuitBreaker, if set, tracks failures per request host and, once
+	// too many fail, rejects requests to that host with ErrCircuitOpen until
+	// probe requests succeed. Default: no circuit breaker.
+	CircuitBreaker *BreakerConfig
+
+	// Metrics, if set, receives the method, host, route, status, outcome,
+	// latency and byte counts of every request sent. See WithRoute.
+	Metrics Metrics
+
+	// CompressionThreshold, if positive, gzips JSON request bodies of at
+	// least this many bytes, setting Content-Encoding. Only enable it for
+	// servers that accept gzip request bodies. It also requests gzip
+	// responses and decodes them, even with a transport that has automatic
+	// decompression disabled. See DefaultCompressionThreshold.
+	// Default: no compression.
+	CompressionThreshold int
+
+	// ErrorParser extracts the message and details of error responses
+	// decoded by the JSON and XML helpers, for APIs with an unusual error
+	// envelope. Default: DefaultErrorParser
+	ErrorParser ErrorParser
+
+	// HedgeDelay, if positive, enables hedged GET and HEAD requests without
+	// a body: when an attempt has not answered within HedgeDelay, or fails,
+	// another copy is sent in parallel, up to MaxHedges extra copies. The
+	// first response that is not a 5xx or 429 is used and the other copies
+	// are canceled. This trades extra upstream load for lower tail latency.
+	HedgeDelay time.Duration
+
+	// MaxHedges is the number of extra copies HedgeDelay may send.
+	// Default: 1 when HedgeDelay is set.
+	MaxHedges int
+
+	// BeforeRequest is called with a sanitized summary before each request
+	// is sent. It is intended for audit trails and is independent of Logger.
+	BeforeRequest func(ctx context.Context, summary RequestSummary)
+
+	// AfterResponse is called with a sanitized summary once the response body
+	// is closed, so ResponseBytes is final, or immediately if the request failed.
+	AfterResponse func(ctx context.Context, summary ResponseSummary)
 }
 
 // New creates a new HTTP client with default settings
 func New() *Client {
 	return &Client{
 		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout:       30 * time.Second,
+			CheckRedirect: Config{}.checkRedirect(),
 		},
 		headers: make(map[string]string),
 	}
@@ -47,15 +218,69 @@ func NewWithConfig(cfg Config) *Client {
 	if cfg.Headers == nil {
 		cfg.Headers = make(map[string]string)
 	}
+	if cfg.Logger == nil {
+		cfg.Logger = cfg.Observability.Log()
+	}
+	if cfg.HedgeDelay > 0 && cfg.MaxHedges == 0 {
+		cfg.MaxHedges = 1
+	}
+
+	var bs *breakers
+	if cfg.CircuitBreaker != nil {
+		bs = newBreakers(*cfg.CircuitBreaker)
+	}
 
 	return &Client{
 		httpClient: &http.Client{
-			Timeout: cfg.Timeout,
+			Timeout:       cfg.Timeout,
+			Transport:     cfg.transport(),
+			Jar:           cfg.CookieJar,
+			CheckRedirect: cfg.checkRedirect(),
 		},
 		baseURL: cfg.BaseURL,
 		headers: cfg.Headers,
 		logger:  cfg.Logger,
+		obs:     cfg.Observability,
+		retry:   cfg.RetryStrategy,
+		cache:   cfg.Cache,
+
+		propagate:       cfg.PropagateTrace,
+		maxRetryAfter:   cfg.MaxRetryAfter,
+		idempotencyKeys: cfg.IdempotencyKeys,
+
+		limiter:      cfg.Limiter,
+		hostLimiters: cfg.HostLimiters,
+		breakers:     bs,
+		metrics:      cfg.Metrics,
+		compressMin:  cfg.CompressionThreshold,
+		errorParser:  cfg.ErrorParser,
+		hedgeDelay:   cfg.HedgeDelay,
+		maxHedges:    cfg.MaxHedges,
+
+		beforeRequest: cfg.BeforeRequest,
+		afterResponse: cfg.AfterResponse,
+	}
+}
+
+// Clone returns a copy of the client that can be configured with the With*
+// methods without affecting c, so a base client can be specialized per
+// subsystem:
+//
+//	billing := base.Clone().WithBaseURL(billingURL).WithHeader("X-Team", "billing")
+//
+// The copy shares c's transport and connection pool, cookie jar, cache,
+// rate limiters and circuit breakers. The With* methods modify the client
+// they are called on and must not be called while it is in use; Clone
+// may be called concurrently with requests, but not with With* on c.
+func (c *Client) Clone() *Client {
+	clone := *c
+	httpClient := *c.httpClient
+	clone.httpClient = &httpClient
+	clone.headers = maps.Clone(c.headers)
+	if clone.headers == nil {
+		clone.headers = make(map[string]string)
 	}
+	return &clone
 }
 
 // WithHTTPClient sets a custom HTTP client
@@ -70,7 +295,8 @@ func (c *Client) WithTimeout(timeout time.Duration) *Client {
 	return c
 }
 
-// WithBaseURL sets the base URL for all requests
+// WithBaseURL sets the base URL for all requests. Request paths are resolved
+// under it; absolute URLs must share its scheme and host
 func (c *Client) WithBaseURL(baseURL string) *Client {
 	c.baseURL = baseURL
 	return c
@@ -100,167 +326,338 @@ func (c *Client) WithLogger(logger *slog.Logger) *Client {
 	return c
 }
 
-// buildURL constructs the full URL from baseURL and path
-func (c *Client) buildURL(path string) string {
-	if c.baseURL == "" {
-		return path
+// WithObservability sets the observability configuration.
+// Its Logger is used if no logger has been set.
+func (c *Client) WithObservability(obs *observability.Config) *Client {
+	c.obs = obs
+	if c.logger == nil {
+		c.logger = obs.Log()
 	}
-	if path == "" {
-		return c.baseURL
-	}
-	if c.baseURL[len(c.baseURL)-1] == '/' && path[0] == '/' {
-		return c.baseURL + path[1:]
-	}
-	if c.baseURL[len(c.baseURL)-1] != '/' && path[0] != '/' {
-		return c.baseURL + "/" + path
-	}
-	return c.baseURL + path
+	return c
+}
+
+// WithTracePropagation enables trace context headers, see
+// Config.PropagateTrace
+func (c *Client) WithTracePropagation() *Client {
+	c.propagate = true
+	return c
+}
+
+// WithRetry sets the retry strategy, see Config.RetryStrategy
+func (c *Client) WithRetry(strategy *retry.Strategy) *Client {
+	c.retry = strategy
+	return c
+}
+
+// WithCache sets the response cache, see Config.Cache
+func (c *Client) WithCache(store CacheStore) *Client {
+	c.cache = store
+	return c
+}
+
+// WithLimiter sets the rate limiter shared by all hosts, see Config.Limiter
+func (c *Client) WithLimiter(limiter ratelimit.Limiter) *Client {
+	c.limiter = limiter
+	return c
+}
+
+// WithHostLimiters sets the per-host rate limiters, see Config.HostLimiters
+func (c *Client) WithHostLimiters(limiters *HostLimiters) *Client {
+	c.hostLimiters = limiters
+	return c
+}
+
+// WithCircuitBreaker enables per-host circuit breakers, see
+// Config.CircuitBreaker
+func (c *Client) WithCircuitBreaker(cfg BreakerConfig) *Client {
+	c.breakers = newBreakers(cfg)
+	return c
+}
+
+// WithBeforeRequest sets the audit hook called before each request is sent
+func (c *Client) WithBeforeRequest(fn func(ctx context.Context, summary RequestSummary)) *Client {
+	c.beforeRequest = fn
+	return c
+}
+
+// WithAfterResponse sets the audit hook called when a request completes
+func (c *Client) WithAfterResponse(fn func(ctx context.Context, summary ResponseSummary)) *Client {
+	c.afterResponse = fn
+	return c
 }
 
-// setHeaders sets default headers on the request
+// setHeaders sets default headers on the request, except those the request
+// already has
 func (c *Client) setHeaders(req *http.Request) {
 	for k, v := range c.headers {
-		req.Header.Set(k, v)
+		if req.Header.Get(k) == "" {
+			req.Header.Set(k, v)
+		}
 	}
 }
 
-// Do executes an HTTP request
+// Do executes an HTTP request. With a retry strategy, requests whose body
+// can be replayed through GetBody, as for those built by Post, Put and
+// Patch, are retried; the last response is returned even if its status is
+// retryable. With a cache, GET requests may be answered from it. Requests
+// without an Authorization header get the client's credentials, if any.
+// The remaining quota a response reports in its rate limit headers can be
+// read with ratelimit.ParseHeaders.
 func (c *Client) Do(ctx context.Context, req *http.Request) (*http.Response, error) {
+	if c.cache != nil && req.Method == http.MethodGet && req.Header.Get("Range") == "" {
+		return c.doCached(ctx, req)
+	}
+	return c.do(ctx, req)
+}
+
+// do executes a request with the client's credentials and retry strategy,
+// if any.
+func (c *Client) do(ctx context.Context, req *http.Request) (*http.Response, error) {
+	req = c.withIdempotencyKey(req)
+	if c.authenticates(req) {
+		return c.doAuth(ctx, req)
+	}
+	return c.dispatch(ctx, req)
+}
+
+// dispatch executes a request with the client's retry strategy, if any.
+func (c *Client) dispatch(ctx context.Context, req *http.Request) (*http.Response, error) {
+	if c.retry == nil || !replayable(req) {
+		return c.attempt(ctx, req, 1)
+	}
+	return c.doRetry(ctx, req)
+}
+
+// send executes a single attempt of a request.
+func (c *Client) send(ctx context.Context, req *http.Request, attempt int) (*http.Response, error) {
+	var b *breaker
+	if c.breakers != nil {
+		b = c.breakers.get(req.URL.Host)
+		if err := b.allow(); err != nil {
+			return nil, fmt.Errorf("%s: %w", req.URL.Host, err)
+		}
+	}
+	if err := c.wait(ctx, req); err != nil {
+		if b != nil {
+			b.done(false, false)
+		}
+		return nil, err
+	}
 	req = req.WithContext(ctx)
 	c.setHeaders(req)
+	if c.compressMin > 0 {
+		acceptGzip(req)
+	}
 
 	if c.logger != nil {
 		c.logger.Debug("making http request", "method", req.Method, "url", req.URL.String())
 	}
 
-	resp, err := c.httpClient.Do(req)
+	ctx, span := c.obs.StartSpan(ctx, "httpclient.request",
+		slog.String("http.method", req.Method),
+		slog.String("http.url", req.URL.String()),
+	)
+	defer span.End()
+	req = req.WithContext(ctx)
+	if c.propagate {
+		// the header differs per attempt; keep it off the caller's request
+		req.Header = req.Header.Clone()
+		c.obs.Inject(ctx, req.Header)
+	}
+
+	summary := summarize(req, attempt)
+	if c.beforeRequest != nil {
+		c.beforeRequest(ctx, summary)
+	}
+
+	start := time.Now()
+	httpClient := c.httpClient
+	if ctx.Value(timeoutKey{}) != nil && httpClient.Timeout > 0 {
+		// the request deadline replaces the client timeout
+		hc := *httpClient
+		hc.Timeout = 0
+		httpClient = &hc
+	}
+	resp, err := httpClient.Do(req)
+	elapsed := time.Since(start)
+	if b != nil {
+		b.done(c.breakers.cfg.IsFailure(resp, err), ctx.Err() == nil)
+	}
+	status := "error"
+	if err == nil {
+		status = strconv.Itoa(resp.StatusCode)
+		span.SetAttributes(slog.Int("http.status_code", resp.StatusCode))
+	}
+	span.SetAttributes(slog.Duration("http.duration", elapsed))
+	c.obs.Count("http_client_requests_total", "method", req.Method, "status", status)
+	c.obs.ObserveDuration("http_client_request_duration_seconds", elapsed, "method", req.Method)
+
+	complete := func(ctx context.Context, s ResponseSummary) {
+		if c.afterResponse != nil {
+			c.afterResponse(ctx, s)
+		}
+		if c.metrics != nil {
+			c.metrics.ObserveRequest(ctx, requestMetrics(req, s, err))
+		}
+	}
+
+	if err == nil && c.compressMin > 0 {
+		decodeGzip(resp)
+	}
+
 	if err != nil {
+		span.RecordError(err)
+		complete(ctx, ResponseSummary{RequestSummary: summary, Duration: elapsed, Error: err.Error()})
 		return nil, fmt.Errorf("http request failed: %w", err)
 	}
 
+	if c.afterResponse != nil || c.metrics != nil {
+		resp.Body = &auditBody{
+			ReadCloser: resp.Body,
+			ctx:        ctx,
+			summary:    ResponseSummary{RequestSummary: summary, StatusCode: resp.StatusCode, Duration: elapsed},
+			hook:       complete,
+		}
+	}
+
 	return resp, nil
 }
 
 // Get performs a GET request
-func (c *Client) Get(ctx context.Context, path string) (*http.Response, error) {
-	url := c.buildURL(path)
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+func (c *Client) Get(ctx context.Context, path string, opts ...RequestOption) (*http.Response, error) {
+	return c.request(ctx, http.MethodGet, path, nil, "", opts)
+}
+
+// GetWithQuery performs a GET request with query added to the query string
+// of path, properly escaped
+func (c *Client) GetWithQuery(ctx context.Context, path string, query url.Values, opts ...RequestOption) (*http.Response, error) {
+	return c.Get(ctx, path, append(opts, WithQuery(query))...)
+}
+
+// addQuery returns path with query merged into its query string
+func addQuery(path string, query url.Values) (string, error) {
+	if len(query) == 0 {
+		return path, nil
+	}
+	u, err := url.Parse(path)
 	if err != nil {
-		return nil, fmt.Errorf("create request: %w", err)
+		return "", fmt.Errorf("parse path: %w", err)
 	}
-	return c.Do(ctx, req)
+	q := u.Query()
+	for k, vs := range query {
+		for _, v := range vs {
+			q.Add(k, v)
+		}
+	}
+	u.RawQuery = q.Encode()
+	return u.String(), nil
 }
 
 // Post performs a POST request with JSON body
-func (c *Client) Post(ctx context.Context, path string, body any) (*http.Response, error) {
-	return c.postPutPatch(ctx, http.MethodPost, path, body)
+func (c *Client) Post(ctx context.Context, path string, body any, opts ...RequestOption) (*http.Response, error) {
+	return c.postPutPatch(ctx, http.MethodPost, path, body, opts)
 }
 
 // Put performs a PUT request with JSON body
-func (c *Client) Put(ctx context.Context, path string, body any) (*http.Response, error) {
-	return c.postPutPatch(ctx, http.MethodPut, path, body)
+func (c *Client) Put(ctx context.Context, path string, body any, opts ...RequestOption) (*http.Response, error) {
+	return c.postPutPatch(ctx, http.MethodPut, path, body, opts)
 }
 
 // Patch performs a PATCH request with JSON body
-func (c *Client) Patch(ctx context.Context, path string, body any) (*http.Response, error) {
-	return c.postPutPatch(ctx, http.MethodPatch, path, body)
+func (c *Client) Patch(ctx context.Context, path string, body any, opts ...RequestOption) (*http.Response, error) {
+	return c.postPutPatch(ctx, http.MethodPatch, path, body, opts)
 }
 
 // postPutPatch is a helper for POST, PUT, and PATCH requests
-func (c *Client) postPutPatch(ctx context.Context, method, path string, body any) (*http.Response, error) {
-	url := c.buildURL(path)
-
-	var bodyReader io.Reader
-	if body != nil {
-		jsonData, err := json.Marshal(body)
-		if err != nil {
-			return nil, fmt.Errorf("marshal json: %w", err)
-		}
-		bodyReader = bytes.NewBuffer(jsonData)
+func (c *Client) postPutPatch(ctx context.Context, method, path string, body any, opts []RequestOption) (*http.Response, error) {
+	if body == nil {
+		return c.request(ctx, method, path, nil, "", opts)
 	}
-
-	req, err := http.NewRequestWithContext(ctx, method, url, bodyReader)
+	jsonData, err := json.Marshal(body)
 	if err != nil {
-		return nil, fmt.Errorf("create request: %w", err)
+		return nil, fmt.Errorf("marshal json: %w", err)
 	}
-
-	if body != nil {
-		req.Header.Set("Content-Type", "application/json")
+	jsonData, gzipped, err := c.compressJSON(jsonData)
+	if err != nil {
+		return nil, err
 	}
-
-	return c.Do(ctx, req)
+	if gzipped {
+		opts = append([]RequestOption{WithHeader("Content-Encoding", "gzip")}, opts...)
+	}
+	return c.request(ctx, method, path, bytes.NewBuffer(jsonData), "application/json", opts)
 }
 
 // Delete performs a DELETE request
-func (c *Client) Delete(ctx context.Context, path string) (*http.Response, error) {
-	url := c.buildURL(path)
-	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("create request: %w", err)
-	}
-	return c.Do(ctx, req)
+func (c *Client) Delete(ctx context.Context, path string, opts ...RequestOption) (*http.Response, error) {
+	return c.request(ctx, http.MethodDelete, path, nil, "", opts)
 }
 
 // GetJSON performs a GET request and decodes the JSON response
-func (c *Client) GetJSON(ctx context.Context, path string, target any) error {
-	resp, err := c.Get(ctx, path)
+func (c *Client) GetJSON(ctx context.Context, path string, target any, opts ...RequestOption) error {
+	resp, err := c.Get(ctx, path, opts...)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 
-	return DecodeJSONResponse(resp, target)
+	return c.decodeJSON(resp, target)
+}
+
+// GetJSONWithQuery performs a GET request with query parameters and decodes
+// the JSON response
+func (c *Client) GetJSONWithQuery(ctx context.Context, path string, query url.Values, target any, opts ...RequestOption) error {
+	return c.GetJSON(ctx, path, target, append(opts, WithQuery(query))...)
 }
 
 // PostJSON performs a POST request with JSON body and decodes the JSON response
-func (c *Client) PostJSON(ctx context.Context, path string, body any, target any) error {
-	resp, err := c.Post(ctx, path, body)
+func (c *Client) PostJSON(ctx context.Context, path string, body any, target any, opts ...RequestOption) error {
+	resp, err := c.Post(ctx, path, body, opts...)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 
-	return DecodeJSONResponse(resp, target)
+	return c.decodeJSON(resp, target)
 }
 
 // PutJSON performs a PUT request with JSON body and decodes the JSON response
-func (c *Client) PutJSON(ctx context.Context, path string, body any, target any) error {
-	resp, err := c.Put(ctx, path, body)
+func (c *Client) PutJSON(ctx context.Context, path string, body any, target any, opts ...RequestOption) error {
+	resp, err := c.Put(ctx, path, body, opts...)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 
-	return DecodeJSONResponse(resp, target)
+	return c.decodeJSON(resp, target)
 }
 
 // PatchJSON performs a PATCH request with JSON body and decodes the JSON response
-func (c *Client) PatchJSON(ctx context.Context, path string, body any, target any) error {
-	resp, err := c.Patch(ctx, path, body)
+func (c *Client) PatchJSON(ctx context.Context, path string, body any, target any, opts ...RequestOption) error {
+	resp, err := c.Patch(ctx, path, body, opts...)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 
-	return DecodeJSONResponse(resp, target)
+	return c.decodeJSON(resp, target)
 }
 
 // DeleteJSON performs a DELETE request and decodes the JSON response
-func (c *Client) DeleteJSON(ctx context.Context, path string, target any) error {
-	resp, err := c.Delete(ctx, path)
+func (c *Client) DeleteJSON(ctx context.Context, path string, target any, opts ...RequestOption) error {
+	resp, err := c.Delete(ctx, path, opts...)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 
-	return DecodeJSONResponse(resp, target)
+	return c.decodeJSON(resp, target)
 }
 
-// DecodeJSONResponse decodes a JSON response from an HTTP response
+// DecodeJSONResponse decodes a JSON response from an HTTP response. A non-2xx
+// response is returned as an *httperrors.APIError, see ErrorFromResponse.
 func DecodeJSONResponse(resp *http.Response, target any) error {
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("http error %d: %s", resp.StatusCode, string(body))
+		return ErrorFromResponse(resp, nil)
 	}
 
 	if target == nil {
@@ -3,11 +3,22 @@ package httpclient
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"testing/iotest"
 	"time"
+
+	"github.com/en9inerd/go-pkgs/httpclient/httpclienttest"
+	"github.com/en9inerd/go-pkgs/observability/observabilitytest"
+	"github.com/en9inerd/go-pkgs/ratelimit"
+	"github.com/en9inerd/go-pkgs/retry"
 )
 
 func TestNew_Defaults(t *testing.T) {
@@ -54,16 +65,47 @@ func TestBuildURL(t *testing.T) {
 		{"https://example.com/", "/api", "https://example.com/api"},
 		{"https://example.com", "api", "https://example.com/api"},
 		{"https://example.com/", "api", "https://example.com/api"},
+		{"https://example.com/v1", "/users", "https://example.com/v1/users"},
+		{"https://example.com/v1/", "users/", "https://example.com/v1/users/"},
+		{"https://example.com/v1?key=k", "/users?page=2", "https://example.com/v1/users?key=k&page=2"},
+		{"https://example.com/v1", "a/../b", "https://example.com/v1/a/../b"},
+		{"https://example.com/v1", "/files/a%2Fb", "https://example.com/v1/files/a%2Fb"},
+		{"https://example.com/v1", "https://example.com/x", "https://example.com/x"},
+		{"https://Example.com/v1", "https://example.COM/x?a=1", "https://example.COM/x?a=1"},
 	}
 	for _, tt := range tests {
 		c := &Client{baseURL: tt.base}
-		got := c.buildURL(tt.path)
-		if got != tt.want {
-			t.Errorf("buildURL(%q, %q) = %q, want %q", tt.base, tt.path, got, tt.want)
+		got, err := c.buildURL(tt.path)
+		if err != nil || got != tt.want {
+			t.Errorf("buildURL(%q, %q) = %q, %v; want %q", tt.base, tt.path, got, err, tt.want)
+		}
+	}
+
+	for _, path := range []string{"../admin", "/v2/../../admin", "/%2e%2e/admin", "//evil.example/x",
+		"https://evil.example/x", "http://example.com/v1/x", "https://example.com:8443/v1/x"} {
+		c := &Client{baseURL: "https://example.com/v1"}
+		if got, err := c.buildURL(path); err == nil {
+			t.Errorf("buildURL(%q) = %q, want an error", path, got)
 		}
 	}
 }
 
+func TestDo_AbsoluteURLOtherOrigin(t *testing.T) {
+	var hits atomic.Int32
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+	}))
+	defer other.Close()
+
+	c := New().WithBaseURL("https://api.example.com").WithBasicAuth("user", "secret")
+	if _, err := c.Get(context.Background(), other.URL+"/collect"); err == nil {
+		t.Error("request to another origin succeeded")
+	}
+	if hits.Load() != 0 {
+		t.Error("credentials sent to another origin")
+	}
+}
+
 func TestGetJSON(t *testing.T) {
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
@@ -84,6 +126,24 @@ func TestGetJSON(t *testing.T) {
 	}
 }
 
+func TestGetJSONWithQuery(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(r.URL.Query())
+	}))
+	defer srv.Close()
+
+	c := New().WithBaseURL(srv.URL)
+	var got url.Values
+	query := url.Values{"q": {"a&b=c d"}, "tag": {"x", "y"}}
+	if err := c.GetJSONWithQuery(context.Background(), "/search?page=2", query, &got); err != nil {
+		t.Fatal(err)
+	}
+	want := url.Values{"q": {"a&b=c d"}, "tag": {"x", "y"}, "page": {"2"}}
+	if got.Encode() != want.Encode() {
+		t.Errorf("query = %v, want %v", got, want)
+	}
+}
+
 func TestPostJSON(t *testing.T) {
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
@@ -233,3 +293,510 @@ func TestPost_NilBody(t *testing.T) {
 	}
 	resp.Body.Close()
 }
+
+func TestDo_Observability(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	defer srv.Close()
+
+	obs, metrics, tracer := observabilitytest.New()
+	c := NewWithConfig(Config{BaseURL: srv.URL, Observability: obs})
+
+	resp, err := c.Get(context.Background(), "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if v := metrics.Value("http_client_requests_total", "method", "GET", "status", "418"); v != 1 {
+		t.Errorf("http_client_requests_total = %v, want 1", v)
+	}
+	if s := metrics.Samples("http_client_request_duration_seconds", "method", "GET"); len(s) != 1 {
+		t.Errorf("expected one duration sample, got %d", len(s))
+	}
+	spans := tracer.Spans("httpclient.request")
+	if len(spans) != 1 || !spans[0].Ended {
+		t.Fatalf("expected one ended span, got %d", len(spans))
+	}
+	if v, ok := spans[0].Attr("http.status_code"); !ok || v.Int64() != 418 {
+		t.Errorf("http.status_code = %v", v)
+	}
+}
+
+func TestDo_AuditHooks(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hello"))
+	}))
+	defer srv.Close()
+
+	var before []RequestSummary
+	var after []ResponseSummary
+	c := New().
+		WithBeforeRequest(func(_ context.Context, s RequestSummary) { before = append(before, s) }).
+		WithAfterResponse(func(_ context.Context, s ResponseSummary) { after = append(after, s) })
+
+	u := strings.Replace(srv.URL, "http://", "http://user:secret@", 1) + "/items?token=abc"
+	resp, err := c.Post(context.Background(), u, map[string]string{"a": "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(after) != 0 {
+		t.Fatal("AfterResponse called before the body was closed")
+	}
+	io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body.Close()
+
+	if len(before) != 1 || len(after) != 1 {
+		t.Fatalf("hooks called %d/%d times, want 1/1", len(before), len(after))
+	}
+	want := srv.URL + "/items?token=REDACTED"
+	if before[0].URL != want || before[0].Method != http.MethodPost || before[0].Attempt != 1 || before[0].RequestBytes != 9 {
+		t.Errorf("before = %+v", before[0])
+	}
+	got := after[0]
+	if got.URL != want || got.StatusCode != http.StatusOK || got.ResponseBytes != 5 || got.Error != "" || got.Duration <= 0 {
+		t.Errorf("after = %+v", got)
+	}
+}
+
+func TestDo_AuditHooksTransportError(t *testing.T) {
+	var after []ResponseSummary
+	c := NewWithConfig(Config{
+		AfterResponse: func(_ context.Context, s ResponseSummary) { after = append(after, s) },
+	})
+
+	if _, err := c.Get(context.Background(), "http://127.0.0.1:1/"); err == nil {
+		t.Fatal("expected error")
+	}
+	if len(after) != 1 || after[0].StatusCode != 0 || after[0].Error == "" {
+		t.Errorf("after = %+v", after)
+	}
+}
+
+func TestSanitizeURL(t *testing.T) {
+	u, _ := url.Parse("https://u:p@example.com/a/b?key=secret&key=other&page=2#frag")
+	if got, want := SanitizeURL(u), "https://example.com/a/b?key=REDACTED&key=REDACTED&page=REDACTED#frag"; got != want {
+		t.Errorf("SanitizeURL = %q, want %q", got, want)
+	}
+	if u.User == nil {
+		t.Error("SanitizeURL modified its argument")
+	}
+}
+
+func TestDo_Retry(t *testing.T) {
+	var bodies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(b))
+		if len(bodies) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer srv.Close()
+
+	var attempts []int
+	strategy := &retry.Strategy{MaxAttempts: 3, InitialDelay: time.Millisecond, MaxDelay: time.Millisecond, Multiplier: 1, RetryableErrors: retry.IsRetryableError}
+	c := NewWithConfig(Config{
+		BaseURL:       srv.URL,
+		RetryStrategy: strategy,
+		BeforeRequest: func(_ context.Context, s RequestSummary) { attempts = append(attempts, s.Attempt) },
+	})
+
+	var out struct{ OK bool }
+	if err := c.PostJSON(context.Background(), "/items", map[string]int{"n": 1}, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !out.OK || len(bodies) != 3 {
+		t.Fatalf("ok = %v after %d attempts, want success on the 3rd", out.OK, len(bodies))
+	}
+	for i, b := range bodies {
+		if b != `{"n":1}` {
+			t.Errorf("attempt %d body = %q, want the replayed JSON body", i+1, b)
+		}
+	}
+	if len(attempts) != 3 || attempts[2] != 3 {
+		t.Errorf("audited attempts = %v, want [1 2 3]", attempts)
+	}
+
+	// when attempts run out the last response is returned
+	bodies = nil
+	c.WithRetry(&retry.Strategy{MaxAttempts: 2, InitialDelay: time.Millisecond, MaxDelay: time.Millisecond, Multiplier: 1, RetryableErrors: retry.IsRetryableError})
+	resp, err := c.Get(context.Background(), "/items")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable || len(bodies) != 2 {
+		t.Errorf("status = %d after %d attempts, want 503 after 2", resp.StatusCode, len(bodies))
+	}
+}
+
+func TestPostMultipartJSON(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength != -1 {
+			t.Errorf("ContentLength = %d, want a streamed body", r.ContentLength)
+		}
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		f, fh, err := r.FormFile("doc")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		content, _ := io.ReadAll(f)
+		json.NewEncoder(w).Encode(map[string]string{
+			"title":    r.FormValue("title"),
+			"filename": fh.Filename,
+			"type":     fh.Header.Get("Content-Type"),
+			"content":  string(content),
+		})
+	}))
+	defer srv.Close()
+
+	c := New().WithBaseURL(srv.URL)
+	var got map[string]string
+	err := c.PostMultipartJSON(context.Background(), "/upload",
+		map[string]string{"title": "report"},
+		[]FileField{{FieldName: "doc", FileName: "a.txt", ContentType: "text/plain", Reader: strings.NewReader("hello")}},
+		&got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"title": "report", "filename": "a.txt", "type": "text/plain", "content": "hello"}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s = %q, want %q", k, got[k], v)
+		}
+	}
+
+	_, err = c.PostMultipart(context.Background(), "/upload", nil,
+		[]FileField{{FieldName: "doc", FileName: "b.txt", Reader: iotest.ErrReader(io.ErrUnexpectedEOF)}})
+	if err == nil {
+		t.Error("a failing file reader did not fail the request")
+	}
+}
+
+func TestRequestOptions(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+			return
+		}
+		user, pass, _ := r.BasicAuth()
+		json.NewEncoder(w).Encode(map[string]string{
+			"x-custom": r.Header.Get("X-Custom"),
+			"x-shared": r.Header.Get("X-Shared"),
+			"q":        r.URL.Query().Get("q"),
+			"auth":     user + ":" + pass,
+		})
+	}))
+	defer srv.Close()
+
+	c := New().WithBaseURL(srv.URL).WithHeader("X-Custom", "default").WithHeader("X-Shared", "shared")
+	var got map[string]string
+	err := c.GetJSON(context.Background(), "/echo", &got,
+		WithHeader("X-Custom", "per-call"),
+		WithQuery(url.Values{"q": {"a b&c"}}),
+		WithBasicAuth("user", "pa:ss"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"x-custom": "per-call", "x-shared": "shared", "q": "a b&c", "auth": "user:pa:ss"}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s = %q, want %q", k, got[k], v)
+		}
+	}
+
+	// options do not leak into later calls
+	if err := c.GetJSON(context.Background(), "/echo", &got); err != nil {
+		t.Fatal(err)
+	}
+	if got["x-custom"] != "default" || got["q"] != "" || got["auth"] != ":" {
+		t.Errorf("second call = %v, want client defaults only", got)
+	}
+
+	start := time.Now()
+	if _, err := c.Get(context.Background(), "/slow", WithTimeout(20*time.Millisecond)); err == nil {
+		t.Error("request exceeding WithTimeout succeeded")
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("WithTimeout(20ms) request took %v", elapsed)
+	}
+}
+
+func TestDo_RetryHonorsRateLimitHeaders(t *testing.T) {
+	var times []time.Time
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		times = append(times, time.Now())
+		switch len(times) {
+		case 1:
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 2:
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.Header().Set("X-RateLimit-Reset", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			w.Header().Set("RateLimit-Limit", "10")
+			w.Header().Set("RateLimit-Remaining", "9")
+		}
+	}))
+	defer srv.Close()
+
+	// the backoff alone would retry after a millisecond
+	c := NewWithConfig(Config{
+		BaseURL:       srv.URL,
+		RetryStrategy: &retry.Strategy{MaxAttempts: 3, InitialDelay: time.Millisecond, MaxDelay: time.Millisecond, Multiplier: 1},
+		MaxRetryAfter: 50 * time.Millisecond,
+	})
+	resp, err := c.Get(context.Background(), "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if len(times) != 3 {
+		t.Fatalf("attempts = %d, want 3", len(times))
+	}
+	for i := 1; i < len(times); i++ {
+		if d := times[i].Sub(times[i-1]); d < 40*time.Millisecond || d > 500*time.Millisecond {
+			t.Errorf("delay before attempt %d = %v, want ~50ms (the capped server delay)", i+1, d)
+		}
+	}
+
+	q, ok := ratelimit.ParseHeaders(resp.Header)
+	if !ok || q.Limit != 10 || q.Remaining != 9 {
+		t.Errorf("quota = %+v, %v; want limit 10, remaining 9", q, ok)
+	}
+}
+
+func TestWithTimeout_ReplacesClientTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(100 * time.Millisecond):
+			w.Write([]byte("report"))
+		}
+	}))
+	defer srv.Close()
+
+	c := NewWithConfig(Config{BaseURL: srv.URL, Timeout: 20 * time.Millisecond})
+	if _, err := c.Get(context.Background(), "/"); err == nil {
+		t.Fatal("request exceeding the client timeout succeeded")
+	}
+
+	for _, opt := range []RequestOption{WithTimeout(time.Second), WithDeadline(time.Now().Add(time.Second))} {
+		resp, err := c.Get(context.Background(), "/", opt)
+		if err != nil {
+			t.Fatalf("request with a longer per-request timeout: %v", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if string(body) != "report" {
+			t.Errorf("body = %q", body)
+		}
+	}
+}
+
+func TestClone(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Header.Get("X-Team")))
+	}))
+	defer srv.Close()
+
+	base := New().WithBaseURL(srv.URL).WithHeader("X-Team", "base").WithTimeout(5 * time.Second)
+
+	var wg sync.WaitGroup
+	for _, team := range []string{"billing", "search", "auth"} {
+		wg.Go(func() {
+			c := base.Clone().WithHeader("X-Team", team).WithTimeout(time.Second)
+			resp, err := c.Get(context.Background(), "/")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer resp.Body.Close()
+			if got, _ := io.ReadAll(resp.Body); string(got) != team {
+				t.Errorf("header = %q, want %q", got, team)
+			}
+		})
+		// the base client stays usable alongside its clones
+		resp, err := base.Get(context.Background(), "/")
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if string(got) != "base" {
+			t.Errorf("base header = %q, want %q", got, "base")
+		}
+	}
+	wg.Wait()
+
+	if base.httpClient.Timeout != 5*time.Second || base.headers["X-Team"] != "base" {
+		t.Errorf("base client modified: timeout %v, headers %v", base.httpClient.Timeout, base.headers)
+	}
+}
+
+func TestIdempotencyKeys(t *testing.T) {
+	var keys []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get(IdempotencyKeyHeader))
+		if len(keys) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	c := NewWithConfig(Config{
+		BaseURL:         srv.URL,
+		IdempotencyKeys: true,
+		RetryStrategy:   &retry.Strategy{MaxAttempts: 2, InitialDelay: time.Millisecond, MaxDelay: time.Millisecond, Multiplier: 1},
+	})
+	post := func(opts ...RequestOption) {
+		t.Helper()
+		resp, err := c.Post(context.Background(), "/charges", map[string]int{"amount": 100}, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+
+	post()
+	if len(keys) != 2 || keys[0] == "" || keys[0] != keys[1] {
+		t.Fatalf("keys = %q, want one key reused by the retry", keys)
+	}
+	post()
+	if keys[2] == "" || keys[2] == keys[0] {
+		t.Errorf("second request key = %q, want a new key", keys[2])
+	}
+	post(WithIdempotencyKey("order-42"))
+	if keys[3] != "order-42" {
+		t.Errorf("explicit key = %q, want order-42", keys[3])
+	}
+
+	resp, err := c.Get(context.Background(), "/charges")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if keys[4] != "" {
+		t.Errorf("GET key = %q, want none", keys[4])
+	}
+}
+
+func TestWithPathParam(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.URL.EscapedPath()))
+	}))
+	defer srv.Close()
+
+	var route string
+	c := New().WithBaseURL(srv.URL + "/api").WithMetrics(MetricsFunc(func(_ context.Context, m RequestMetrics) {
+		route = m.Route
+	}))
+	resp, err := c.Get(context.Background(), "/users/{id}/files/{name}?v=1",
+		WithPathParam("id", "42"), WithPathParam("name", "a b/../c"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if want := "/api/users/42/files/a%20b%2F..%2Fc"; string(got) != want {
+		t.Errorf("path = %q, want %q", got, want)
+	}
+	if route != "/users/{id}/files/{name}" {
+		t.Errorf("route = %q, want the template", route)
+	}
+
+	if _, err := c.Get(context.Background(), "/users/{id}", WithPathParam("other", "x")); err == nil {
+		t.Error("expected error for a missing path parameter")
+	}
+}
+
+func TestDo_TracePropagation(t *testing.T) {
+	var traceparent string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("Traceparent")
+	}))
+	defer srv.Close()
+
+	obs, _, tracer := observabilitytest.New()
+	c := NewWithConfig(Config{BaseURL: srv.URL, Observability: obs})
+	resp, err := c.Get(context.Background(), "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if traceparent != "" {
+		t.Errorf("traceparent = %q without PropagateTrace, want none", traceparent)
+	}
+
+	c.WithTracePropagation()
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	resp, err = c.Do(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	spans := tracer.Spans("httpclient.request")
+	span := spans[len(spans)-1]
+	if want := observabilitytest.TraceParent(span.ID); traceparent != want {
+		t.Errorf("traceparent = %q, want %q of the request span", traceparent, want)
+	}
+	if req.Header.Get("Traceparent") != "" {
+		t.Error("trace header leaked into the caller's request")
+	}
+	if _, ok := span.Attr("http.duration"); !ok {
+		t.Error("span has no http.duration attribute")
+	}
+}
+
+func TestWithHTTPClient_MockTransport(t *testing.T) {
+	tr := httpclienttest.NewTransport()
+	tr.Expect(http.MethodGet, "/users/1").RespondJSON(http.StatusOK, map[string]int{"id": 1})
+	created := tr.Expect(http.MethodPost, "/users").Respond(http.StatusCreated, `{"id":2}`).Times(1)
+	c := New().WithBaseURL("https://api.test").WithHTTPClient(tr.Client())
+
+	var user struct{ ID int }
+	if err := c.GetJSON(context.Background(), "/users/1", &user); err != nil || user.ID != 1 {
+		t.Fatalf("GetJSON = %+v, %v", user, err)
+	}
+	if err := c.PostJSON(context.Background(), "/users", map[string]string{"name": "b"}, &user); err != nil || user.ID != 2 {
+		t.Fatalf("PostJSON = %+v, %v", user, err)
+	}
+	if b := created.Bodies(); len(b) != 1 || b[0] != `{"name":"b"}` {
+		t.Errorf("POST bodies = %q", b)
+	}
+	if _, err := c.Get(context.Background(), "/missing"); err == nil {
+		t.Error("unexpected request succeeded")
+	}
+
+	rec := &errorRecorder{TB: t}
+	tr.AssertExpectations(rec)
+	if len(rec.errors) != 1 || !strings.Contains(rec.errors[0], "GET /missing") {
+		t.Errorf("AssertExpectations reported %q, want the unexpected request", rec.errors)
+	}
+}
+
+// errorRecorder records the errors reported on it instead of failing TB.
+type errorRecorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *errorRecorder) Helper() {}
+
+func (r *errorRecorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
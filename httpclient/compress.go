@@ -0,0 +1,84 @@
+package httpclient
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// DefaultCompressionThreshold is the request body size from which
+// WithCompression gzips JSON bodies. Smaller bodies gain too little to be
+// worth the CPU.
+const DefaultCompressionThreshold = 1024
+
+// WithCompression gzips JSON request bodies of at least
+// DefaultCompressionThreshold bytes and decodes gzip responses, see
+// Config.CompressionThreshold
+func (c *Client) WithCompression() *Client {
+	c.compressMin = DefaultCompressionThreshold
+	return c
+}
+
+// compressJSON returns data gzipped if compression is enabled and data is
+// large enough, and whether it did.
+func (c *Client) compressJSON(data []byte) ([]byte, bool, error) {
+	if c.compressMin <= 0 || len(data) < c.compressMin {
+		return data, false, nil
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return nil, false, fmt.Errorf("gzip body: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, false, fmt.Errorf("gzip body: %w", err)
+	}
+	return buf.Bytes(), true, nil
+}
+
+// acceptGzip asks for a gzip response on behalf of the transport, so
+// responses are decoded the same way whether or not the transport decodes
+// them itself. Range requests are left alone, as the transport does.
+func acceptGzip(req *http.Request) {
+	if req.Header.Get("Accept-Encoding") == "" && req.Header.Get("Range") == "" && req.Method != http.MethodHead {
+		req.Header.Set("Accept-Encoding", "gzip")
+	}
+}
+
+// decodeGzip replaces a gzip-encoded body of resp, left encoded by the
+// transport, with the decoded one.
+func decodeGzip(resp *http.Response) {
+	if resp.Uncompressed || !strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		return
+	}
+	resp.Body = &gzipBody{body: resp.Body}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+}
+
+// gzipBody decodes a gzip body, reading the gzip header on first Read so an
+// empty body is only an error if read.
+type gzipBody struct {
+	body io.ReadCloser
+	zr   *gzip.Reader
+	err  error
+}
+
+func (b *gzipBody) Read(p []byte) (int, error) {
+	if b.zr == nil && b.err == nil {
+		b.zr, b.err = gzip.NewReader(b.body)
+	}
+	if b.err != nil {
+		return 0, b.err
+	}
+	return b.zr.Read(p)
+}
+
+func (b *gzipBody) Close() error {
+	return b.body.Close()
+}
@@ -0,0 +1,81 @@
+package httpclient
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWithCompression(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := r.Body
+		encoding := r.Header.Get("Content-Encoding")
+		if encoding == "gzip" {
+			zr, err := gzip.NewReader(r.Body)
+			if err != nil {
+				t.Errorf("request body is not gzip: %v", err)
+				return
+			}
+			body = zr
+		}
+		var in map[string]string
+		if err := json.NewDecoder(body).Decode(&in); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+
+		out := map[string]any{"encoding": encoding, "size": len(in["data"])}
+		if r.Header.Get("Accept-Encoding") == "gzip" {
+			w.Header().Set("Content-Encoding", "gzip")
+			zw := gzip.NewWriter(w)
+			defer zw.Close()
+			json.NewEncoder(zw).Encode(out)
+			return
+		}
+		json.NewEncoder(w).Encode(out)
+	}))
+	defer srv.Close()
+
+	// a transport that leaves responses encoded
+	c := New().WithBaseURL(srv.URL).WithCompression().WithHTTPClient(&http.Client{
+		Transport: &http.Transport{DisableCompression: true},
+	})
+
+	for _, tc := range []struct {
+		size     int
+		encoding string
+	}{
+		{10, ""},
+		{4 * DefaultCompressionThreshold, "gzip"},
+	} {
+		var got struct {
+			Encoding string `json:"encoding"`
+			Size     int    `json:"size"`
+		}
+		err := c.PostJSON(context.Background(), "/", map[string]string{"data": strings.Repeat("x", tc.size)}, &got)
+		if err != nil {
+			t.Fatalf("size %d: %v", tc.size, err)
+		}
+		if got.Encoding != tc.encoding || got.Size != tc.size {
+			t.Errorf("size %d: got %+v, want encoding %q", tc.size, got, tc.encoding)
+		}
+	}
+}
+
+func TestDecodeGzip_EmptyBody(t *testing.T) {
+	resp := &http.Response{
+		Header: http.Header{"Content-Encoding": {"gzip"}},
+		Body:   io.NopCloser(strings.NewReader("")),
+	}
+	decodeGzip(resp)
+	if resp.Header.Get("Content-Encoding") != "" || !resp.Uncompressed {
+		t.Errorf("response not marked decoded: %+v", resp)
+	}
+	if err := resp.Body.Close(); err != nil {
+		t.Errorf("Close of an unread empty body = %v", err)
+	}
+}
@@ -0,0 +1,156 @@
+package httpclient
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// WithCookieJar sets the cookie jar that stores cookies from responses and
+// sends them with later requests, see NewFileJar
+func (c *Client) WithCookieJar(jar http.CookieJar) *Client {
+	c.httpClient.Jar = jar
+	return c
+}
+
+// FileJar is a cookie jar persisted to a JSON file, so sessions survive
+// restarts. It keeps the cookies in a net/http/cookiejar.Jar and writes the
+// file each time a response sets cookies. Session cookies, without Expires
+// or Max-Age, are persisted as well. It is safe for concurrent use.
+type FileJar struct {
+	path string
+	jar  *cookiejar.Jar
+
+	mu      sync.Mutex
+	entries map[string]jarEntry
+	err     error // last write error, reported by Save
+}
+
+// jarEntry is a cookie as set for a URL, the form it is persisted in
+type jarEntry struct {
+	URL    string       `json:"url"`
+	Cookie *http.Cookie `json:"cookie"`
+}
+
+// NewFileJar creates a jar persisted to path, loading the cookies it holds
+// unless they have expired. A missing file is not an error; it is created,
+// readable by the owner only, when the first cookie is set.
+func NewFileJar(path string) (*FileJar, error) {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, err
+	}
+	j := &FileJar{path: path, jar: jar, entries: make(map[string]jarEntry)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return j, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read cookie jar: %w", err)
+	}
+	var entries []jarEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("decode cookie jar %s: %w", path, err)
+	}
+	now := time.Now()
+	for _, e := range entries {
+		u, err := url.Parse(e.URL)
+		if err != nil || e.Cookie == nil || (!e.Cookie.Expires.IsZero() && e.Cookie.Expires.Before(now)) {
+			continue
+		}
+		j.jar.SetCookies(u, []*http.Cookie{e.Cookie})
+		j.entries[entryKey(u, e.Cookie)] = e
+	}
+	return j, nil
+}
+
+// SetCookies implements http.CookieJar, persisting the jar.
+func (j *FileJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	j.jar.SetCookies(u, cookies)
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	now := time.Now()
+	for _, c := range cookies {
+		c := *c
+		key := entryKey(u, &c)
+		if c.MaxAge < 0 || (!c.Expires.IsZero() && c.Expires.Before(now)) {
+			delete(j.entries, key)
+			continue
+		}
+		// Max-Age is relative to now; store it as the absolute time
+		if c.MaxAge > 0 {
+			c.Expires, c.MaxAge = now.Add(time.Duration(c.MaxAge)*time.Second), 0
+		}
+		c.Raw, c.Unparsed = "", nil
+		j.entries[key] = jarEntry{URL: (&url.URL{Scheme: u.Scheme, Host: u.Host, Path: u.Path}).String(), Cookie: &c}
+	}
+	j.err = j.write()
+}
+
+// Cookies implements http.CookieJar.
+func (j *FileJar) Cookies(u *url.URL) []*http.Cookie {
+	return j.jar.Cookies(u)
+}
+
+// Save writes the jar to its file. Cookies are written as they are set, so
+// Save is only needed to retry after an error, which it returns.
+func (j *FileJar) Save() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.err = j.write()
+	return j.err
+}
+
+// write replaces the jar file atomically.
+func (j *FileJar) write() error {
+	now := time.Now()
+	entries := make([]jarEntry, 0, len(j.entries))
+	for key, e := range j.entries {
+		if !e.Cookie.Expires.IsZero() && e.Cookie.Expires.Before(now) {
+			delete(j.entries, key)
+			continue
+		}
+		entries = append(entries, e)
+	}
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode cookie jar: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(j.path), filepath.Base(j.path)+".tmp-")
+	if err != nil {
+		return fmt.Errorf("write cookie jar: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write cookie jar: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write cookie jar: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), j.path); err != nil {
+		return fmt.Errorf("write cookie jar: %w", err)
+	}
+	return nil
+}
+
+// entryKey identifies the cookie c set for u: the jar replaces cookies
+// with the same name, domain and path
+func entryKey(u *url.URL, c *http.Cookie) string {
+	domain := c.Domain
+	if domain == "" {
+		domain = u.Hostname()
+	}
+	return domain + ";" + c.Path + ";" + c.Name
+}
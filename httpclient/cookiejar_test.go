@@ -0,0 +1,79 @@
+package httpclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileJar(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login":
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc", Path: "/"})
+			http.SetCookie(w, &http.Cookie{Name: "pref", Value: "dark", Path: "/", MaxAge: 3600})
+			http.SetCookie(w, &http.Cookie{Name: "gone", Value: "x", Path: "/", MaxAge: -1})
+		default:
+			c, err := r.Cookie("session")
+			if err != nil {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte(c.Value))
+		}
+	}))
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "cookies.json")
+	get := func(c *Client, p string) (int, string) {
+		t.Helper()
+		resp, err := c.Get(context.Background(), p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+
+	jar, err := NewFileJar(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := New().WithBaseURL(srv.URL).WithCookieJar(jar)
+	get(c, "/login")
+	if code, body := get(c, "/me"); code != http.StatusOK || body != "abc" {
+		t.Fatalf("after login: %d %q", code, body)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o600 {
+		t.Fatalf("jar file: %v, %v; want mode 0600", info, err)
+	}
+
+	// a new client, as after a restart, reuses the session
+	jar, err = NewFileJar(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c = New().WithBaseURL(srv.URL).WithCookieJar(jar)
+	if code, body := get(c, "/me"); code != http.StatusOK || body != "abc" {
+		t.Errorf("after reload: %d %q", code, body)
+	}
+	if len(jar.entries) != 2 {
+		t.Errorf("persisted cookies = %v, want session and pref", jar.entries)
+	}
+	if err := jar.Save(); err != nil {
+		t.Errorf("Save = %v", err)
+	}
+}
+
+func TestNewFileJar_Corrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cookies.json")
+	os.WriteFile(path, []byte("not json"), 0o600)
+	if _, err := NewFileJar(path); err == nil {
+		t.Error("expected error for a corrupt jar file")
+	}
+}
@@ -0,0 +1,159 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ErrChecksumMismatch is returned by Download when the downloaded content
+// does not match DownloadOptions.Checksum.
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+// ErrResourceChanged is returned by Download when resuming was refused
+// because the resource no longer matches DownloadOptions.IfRange.
+var ErrResourceChanged = errors.New("resource changed since the partial download")
+
+// DownloadOptions configures Download
+type DownloadOptions struct {
+	// Offset is the number of bytes already downloaded, e.g. the size of a
+	// partial file w appends to. Download requests the rest with a Range
+	// header; if the server ignores it, the first Offset bytes of the
+	// response are skipped.
+	Offset int64
+
+	// IfRange is the ETag or Last-Modified value of the partial download.
+	// When set, a server whose resource has changed answers with the whole
+	// new content and Download fails with ErrResourceChanged instead of
+	// appending it.
+	IfRange string
+
+	// Progress, if set, is called as data is written with the bytes
+	// downloaded so far and the total size, both including Offset. The
+	// total is -1 if the server did not report it.
+	Progress func(downloaded, total int64)
+
+	// Hash, if set together with Checksum, is fed the written bytes and
+	// compared with Checksum at the end. When resuming, feed it the first
+	// Offset bytes beforehand to check the whole file.
+	Hash hash.Hash
+
+	// Checksum is the expected hex-encoded sum of Hash.
+	Checksum string
+}
+
+// Download performs a GET request and streams the response body to w,
+// returning the number of bytes written. reqOpts apply to the request as
+// for Get. A server answering 416 Range Not
+// Satisfiable because Offset is already the full size counts as a finished
+// download.
+//
+//	f, _ := os.OpenFile("app.tar.gz", os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+//	st, _ := f.Stat()
+//	_, err := c.Download(ctx, "/releases/app.tar.gz", f, httpclient.DownloadOptions{
+//		Offset:   st.Size(),
+//		Hash:     sha256.New(), // fed the existing bytes beforehand
+//		Checksum: wantSHA256,
+//	})
+func (c *Client) Download(ctx context.Context, path string, w io.Writer, opts DownloadOptions, reqOpts ...RequestOption) (int64, error) {
+	if opts.Offset > 0 {
+		reqOpts = append(reqOpts, WithHeader("Range", "bytes="+strconv.FormatInt(opts.Offset, 10)+"-"))
+		if opts.IfRange != "" {
+			reqOpts = append(reqOpts, WithHeader("If-Range", opts.IfRange))
+		}
+	}
+
+	resp, err := c.request(ctx, http.MethodGet, path, nil, "", reqOpts)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	body := io.Reader(resp.Body)
+	total := int64(-1)
+	switch {
+	case resp.StatusCode == http.StatusPartialContent:
+		total = contentRangeSize(resp.Header.Get("Content-Range"))
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && opts.Offset > 0 &&
+		contentRangeSize(resp.Header.Get("Content-Range")) == opts.Offset:
+		return 0, verifyChecksum(opts)
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		if resp.ContentLength >= 0 {
+			total = resp.ContentLength
+		}
+		if opts.Offset > 0 {
+			if opts.IfRange != "" {
+				return 0, ErrResourceChanged
+			}
+			if _, err := io.CopyN(io.Discard, body, opts.Offset); err != nil {
+				return 0, fmt.Errorf("skip downloaded bytes: %w", err)
+			}
+		}
+	default:
+		return 0, ErrorFromResponse(resp, c.errorParser)
+	}
+
+	dst := w
+	if opts.Hash != nil {
+		dst = io.MultiWriter(w, opts.Hash)
+	}
+	if opts.Progress != nil {
+		dst = &progressWriter{w: dst, done: opts.Offset, total: total, fn: opts.Progress}
+	}
+	n, err := io.Copy(dst, body)
+	if err != nil {
+		return n, fmt.Errorf("download: %w", err)
+	}
+	return n, verifyChecksum(opts)
+}
+
+// verifyChecksum compares the sum of opts.Hash with opts.Checksum
+func verifyChecksum(opts DownloadOptions) error {
+	if opts.Hash == nil || opts.Checksum == "" {
+		return nil
+	}
+	want, err := hex.DecodeString(strings.TrimSpace(opts.Checksum))
+	if err != nil {
+		return fmt.Errorf("decode checksum: %w", err)
+	}
+	if got := opts.Hash.Sum(nil); !bytes.Equal(got, want) {
+		return fmt.Errorf("%w: got %x, want %x", ErrChecksumMismatch, got, want)
+	}
+	return nil
+}
+
+// contentRangeSize returns the complete length from a Content-Range value
+// such as "bytes 100-199/200" or "bytes */200", or -1 if unknown
+func contentRangeSize(v string) int64 {
+	_, size, ok := strings.Cut(v, "/")
+	if !ok {
+		return -1
+	}
+	n, err := strconv.ParseInt(size, 10, 64)
+	if err != nil {
+		return -1
+	}
+	return n
+}
+
+// progressWriter reports the bytes written through it
+type progressWriter struct {
+	w     io.Writer
+	done  int64
+	total int64
+	fn    func(downloaded, total int64)
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	n, err := p.w.Write(b)
+	p.done += int64(n)
+	p.fn(p.done, p.total)
+	return n, err
+}
@@ -0,0 +1,83 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDownload(t *testing.T) {
+	content := strings.Repeat("0123456789", 100)
+	sum := sha256.Sum256([]byte(content))
+	checksum := hex.EncodeToString(sum[:])
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		http.ServeContent(w, r, "file.bin", time.Time{}, strings.NewReader(content))
+	}))
+	defer srv.Close()
+	c := New().WithBaseURL(srv.URL)
+
+	t.Run("full", func(t *testing.T) {
+		var buf bytes.Buffer
+		var last, total int64
+		n, err := c.Download(context.Background(), "/file", &buf, DownloadOptions{
+			Progress: func(d, tot int64) { last, total = d, tot },
+			Hash:     sha256.New(),
+			Checksum: checksum,
+		})
+		if err != nil || n != int64(len(content)) || buf.String() != content {
+			t.Fatalf("Download = %d, %v", n, err)
+		}
+		if last != 1000 || total != 1000 {
+			t.Errorf("progress = %d/%d, want 1000/1000", last, total)
+		}
+	})
+
+	t.Run("resume", func(t *testing.T) {
+		buf := bytes.NewBufferString(content[:400])
+		h := sha256.New()
+		h.Write(buf.Bytes())
+		var total int64
+		n, err := c.Download(context.Background(), "/file", buf, DownloadOptions{
+			Offset:   400,
+			IfRange:  `"v1"`,
+			Progress: func(_, tot int64) { total = tot },
+			Hash:     h,
+			Checksum: checksum,
+		})
+		if err != nil || n != 600 || buf.String() != content {
+			t.Fatalf("Download = %d, %v", n, err)
+		}
+		if total != 1000 {
+			t.Errorf("total = %d, want 1000", total)
+		}
+	})
+
+	t.Run("already complete", func(t *testing.T) {
+		n, err := c.Download(context.Background(), "/file", &bytes.Buffer{}, DownloadOptions{Offset: 1000})
+		if err != nil || n != 0 {
+			t.Errorf("Download = %d, %v, want 0, nil", n, err)
+		}
+	})
+
+	t.Run("changed", func(t *testing.T) {
+		_, err := c.Download(context.Background(), "/file", &bytes.Buffer{}, DownloadOptions{Offset: 400, IfRange: `"v0"`})
+		if !errors.Is(err, ErrResourceChanged) {
+			t.Errorf("err = %v, want ErrResourceChanged", err)
+		}
+	})
+
+	t.Run("checksum mismatch", func(t *testing.T) {
+		_, err := c.Download(context.Background(), "/file", &bytes.Buffer{}, DownloadOptions{Hash: sha256.New(), Checksum: strings.Repeat("00", 32)})
+		if !errors.Is(err, ErrChecksumMismatch) {
+			t.Errorf("err = %v, want ErrChecksumMismatch", err)
+		}
+	})
+}
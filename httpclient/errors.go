@@ -0,0 +1,99 @@
+package httpclient
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/en9inerd/go-pkgs/httperrors"
+)
+
+// maxErrorBody limits how much of an error response body is read
+const maxErrorBody = 1 << 20
+
+// ErrorParser extracts the message and details from the body of an error
+// response, reporting false if the body does not have the expected shape.
+type ErrorParser func(body []byte) (message, details string, ok bool)
+
+// DefaultErrorParser understands the common JSON error envelopes:
+//
+//	{"message": "...", "details": "..."}               httperrors and many APIs
+//	{"error": "...", "error_description": "..."}       OAuth 2.0
+//	{"error": {"message": "...", "details": "..."}}    nested error objects
+//	{"title": "...", "detail": "..."}                  RFC 9457 problem details
+func DefaultErrorParser(body []byte) (message, details string, ok bool) {
+	var env map[string]json.RawMessage
+	if json.Unmarshal(body, &env) != nil {
+		return "", "", false
+	}
+	if nested, isObject := env["error"]; isObject && len(nested) > 0 && nested[0] == '{' {
+		var inner map[string]json.RawMessage
+		if json.Unmarshal(nested, &inner) == nil {
+			env = inner
+		}
+	}
+	message = stringField(env, "message", "error", "title")
+	details = stringField(env, "details", "detail", "error_description")
+	return message, details, message != ""
+}
+
+// stringField returns the first of the named fields of env holding a string
+func stringField(env map[string]json.RawMessage, names ...string) string {
+	for _, name := range names {
+		var s string
+		if json.Unmarshal(env[name], &s) == nil && s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+// ErrorFromResponse reads the body of the non-2xx response resp and returns
+// it as an *httperrors.APIError with the response status, the message and
+// details found by parse, and the raw body. If parse is nil
+// DefaultErrorParser is used; if it fails, the message is the status text
+// and the details the body text.
+func ErrorFromResponse(resp *http.Response, parse ErrorParser) *httperrors.APIError {
+	if parse == nil {
+		parse = DefaultErrorParser
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	apiErr := &httperrors.APIError{Code: resp.StatusCode, Body: body, Err: err}
+
+	if message, details, ok := parse(body); ok {
+		apiErr.Message, apiErr.Details = message, details
+	} else {
+		apiErr.Message = http.StatusText(resp.StatusCode)
+		apiErr.Details = strings.TrimSpace(string(body))
+	}
+	if apiErr.Message == "" {
+		apiErr.Message = resp.Status
+	}
+	return apiErr
+}
+
+// WithErrorParser sets how error responses are parsed, see
+// Config.ErrorParser
+func (c *Client) WithErrorParser(parse ErrorParser) *Client {
+	c.errorParser = parse
+	return c
+}
+
+// decodeJSON decodes resp like DecodeJSONResponse, parsing error responses
+// with the client's error parser
+func (c *Client) decodeJSON(resp *http.Response, target any) error {
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return ErrorFromResponse(resp, c.errorParser)
+	}
+	return DecodeJSONResponse(resp, target)
+}
+
+// decodeXML decodes resp like DecodeXMLResponse, parsing error responses
+// with the client's error parser
+func (c *Client) decodeXML(resp *http.Response, target any) error {
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return ErrorFromResponse(resp, c.errorParser)
+	}
+	return DecodeXMLResponse(resp, target)
+}
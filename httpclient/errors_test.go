@@ -0,0 +1,70 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/en9inerd/go-pkgs/httperrors"
+)
+
+func TestDefaultErrorParser(t *testing.T) {
+	tests := []struct {
+		body, message, details string
+		ok                     bool
+	}{
+		{`{"code":404,"message":"user not found","details":"id 7"}`, "user not found", "id 7", true},
+		{`{"error":"invalid_grant","error_description":"expired"}`, "invalid_grant", "expired", true},
+		{`{"error":{"message":"quota exceeded","code":429}}`, "quota exceeded", "", true},
+		{`{"type":"about:blank","title":"Conflict","detail":"version mismatch"}`, "Conflict", "version mismatch", true},
+		{`{"status":"error"}`, "", "", false},
+		{`<html>oops</html>`, "", "", false},
+	}
+	for _, tt := range tests {
+		message, details, ok := DefaultErrorParser([]byte(tt.body))
+		if message != tt.message || details != tt.details || ok != tt.ok {
+			t.Errorf("DefaultErrorParser(%s) = %q, %q, %v; want %q, %q, %v",
+				tt.body, message, details, ok, tt.message, tt.details, tt.ok)
+		}
+	}
+}
+
+func TestGetJSON_APIError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		switch r.URL.Path {
+		case "/custom":
+			w.Write([]byte(`{"errors":[{"msg":"name is required"}]}`))
+		case "/text":
+			w.Write([]byte("bad input\n"))
+		default:
+			w.Write([]byte(`{"message":"invalid","details":"name is required"}`))
+		}
+	}))
+	defer srv.Close()
+
+	c := New().WithBaseURL(srv.URL)
+	check := func(path, message, details string) {
+		t.Helper()
+		err := c.GetJSON(context.Background(), path, nil)
+		var apiErr *httperrors.APIError
+		if !errors.As(err, &apiErr) {
+			t.Fatalf("%s: err = %v, want *httperrors.APIError", path, err)
+		}
+		if apiErr.Code != http.StatusUnprocessableEntity || apiErr.Message != message || apiErr.Details != details {
+			t.Errorf("%s: got code %d, %q, %q; want 422, %q, %q", path, apiErr.Code, apiErr.Message, apiErr.Details, message, details)
+		}
+		if len(apiErr.Body) == 0 {
+			t.Errorf("%s: raw body not preserved", path)
+		}
+	}
+	check("/", "invalid", "name is required")
+	check("/text", "Unprocessable Entity", "bad input")
+
+	c.WithErrorParser(func(body []byte) (string, string, bool) {
+		return "custom", string(body), true
+	})
+	check("/custom", "custom", `{"errors":[{"msg":"name is required"}]}`)
+}
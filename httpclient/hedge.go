@@ -0,0 +1,110 @@
+package httpclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+)
+
+// WithHedging enables hedged requests, see Config.HedgeDelay
+func (c *Client) WithHedging(delay time.Duration, maxExtra int) *Client {
+	c.hedgeDelay, c.maxHedges = delay, maxExtra
+	return c
+}
+
+// hedgeable reports whether req may be sent several times in parallel
+func (c *Client) hedgeable(req *http.Request) bool {
+	return c.hedgeDelay > 0 && c.maxHedges > 0 &&
+		(req.Method == http.MethodGet || req.Method == http.MethodHead) &&
+		(req.Body == nil || req.Body == http.NoBody)
+}
+
+// attempt executes one attempt of a request, hedged if the client hedges
+// requests and req is eligible.
+func (c *Client) attempt(ctx context.Context, req *http.Request, attempt int) (*http.Response, error) {
+	if !c.hedgeable(req) {
+		return c.send(ctx, req, attempt)
+	}
+	return c.hedge(ctx, req, attempt)
+}
+
+// hedgeResult is the outcome of the i-th copy of a hedged request
+type hedgeResult struct {
+	resp *http.Response
+	err  error
+	i    int
+}
+
+// hedge sends req, and another copy each time hedgeDelay passes without an
+// answer or a copy fails, up to maxHedges extra copies. The first response
+// that is not a retryable failure wins and the other copies are canceled;
+// if none wins, the last outcome is returned.
+func (c *Client) hedge(ctx context.Context, req *http.Request, attempt int) (*http.Response, error) {
+	results := make(chan hedgeResult, c.maxHedges+1)
+	var cancels []context.CancelFunc
+	pending := 0
+	launch := func() {
+		sendCtx, cancel := context.WithCancel(ctx)
+		i := len(cancels)
+		cancels = append(cancels, cancel)
+		pending++
+		go func() {
+			resp, err := c.send(sendCtx, req.Clone(sendCtx), attempt)
+			results <- hedgeResult{resp: resp, err: err, i: i}
+		}()
+	}
+	canLaunch := func() bool { return len(cancels) <= c.maxHedges && ctx.Err() == nil }
+
+	launch()
+	timer := time.NewTimer(c.hedgeDelay)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+			if canLaunch() {
+				launch()
+				timer.Reset(c.hedgeDelay)
+			}
+		case res := <-results:
+			pending--
+			won := res.err == nil && !retryableStatus(res.resp.StatusCode)
+			if won || (pending == 0 && !canLaunch()) {
+				for i, cancel := range cancels {
+					if i != res.i {
+						cancel()
+					}
+				}
+				go discardHedges(results, pending)
+				if res.err != nil {
+					cancels[res.i]()
+					return nil, res.err
+				}
+				res.resp.Body = &cancelBody{ReadCloser: res.resp.Body, cancel: cancels[res.i]}
+				return res.resp, nil
+			}
+
+			// this copy failed, but another one may still succeed
+			if res.err == nil {
+				io.Copy(io.Discard, io.LimitReader(res.resp.Body, 64<<10))
+				res.resp.Body.Close()
+			}
+			cancels[res.i]()
+			if pending == 0 {
+				launch()
+				timer.Reset(c.hedgeDelay)
+			}
+		}
+	}
+}
+
+// discardHedges closes the responses of the n copies of a hedged request
+// still in flight once another copy won.
+func discardHedges(results <-chan hedgeResult, n int) {
+	for range n {
+		if res := <-results; res.err == nil {
+			res.resp.Body.Close()
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package httpclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithHedging(t *testing.T) {
+	var hits atomic.Int32
+	canceled := make(chan struct{}, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body) // lets the server notice a canceled client
+		if hits.Add(1) == 1 {
+			// the first copy stalls until the winner cancels it
+			select {
+			case <-r.Context().Done():
+				canceled <- struct{}{}
+			case <-time.After(5 * time.Second):
+			}
+			return
+		}
+		w.Write([]byte("fast"))
+	}))
+	defer srv.Close()
+
+	c := New().WithBaseURL(srv.URL).WithHedging(20*time.Millisecond, 2)
+
+	start := time.Now()
+	resp, err := c.Get(context.Background(), "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil || string(body) != "fast" {
+		t.Fatalf("body = %q, %v; want the hedged response", body, err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("hedged request took %v", elapsed)
+	}
+	if n := hits.Load(); n != 2 {
+		t.Errorf("copies sent = %d, want 2", n)
+	}
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Error("the slow copy was not canceled")
+	}
+
+	// requests with a body are never hedged
+	hits.Store(0)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		c.Post(ctx, "/", map[string]int{"n": 1})
+	}()
+	<-done
+	if n := hits.Load(); n != 1 {
+		t.Errorf("POST copies sent = %d, want 1", n)
+	}
+}
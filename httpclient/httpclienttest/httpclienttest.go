@@ -0,0 +1,193 @@
+// Package httpclienttest provides a scripted http.RoundTripper for testing
+// code that uses httpclient without starting a server.
+//
+//	tr := httpclienttest.NewTransport()
+//	tr.Expect("GET", "/users/1").RespondJSON(200, User{ID: 1})
+//	c := httpclient.New().WithBaseURL("https://api.test").WithHTTPClient(tr.Client())
+//	// ... exercise code using c ...
+//	tr.AssertExpectations(t)
+package httpclienttest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// Transport is an http.RoundTripper answering requests with the responses of
+// matching expectations. Requests no expectation matches fail with an error
+// and are reported by AssertExpectations. It is safe for concurrent use.
+type Transport struct {
+	mu           sync.Mutex
+	expectations []*Expectation
+	unexpected   []string
+}
+
+// NewTransport creates a Transport without expectations.
+func NewTransport() *Transport {
+	return &Transport{}
+}
+
+// Client returns an http.Client using t, for httpclient.Client.WithHTTPClient.
+func (t *Transport) Client() *http.Client {
+	return &http.Client{Transport: t}
+}
+
+// Expect adds an expectation for requests with method and URL path, which
+// responds with 200 and an empty body until configured otherwise.
+// Expectations are matched in the order they were added, skipping those
+// that have used up their Times.
+func (t *Transport) Expect(method, path string) *Expectation {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	e := &Expectation{method: method, path: path, status: http.StatusOK, header: make(http.Header), mu: &t.mu}
+	t.expectations = append(t.expectations, e)
+	return e
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, e := range t.expectations {
+		if e.method != req.Method || e.path != req.URL.Path || (e.times > 0 && len(e.bodies) >= e.times) {
+			continue
+		}
+		e.bodies = append(e.bodies, body)
+		if e.err != nil {
+			return nil, e.err
+		}
+		return &http.Response{
+			Status:        strconv.Itoa(e.status) + " " + http.StatusText(e.status),
+			StatusCode:    e.status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        e.header.Clone(),
+			Body:          io.NopCloser(bytes.NewReader(e.body)),
+			ContentLength: int64(len(e.body)),
+			Request:       req,
+		}, nil
+	}
+	call := req.Method + " " + req.URL.Path
+	t.unexpected = append(t.unexpected, call)
+	return nil, fmt.Errorf("httpclienttest: unexpected request %s", call)
+}
+
+// AssertExpectations reports an error on tb for every expectation that was
+// not called (or not called exactly Times times, if set) and for every
+// unexpected request.
+func (t *Transport) AssertExpectations(tb testing.TB) {
+	tb.Helper()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, e := range t.expectations {
+		switch n := len(e.bodies); {
+		case e.times > 0 && n != e.times:
+			tb.Errorf("httpclienttest: %s %s called %d times, want %d", e.method, e.path, n, e.times)
+		case n == 0:
+			tb.Errorf("httpclienttest: %s %s not called", e.method, e.path)
+		}
+	}
+	for _, call := range t.unexpected {
+		tb.Errorf("httpclienttest: unexpected request %s", call)
+	}
+}
+
+// Expectation is a scripted response to matching requests, configured with
+// its chainable methods.
+type Expectation struct {
+	method, path string
+	status       int
+	header       http.Header
+	body         []byte
+	err          error
+	times        int
+
+	// request bodies of the matched calls
+	bodies [][]byte
+
+	mu *sync.Mutex
+}
+
+// Respond sets the response status and body.
+func (e *Expectation) Respond(status int, body string) *Expectation {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.status, e.body = status, []byte(body)
+	return e
+}
+
+// RespondJSON sets the response status and v, encoded as JSON, as the body,
+// and sets the Content-Type header. It panics if v cannot be encoded.
+func (e *Expectation) RespondJSON(status int, v any) *Expectation {
+	body, err := json.Marshal(v)
+	if err != nil {
+		panic("httpclienttest: " + err.Error())
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.status, e.body = status, body
+	e.header.Set("Content-Type", "application/json")
+	return e
+}
+
+// Header sets a response header.
+func (e *Expectation) Header(key, value string) *Expectation {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.header.Set(key, value)
+	return e
+}
+
+// Fail makes matching requests fail with err instead of responding, as a
+// network error would.
+func (e *Expectation) Fail(err error) *Expectation {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.err = err
+	return e
+}
+
+// Times limits the expectation to n calls, after which later expectations
+// for the same request match, and makes AssertExpectations require exactly
+// n calls. Default: unlimited, at least one call required
+func (e *Expectation) Times(n int) *Expectation {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.times = n
+	return e
+}
+
+// Calls returns how many requests the expectation matched.
+func (e *Expectation) Calls() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return len(e.bodies)
+}
+
+// Bodies returns the request bodies of the matched calls, in call order.
+func (e *Expectation) Bodies() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	out := make([]string, len(e.bodies))
+	for i, b := range e.bodies {
+		out[i] = string(b)
+	}
+	return out
+}
@@ -0,0 +1,35 @@
+package httpclient
+
+import (
+	"crypto/rand"
+	"net/http"
+)
+
+// IdempotencyKeyHeader is the header carrying the idempotency key of a
+// request, as understood by Stripe-style APIs.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// WithIdempotencyKeys attaches a generated idempotency key to POST and PATCH
+// requests, see Config.IdempotencyKeys
+func (c *Client) WithIdempotencyKeys() *Client {
+	c.idempotencyKeys = true
+	return c
+}
+
+// WithIdempotencyKey sets the idempotency key of the request, for callers
+// that persist the key to reuse it for the same operation across calls
+func WithIdempotencyKey(key string) RequestOption {
+	return WithHeader(IdempotencyKeyHeader, key)
+}
+
+// withIdempotencyKey returns req with a generated idempotency key if the
+// client generates keys and req is a POST or PATCH without one.
+func (c *Client) withIdempotencyKey(req *http.Request) *http.Request {
+	if !c.idempotencyKeys || (req.Method != http.MethodPost && req.Method != http.MethodPatch) ||
+		req.Header.Get(IdempotencyKeyHeader) != "" {
+		return req
+	}
+	r := req.Clone(req.Context())
+	r.Header.Set(IdempotencyKeyHeader, rand.Text())
+	return r
+}
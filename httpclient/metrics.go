@@ -0,0 +1,121 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Metrics receives a record of every request the client sends, for export
+// to Prometheus, OpenTelemetry or similar. Unlike Config.Observability,
+// which emits fixed metric names, it leaves naming and labels to the caller.
+type Metrics interface {
+	ObserveRequest(ctx context.Context, m RequestMetrics)
+}
+
+// MetricsFunc adapts a function to the Metrics interface.
+type MetricsFunc func(ctx context.Context, m RequestMetrics)
+
+// ObserveRequest calls f.
+func (f MetricsFunc) ObserveRequest(ctx context.Context, m RequestMetrics) {
+	f(ctx, m)
+}
+
+// Outcome classifies how a request ended.
+type Outcome string
+
+const (
+	OutcomeSuccess      Outcome = "success"       // 1xx, 2xx or 3xx response
+	OutcomeClientError  Outcome = "client_error"  // 4xx response
+	OutcomeServerError  Outcome = "server_error"  // 5xx response
+	OutcomeTimeout      Outcome = "timeout"       // deadline or timeout exceeded
+	OutcomeCanceled     Outcome = "canceled"      // context canceled
+	OutcomeNetworkError Outcome = "network_error" // any other transport error
+)
+
+// RequestMetrics describes a completed request. It is reported once the
+// response body is closed, so ResponseBytes is final, or immediately if the
+// request failed.
+type RequestMetrics struct {
+	Method string
+	Host   string
+
+	// Route is the path template set with WithRoute, such as
+	// "/users/{id}", and empty otherwise. Prefer it over Path as a metric
+	// label to keep cardinality bounded.
+	Route string
+
+	// Path is the request URL path.
+	Path string
+
+	// StatusCode is the response status, or 0 if the request failed.
+	StatusCode int
+
+	Outcome Outcome
+
+	// Duration is the time until the response headers were received.
+	Duration time.Duration
+
+	// RequestBytes is the request body size, or -1 if unknown.
+	RequestBytes int64
+
+	// ResponseBytes is the number of response body bytes read by the caller.
+	ResponseBytes int64
+}
+
+// WithMetrics sets the request metrics recorder, see Config.Metrics
+func (c *Client) WithMetrics(m Metrics) *Client {
+	c.metrics = m
+	return c
+}
+
+// routeKey is the context key of the route template set with WithRoute
+type routeKey struct{}
+
+// WithRoute sets the path template the request is reported under in
+// RequestMetrics.Route, e.g.
+//
+//	c.GetJSON(ctx, "/users/"+id, &u, httpclient.WithRoute("/users/{id}"))
+func WithRoute(template string) RequestOption {
+	return func(o *requestOptions) { o.route = template }
+}
+
+// requestMetrics builds the metrics of req from its response summary and
+// transport error, if any.
+func requestMetrics(req *http.Request, s ResponseSummary, err error) RequestMetrics {
+	route, _ := req.Context().Value(routeKey{}).(string)
+	return RequestMetrics{
+		Method:        req.Method,
+		Host:          req.URL.Host,
+		Route:         route,
+		Path:          req.URL.Path,
+		StatusCode:    s.StatusCode,
+		Outcome:       classify(s.StatusCode, err),
+		Duration:      s.Duration,
+		RequestBytes:  s.RequestBytes,
+		ResponseBytes: s.ResponseBytes,
+	}
+}
+
+// classify returns the outcome of a request that got status or failed
+// with err.
+func classify(status int, err error) Outcome {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.DeadlineExceeded),
+		errors.As(err, &netErr) && netErr.Timeout():
+		return OutcomeTimeout
+	case errors.Is(err, context.Canceled):
+		return OutcomeCanceled
+	case err != nil:
+		return OutcomeNetworkError
+	case status >= 500:
+		return OutcomeServerError
+	case status >= 400:
+		return OutcomeClientError
+	default:
+		return OutcomeSuccess
+	}
+}
@@ -0,0 +1,69 @@
+package httpclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestDo_Metrics(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/slow":
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+		case "/missing":
+			http.NotFound(w, r)
+		default:
+			w.Write([]byte("hello"))
+		}
+	}))
+	defer srv.Close()
+
+	var got []RequestMetrics
+	c := New().WithBaseURL(srv.URL).WithMetrics(MetricsFunc(func(ctx context.Context, m RequestMetrics) {
+		got = append(got, m)
+	}))
+
+	resp, err := c.Post(context.Background(), "/users/42", map[string]int{"n": 1}, WithRoute("/users/{id}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.ReadAll(resp.Body)
+	if len(got) != 0 {
+		t.Fatal("metrics reported before the body was closed")
+	}
+	resp.Body.Close()
+
+	resp, err = c.Get(context.Background(), "/missing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	c.Get(context.Background(), "/slow", WithTimeout(20*time.Millisecond))
+
+	if len(got) != 3 {
+		t.Fatalf("got %d records, want 3", len(got))
+	}
+	host, _ := url.Parse(srv.URL)
+	m := got[0]
+	if m.Method != http.MethodPost || m.Host != host.Host || m.Route != "/users/{id}" || m.Path != "/users/42" {
+		t.Errorf("request fields = %+v", m)
+	}
+	if m.StatusCode != 200 || m.Outcome != OutcomeSuccess || m.RequestBytes != 7 || m.ResponseBytes != 5 {
+		t.Errorf("outcome fields = %+v", m)
+	}
+	if got[1].Outcome != OutcomeClientError || got[1].Route != "" {
+		t.Errorf("404 record = %+v", got[1])
+	}
+	if got[2].Outcome != OutcomeTimeout || got[2].StatusCode != 0 {
+		t.Errorf("timeout record = %+v", got[2])
+	}
+}
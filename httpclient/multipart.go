@@ -0,0 +1,86 @@
+package httpclient
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"slices"
+)
+
+// FileField is a file part of a multipart upload
+type FileField struct {
+	// FieldName is the form field name.
+	FieldName string
+
+	// FileName is the file name reported to the server.
+	FileName string
+
+	// ContentType is the part's content type.
+	// Default: "application/octet-stream"
+	ContentType string
+
+	// Reader supplies the file content. It is read while the request is
+	// sent and is not closed.
+	Reader io.Reader
+}
+
+// PostMultipart performs a POST request with a multipart/form-data body of
+// fields, in key order, followed by files. File content is streamed from
+// the readers as the request is sent, so large uploads are not buffered in
+// memory; as a consequence the request is never retried.
+func (c *Client) PostMultipart(ctx context.Context, path string, fields map[string]string, files []FileField, opts ...RequestOption) (*http.Response, error) {
+	pr, pw := io.Pipe()
+	mw := multipart.NewWriter(pw)
+	go func() {
+		pw.CloseWithError(writeMultipart(mw, fields, files))
+	}()
+
+	resp, err := c.request(ctx, http.MethodPost, path, pr, mw.FormDataContentType(), opts)
+	if err != nil {
+		// unblock the writer if the transport did not consume the body
+		pr.CloseWithError(err)
+		return nil, err
+	}
+	return resp, nil
+}
+
+// writeMultipart writes fields and files to mw and closes it
+func writeMultipart(mw *multipart.Writer, fields map[string]string, files []FileField) error {
+	for _, k := range slices.Sorted(maps.Keys(fields)) {
+		if err := mw.WriteField(k, fields[k]); err != nil {
+			return err
+		}
+	}
+	for _, f := range files {
+		contentType := f.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		h := make(textproto.MIMEHeader)
+		h.Set("Content-Disposition", multipart.FileContentDisposition(f.FieldName, f.FileName))
+		h.Set("Content-Type", contentType)
+		part, err := mw.CreatePart(h)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(part, f.Reader); err != nil {
+			return fmt.Errorf("read file %q: %w", f.FileName, err)
+		}
+	}
+	return mw.Close()
+}
+
+// PostMultipartJSON performs a multipart POST request and decodes the JSON response
+func (c *Client) PostMultipartJSON(ctx context.Context, path string, fields map[string]string, files []FileField, target any, opts ...RequestOption) error {
+	resp, err := c.PostMultipart(ctx, path, fields, files, opts...)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return c.decodeJSON(resp, target)
+}
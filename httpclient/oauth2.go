@@ -0,0 +1,162 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ClientCredentialsConfig configures the OAuth 2.0 client credentials flow
+// (RFC 6749, section 4.4).
+type ClientCredentialsConfig struct {
+	// TokenURL is the authorization server's token endpoint.
+	TokenURL string
+
+	ClientID     string
+	ClientSecret string
+
+	// Scopes requested for the token. Default: none
+	Scopes []string
+
+	// EndpointParams are additional form values sent to TokenURL, e.g. an
+	// audience.
+	EndpointParams url.Values
+
+	// AuthInParams sends the client credentials as form values instead of
+	// HTTP basic authentication, for servers that require it.
+	AuthInParams bool
+
+	// ExpiryDelta is how long before its expiry a token is refreshed, so it
+	// does not expire in flight. Default: 10s
+	ExpiryDelta time.Duration
+
+	// HTTPClient fetches the tokens. Default: an http.Client with a 30s
+	// timeout
+	HTTPClient *http.Client
+}
+
+// ClientCredentials fetches access tokens with the client credentials flow
+// and caches them until shortly before they expire. It is safe for
+// concurrent use; concurrent callers share a single token request.
+type ClientCredentials struct {
+	cfg ClientCredentialsConfig
+
+	// sem guards the token and serializes token requests
+	sem    chan struct{}
+	token  string
+	expiry time.Time // zero if the token does not expire
+}
+
+// NewClientCredentials creates a token manager for cfg. Its Token method is
+// a token source for Client.WithBearerTokenSource, see
+// Client.WithClientCredentials.
+func NewClientCredentials(cfg ClientCredentialsConfig) *ClientCredentials {
+	if cfg.ExpiryDelta == 0 {
+		cfg.ExpiryDelta = 10 * time.Second
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &ClientCredentials{cfg: cfg, sem: make(chan struct{}, 1)}
+}
+
+// WithClientCredentials authenticates requests with bearer tokens obtained
+// through the OAuth 2.0 client credentials flow, see NewClientCredentials
+// and WithBearerTokenSource
+func (c *Client) WithClientCredentials(cfg ClientCredentialsConfig) *Client {
+	return c.WithBearerTokenSource(NewClientCredentials(cfg).Token)
+}
+
+// Token returns a valid access token, fetching a new one if none is cached,
+// the cached one is about to expire, or TokenRefresh reports true for ctx.
+func (cc *ClientCredentials) Token(ctx context.Context) (string, error) {
+	select {
+	case cc.sem <- struct{}{}:
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+	defer func() { <-cc.sem }()
+
+	if cc.token != "" && !TokenRefresh(ctx) &&
+		(cc.expiry.IsZero() || time.Until(cc.expiry) > cc.cfg.ExpiryDelta) {
+		return cc.token, nil
+	}
+
+	token, expiresIn, err := cc.fetch(ctx)
+	if err != nil {
+		return "", err
+	}
+	cc.token, cc.expiry = token, time.Time{}
+	if expiresIn > 0 {
+		cc.expiry = time.Now().Add(time.Duration(expiresIn) * time.Second)
+	}
+	return token, nil
+}
+
+// tokenResponse is a token endpoint response, successful or not
+type tokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	TokenType        string `json:"token_type"`
+	ExpiresIn        int64  `json:"expires_in"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// fetch requests a new token from the token endpoint
+func (cc *ClientCredentials) fetch(ctx context.Context) (string, int64, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(cc.cfg.Scopes) > 0 {
+		form.Set("scope", strings.Join(cc.cfg.Scopes, " "))
+	}
+	for k, vs := range cc.cfg.EndpointParams {
+		form[k] = append(form[k], vs...)
+	}
+	if cc.cfg.AuthInParams {
+		form.Set("client_id", cc.cfg.ClientID)
+		form.Set("client_secret", cc.cfg.ClientSecret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cc.cfg.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", 0, fmt.Errorf("create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if !cc.cfg.AuthInParams {
+		req.SetBasicAuth(url.QueryEscape(cc.cfg.ClientID), url.QueryEscape(cc.cfg.ClientSecret))
+	}
+
+	resp, err := cc.cfg.HTTPClient.Do(req)
+	if err != nil {
+		return "", 0, fmt.Errorf("token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", 0, fmt.Errorf("read token response: %w", err)
+	}
+	var tr tokenResponse
+	jsonErr := json.Unmarshal(body, &tr)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		if jsonErr == nil && tr.Error != "" {
+			return "", 0, fmt.Errorf("token request failed: %s %s: %s", resp.Status, tr.Error, tr.ErrorDescription)
+		}
+		return "", 0, fmt.Errorf("token request failed: %s", resp.Status)
+	}
+	if jsonErr != nil {
+		return "", 0, fmt.Errorf("decode token response: %w", jsonErr)
+	}
+	if tr.AccessToken == "" {
+		return "", 0, fmt.Errorf("token response has no access_token")
+	}
+	if tr.TokenType != "" && !strings.EqualFold(tr.TokenType, "bearer") {
+		return "", 0, fmt.Errorf("unsupported token type %q", tr.TokenType)
+	}
+	return tr.AccessToken, tr.ExpiresIn, nil
+}
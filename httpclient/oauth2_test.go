@@ -0,0 +1,78 @@
+package httpclient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestClientCredentials(t *testing.T) {
+	var issued atomic.Int32
+	tokenSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, secret, _ := r.BasicAuth()
+		if r.FormValue("grant_type") != "client_credentials" || id != "app" || secret != "s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"error":"invalid_client"}`)
+			return
+		}
+		if got := r.FormValue("scope"); got != "read write" {
+			t.Errorf("scope = %q, want %q", got, "read write")
+		}
+		n := issued.Add(1)
+		fmt.Fprintf(w, `{"access_token":"token-%d","token_type":"Bearer","expires_in":3600}`, n)
+	}))
+	defer tokenSrv.Close()
+
+	var current atomic.Value
+	current.Store("token-1")
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+current.Load().(string) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer api.Close()
+
+	c := New().WithBaseURL(api.URL).WithClientCredentials(ClientCredentialsConfig{
+		TokenURL:     tokenSrv.URL,
+		ClientID:     "app",
+		ClientSecret: "s3cret",
+		Scopes:       []string{"read", "write"},
+	})
+	for range 3 {
+		if err := c.GetJSON(context.Background(), "/", nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := issued.Load(); n != 1 {
+		t.Errorf("tokens issued = %d, want 1 (cached)", n)
+	}
+
+	// the server revokes the token: the 401 triggers a new one
+	current.Store("token-2")
+	if err := c.GetJSON(context.Background(), "/", nil); err != nil {
+		t.Fatal(err)
+	}
+	if n := issued.Load(); n != 2 {
+		t.Errorf("tokens issued = %d, want 2", n)
+	}
+}
+
+func TestClientCredentials_Error(t *testing.T) {
+	tokenSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"error":"invalid_scope","error_description":"unknown scope"}`)
+	}))
+	defer tokenSrv.Close()
+
+	cc := NewClientCredentials(ClientCredentialsConfig{TokenURL: tokenSrv.URL, AuthInParams: true})
+	_, err := cc.Token(context.Background())
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if want := "token request failed: 400 Bad Request invalid_scope: unknown scope"; err.Error() != want {
+		t.Errorf("err = %q, want %q", err, want)
+	}
+}
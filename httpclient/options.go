@@ -0,0 +1,157 @@
+package httpclient
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RequestOption customizes a single request made by the verb helpers (Get,
+// Post, GetJSON, ...). Unlike the Client's With* methods, which configure
+// the shared client and are not safe to call while it is in use, options
+// only affect the call they are passed to.
+type RequestOption func(*requestOptions)
+
+// requestOptions is the per-request configuration built from RequestOptions
+type requestOptions struct {
+	header     http.Header
+	query      url.Values
+	deadline   time.Time
+	basicAuth  *url.Userinfo
+	route      string
+	pathParams map[string]string
+}
+
+// WithHeader sets a header on the request, overriding the client's default
+// header of the same name
+func WithHeader(key, value string) RequestOption {
+	return func(o *requestOptions) {
+		if o.header == nil {
+			o.header = make(http.Header)
+		}
+		o.header.Set(key, value)
+	}
+}
+
+// WithQuery adds query parameters to the request URL, properly escaped
+func WithQuery(values url.Values) RequestOption {
+	return func(o *requestOptions) {
+		if o.query == nil {
+			o.query = make(url.Values)
+		}
+		for k, vs := range values {
+			o.query[k] = append(o.query[k], vs...)
+		}
+	}
+}
+
+// WithTimeout limits the request, including reading the response body, to
+// d. It replaces the client's timeout for this request, so it may be longer
+// or shorter; the dial timeout of the client's transport still applies.
+func WithTimeout(d time.Duration) RequestOption {
+	return func(o *requestOptions) {
+		if d > 0 {
+			o.deadline = time.Now().Add(d)
+		}
+	}
+}
+
+// WithDeadline is like WithTimeout, with the request ending at t
+func WithDeadline(t time.Time) RequestOption {
+	return func(o *requestOptions) { o.deadline = t }
+}
+
+// timeoutKey marks the context of a request whose deadline replaces the
+// client's timeout
+type timeoutKey struct{}
+
+// WithBasicAuth sets HTTP basic authentication on the request
+func WithBasicAuth(username, password string) RequestOption {
+	return func(o *requestOptions) { o.basicAuth = url.UserPassword(username, password) }
+}
+
+// request builds a request for path with opts applied and executes it
+func (c *Client) request(ctx context.Context, method, path string, body io.Reader, contentType string, opts []RequestOption) (*http.Response, error) {
+	var o requestOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.route == "" && len(o.pathParams) > 0 {
+		o.route, _, _ = strings.Cut(path, "?")
+	}
+	if o.route != "" {
+		ctx = context.WithValue(ctx, routeKey{}, o.route)
+	}
+	if o.deadline.IsZero() {
+		req, err := c.newRequest(ctx, method, path, body, contentType, &o)
+		if err != nil {
+			return nil, err
+		}
+		return c.Do(ctx, req)
+	}
+
+	ctx, cancel := context.WithDeadline(context.WithValue(ctx, timeoutKey{}, true), o.deadline)
+	req, err := c.newRequest(ctx, method, path, body, contentType, &o)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp, err := c.Do(ctx, req)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// newRequest creates a request for path with the options o
+func (c *Client) newRequest(ctx context.Context, method, path string, body io.Reader, contentType string, o *requestOptions) (*http.Request, error) {
+	if len(o.pathParams) > 0 {
+		var err error
+		if path, err = expandPath(path, o.pathParams); err != nil {
+			return nil, err
+		}
+	}
+	path, err := addQuery(path, o.query)
+	if err != nil {
+		return nil, err
+	}
+	u, err := c.buildURL(path)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	for k, vs := range o.header {
+		req.Header[k] = vs
+	}
+	if o.basicAuth != nil {
+		password, _ := o.basicAuth.Password()
+		req.SetBasicAuth(o.basicAuth.Username(), password)
+	}
+	return req, nil
+}
+
+// cancelBody releases the request's timeout context once the body is closed
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+	once   sync.Once
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.cancel)
+	return err
+}
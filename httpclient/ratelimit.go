@@ -0,0 +1,68 @@
+package httpclient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/en9inerd/go-pkgs/ratelimit"
+)
+
+// HostLimiters hands out one rate limiter per request host, so a client
+// talking to several services respects each service's quota separately.
+type HostLimiters struct {
+	newLimiter func(host string) ratelimit.Limiter
+
+	mu       sync.Mutex
+	limiters map[string]ratelimit.Limiter
+}
+
+// NewHostLimiters creates a per-host limiter manager. newLimiter is called
+// once per host, on its first request, and may return nil to leave the host
+// unlimited.
+//
+//	limiters := httpclient.NewHostLimiters(func(host string) ratelimit.Limiter {
+//		return ratelimit.NewTokenBucket(10, 5)
+//	})
+func NewHostLimiters(newLimiter func(host string) ratelimit.Limiter) *HostLimiters {
+	return &HostLimiters{
+		newLimiter: newLimiter,
+		limiters:   make(map[string]ratelimit.Limiter),
+	}
+}
+
+// Limiter returns the limiter for host, creating it if needed.
+func (h *HostLimiters) Limiter(host string) ratelimit.Limiter {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	l, ok := h.limiters[host]
+	if !ok {
+		l = h.newLimiter(host)
+		h.limiters[host] = l
+	}
+	return l
+}
+
+// Wait blocks until the limiter of host permits a request or ctx is done.
+func (h *HostLimiters) Wait(ctx context.Context, host string) error {
+	if l := h.Limiter(host); l != nil {
+		return l.Wait(ctx)
+	}
+	return nil
+}
+
+// wait blocks until the client's limiters permit req to be sent.
+func (c *Client) wait(ctx context.Context, req *http.Request) error {
+	if c.limiter != nil {
+		if err := c.limiter.Wait(ctx); err != nil {
+			return fmt.Errorf("rate limit: %w", err)
+		}
+	}
+	if c.hostLimiters != nil {
+		if err := c.hostLimiters.Wait(ctx, req.URL.Host); err != nil {
+			return fmt.Errorf("rate limit %s: %w", req.URL.Host, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/en9inerd/go-pkgs/ratelimit"
+)
+
+func TestDo_Limiter(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+	}))
+	defer srv.Close()
+
+	// one token, refilled far slower than the test runs
+	c := NewWithConfig(Config{BaseURL: srv.URL, Limiter: ratelimit.NewTokenBucket(1, 0.001)})
+	resp, err := c.Get(context.Background(), "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := c.Get(ctx, "/"); err == nil {
+		t.Fatal("request over the limit succeeded")
+	}
+	if n := hits.Load(); n != 1 {
+		t.Errorf("server hits = %d, want 1", n)
+	}
+}
+
+func TestDo_HostLimiters(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	a := httptest.NewServer(handler)
+	defer a.Close()
+	b := httptest.NewServer(handler)
+	defer b.Close()
+
+	var created atomic.Int32
+	limiters := NewHostLimiters(func(host string) ratelimit.Limiter {
+		created.Add(1)
+		return ratelimit.NewTokenBucket(1, 0.001)
+	})
+	c := New().WithHostLimiters(limiters)
+
+	for _, url := range []string{a.URL, b.URL} {
+		resp, err := c.Get(context.Background(), url)
+		if err != nil {
+			t.Fatalf("first request to %s: %v", url, err)
+		}
+		resp.Body.Close()
+	}
+	if n := created.Load(); n != 2 {
+		t.Errorf("limiters created = %d, want 2", n)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := c.Get(ctx, a.URL); err == nil {
+		t.Error("second request to the same host succeeded")
+	}
+}
@@ -0,0 +1,73 @@
+package httpclient
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ErrTooManyRedirects is returned, wrapped in a *url.Error, when a request
+// is redirected more often than Config.MaxRedirects allows.
+var ErrTooManyRedirects = errors.New("too many redirects")
+
+// credentialHeaders are removed from requests redirected to another origin
+// unless Config.ForwardAuthorization is set.
+var credentialHeaders = []string{"Authorization", "Www-Authenticate", "Cookie", "Cookie2"}
+
+// checkRedirect returns the http.Client.CheckRedirect policy configured by
+// the redirect fields of cfg.
+func (cfg Config) checkRedirect() func(*http.Request, []*http.Request) error {
+	limit := cfg.MaxRedirects
+	if limit == 0 {
+		limit = 10
+	}
+	return func(req *http.Request, via []*http.Request) error {
+		if limit < 0 {
+			return http.ErrUseLastResponse
+		}
+		if len(via) > limit {
+			return fmt.Errorf("%w: stopped after %d", ErrTooManyRedirects, limit)
+		}
+
+		if cfg.ForwardAuthorization {
+			// net/http drops it for hosts other than the original and its
+			// subdomains
+			if auth := via[0].Header.Get("Authorization"); auth != "" {
+				req.Header.Set("Authorization", auth)
+			}
+		} else if leftOrigin(req, via) {
+			for _, h := range credentialHeaders {
+				req.Header.Del(h)
+			}
+		}
+
+		if cfg.OnRedirect != nil {
+			return cfg.OnRedirect(req, via)
+		}
+		return nil
+	}
+}
+
+// leftOrigin reports whether req or any earlier hop in via has a scheme or
+// host other than the original request. Once a redirect left the origin,
+// credentials are not restored for later hops that return to it.
+func leftOrigin(req *http.Request, via []*http.Request) bool {
+	origin := via[0].URL
+	if !sameOrigin(origin, req.URL) {
+		return true
+	}
+	for _, r := range via[1:] {
+		if !sameOrigin(origin, r.URL) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameOrigin reports whether a and b have the same scheme and host,
+// including the port.
+func sameOrigin(a, b *url.URL) bool {
+	return strings.EqualFold(a.Scheme, b.Scheme) && strings.EqualFold(a.Host, b.Host)
+}
@@ -0,0 +1,97 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRedirectPolicy(t *testing.T) {
+	var gotAuth, gotCookie string
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth, gotCookie = r.Header.Get("Authorization"), r.Header.Get("Cookie")
+	}))
+	defer other.Close()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/loop":
+			http.Redirect(w, r, "/loop", http.StatusFound)
+		case "/away":
+			// another hostname, which net/http alone would not strip for
+			http.Redirect(w, r, strings.Replace(other.URL, "127.0.0.1", "localhost", 1), http.StatusFound)
+		case "/port":
+			// same hostname, another port
+			http.Redirect(w, r, other.URL, http.StatusFound)
+		}
+	}))
+	defer srv.Close()
+
+	get := func(c *Client, path string) (*http.Response, error) {
+		gotAuth, gotCookie = "", ""
+		resp, err := c.Get(context.Background(), path)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return resp, err
+	}
+
+	t.Run("max redirects", func(t *testing.T) {
+		var hops int
+		c := NewWithConfig(Config{BaseURL: srv.URL, MaxRedirects: 3, OnRedirect: func(req *http.Request, via []*http.Request) error {
+			hops++
+			return nil
+		}})
+		if _, err := get(c, "/loop"); !errors.Is(err, ErrTooManyRedirects) {
+			t.Errorf("err = %v, want ErrTooManyRedirects", err)
+		}
+		if hops != 3 {
+			t.Errorf("OnRedirect called %d times, want 3", hops)
+		}
+	})
+
+	t.Run("no redirects", func(t *testing.T) {
+		c := NewWithConfig(Config{BaseURL: srv.URL, MaxRedirects: -1})
+		resp, err := get(c, "/loop")
+		if err != nil || resp.StatusCode != http.StatusFound {
+			t.Errorf("got %v, %v, want the 302 response", resp, err)
+		}
+	})
+
+	t.Run("on redirect stops", func(t *testing.T) {
+		stop := errors.New("stop")
+		c := NewWithConfig(Config{BaseURL: srv.URL, OnRedirect: func(*http.Request, []*http.Request) error { return stop }})
+		if _, err := get(c, "/away"); !errors.Is(err, stop) {
+			t.Errorf("err = %v, want the OnRedirect error", err)
+		}
+	})
+
+	for _, path := range []string{"/away", "/port"} {
+		t.Run("strips credentials "+path, func(t *testing.T) {
+			c := New().WithBaseURL(srv.URL).WithHeader("Authorization", "Bearer secret").WithHeader("Cookie", "session=1")
+			if _, err := get(c, path); err != nil {
+				t.Fatal(err)
+			}
+			if gotAuth != "" || gotCookie != "" {
+				t.Errorf("credentials forwarded: Authorization %q, Cookie %q", gotAuth, gotCookie)
+			}
+		})
+	}
+
+	t.Run("forward authorization", func(t *testing.T) {
+		c := NewWithConfig(Config{
+			BaseURL:              srv.URL,
+			Headers:              map[string]string{"Authorization": "Bearer secret"},
+			ForwardAuthorization: true,
+		})
+		if _, err := get(c, "/away"); err != nil {
+			t.Fatal(err)
+		}
+		if gotAuth != "Bearer secret" {
+			t.Errorf("Authorization = %q, want it forwarded", gotAuth)
+		}
+	})
+}
@@ -0,0 +1,103 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/en9inerd/go-pkgs/ratelimit"
+)
+
+// retryStatusError is the error an attempt fails with when the response
+// status is retryable.
+type retryStatusError struct {
+	statusCode int
+}
+
+func (e *retryStatusError) Error() string {
+	return fmt.Sprintf("retryable http status %d", e.statusCode)
+}
+
+// retryableStatus reports whether a response with code is worth retrying.
+func retryableStatus(code int) bool {
+	return code >= 500 || code == http.StatusTooManyRequests
+}
+
+// replayable reports whether req can be sent more than once.
+func replayable(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// doRetry sends req with the client's retry strategy. Between attempts it
+// waits as long as a Retry-After or rate limit reset header of the last
+// response asks, capped at maxRetryAfter, or else the strategy's backoff.
+func (c *Client) doRetry(ctx context.Context, req *http.Request) (*http.Response, error) {
+	s := c.retry
+	maxAttempts := max(s.MaxAttempts, 1)
+	for attempt := 1; ; attempt++ {
+		r := req
+		if attempt > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, fmt.Errorf("replay body: %w", err)
+			}
+			r = req.Clone(ctx)
+			r.Body = body
+		}
+
+		delay := s.Delay(attempt - 1)
+		resp, err := c.attempt(ctx, r, attempt)
+		if err != nil {
+			// retrying an open circuit would only be rejected again
+			if errors.Is(err, ErrCircuitOpen) || (s.RetryableErrors != nil && !s.RetryableErrors(err)) {
+				return nil, err
+			}
+			if attempt >= maxAttempts {
+				return nil, fmt.Errorf("max attempts (%d) reached: %w", maxAttempts, err)
+			}
+		} else {
+			if !retryableStatus(resp.StatusCode) || attempt >= maxAttempts {
+				return resp, nil
+			}
+			if s.RetryableErrors != nil && !s.RetryableErrors(&retryStatusError{statusCode: resp.StatusCode}) {
+				return resp, nil
+			}
+			if d, ok := c.serverDelay(resp); ok {
+				delay = d
+			}
+			// drain a bounded amount so the connection can be reused
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+		}
+
+		if c.logger != nil {
+			c.logger.Debug("retrying http request", "method", req.Method, "url", SanitizeURL(req.URL), "attempt", attempt, "delay", delay)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// serverDelay returns the delay before retrying that resp asks for through
+// its Retry-After header or, on 429 or an exhausted quota, the reset time of
+// its rate limit headers, capped at maxRetryAfter.
+func (c *Client) serverDelay(resp *http.Response) (time.Duration, bool) {
+	delay, ok := ratelimit.ParseRetryAfter(resp.Header.Get("Retry-After"))
+	if !ok {
+		q, found := ratelimit.ParseHeaders(resp.Header)
+		if !found || q.Reset <= 0 || (q.Remaining > 0 && resp.StatusCode != http.StatusTooManyRequests) {
+			return 0, false
+		}
+		delay = q.Reset
+	}
+	if c.maxRetryAfter > 0 {
+		delay = min(delay, c.maxRetryAfter)
+	}
+	return delay, true
+}
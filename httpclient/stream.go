@@ -0,0 +1,88 @@
+package httpclient
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// GetStream performs a GET request and decodes the response body one element
+// at a time, calling fn for each, without buffering the whole body. The body
+// may be newline-delimited JSON (or any sequence of JSON values) or a single
+// top-level JSON array. An error from fn stops the stream and is returned.
+//
+//	err := httpclient.GetStream(ctx, c, "/export", func(e Event) error {
+//		return store.Save(ctx, e)
+//	})
+func GetStream[T any](ctx context.Context, c *Client, path string, fn func(T) error, opts ...RequestOption) error {
+	opts = append([]RequestOption{WithHeader("Accept", "application/x-ndjson, application/json")}, opts...)
+	resp, err := c.Get(ctx, path, opts...)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return ErrorFromResponse(resp, c.errorParser)
+	}
+	return decodeStream(resp.Body, fn)
+}
+
+// decodeStream decodes the JSON values of r, or the elements of a JSON array
+// if r holds one, into fn.
+func decodeStream[T any](r io.Reader, fn func(T) error) error {
+	br := bufio.NewReader(r)
+	array, err := startsWithArray(br)
+	if err != nil {
+		return err
+	}
+
+	dec := json.NewDecoder(br)
+	if array {
+		if _, err := dec.Token(); err != nil {
+			return fmt.Errorf("decode json: %w", err)
+		}
+	}
+	for n := 0; ; n++ {
+		if array && !dec.More() {
+			break
+		}
+		var v T
+		if err := dec.Decode(&v); err != nil {
+			if !array && errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("decode json element %d: %w", n, err)
+		}
+		if err := fn(v); err != nil {
+			return err
+		}
+	}
+	if _, err := dec.Token(); err != nil {
+		return fmt.Errorf("decode json: %w", err)
+	}
+	return nil
+}
+
+// startsWithArray reports whether the first non-space byte of br opens a
+// JSON array, without consuming it.
+func startsWithArray(br *bufio.Reader) (bool, error) {
+	for {
+		b, err := br.Peek(1)
+		if errors.Is(err, io.EOF) {
+			return false, nil
+		}
+		if err != nil {
+			return false, fmt.Errorf("read body: %w", err)
+		}
+		switch b[0] {
+		case ' ', '\t', '\r', '\n':
+			br.Discard(1)
+		default:
+			return b[0] == '[', nil
+		}
+	}
+}
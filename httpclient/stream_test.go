@@ -0,0 +1,63 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGetStream(t *testing.T) {
+	bodies := map[string]string{
+		"/ndjson": "{\"id\":1}\n{\"id\":2}\n{\"id\":3}\n",
+		"/array":  " \n[{\"id\":1}, {\"id\":2},\n {\"id\":3}]\n",
+		"/empty":  "[]",
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(bodies[r.URL.Path]))
+	}))
+	defer srv.Close()
+	c := New().WithBaseURL(srv.URL)
+
+	type item struct {
+		ID int `json:"id"`
+	}
+	for _, path := range []string{"/ndjson", "/array"} {
+		var ids []int
+		err := GetStream(context.Background(), c, path, func(it item) error {
+			ids = append(ids, it.ID)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		if len(ids) != 3 || ids[0] != 1 || ids[2] != 3 {
+			t.Errorf("%s: ids = %v, want [1 2 3]", path, ids)
+		}
+	}
+
+	calls := 0
+	err := GetStream(context.Background(), c, "/empty", func(it item) error { calls++; return nil })
+	if err != nil || calls != 0 {
+		t.Errorf("empty array: err = %v, calls = %d", err, calls)
+	}
+
+	stop := errors.New("stop")
+	calls = 0
+	err = GetStream(context.Background(), c, "/array", func(it item) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) || calls != 1 {
+		t.Errorf("callback error: err = %v, calls = %d", err, calls)
+	}
+}
+
+func TestDecodeStream_Malformed(t *testing.T) {
+	err := decodeStream(strings.NewReader(`[{"id":1},{"id":`), func(v map[string]int) error { return nil })
+	if err == nil || !strings.Contains(err.Error(), "element 1") {
+		t.Errorf("err = %v, want an error for element 1", err)
+	}
+}
@@ -0,0 +1,38 @@
+package httpclient
+
+import (
+	"net"
+	"net/http"
+	"time"
+)
+
+// transport returns the transport tuned by the transport fields of cfg, or
+// nil to use http.DefaultTransport if none is set.
+func (cfg Config) transport() http.RoundTripper {
+	if cfg.ProxyURL == nil && cfg.MaxIdleConns == 0 && cfg.MaxIdleConnsPerHost == 0 &&
+		cfg.IdleConnTimeout == 0 && cfg.DialTimeout == 0 && cfg.TLSHandshakeTimeout == 0 {
+		return nil
+	}
+
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	if cfg.ProxyURL != nil {
+		t.Proxy = http.ProxyURL(cfg.ProxyURL)
+	}
+	if cfg.MaxIdleConns != 0 {
+		t.MaxIdleConns = cfg.MaxIdleConns
+	}
+	if cfg.MaxIdleConnsPerHost != 0 {
+		t.MaxIdleConnsPerHost = cfg.MaxIdleConnsPerHost
+	}
+	if cfg.IdleConnTimeout != 0 {
+		t.IdleConnTimeout = cfg.IdleConnTimeout
+	}
+	if cfg.DialTimeout != 0 {
+		dialer := &net.Dialer{Timeout: cfg.DialTimeout, KeepAlive: 30 * time.Second}
+		t.DialContext = dialer.DialContext
+	}
+	if cfg.TLSHandshakeTimeout != 0 {
+		t.TLSHandshakeTimeout = cfg.TLSHandshakeTimeout
+	}
+	return t
+}
@@ -0,0 +1,50 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestNewWithConfig_Transport(t *testing.T) {
+	if c := NewWithConfig(Config{}); c.httpClient.Transport != nil {
+		t.Errorf("transport = %T, want nil (http.DefaultTransport)", c.httpClient.Transport)
+	}
+
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+	}))
+	defer proxy.Close()
+	proxyURL, _ := url.Parse(proxy.URL)
+
+	c := NewWithConfig(Config{
+		ProxyURL:            proxyURL,
+		MaxIdleConnsPerHost: 32,
+		IdleConnTimeout:     time.Minute,
+		DialTimeout:         time.Second,
+		TLSHandshakeTimeout: 2 * time.Second,
+	})
+	tr, ok := c.httpClient.Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("transport = %T, want *http.Transport", c.httpClient.Transport)
+	}
+	if tr.MaxIdleConnsPerHost != 32 || tr.IdleConnTimeout != time.Minute || tr.TLSHandshakeTimeout != 2*time.Second {
+		t.Errorf("transport not tuned: %+v", tr)
+	}
+	if tr.MaxIdleConns != http.DefaultTransport.(*http.Transport).MaxIdleConns {
+		t.Errorf("MaxIdleConns = %d, want the default", tr.MaxIdleConns)
+	}
+
+	resp, err := c.Get(context.Background(), "http://upstream.invalid/path")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if proxied != "http://upstream.invalid/path" {
+		t.Errorf("proxy saw %q", proxied)
+	}
+}
@@ -0,0 +1,111 @@
+package httpclient
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// buildURL resolves path against the base URL. The path is appended to the
+// base path, even if it starts with a slash, and the query strings of both
+// are kept. A path whose ".." segments climb above the base path, or that
+// names another host, is rejected. An absolute URL is used as is if it has
+// the scheme and host of the base URL, and rejected otherwise, so the
+// client's credentials and default headers only go to the base URL's origin.
+func (c *Client) buildURL(path string) (string, error) {
+	if c.baseURL == "" {
+		return path, nil
+	}
+	ref, err := url.Parse(path)
+	if err != nil {
+		return "", fmt.Errorf("parse path: %w", err)
+	}
+	base, err := url.Parse(c.baseURL)
+	if err != nil {
+		return "", fmt.Errorf("parse base URL: %w", err)
+	}
+	if ref.IsAbs() {
+		if !sameOrigin(base, ref) {
+			return "", fmt.Errorf("URL %q is outside the origin of the base URL", path)
+		}
+		return path, nil
+	}
+	if ref.Host != "" || escapesRoot(ref.Path) {
+		return "", fmt.Errorf("path %q escapes the base URL", path)
+	}
+
+	u := *base
+	if ref.Path != "" {
+		u.Path = joinPath(base.Path, ref.Path)
+		u.RawPath = joinPath(base.EscapedPath(), ref.EscapedPath())
+	}
+	if ref.RawQuery != "" {
+		if u.RawQuery != "" {
+			u.RawQuery += "&"
+		}
+		u.RawQuery += ref.RawQuery
+	}
+	u.Fragment, u.RawFragment = ref.Fragment, ref.RawFragment
+	return u.String(), nil
+}
+
+// joinPath joins two URL paths with exactly one slash between them
+func joinPath(a, b string) string {
+	return strings.TrimSuffix(a, "/") + "/" + strings.TrimPrefix(b, "/")
+}
+
+// escapesRoot reports whether the ".." segments of path climb above its
+// root.
+func escapesRoot(path string) bool {
+	depth := 0
+	for seg := range strings.SplitSeq(path, "/") {
+		switch seg {
+		case "", ".":
+		case "..":
+			if depth--; depth < 0 {
+				return true
+			}
+		default:
+			depth++
+		}
+	}
+	return false
+}
+
+// WithPathParam sets the value of the {name} parameter of a path template
+// such as "/users/{id}". The value is escaped as a single path segment.
+// Unless WithRoute is given, the template is the request's metrics route.
+func WithPathParam(name, value string) RequestOption {
+	return func(o *requestOptions) {
+		if o.pathParams == nil {
+			o.pathParams = make(map[string]string)
+		}
+		o.pathParams[name] = value
+	}
+}
+
+// expandPath replaces the {name} parameters of the path template with their
+// escaped values, failing if any parameter has no value.
+func expandPath(template string, params map[string]string) (string, error) {
+	var b strings.Builder
+	rest := template
+	for {
+		start := strings.IndexByte(rest, '{')
+		if start < 0 {
+			b.WriteString(rest)
+			return b.String(), nil
+		}
+		end := strings.IndexByte(rest[start:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("path %q: unclosed parameter", template)
+		}
+		name := rest[start+1 : start+end]
+		value, ok := params[name]
+		if !ok {
+			return "", fmt.Errorf("path %q: no value for parameter %q", template, name)
+		}
+		b.WriteString(rest[:start])
+		b.WriteString(url.PathEscape(value))
+		rest = rest[start+end+1:]
+	}
+}
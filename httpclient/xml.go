@@ -0,0 +1,68 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// acceptXML is the Accept header sent by the XML helpers
+const acceptXML = "application/xml, text/xml;q=0.9"
+
+// GetXML performs a GET request and decodes the XML response
+func (c *Client) GetXML(ctx context.Context, path string, target any, opts ...RequestOption) error {
+	opts = append([]RequestOption{WithHeader("Accept", acceptXML)}, opts...)
+	resp, err := c.Get(ctx, path, opts...)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return c.decodeXML(resp, target)
+}
+
+// PostXML performs a POST request with XML body and decodes the XML response.
+// The body is preceded by the standard XML declaration; a nil body sends none.
+func (c *Client) PostXML(ctx context.Context, path string, body any, target any, opts ...RequestOption) error {
+	opts = append([]RequestOption{WithHeader("Accept", acceptXML)}, opts...)
+
+	var r io.Reader
+	contentType := ""
+	if body != nil {
+		data, err := xml.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("marshal xml: %w", err)
+		}
+		r = bytes.NewBuffer(append([]byte(xml.Header), data...))
+		contentType = "application/xml; charset=utf-8"
+	}
+
+	resp, err := c.request(ctx, http.MethodPost, path, r, contentType, opts)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return c.decodeXML(resp, target)
+}
+
+// DecodeXMLResponse decodes an XML response from an HTTP response. A non-2xx
+// response is returned as an *httperrors.APIError, see ErrorFromResponse.
+func DecodeXMLResponse(resp *http.Response, target any) error {
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return ErrorFromResponse(resp, nil)
+	}
+
+	if target == nil {
+		return nil
+	}
+
+	if err := xml.NewDecoder(resp.Body).Decode(target); err != nil {
+		return fmt.Errorf("decode xml: %w", err)
+	}
+
+	return nil
+}
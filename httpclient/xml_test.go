@@ -0,0 +1,61 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/en9inerd/go-pkgs/httperrors"
+)
+
+type xmlItem struct {
+	XMLName xml.Name `xml:"item"`
+	ID      int      `xml:"id,attr"`
+	Name    string   `xml:"name"`
+}
+
+func TestPostXML(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ct := r.Header.Get("Content-Type"); ct != "application/xml; charset=utf-8" {
+			t.Errorf("Content-Type = %q", ct)
+		}
+		if !strings.Contains(r.Header.Get("Accept"), "application/xml") {
+			t.Errorf("Accept = %q", r.Header.Get("Accept"))
+		}
+		var in xmlItem
+		if err := xml.NewDecoder(r.Body).Decode(&in); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		in.ID = 7
+		w.Header().Set("Content-Type", "application/xml")
+		xml.NewEncoder(w).Encode(in)
+	}))
+	defer srv.Close()
+
+	c := New().WithBaseURL(srv.URL)
+	var got xmlItem
+	if err := c.PostXML(context.Background(), "/items", xmlItem{Name: "widget"}, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != 7 || got.Name != "widget" {
+		t.Errorf("got %+v", got)
+	}
+}
+
+func TestGetXML_HTTPError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "<error>nope</error>", http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	var got xmlItem
+	err := New().WithBaseURL(srv.URL).GetXML(context.Background(), "/", &got)
+	var apiErr *httperrors.APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != http.StatusBadRequest || !strings.Contains(apiErr.Details, "nope") {
+		t.Errorf("err = %v, want an APIError with code 400", err)
+	}
+}
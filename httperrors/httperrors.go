@@ -70,6 +70,10 @@ type APIError struct {
 	Message string `json:"message"`
 	Details string `json:"details,omitempty"`
 	Err     error  `json:"-"`
+
+	// Body is the raw body of the API's error response, if any. It is not
+	// written by WriteJSON.
+	Body []byte `json:"-"`
 }
 
 // Error implements the error interface
@@ -183,6 +187,23 @@ func NewNetworkError(message string, err error) *NetworkError {
 	}
 }
 
+// WriteError writes err as a JSON response. An *Error or *ValidationError
+// anywhere in the chain writes itself with its own status code; any other
+// error becomes a 500 response that does not expose the error message.
+func WriteError(w http.ResponseWriter, err error) {
+	var he *Error
+	if errors.As(err, &he) {
+		he.WriteJSON(w)
+		return
+	}
+	var ve *ValidationError
+	if errors.As(err, &ve) {
+		ve.WriteJSON(w)
+		return
+	}
+	NewError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError)).WriteJSON(w)
+}
+
 // IsValidationError checks if an error is a ValidationError
 func IsValidationError(err error) bool {
 	var ve *ValidationError
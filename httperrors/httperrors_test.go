@@ -3,6 +3,7 @@ package httperrors
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -238,3 +239,31 @@ func TestIsHTTPError(t *testing.T) {
 		t.Error("expected IsHTTPError to return false")
 	}
 }
+
+func TestWriteError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantCode int
+		wantBody string
+	}{
+		{"http error", NewError(http.StatusUnsupportedMediaType, "bad type"), http.StatusUnsupportedMediaType, "bad type"},
+		{"wrapped http error", fmt.Errorf("decode: %w", NewError(http.StatusConflict, "conflict")), http.StatusConflict, "conflict"},
+		{"validation error", NewValidationError(map[string][]string{"name": {"required"}}, nil), http.StatusBadRequest, "required"},
+		{"plain error", errors.New("db password is hunter2"), http.StatusInternalServerError, "Internal Server Error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			WriteError(w, tt.err)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantCode)
+			}
+			if body := w.Body.String(); !strings.Contains(body, tt.wantBody) || strings.Contains(body, "hunter2") {
+				t.Errorf("body = %q", body)
+			}
+		})
+	}
+}
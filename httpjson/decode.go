@@ -0,0 +1,112 @@
+package httpjson
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/en9inerd/go-pkgs/httperrors"
+)
+
+// DefaultMaxBodySize is the body size limit DecodeRequest applies when
+// maxSize is zero.
+const DefaultMaxBodySize = 1 << 20
+
+// DecodeRequest decodes a JSON request body into target strictly and
+// returns errors already classified as *httperrors.Error, so a handler can
+// pass them straight to httperrors.WriteError:
+//
+//   - 415 Unsupported Media Type if Content-Type is not application/json
+//     or a +json type (parameters such as charset are allowed)
+//   - 413 Content Too Large if the body exceeds maxSize bytes
+//     (DefaultMaxBodySize if maxSize is zero)
+//   - 400 Bad Request for an empty body, malformed JSON or trailing data
+//     after the JSON value
+//   - 422 Unprocessable Entity for well-formed JSON that does not fit
+//     target: unknown fields or values of the wrong type
+//
+// The error message is safe to show to clients; the underlying decoding
+// error is available through errors.Unwrap.
+//
+//	var req CreateUserRequest
+//	if err := httpjson.DecodeRequest(w, r, &req, 0); err != nil {
+//		httperrors.WriteError(w, err)
+//		return
+//	}
+func DecodeRequest[T any](w http.ResponseWriter, r *http.Request, target *T, maxSize int64) error {
+	if err := checkJSONContentType(r.Header.Get("Content-Type")); err != nil {
+		return err
+	}
+	if maxSize <= 0 {
+		maxSize = DefaultMaxBodySize
+	}
+
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxSize))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(target); err != nil {
+		return classifyDecodeError(err)
+	}
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			return classifyDecodeError(err)
+		}
+		return httperrors.NewErrorWithErr(http.StatusBadRequest,
+			"request body must contain a single JSON value", err)
+	}
+	return nil
+}
+
+// checkJSONContentType returns a 415 error unless contentType is JSON.
+func checkJSONContentType(contentType string) error {
+	if contentType == "" {
+		return httperrors.NewError(http.StatusUnsupportedMediaType, "Content-Type must be application/json")
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return httperrors.NewErrorWithErr(http.StatusUnsupportedMediaType, "Content-Type must be application/json", err)
+	}
+	if mediaType != "application/json" && !strings.HasSuffix(mediaType, "+json") {
+		return httperrors.NewError(http.StatusUnsupportedMediaType, "Content-Type must be application/json")
+	}
+	return nil
+}
+
+// classifyDecodeError maps a json.Decoder error to an HTTP error.
+func classifyDecodeError(err error) error {
+	var (
+		syntaxErr *json.SyntaxError
+		typeErr   *json.UnmarshalTypeError
+		maxErr    *http.MaxBytesError
+	)
+	switch {
+	case errors.As(err, &maxErr):
+		return httperrors.NewErrorWithErr(http.StatusRequestEntityTooLarge,
+			fmt.Sprintf("request body must not be larger than %d bytes", maxErr.Limit), err)
+	case errors.Is(err, io.EOF):
+		return httperrors.NewErrorWithErr(http.StatusBadRequest, "request body must not be empty", err)
+	case errors.As(err, &syntaxErr):
+		return httperrors.NewErrorWithErr(http.StatusBadRequest,
+			fmt.Sprintf("request body contains malformed JSON at position %d", syntaxErr.Offset), err)
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return httperrors.NewErrorWithErr(http.StatusBadRequest, "request body contains malformed JSON", err)
+	case errors.As(err, &typeErr):
+		if typeErr.Field != "" {
+			return httperrors.NewErrorWithErr(http.StatusUnprocessableEntity,
+				fmt.Sprintf("field %q must be of type %s", typeErr.Field, typeErr.Type), err)
+		}
+		return httperrors.NewErrorWithErr(http.StatusUnprocessableEntity,
+			fmt.Sprintf("request body must be a JSON %s", typeErr.Type), err)
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		// encoding/json has no error type for unknown fields
+		field := strings.TrimPrefix(err.Error(), "json: unknown field ")
+		return httperrors.NewErrorWithErr(http.StatusUnprocessableEntity,
+			fmt.Sprintf("request body contains unknown field %s", field), err)
+	default:
+		return httperrors.NewErrorWithErr(http.StatusBadRequest, "request body could not be decoded", err)
+	}
+}
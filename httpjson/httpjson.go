@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"net/http"
 	"time"
+
+	"github.com/en9inerd/go-pkgs/conditional"
 )
 
 // JSON is a convenience alias for a generic JSON object
@@ -52,6 +54,22 @@ func WriteJSONWithStatus(w http.ResponseWriter, code int, data any) {
 	writeResponse(w, encoded, code)
 }
 
+// WriteJSONWithETag encodes data, sets a strong ETag derived from the encoded
+// bytes and answers conditional requests (If-None-Match, If-Match) with
+// 304 or 412 using the conditional package, so the semantics match
+// middleware.ETag. Otherwise it writes the JSON with HTTP 200.
+func WriteJSONWithETag(w http.ResponseWriter, r *http.Request, data any) {
+	encoded, err := encodeJSON(data, true)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if conditional.Check(w, r, conditional.StrongETag(encoded), time.Time{}) {
+		return
+	}
+	writeResponse(w, encoded, 0)
+}
+
 // WriteJSONBytes writes pre-encoded JSON bytes to the response
 func WriteJSONBytes(w http.ResponseWriter, data []byte) {
 	writeResponse(w, data, 0)
@@ -3,11 +3,14 @@ package httpjson
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+
+	"github.com/en9inerd/go-pkgs/httperrors"
 )
 
 func TestWriteJSON(t *testing.T) {
@@ -44,6 +47,33 @@ func TestWriteJSONWithStatus(t *testing.T) {
 	}
 }
 
+func TestWriteJSONWithETag(t *testing.T) {
+	data := JSON{"id": 42}
+
+	w := httptest.NewRecorder()
+	WriteJSONWithETag(w, httptest.NewRequest("GET", "/", nil), data)
+	etag := w.Header().Get("ETag")
+	if w.Code != http.StatusOK || etag == "" {
+		t.Fatalf("status = %d, ETag = %q", w.Code, etag)
+	}
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	WriteJSONWithETag(w, r, data)
+	if w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+		t.Errorf("status = %d, body = %q, want 304 with empty body", w.Code, w.Body.String())
+	}
+
+	r = httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("If-None-Match", `"stale"`)
+	w = httptest.NewRecorder()
+	WriteJSONWithETag(w, r, data)
+	if w.Code != http.StatusOK {
+		t.Errorf("status = %d, want 200 for stale ETag", w.Code)
+	}
+}
+
 func TestWriteJSONBytes(t *testing.T) {
 	w := httptest.NewRecorder()
 	data := []byte(`{"raw":true}`)
@@ -202,3 +232,62 @@ func TestEncodeJSON_RoundTrip(t *testing.T) {
 		t.Errorf("count = %v", decoded["count"])
 	}
 }
+
+func TestDecodeRequest(t *testing.T) {
+	type payload struct {
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+	}
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		maxSize     int64
+		wantCode    int
+	}{
+		{"valid", "application/json", `{"name":"a","age":3}`, 0, 0},
+		{"charset", "application/json; charset=utf-8", `{"name":"a"}`, 0, 0},
+		{"vendor type", "application/vnd.api+json", `{"name":"a"}`, 0, 0},
+		{"missing content type", "", `{"name":"a"}`, 0, http.StatusUnsupportedMediaType},
+		{"wrong content type", "text/plain", `{"name":"a"}`, 0, http.StatusUnsupportedMediaType},
+		{"malformed content type", "application/json; =", `{"name":"a"}`, 0, http.StatusUnsupportedMediaType},
+		{"too large", "application/json", `{"name":"` + strings.Repeat("a", 100) + `"}`, 50, http.StatusRequestEntityTooLarge},
+		{"empty", "application/json", ``, 0, http.StatusBadRequest},
+		{"syntax", "application/json", `{"name":}`, 0, http.StatusBadRequest},
+		{"truncated", "application/json", `{"name":"a"`, 0, http.StatusBadRequest},
+		{"trailing value", "application/json", `{"name":"a"}{}`, 0, http.StatusBadRequest},
+		{"wrong type", "application/json", `{"age":"old"}`, 0, http.StatusUnprocessableEntity},
+		{"not an object", "application/json", `[1,2]`, 0, http.StatusUnprocessableEntity},
+		{"unknown field", "application/json", `{"nickname":"a"}`, 0, http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				r.Header.Set("Content-Type", tt.contentType)
+			}
+
+			var p payload
+			err := DecodeRequest(httptest.NewRecorder(), r, &p, tt.maxSize)
+			if tt.wantCode == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if p.Name != "a" {
+					t.Errorf("Name = %q, want a", p.Name)
+				}
+				return
+			}
+
+			var he *httperrors.Error
+			if !errors.As(err, &he) {
+				t.Fatalf("error %v is not an *httperrors.Error", err)
+			}
+			if he.Code != tt.wantCode {
+				t.Errorf("code = %d, want %d (%v)", he.Code, tt.wantCode, err)
+			}
+		})
+	}
+}
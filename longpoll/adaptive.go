@@ -0,0 +1,112 @@
+package longpoll
+
+import (
+	"errors"
+	"net"
+	neturl "net/url"
+	"strconv"
+	"time"
+)
+
+// AdaptiveTimeout tunes how long each poll asks the server to hold the
+// request, and how long the client waits for it, from the hold times
+// actually observed. See Config.AdaptiveTimeout.
+type AdaptiveTimeout struct {
+	// Param is the query parameter that carries the requested hold time in
+	// whole seconds. Default: "timeout"
+	Param string
+
+	// Min is the shortest hold time requested. Default: 1 second
+	Min time.Duration
+
+	// Max is the longest hold time requested, and the first one tried.
+	// Default: PollTimeout
+	Max time.Duration
+
+	// Margin is added to the hold time to form the client timeout of each
+	// request, covering network latency. Default: 5 seconds
+	Margin time.Duration
+}
+
+// adaptiveState is the tuning state of one poll loop.
+type adaptiveState struct {
+	cfg AdaptiveTimeout
+
+	// hold is the hold time requested from the server.
+	hold time.Duration
+
+	// slack is how much longer than requested the server has been seen to
+	// hold requests; it extends the client timeout.
+	slack time.Duration
+}
+
+// newAdaptiveState returns the initial state for cfg, filling in defaults.
+func newAdaptiveState(cfg AdaptiveTimeout, pollTimeout time.Duration) *adaptiveState {
+	if cfg.Param == "" {
+		cfg.Param = "timeout"
+	}
+	if cfg.Min <= 0 {
+		cfg.Min = time.Second
+	}
+	if cfg.Max <= 0 {
+		cfg.Max = pollTimeout
+	}
+	cfg.Max = max(cfg.Max, cfg.Min)
+	if cfg.Margin <= 0 {
+		cfg.Margin = 5 * time.Second
+	}
+	return &adaptiveState{cfg: cfg, hold: cfg.Max}
+}
+
+// url returns rawURL with the hold time query parameter set.
+func (a *adaptiveState) url(rawURL string) string {
+	u, err := neturl.Parse(rawURL)
+	if err != nil {
+		return rawURL // the request will fail with a proper error
+	}
+	q := u.Query()
+	q.Set(a.cfg.Param, strconv.Itoa(int(a.hold/time.Second)))
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+// timeout returns the client timeout for the next request.
+func (a *adaptiveState) timeout() time.Duration {
+	return a.hold + a.slack + a.cfg.Margin
+}
+
+// observe adjusts the state after a request that took elapsed and failed
+// with err, if not nil. It reports whether the hold time or client timeout
+// changed.
+//
+//   - A response arriving later than requested extends the client timeout.
+//   - A response arriving only at the end of the hold time shows the server
+//     honors it, so a previously lowered hold time is raised again.
+//   - A client timeout means the server holds longer than expected: the
+//     client timeout grows by Margin.
+//   - A connection dropped while the server was holding it, typically by a
+//     proxy with a shorter idle timeout, lowers the hold time below the
+//     point where the drop happened.
+func (a *adaptiveState) observe(elapsed time.Duration, err error) bool {
+	hold, slack := a.hold, a.slack
+
+	var netErr net.Error
+	var statusErr *StatusError
+	switch {
+	case err == nil:
+		if elapsed > a.hold {
+			a.slack = min(max(a.slack, elapsed-a.hold), a.cfg.Max)
+		}
+		if elapsed >= a.hold*9/10 {
+			a.hold = min(a.hold*2, a.cfg.Max)
+		}
+	case errors.As(err, &netErr) && netErr.Timeout():
+		a.slack = min(a.slack+a.cfg.Margin, a.cfg.Max)
+	case errors.As(err, &statusErr), IsConnectError(err):
+		// the server answered or was never reached; nothing to learn
+	case elapsed >= a.cfg.Min && elapsed < a.hold:
+		a.hold = max((elapsed * 9 / 10).Truncate(time.Second), a.cfg.Min)
+	}
+
+	return a.hold != hold || a.slack != slack
+}
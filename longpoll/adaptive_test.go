@@ -0,0 +1,89 @@
+package longpoll
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestAdaptiveState_Observe(t *testing.T) {
+	cfg := AdaptiveTimeout{Min: time.Second, Max: 50 * time.Second, Margin: 5 * time.Second}
+
+	tests := []struct {
+		name      string
+		hold      time.Duration
+		elapsed   time.Duration
+		err       error
+		wantHold  time.Duration
+		wantSlack time.Duration
+	}{
+		{"early data keeps state", 50 * time.Second, 3 * time.Second, nil, 50 * time.Second, 0},
+		{"late response extends timeout", 50 * time.Second, 53 * time.Second, nil, 50 * time.Second, 3 * time.Second},
+		{"full hold raises lowered hold", 20 * time.Second, 20 * time.Second, nil, 40 * time.Second, 0},
+		{"raise is capped at Max", 40 * time.Second, 40 * time.Second, nil, 50 * time.Second, 0},
+		{"client timeout grows slack", 50 * time.Second, 55 * time.Second, timeoutError{}, 50 * time.Second, 5 * time.Second},
+		{"dropped connection lowers hold", 50 * time.Second, 30 * time.Second, io.ErrUnexpectedEOF, 27 * time.Second, 0},
+		{"lowered hold is clamped at Min", 50 * time.Second, time.Second, io.ErrUnexpectedEOF, time.Second, 0},
+		{"status error is ignored", 50 * time.Second, 30 * time.Second, &StatusError{StatusCode: 502}, 50 * time.Second, 0},
+		{"connect error is ignored", 50 * time.Second, 2 * time.Second, &ConnectError{Op: "dial", Err: errors.New("refused")}, 50 * time.Second, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newAdaptiveState(cfg, time.Minute)
+			a.hold = tt.hold
+			a.observe(tt.elapsed, tt.err)
+			if a.hold != tt.wantHold || a.slack != tt.wantSlack {
+				t.Errorf("hold, slack = %v, %v; want %v, %v", a.hold, a.slack, tt.wantHold, tt.wantSlack)
+			}
+		})
+	}
+}
+
+func TestAdaptiveState_URLAndTimeout(t *testing.T) {
+	a := newAdaptiveState(AdaptiveTimeout{}, 30*time.Second)
+	if got, want := a.url("https://api.example.com/updates?offset=7"), "https://api.example.com/updates?offset=7&timeout=30"; got != want {
+		t.Errorf("url = %q, want %q", got, want)
+	}
+	if got := a.timeout(); got != 35*time.Second {
+		t.Errorf("timeout = %v, want 35s", got)
+	}
+
+	a.hold = 12 * time.Second
+	if got, want := a.url("https://api.example.com/updates?timeout=30"), "https://api.example.com/updates?timeout=12"; got != want {
+		t.Errorf("url = %q, want %q", got, want)
+	}
+}
+
+func TestClient_Poll_AdaptiveTimeout(t *testing.T) {
+	var got []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.URL.Query().Get("wait"))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewWithConfig(Config{
+		PollTimeout:     time.Second,
+		AdaptiveTimeout: &AdaptiveTimeout{Param: "wait", Max: 20 * time.Second},
+	})
+
+	err := client.Poll(context.Background(), server.URL+"/updates?offset=1", func(resp *http.Response) (string, bool, error) {
+		return "", false, nil
+	})
+	if err != nil {
+		t.Fatalf("Poll: %v", err)
+	}
+	if len(got) != 1 || got[0] != "20" {
+		t.Errorf("wait params = %v, want [20]", got)
+	}
+}
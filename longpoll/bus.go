@@ -0,0 +1,300 @@
+package longpoll
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrSlowConsumer is reported by Subscriber.Err when the subscriber was
+// disconnected by the Disconnect policy because its buffer was full.
+var ErrSlowConsumer = errors.New("longpoll: slow consumer disconnected")
+
+// Update is a decoded item published on a client's event bus.
+type Update struct {
+	// Poll is the name of the poll that published the update: the name
+	// given to PollNamed or, for other polls, the host of the polled URL.
+	Poll string
+
+	// Data is the decoded item.
+	Data any
+}
+
+// SlowConsumerPolicy decides what Publish does when a subscriber's buffer
+// is full.
+type SlowConsumerPolicy int
+
+const (
+	// DropNewest discards the update for that subscriber.
+	DropNewest SlowConsumerPolicy = iota
+
+	// DropOldest discards the oldest buffered update to make room.
+	DropOldest
+
+	// Block waits until the subscriber has room, which slows down the
+	// publishing poll loop.
+	Block
+
+	// Disconnect closes the subscriber; its Err returns ErrSlowConsumer.
+	Disconnect
+)
+
+// BusSubscribeOptions configures a subscriber of a Bus.
+type BusSubscribeOptions struct {
+	// BufferSize is the capacity of the subscriber's channel.
+	// Default: 16
+	BufferSize int
+
+	// Policy is applied when the buffer is full.
+	// Default: DropNewest
+	Policy SlowConsumerPolicy
+
+	// Filter, if set, selects the updates delivered to the subscriber.
+	Filter func(Update) bool
+}
+
+// Bus broadcasts updates published by poll loops to any number of
+// subscribers, each with its own buffer and slow-consumer policy.
+type Bus struct {
+	mu     sync.Mutex
+	subs   map[*Subscriber]struct{}
+	closed bool
+}
+
+// Subscriber receives updates from a Bus.
+type Subscriber struct {
+	bus     *Bus
+	opts    BusSubscribeOptions
+	ch      chan Update
+	done    chan struct{}
+	once    sync.Once
+	dropped atomic.Int64
+
+	err error // set before done is closed
+
+	mu     sync.Mutex // serializes sends with closing ch
+	closed bool
+}
+
+// Events returns the event bus of the client. Poll loops publish to it with
+// PollPublish, or handlers call Publish directly, and any number of
+// subscribers receive the updates:
+//
+//	messages := client.Events().Subscribe(longpoll.BusSubscribeOptions{BufferSize: 64})
+//	defer messages.Close()
+//	go func() {
+//		for u := range messages.Updates() {
+//			handle(u.Data.(Message))
+//		}
+//	}()
+//
+//	err := longpoll.PollPublish(ctx, client, url, decodeMessages)
+//
+// The bus is closed by Shutdown once all polls have ended, which closes the
+// channels of all subscribers.
+func (c *Client) Events() *Bus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.bus == nil {
+		c.bus = &Bus{subs: make(map[*Subscriber]struct{})}
+	}
+	return c.bus
+}
+
+// PollPublish polls url like Poll and publishes each item decoded from a
+// response on the client's event bus. It returns when polling stops, for
+// the same reasons as Poll. A Publish blocked on a slow subscriber waits as
+// long as the poll runs; PollTimeout does not limit it.
+func PollPublish[T any](ctx context.Context, c *Client, url string, decode DecodeFunc[T]) error {
+	pc, done, err := c.track(ctx, "", url)
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	bus := c.Events()
+	return pc.result(c.pollLoop(pc, url, func(resp *http.Response) (string, bool, error) {
+		items, nextURL, shouldContinue, err := decode(resp)
+		if err != nil {
+			return "", false, err
+		}
+		info, _ := FromContext(resp.Request.Context())
+		poll := info.Name
+		if poll == "" {
+			poll = pollName(info.URL)
+		}
+		for _, item := range items {
+			if err := bus.Publish(pc.ctx, Update{Poll: poll, Data: item}); err != nil {
+				return "", false, err
+			}
+		}
+		return nextURL, shouldContinue, nil
+	}))
+}
+
+// Subscribe adds a subscriber to the bus. It must be closed with Close when
+// no longer needed. Subscribing to a closed bus returns a closed subscriber.
+func (b *Bus) Subscribe(opts BusSubscribeOptions) *Subscriber {
+	if opts.BufferSize <= 0 {
+		opts.BufferSize = 16
+	}
+	s := &Subscriber{
+		bus:  b,
+		opts: opts,
+		ch:   make(chan Update, opts.BufferSize),
+		done: make(chan struct{}),
+	}
+
+	b.mu.Lock()
+	closed := b.closed
+	if !closed {
+		b.subs[s] = struct{}{}
+	}
+	b.mu.Unlock()
+	if closed {
+		s.close(nil)
+	}
+	return s
+}
+
+// Publish delivers u to every subscriber whose Filter accepts it. It only
+// waits for subscribers with the Block policy, and returns ctx.Err() if ctx
+// ends while waiting. Publishing on a closed bus does nothing.
+func (b *Bus) Publish(ctx context.Context, u Update) error {
+	b.mu.Lock()
+	subs := make([]*Subscriber, 0, len(b.subs))
+	for s := range b.subs {
+		subs = append(subs, s)
+	}
+	b.mu.Unlock()
+
+	for _, s := range subs {
+		if s.opts.Filter != nil && !s.opts.Filter(u) {
+			continue
+		}
+		if err := s.send(ctx, u); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Len returns the number of subscribers.
+func (b *Bus) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subs)
+}
+
+// close closes the bus and all its subscribers.
+func (b *Bus) close() {
+	b.mu.Lock()
+	b.closed = true
+	subs := b.subs
+	b.subs = make(map[*Subscriber]struct{})
+	b.mu.Unlock()
+
+	for s := range subs {
+		s.close(nil)
+	}
+}
+
+// Updates returns the channel of updates. It is closed when the subscriber
+// is closed or disconnected, or the bus is closed.
+func (s *Subscriber) Updates() <-chan Update {
+	return s.ch
+}
+
+// Dropped returns the number of updates discarded because the buffer was
+// full.
+func (s *Subscriber) Dropped() int64 {
+	return s.dropped.Load()
+}
+
+// Err returns ErrSlowConsumer if the subscriber was disconnected by the
+// Disconnect policy, and nil otherwise.
+func (s *Subscriber) Err() error {
+	select {
+	case <-s.done:
+		return s.err
+	default:
+		return nil
+	}
+}
+
+// Close removes the subscriber from the bus and closes its channel.
+func (s *Subscriber) Close() {
+	s.close(nil)
+}
+
+// close closes the subscriber with err.
+func (s *Subscriber) close(err error) {
+	s.once.Do(func() {
+		// wake up a Publish blocked on this subscriber before taking mu
+		s.err = err
+		close(s.done)
+
+		s.mu.Lock()
+		s.closed = true
+		close(s.ch)
+		s.mu.Unlock()
+
+		s.bus.mu.Lock()
+		delete(s.bus.subs, s)
+		s.bus.mu.Unlock()
+	})
+}
+
+// send delivers u according to the subscriber's policy.
+func (s *Subscriber) send(ctx context.Context, u Update) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+
+	select {
+	case s.ch <- u:
+		s.mu.Unlock()
+		return nil
+	default:
+	}
+
+	switch s.opts.Policy {
+	case DropOldest:
+		for {
+			select {
+			case s.ch <- u:
+				s.mu.Unlock()
+				return nil
+			default:
+			}
+			select {
+			case <-s.ch:
+				s.dropped.Add(1)
+			default:
+			}
+		}
+	case Block:
+		defer s.mu.Unlock()
+		select {
+		case s.ch <- u:
+			return nil
+		case <-s.done:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	case Disconnect:
+		s.mu.Unlock()
+		s.dropped.Add(1)
+		s.close(ErrSlowConsumer)
+		return nil
+	default:
+		s.mu.Unlock()
+		s.dropped.Add(1)
+		return nil
+	}
+}
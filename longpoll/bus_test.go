@@ -0,0 +1,150 @@
+package longpoll
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func collect(s *Subscriber) []any {
+	var data []any
+	for u := range s.Updates() {
+		data = append(data, u.Data)
+	}
+	return data
+}
+
+func TestBus_Policies(t *testing.T) {
+	ctx := context.Background()
+	bus := New().Events()
+
+	newest := bus.Subscribe(BusSubscribeOptions{BufferSize: 2})
+	oldest := bus.Subscribe(BusSubscribeOptions{BufferSize: 2, Policy: DropOldest})
+	slow := bus.Subscribe(BusSubscribeOptions{BufferSize: 2, Policy: Disconnect})
+	odd := bus.Subscribe(BusSubscribeOptions{BufferSize: 8, Filter: func(u Update) bool { return u.Data.(int)%2 == 1 }})
+
+	for i := range 4 {
+		if err := bus.Publish(ctx, Update{Data: i}); err != nil {
+			t.Fatalf("Publish: %v", err)
+		}
+	}
+	if n := bus.Len(); n != 3 {
+		t.Errorf("Len = %d after a disconnect, want 3", n)
+	}
+	for _, s := range []*Subscriber{newest, oldest, odd} {
+		s.Close()
+	}
+
+	tests := []struct {
+		name    string
+		sub     *Subscriber
+		want    []any
+		dropped int64
+		err     error
+	}{
+		{"DropNewest", newest, []any{0, 1}, 2, nil},
+		{"DropOldest", oldest, []any{2, 3}, 2, nil},
+		{"Disconnect", slow, []any{0, 1}, 1, ErrSlowConsumer},
+		{"Filter", odd, []any{1, 3}, 0, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := collect(tt.sub); !slices.Equal(got, tt.want) {
+				t.Errorf("updates = %v, want %v", got, tt.want)
+			}
+			if n := tt.sub.Dropped(); n != tt.dropped {
+				t.Errorf("Dropped = %d, want %d", n, tt.dropped)
+			}
+			if err := tt.sub.Err(); err != tt.err {
+				t.Errorf("Err = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestBus_Block(t *testing.T) {
+	bus := New().Events()
+	s := bus.Subscribe(BusSubscribeOptions{BufferSize: 1, Policy: Block})
+
+	bus.Publish(context.Background(), Update{Data: 1})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := bus.Publish(ctx, Update{Data: 2}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Publish to a full subscriber = %v, want DeadlineExceeded", err)
+	}
+
+	// closing the subscriber releases a blocked Publish
+	done := make(chan error)
+	go func() { done <- bus.Publish(context.Background(), Update{Data: 3}) }()
+	time.Sleep(10 * time.Millisecond)
+	s.Close()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Publish = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Publish still blocked after Close")
+	}
+}
+
+func TestPollPublish(t *testing.T) {
+	server := newOKServer(t)
+	client := NewWithConfig(Config{PollTimeout: time.Second})
+
+	first := client.Events().Subscribe(BusSubscribeOptions{
+		BufferSize: 32,
+		Policy:     Block,
+		Filter:     func(u Update) bool { return u.Poll == pollName(server.URL) },
+	})
+	second := client.Events().Subscribe(BusSubscribeOptions{BufferSize: 32, Policy: Block})
+
+	err := PollPublish(context.Background(), client, server.URL, decodeOffsets(server.URL, 3))
+	if err != nil {
+		t.Fatalf("PollPublish: %v", err)
+	}
+	if err := client.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+
+	want := []any{0, 1, 2, 3, 4, 5, 6, 7, 8}
+	for i, s := range []*Subscriber{first, second} {
+		if got := collect(s); !slices.Equal(got, want) {
+			t.Errorf("subscriber %d got %v, want %v", i, got, want)
+		}
+	}
+
+	sub := client.Events().Subscribe(BusSubscribeOptions{})
+	if _, ok := <-sub.Updates(); ok {
+		t.Error("subscriber of a closed bus received an update")
+	}
+}
+
+func TestPollPublish_SlowSubscriber(t *testing.T) {
+	server := newOKServer(t)
+	client := NewWithConfig(Config{PollTimeout: 50 * time.Millisecond})
+	sub := client.Events().Subscribe(BusSubscribeOptions{BufferSize: 1, Policy: Block})
+
+	done := make(chan error, 1)
+	go func() { done <- PollPublish(context.Background(), client, server.URL, decodeOffsets(server.URL, 2)) }()
+
+	var got []any
+	for range 6 {
+		time.Sleep(30 * time.Millisecond) // six slow reads outlast PollTimeout
+		select {
+		case u := <-sub.Updates():
+			got = append(got, u.Data)
+		case <-time.After(time.Second):
+			t.Fatalf("got %d updates, then none: %v", len(got), <-done)
+		}
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("PollPublish: %v", err)
+	}
+	if want := []any{0, 1, 2, 3, 4, 5}; !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,81 @@
+package longpoll
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// acceptEncoding is sent when Config.Decompress is set.
+const acceptEncoding = "gzip, deflate"
+
+// decompress replaces a gzip or deflate encoded response body with a
+// decoding reader, like http.Transport does when it negotiated compression
+// itself. Other encodings are left untouched.
+func decompress(resp *http.Response) error {
+	encoding := strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding")))
+	var (
+		body io.ReadCloser
+		err  error
+	)
+	switch encoding {
+	case "gzip", "x-gzip":
+		body, err = newGzipBody(resp.Body)
+	case "deflate":
+		body, err = newDeflateBody(resp.Body)
+	default:
+		return nil
+	}
+	if err != nil {
+		resp.Body.Close()
+		return fmt.Errorf("decompress %s body: %w", encoding, err)
+	}
+
+	resp.Body = body
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+	return nil
+}
+
+// decodedBody closes both the decoder and the underlying body.
+type decodedBody struct {
+	io.Reader
+	decoder io.Closer
+	body    io.Closer
+}
+
+func (b *decodedBody) Close() error {
+	b.decoder.Close()
+	return b.body.Close()
+}
+
+func newGzipBody(body io.ReadCloser) (io.ReadCloser, error) {
+	zr, err := gzip.NewReader(body)
+	if err != nil {
+		return nil, err
+	}
+	return &decodedBody{Reader: zr, decoder: zr, body: body}, nil
+}
+
+// newDeflateBody decodes "deflate" content, which should be zlib wrapped but
+// is sent as a raw deflate stream by some servers.
+func newDeflateBody(body io.ReadCloser) (io.ReadCloser, error) {
+	br := bufio.NewReader(body)
+	header, err := br.Peek(2)
+	if err == nil && header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		zr, err := zlib.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		return &decodedBody{Reader: zr, decoder: zr, body: body}, nil
+	}
+	fr := flate.NewReader(br)
+	return &decodedBody{Reader: fr, decoder: fr, body: body}, nil
+}
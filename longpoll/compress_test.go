@@ -0,0 +1,107 @@
+package longpoll
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func compressed(t *testing.T, encoding, s string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	switch encoding {
+	case "gzip":
+		w = gzip.NewWriter(&buf)
+	case "zlib":
+		w = zlib.NewWriter(&buf)
+	case "flate":
+		w, _ = flate.NewWriter(&buf, flate.DefaultCompression)
+	}
+	io.WriteString(w, s)
+	w.Close()
+	return buf.Bytes()
+}
+
+func TestClient_Poll_Decompress(t *testing.T) {
+	const payload = `{"updates":[1,2,3]}`
+	tests := []struct {
+		name     string
+		encoding string
+		format   string
+	}{
+		{"gzip", "gzip", "gzip"},
+		{"deflate zlib", "deflate", "zlib"},
+		{"deflate raw", "deflate", "flate"},
+		{"identity", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var acceptEncoding string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				acceptEncoding = r.Header.Get("Accept-Encoding")
+				if tt.encoding == "" {
+					io.WriteString(w, payload)
+					return
+				}
+				w.Header().Set("Content-Encoding", tt.encoding)
+				w.Write(compressed(t, tt.format, payload))
+			}))
+			defer server.Close()
+
+			// the transport must not decompress on its own
+			httpClient := &http.Client{Transport: &http.Transport{DisableCompression: true}}
+			client := NewWithConfig(Config{PollTimeout: time.Second, HTTPClient: httpClient, Decompress: true})
+
+			var body []byte
+			var header http.Header
+			err := client.Poll(context.Background(), server.URL, func(resp *http.Response) (string, bool, error) {
+				header = resp.Header
+				var err error
+				body, err = io.ReadAll(resp.Body)
+				return "", false, err
+			})
+			if err != nil {
+				t.Fatalf("Poll: %v", err)
+			}
+			if acceptEncoding != "gzip, deflate" {
+				t.Errorf("Accept-Encoding = %q", acceptEncoding)
+			}
+			if string(body) != payload {
+				t.Errorf("body = %q, want %q", body, payload)
+			}
+			if header.Get("Content-Encoding") != "" {
+				t.Errorf("Content-Encoding not removed: %q", header.Get("Content-Encoding"))
+			}
+		})
+	}
+}
+
+func TestClient_Poll_DecompressStatusError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(compressed(t, "gzip", "bad offset"))
+	}))
+	defer server.Close()
+
+	client := NewWithConfig(Config{PollTimeout: time.Second, Decompress: true})
+	err := client.Poll(context.Background(), server.URL, func(resp *http.Response) (string, bool, error) {
+		return "", false, nil
+	})
+	var se *StatusError
+	if !errors.As(err, &se) {
+		t.Fatalf("Poll error = %v, want StatusError", err)
+	}
+	if string(se.Body) != "bad offset" {
+		t.Errorf("StatusError body = %q, want decompressed text", se.Body)
+	}
+}
@@ -0,0 +1,66 @@
+package longpoll
+
+import (
+	"context"
+	"io"
+	"net/http/httptrace"
+	"strconv"
+	"time"
+)
+
+// ConnReuse controls whether poll requests reuse keep-alive connections.
+type ConnReuse int
+
+const (
+	// ConnReuseDefault leaves reuse to the transport, which reuses a
+	// connection only if the handler read the response body to the end.
+	ConnReuseDefault ConnReuse = iota
+
+	// ConnReuseForce reads up to 64 KiB of the response body the handler
+	// left unread before closing it, so the connection can be reused.
+	// Streamed bodies (PollSSE, PollStream) are not drained.
+	ConnReuseForce
+
+	// ConnReuseNever sends Connection: close with every request, so each
+	// one uses a fresh connection. Some long-poll servers and proxies
+	// misbehave on reused keep-alive connections.
+	ConnReuseNever
+)
+
+// maxDrain is the most ConnReuseForce reads from an unread response body.
+const maxDrain = 64 << 10
+
+// ConnMetrics is implemented by a Metrics hook that also wants to know
+// whether each request reused a connection.
+type ConnMetrics interface {
+	// ObserveConn is called when a request got its connection. idle is how
+	// long a reused connection was idle before; zero for new connections.
+	ObserveConn(poll string, reused bool, idle time.Duration)
+}
+
+// traceConn returns ctx with an httptrace.ClientTrace that reports whether
+// the request reused a connection to the logger, Observability and Metrics.
+func (c *Client) traceConn(ctx context.Context, url, poll string) context.Context {
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			if c.logger != nil {
+				c.logger.Debug("got connection", "url", url, "reused", info.Reused, "idle", info.IdleTime)
+			}
+			c.config.Observability.Count("longpoll_connections_total", "reused", strconv.FormatBool(info.Reused))
+			if m, ok := c.metrics.(ConnMetrics); ok {
+				m.ObserveConn(poll, info.Reused, info.IdleTime)
+			}
+		},
+	})
+}
+
+// drainBody reads what is left of a response body, up to maxDrain bytes,
+// before closing it.
+type drainBody struct {
+	io.ReadCloser
+}
+
+func (b drainBody) Close() error {
+	io.CopyN(io.Discard, b.ReadCloser, maxDrain)
+	return b.ReadCloser.Close()
+}
@@ -0,0 +1,60 @@
+package longpoll
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type connRecorder struct {
+	nopMetrics
+	mu     sync.Mutex
+	reused []bool
+}
+
+func (r *connRecorder) ObserveConn(_ string, reused bool, _ time.Duration) {
+	r.mu.Lock()
+	r.reused = append(r.reused, reused)
+	r.mu.Unlock()
+}
+
+func TestClient_ConnReuse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(strings.Repeat("x", 8<<10)))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name  string
+		reuse ConnReuse
+		want  []bool
+	}{
+		{"force", ConnReuseForce, []bool{false, true, true}},
+		{"never", ConnReuseNever, []bool{false, false, false}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metrics := &connRecorder{}
+			client := NewWithConfig(Config{PollTimeout: time.Second, ConnReuse: tt.reuse, Metrics: metrics})
+
+			n := 0
+			// the handler never reads the body
+			err := client.Poll(context.Background(), server.URL, func(*http.Response) (string, bool, error) {
+				n++
+				return "", n < 3, nil
+			})
+			if err != nil {
+				t.Fatalf("Poll: %v", err)
+			}
+
+			if !slices.Equal(metrics.reused, tt.want) {
+				t.Errorf("reused = %v, want %v", metrics.reused, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package longpoll
+
+import (
+	"context"
+	"fmt"
+	"hash/maphash"
+	"net/http"
+	"sync"
+)
+
+// DecodeFunc splits a response into items for PollDispatch and returns the
+// URL of the next request, as a ResponseHandler does.
+type DecodeFunc[T any] func(resp *http.Response) (items []T, nextURL string, shouldContinue bool, err error)
+
+// DispatchConfig configures the worker pool of PollDispatch.
+type DispatchConfig[T any] struct {
+	// Workers is the number of items processed concurrently.
+	// Default: 1
+	Workers int
+
+	// QueueSize is the number of received items waiting for a worker. When
+	// the queue is full, the next request waits, so a slow consumer slows
+	// down polling instead of buffering without bound.
+	// Default: Workers
+	QueueSize int
+
+	// Ordered processes items in the order they were received. With Key,
+	// only items with the same key keep their order and different keys are
+	// processed concurrently; without it, items are processed one at a time.
+	Ordered bool
+
+	// Key groups items for Ordered delivery, e.g. by chat ID for Telegram
+	// updates. Ignored unless Ordered is set.
+	Key func(item T) string
+}
+
+// PollDispatch polls url like Poll, but hands the items decoded from each
+// response to a pool of workers running process, so slow processing does
+// not delay the next request. This matters for APIs such as Telegram
+// getUpdates, where handling a batch can take longer than the server is
+// willing to wait for the next poll.
+//
+// decode runs on the polling goroutine and should only read the response;
+// the URL it returns, e.g. with an advanced offset, is used right away.
+// Items already received when polling stops are still processed before
+// PollDispatch returns, and Shutdown waits for them: process gets a context
+// that is not cancelled by stopping the poll. An error from process stops
+// polling and is returned wrapped in ErrHandlerStopped; items still queued
+// are then dropped.
+//
+//	err := longpoll.PollDispatch(ctx, client, url, decodeUpdates,
+//		func(ctx context.Context, u Update) error {
+//			return bot.Handle(ctx, u)
+//		},
+//		longpoll.DispatchConfig[Update]{
+//			Workers: 8,
+//			Ordered: true,
+//			Key:     func(u Update) string { return strconv.FormatInt(u.ChatID, 10) },
+//		})
+func PollDispatch[T any](ctx context.Context, c *Client, url string, decode DecodeFunc[T], process func(ctx context.Context, item T) error, cfg DispatchConfig[T]) error {
+	pc, done, err := c.track(ctx, "", url)
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	d := newDispatcher(pc, process, cfg)
+	err = c.pollLoop(pc, url, func(resp *http.Response) (string, bool, error) {
+		items, nextURL, shouldContinue, err := decode(resp)
+		for _, item := range items {
+			if !d.send(item) {
+				break
+			}
+		}
+		return nextURL, shouldContinue, err
+	})
+	if processErr := d.close(); processErr != nil {
+		return fmt.Errorf("%w: %w", ErrHandlerStopped, processErr)
+	}
+	return pc.result(err)
+}
+
+// dispatcher runs the worker pool of PollDispatch.
+type dispatcher[T any] struct {
+	cfg     DispatchConfig[T]
+	process func(context.Context, T) error
+	pc      *pollContext
+	ctx     context.Context
+	cancel  context.CancelFunc
+	queues  []chan T // one shared queue, or one per worker when ordered
+	seed    maphash.Seed
+	wg      sync.WaitGroup
+
+	mu  sync.Mutex
+	err error
+}
+
+func newDispatcher[T any](pc *pollContext, process func(context.Context, T) error, cfg DispatchConfig[T]) *dispatcher[T] {
+	cfg.Workers = max(cfg.Workers, 1)
+	if cfg.Ordered && cfg.Key == nil {
+		cfg.Workers = 1
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = cfg.Workers
+	}
+
+	ctx, cancel := context.WithCancel(context.WithoutCancel(pc.ctx))
+	d := &dispatcher[T]{cfg: cfg, process: process, pc: pc, ctx: ctx, cancel: cancel, seed: maphash.MakeSeed()}
+	if cfg.Ordered {
+		// each worker owns a queue, so items with the same key stay in order
+		size := max(cfg.QueueSize/cfg.Workers, 1)
+		for range cfg.Workers {
+			d.queues = append(d.queues, make(chan T, size))
+		}
+	} else {
+		d.queues = []chan T{make(chan T, cfg.QueueSize)}
+	}
+
+	for i := range cfg.Workers {
+		queue := d.queues[i%len(d.queues)]
+		d.wg.Go(func() { d.work(queue) })
+	}
+	return d
+}
+
+// send queues item for a worker, waiting while the queue is full. It
+// returns false if a worker failed.
+func (d *dispatcher[T]) send(item T) bool {
+	queue := d.queues[0]
+	if d.cfg.Ordered && len(d.queues) > 1 {
+		queue = d.queues[maphash.String(d.seed, d.cfg.Key(item))%uint64(len(d.queues))]
+	}
+	select {
+	case queue <- item:
+		return true
+	case <-d.ctx.Done():
+		return false
+	}
+}
+
+// work processes items from queue until it is closed or a worker failed.
+func (d *dispatcher[T]) work(queue <-chan T) {
+	for item := range queue {
+		if d.ctx.Err() != nil {
+			continue
+		}
+		if err := d.process(d.ctx, item); err != nil {
+			d.fail(err)
+		}
+	}
+}
+
+// fail records the first processing error and stops the poll.
+func (d *dispatcher[T]) fail(err error) {
+	d.mu.Lock()
+	if d.err == nil {
+		d.err = err
+		d.cancel()
+		d.pc.cancel(err)
+	}
+	d.mu.Unlock()
+}
+
+// close waits for the queued items to be processed and returns the first
+// processing error.
+func (d *dispatcher[T]) close() error {
+	for _, queue := range d.queues {
+		close(queue)
+	}
+	d.wg.Wait()
+	d.cancel()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.err
+}
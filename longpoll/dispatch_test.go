@@ -0,0 +1,136 @@
+package longpoll
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// decodeOffsets returns the items 3*offset .. 3*offset+2 for the offset query
+// parameter and stops after the given number of batches.
+func decodeOffsets(base string, batches int) DecodeFunc[int] {
+	return func(resp *http.Response) ([]int, string, bool, error) {
+		offset, _ := strconv.Atoi(resp.Request.URL.Query().Get("offset"))
+		items := []int{3 * offset, 3*offset + 1, 3*offset + 2}
+		return items, fmt.Sprintf("%s?offset=%d", base, offset+1), offset+1 < batches, nil
+	}
+}
+
+func newOKServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestPollDispatch_Concurrent(t *testing.T) {
+	server := newOKServer(t)
+	client := NewWithConfig(Config{PollTimeout: time.Second})
+
+	// every item waits until four are running at once
+	var running atomic.Int32
+	all := make(chan struct{})
+	var processed atomic.Int32
+	err := PollDispatch(context.Background(), client, server.URL, decodeOffsets(server.URL, 4),
+		func(ctx context.Context, item int) error {
+			if running.Add(1) == 4 {
+				close(all)
+			}
+			select {
+			case <-all:
+			case <-time.After(2 * time.Second):
+				return errors.New("items were not processed concurrently")
+			}
+			processed.Add(1)
+			return nil
+		},
+		DispatchConfig[int]{Workers: 4})
+	if err != nil {
+		t.Fatalf("PollDispatch: %v", err)
+	}
+	if n := processed.Load(); n != 12 {
+		t.Errorf("processed %d items, want 12", n)
+	}
+}
+
+func TestPollDispatch_OrderedByKey(t *testing.T) {
+	server := newOKServer(t)
+	client := NewWithConfig(Config{PollTimeout: time.Second})
+
+	var mu sync.Mutex
+	seen := map[string][]int{}
+	err := PollDispatch(context.Background(), client, server.URL, decodeOffsets(server.URL, 10),
+		func(ctx context.Context, item int) error {
+			key := strconv.Itoa(item % 3)
+			mu.Lock()
+			seen[key] = append(seen[key], item)
+			mu.Unlock()
+			return nil
+		},
+		DispatchConfig[int]{Workers: 3, Ordered: true, Key: func(item int) string { return strconv.Itoa(item % 3) }})
+	if err != nil {
+		t.Fatalf("PollDispatch: %v", err)
+	}
+
+	for key, items := range seen {
+		if len(items) != 10 || !slices.IsSorted(items) {
+			t.Errorf("key %s processed %v, want 10 items in order", key, items)
+		}
+	}
+}
+
+func TestPollDispatch_ProcessError(t *testing.T) {
+	server := newOKServer(t)
+	client := NewWithConfig(Config{PollTimeout: time.Second})
+
+	errBad := errors.New("bad item")
+	err := PollDispatch(context.Background(), client, server.URL, decodeOffsets(server.URL, 1000),
+		func(ctx context.Context, item int) error {
+			if item == 4 {
+				return errBad
+			}
+			return nil
+		},
+		DispatchConfig[int]{Ordered: true})
+	if !errors.Is(err, ErrHandlerStopped) || !errors.Is(err, errBad) {
+		t.Fatalf("PollDispatch = %v, want ErrHandlerStopped wrapping the process error", err)
+	}
+}
+
+func TestPollDispatch_DrainsAfterStop(t *testing.T) {
+	server := newOKServer(t)
+	client := NewWithConfig(Config{PollTimeout: time.Second})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var processed atomic.Int32
+	err := PollDispatch(ctx, client, server.URL,
+		func(resp *http.Response) ([]int, string, bool, error) {
+			cancel()
+			return []int{1, 2, 3}, "", true, nil
+		},
+		func(ctx context.Context, item int) error {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			processed.Add(1)
+			return nil
+		},
+		DispatchConfig[int]{Workers: 1, QueueSize: 3})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("PollDispatch = %v, want context.Canceled", err)
+	}
+	if n := processed.Load(); n != 3 {
+		t.Errorf("processed %d items after the poll stopped, want 3", n)
+	}
+}
@@ -8,14 +8,43 @@
 // This package is designed to work with various long polling APIs, including:
 // - Telegram Bot API getUpdates
 // - Custom long polling endpoints
-// - Server-sent events alternatives
+// - JSON-RPC 2.0 polling, e.g. Ethereum filters (see PollJSONRPC)
+// - Server-Sent Events streams (see PollSSE)
 //
 // Key features:
 // - Dynamic URL updates (e.g., for offset parameters like Telegram Bot API)
-// - Support for both GET and POST requests
-// - Automatic retry with configurable backoff
+// - Query parameter updates without URL string building via PollQuery and MergeQuery
+// - URL templates with {name} placeholders filled from a mutable parameter map via PollTemplate
+// - Adaptive tuning of the requested hold time and client timeout via AdaptiveTimeout
+// - Support for both GET and POST requests, with per-iteration bodies via BodyBuilder
+// - Proxies, including authenticating ones, via ProxyURL or the environment
+// - TLS and transport tuning (TLSConfig, Transport) without a hand-built http.Client
+// - Connection reuse diagnostics and forced reuse or fresh connections via ConnReuse
+// - Transparent gzip and deflate response decompression via Decompress
+// - Automatic retry with fixed delay or exponential backoff with jitter
+// - Retry-After support for 429 and 503 responses
+// - Retry limits per failure streak (MaxRetries, MaxElapsedTime) and per rolling window (RetryBudget)
+// - Optional rate limiting of requests through a ratelimit.Limiter
 // - Context cancellation support
-// - Concurrent polling operations
+// - Graceful shutdown that waits for running handlers via Shutdown
+// - Concurrent polling operations, with per-loop diagnostics via ActivePolls
+// - Channel-based subscriptions via Subscribe, and fan-out of decoded updates to many subscribers via Events
+// - Concurrent processing of decoded items by a bounded worker pool, ordered or not, via PollDispatch
+// - Polling many URLs with bounded concurrency via PollMany
+// - Named polls that can be stopped, paused and inspected individually via PollNamed
+// - Automatic restart of named polls with backoff via Supervisor
+// - Server-Sent Events with Last-Event-ID resumption via PollSSE and SubscribeSSE
+// - Long-lived streamed bodies (e.g. newline-delimited JSON) with idle detection via PollStream
+// - Per-request signing through the Signer hook (see HMACSigner)
+// - Bearer tokens refreshed on 401/403 through a TokenSource (see CachedToken)
+// - Per-poll statistics through the Metrics hook
+// - Lifecycle callbacks (OnRequest, OnResponse, OnError, OnRetry) for alerting and debugging
+// - Per-request metadata (iteration, URL, retry streak) for handlers via FromContext
+// - Tracing and metrics through a shared observability.Config
+//
+// The server half, an in-process hub that holds requests until events are
+// published, is in the longpoll/server package. The longpolltest package
+// provides a scriptable fake server for testing handlers.
 //
 // Example usage with static URL:
 //
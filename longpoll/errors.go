@@ -0,0 +1,139 @@
+package longpoll
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/en9inerd/go-pkgs/ratelimit"
+)
+
+// ErrClientClosed is returned by polling methods called after Shutdown.
+var ErrClientClosed = errors.New("longpoll: client closed")
+
+// ErrPollExists is returned by PollNamed when a poll with the same name is
+// already running.
+var ErrPollExists = errors.New("longpoll: poll already running")
+
+// Errors identifying why a polling loop ended. The error returned by a
+// polling method wraps one of them, together with the underlying cause, so
+// callers can use errors.Is to decide whether to restart.
+var (
+	// ErrMaxRetriesExceeded means consecutive failures exceeded MaxRetries
+	// or MaxConnectRetries. It wraps the last request error.
+	ErrMaxRetriesExceeded = errors.New("longpoll: max retries exceeded")
+
+	// ErrMaxElapsedTime means requests kept failing for longer than
+	// MaxElapsedTime. It wraps the last request error.
+	ErrMaxElapsedTime = errors.New("longpoll: max elapsed time exceeded")
+
+	// ErrRetryBudgetExceeded means the RetryBudget was used up. It wraps the
+	// last request error.
+	ErrRetryBudgetExceeded = errors.New("longpoll: retry budget exceeded")
+
+	// ErrRetryRejected means RetryPolicy declined to retry a failed request.
+	// It wraps the request error.
+	ErrRetryRejected = errors.New("longpoll: retry rejected by policy")
+
+	// ErrHandlerStopped means the handler returned an error. It wraps that
+	// error.
+	ErrHandlerStopped = errors.New("longpoll: handler stopped polling")
+
+	// ErrStoppedByClient means the poll was stopped with Stop, StopAll or
+	// Shutdown rather than by its own context. It wraps context.Canceled.
+	ErrStoppedByClient = errors.New("longpoll: stopped by client")
+)
+
+// StatusError is returned when the server responds with a non-2xx status code.
+type StatusError struct {
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+
+	// Header contains the response headers.
+	Header http.Header
+
+	// Body is the response body.
+	Body []byte
+}
+
+// Error implements the error interface
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("http error %d: %s", e.StatusCode, string(e.Body))
+}
+
+// RetryAfter returns the delay requested by the server's Retry-After header.
+// It is only honored for 429 Too Many Requests and 503 Service Unavailable
+// responses. Both the delay-seconds and HTTP-date forms are supported.
+func (e *StatusError) RetryAfter() (time.Duration, bool) {
+	if e.StatusCode != http.StatusTooManyRequests && e.StatusCode != http.StatusServiceUnavailable {
+		return 0, false
+	}
+	return ratelimit.ParseRetryAfter(e.Header.Get("Retry-After"))
+}
+
+// ConnectError is returned when a connection to the server could not be
+// established, as opposed to a request that reached the server and failed.
+type ConnectError struct {
+	// Op is the failed step: "dns", "dial" or "tls".
+	Op string
+
+	// Err is the underlying error.
+	Err error
+}
+
+// Error implements the error interface
+func (e *ConnectError) Error() string {
+	return fmt.Sprintf("connect (%s): %v", e.Op, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *ConnectError) Unwrap() error { return e.Err }
+
+// IsConnectError reports whether err is a connection-establishment failure.
+func IsConnectError(err error) bool {
+	var connErr *ConnectError
+	return errors.As(err, &connErr)
+}
+
+// classifyConnectError wraps err in a *ConnectError if it happened while
+// resolving, dialing or completing the TLS handshake; otherwise it returns nil.
+func classifyConnectError(err error) *ConnectError {
+	var (
+		dnsErr    *net.DNSError
+		opErr     *net.OpError
+		recordErr tls.RecordHeaderError
+		verifyErr *tls.CertificateVerificationError
+		alertErr  tls.AlertError
+		unknownCA x509.UnknownAuthorityError
+		hostErr   x509.HostnameError
+		invalid   x509.CertificateInvalidError
+	)
+	switch {
+	case errors.As(err, &dnsErr):
+		return &ConnectError{Op: "dns", Err: err}
+	case errors.As(err, &recordErr), errors.As(err, &verifyErr), errors.As(err, &alertErr),
+		errors.As(err, &unknownCA), errors.As(err, &hostErr), errors.As(err, &invalid):
+		return &ConnectError{Op: "tls", Err: err}
+	case errors.As(err, &opErr) && opErr.Op == "dial":
+		return &ConnectError{Op: "dial", Err: err}
+	}
+	return nil
+}
+
+// errorKind labels a request failure for logs and metrics:
+// "connect", "status" or "transport".
+func errorKind(err error) string {
+	var statusErr *StatusError
+	switch {
+	case IsConnectError(err):
+		return "connect"
+	case errors.As(err, &statusErr):
+		return "status"
+	default:
+		return "transport"
+	}
+}
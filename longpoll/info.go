@@ -0,0 +1,47 @@
+package longpoll
+
+import (
+	"context"
+	"time"
+)
+
+// RequestInfo describes a poll request and the loop that issued it.
+// Handlers get it with FromContext(resp.Request.Context()); it is also set on
+// the contexts passed to BodyBuilder, Signer and the HTTP transport.
+type RequestInfo struct {
+	// Name is the poll name given to PollNamed, empty for unnamed polls.
+	Name string
+
+	// URL is the URL of this request.
+	URL string
+
+	// Iteration counts the requests made by the loop before this one,
+	// starting at 0.
+	Iteration int
+
+	// RetryStreak is the number of consecutive failed requests before this
+	// one; 0 after a success.
+	RetryStreak int
+
+	// Started is when the polling loop started.
+	Started time.Time
+}
+
+type requestInfoKey struct{}
+
+// FromContext returns the RequestInfo of the poll request ctx belongs to.
+//
+//	client.Poll(ctx, url, func(resp *http.Response) (string, bool, error) {
+//		info, _ := longpoll.FromContext(resp.Request.Context())
+//		logger.Info("update", "iteration", info.Iteration, "url", info.URL)
+//		...
+//	})
+func FromContext(ctx context.Context) (RequestInfo, bool) {
+	info, ok := ctx.Value(requestInfoKey{}).(RequestInfo)
+	return info, ok
+}
+
+// withRequestInfo returns ctx carrying info.
+func withRequestInfo(ctx context.Context, info RequestInfo) context.Context {
+	return context.WithValue(ctx, requestInfoKey{}, info)
+}
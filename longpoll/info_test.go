@@ -0,0 +1,57 @@
+package longpoll
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFromContext_InHandler(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewWithConfig(Config{PollTimeout: time.Second, RetryDelay: time.Millisecond, MaxRetries: 1})
+
+	var infos []RequestInfo
+	err := client.PollNamed(context.Background(), "updates", server.URL+"/a", func(resp *http.Response) (string, bool, error) {
+		info, ok := FromContext(resp.Request.Context())
+		if !ok {
+			t.Fatal("FromContext: no request info")
+		}
+		infos = append(infos, info)
+		return server.URL + "/b", len(infos) < 2, nil
+	})
+	if err != nil {
+		t.Fatalf("PollNamed: %v", err)
+	}
+
+	if len(infos) != 2 {
+		t.Fatalf("handler calls = %d, want 2", len(infos))
+	}
+	first, second := infos[0], infos[1]
+	if first.Name != "updates" || first.URL != server.URL+"/a" || first.Iteration != 1 || first.RetryStreak != 1 {
+		t.Errorf("first info = %+v", first)
+	}
+	if second.URL != server.URL+"/b" || second.Iteration != 2 || second.RetryStreak != 0 {
+		t.Errorf("second info = %+v", second)
+	}
+	if first.Started.IsZero() || !first.Started.Equal(second.Started) {
+		t.Errorf("Started = %v, %v; want the same loop start", first.Started, second.Started)
+	}
+}
+
+func TestFromContext_Missing(t *testing.T) {
+	if _, ok := FromContext(context.Background()); ok {
+		t.Error("FromContext reported info on a plain context")
+	}
+}
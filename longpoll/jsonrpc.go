@@ -0,0 +1,114 @@
+package longpoll
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+)
+
+// JSONRPCCall describes the JSON-RPC 2.0 method polled by PollJSONRPC.
+type JSONRPCCall struct {
+	// Method is the JSON-RPC method, e.g. "eth_getFilterChanges".
+	Method string
+
+	// Params returns the params of each call. It is called before every
+	// request, retries included, so it can reflect state updated by the
+	// handler, such as a "since" cursor. If nil, no params are sent.
+	Params func() (any, error)
+}
+
+// RPCError is a JSON-RPC error object returned by the server.
+type RPCError struct {
+	// Code is the JSON-RPC error code.
+	Code int `json:"code"`
+
+	// Message is the error message.
+	Message string `json:"message"`
+
+	// Data holds additional information, if the server sent any.
+	Data json.RawMessage `json:"data,omitempty"`
+}
+
+// Error implements the error interface
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("json-rpc error %d: %s", e.Code, e.Message)
+}
+
+// rpcRequest is the JSON-RPC 2.0 request envelope.
+type rpcRequest struct {
+	JSONRPC string `json:"jsonrpc"`
+	ID      uint64 `json:"id"`
+	Method  string `json:"method"`
+	Params  any    `json:"params,omitempty"`
+}
+
+// rpcResponse is the JSON-RPC 2.0 response envelope.
+type rpcResponse[T any] struct {
+	ID     *uint64   `json:"id"`
+	Result T         `json:"result"`
+	Error  *RPCError `json:"error"`
+}
+
+// PollJSONRPC repeatedly calls a JSON-RPC 2.0 method over HTTP POST, as
+// used by endpoints such as Ethereum filter polling, and passes each decoded
+// result to handler. It manages the request envelope and numbers requests
+// with increasing IDs, checking that each response carries the ID of its
+// request. Config.Method and Config.BodyBuilder are not used; everything
+// else, including retries, signing and limits, works as for Poll.
+//
+// An error object in a response stops polling with an *RPCError wrapped in
+// ErrHandlerStopped.
+//
+//	var filterID = "0x1f"
+//	err := longpoll.PollJSONRPC(ctx, client, nodeURL,
+//		longpoll.JSONRPCCall{
+//			Method: "eth_getFilterChanges",
+//			Params: func() (any, error) { return []string{filterID}, nil },
+//		},
+//		func(logs []types.Log) (bool, error) {
+//			return true, process(logs)
+//		})
+func PollJSONRPC[T any](ctx context.Context, c *Client, url string, call JSONRPCCall, handler func(result T) (shouldContinue bool, err error)) error {
+	pc, done, err := c.track(ctx, "", url)
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	var lastID atomic.Uint64
+	pc.method = http.MethodPost
+	pc.body = func(ctx context.Context, iteration int, last Meta) (io.Reader, string, error) {
+		req := rpcRequest{JSONRPC: "2.0", ID: lastID.Add(1), Method: call.Method}
+		if call.Params != nil {
+			params, err := call.Params()
+			if err != nil {
+				return nil, "", fmt.Errorf("json-rpc params: %w", err)
+			}
+			req.Params = params
+		}
+		body, err := json.Marshal(req)
+		if err != nil {
+			return nil, "", err
+		}
+		return bytes.NewReader(body), "application/json", nil
+	}
+
+	return pc.result(c.pollLoop(pc, url, func(resp *http.Response) (string, bool, error) {
+		var rpcResp rpcResponse[T]
+		if err := json.NewDecoder(resp.Body).Decode(&rpcResp); err != nil {
+			return "", false, fmt.Errorf("decode json-rpc response: %w", err)
+		}
+		if rpcResp.Error != nil {
+			return "", false, rpcResp.Error
+		}
+		if id := lastID.Load(); rpcResp.ID == nil || *rpcResp.ID != id {
+			return "", false, fmt.Errorf("json-rpc response id does not match request id %d", id)
+		}
+		shouldContinue, err := handler(rpcResp.Result)
+		return "", shouldContinue, err
+	}))
+}
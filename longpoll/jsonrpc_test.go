@@ -0,0 +1,103 @@
+package longpoll
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestPollJSONRPC(t *testing.T) {
+	var ids []uint64
+	var since []int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("request = %s %q", r.Method, r.Header.Get("Content-Type"))
+		}
+		var req struct {
+			JSONRPC string `json:"jsonrpc"`
+			ID      uint64 `json:"id"`
+			Method  string `json:"method"`
+			Params  []int  `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatal(err)
+		}
+		if req.JSONRPC != "2.0" || req.Method != "changes" {
+			t.Errorf("envelope = %+v", req)
+		}
+		ids = append(ids, req.ID)
+		since = append(since, req.Params[0])
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%d,"result":{"last_seq":%d}}`, req.ID, req.Params[0]+10)
+	}))
+	defer server.Close()
+
+	client := NewWithConfig(Config{PollTimeout: time.Second})
+	cursor := 0
+	call := JSONRPCCall{
+		Method: "changes",
+		Params: func() (any, error) { return []int{cursor}, nil },
+	}
+
+	type changes struct {
+		LastSeq int `json:"last_seq"`
+	}
+	calls := 0
+	err := PollJSONRPC(context.Background(), client, server.URL, call, func(result changes) (bool, error) {
+		calls++
+		cursor = result.LastSeq
+		return calls < 3, nil
+	})
+	if err != nil {
+		t.Fatalf("PollJSONRPC: %v", err)
+	}
+	if fmt.Sprint(ids) != "[1 2 3]" {
+		t.Errorf("ids = %v, want [1 2 3]", ids)
+	}
+	if fmt.Sprint(since) != "[0 10 20]" {
+		t.Errorf("params = %v, want [0 10 20]", since)
+	}
+}
+
+func TestPollJSONRPC_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		reply string
+		check func(error) bool
+	}{
+		{
+			name:  "rpc error",
+			reply: `{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"filter not found"}}`,
+			check: func(err error) bool {
+				var rpcErr *RPCError
+				return errors.As(err, &rpcErr) && rpcErr.Code == -32000 && errors.Is(err, ErrHandlerStopped)
+			},
+		},
+		{
+			name:  "id mismatch",
+			reply: `{"jsonrpc":"2.0","id":7,"result":null}`,
+			check: func(err error) bool { return errors.Is(err, ErrHandlerStopped) },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, tt.reply)
+			}))
+			defer server.Close()
+
+			client := NewWithConfig(Config{PollTimeout: time.Second})
+			err := PollJSONRPC(context.Background(), client, server.URL, JSONRPCCall{Method: "m"}, func(result any) (bool, error) {
+				t.Error("handler called")
+				return false, nil
+			})
+			if !tt.check(err) {
+				t.Errorf("error = %v", err)
+			}
+		})
+	}
+}
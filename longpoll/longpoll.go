@@ -1,14 +1,25 @@
 package longpoll
 
 import (
+	"bytes"
+	"cmp"
 	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"maps"
 	"net/http"
+	"net/url"
+	"strconv"
 	"sync"
 	"time"
+
+	"github.com/en9inerd/go-pkgs/observability"
+	"github.com/en9inerd/go-pkgs/ratelimit"
+	"github.com/en9inerd/go-pkgs/retry"
 )
 
 // ResponseHandler is a function that processes a long polling response.
@@ -30,30 +41,155 @@ type Config struct {
 	PollTimeout time.Duration
 
 	// RetryDelay is the delay between retries when a request fails.
+	// Used only when Backoff is nil.
 	// Default: 1 second
 	RetryDelay time.Duration
 
+	// Backoff is an optional exponential backoff policy for failed requests.
+	// A Retry-After header on 429 and 503 responses takes precedence.
+	// InitialDelay, MaxDelay, Multiplier and Jitter are used to compute the
+	// delay before each retry; MaxAttempts and RetryableErrors are ignored
+	// because the number of retries is governed by MaxRetries.
+	// If nil, the fixed RetryDelay is used.
+	Backoff *retry.Strategy
+
+	// RetryPolicy decides whether a failed request is retried.
+	// resp is non-nil when the server answered with a non-2xx status; its body
+	// has already been read into the *StatusError but can be read again.
+	// resp is nil for transport-level failures.
+	// Return retry=false to stop polling immediately with the error.
+	// A positive delay overrides Retry-After and RetryDelay/Backoff for this attempt.
+	// MaxRetries still limits the number of consecutive retries.
+	// If nil, every failure is retried.
+	RetryPolicy func(resp *http.Response, err error) (retry bool, delay time.Duration)
+
+	// ConnectBackoff is an optional backoff policy for connection-establishment
+	// failures (DNS, dial, TLS; see ConnectError). These are counted separately
+	// from other failures so an unreachable server is not masked by, or
+	// confused with, HTTP errors. If nil, Backoff or RetryDelay is used.
+	ConnectBackoff *retry.Strategy
+
+	// MaxConnectRetries is the maximum number of consecutive connection
+	// failures before giving up. Set to -1 for unlimited.
+	// Zero uses MaxRetries.
+	MaxConnectRetries int
+
+	// AdaptiveTimeout, if set, makes Poll measure how long the server
+	// actually holds requests and tune the hold time sent in a query
+	// parameter (e.g. ?timeout=50) and the client timeout of each request
+	// within the configured bounds, instead of using PollTimeout as is.
+	AdaptiveTimeout *AdaptiveTimeout
+
+	// MaxRetryAfter caps the delay taken from a Retry-After header on 429 and
+	// 503 responses. Zero means no cap.
+	MaxRetryAfter time.Duration
+
 	// MaxRetries is the maximum number of consecutive retries before giving up.
 	// Set to -1 for unlimited retries, 0 for no retries.
 	// When using New(), defaults to -1 (unlimited).
 	MaxRetries int
 
+	// MaxElapsedTime stops polling once requests have kept failing for this
+	// long without a success in between, however few retries that took.
+	// Zero means no limit.
+	MaxElapsedTime time.Duration
+
+	// RetryBudget limits the total number of retries within a rolling
+	// window, across failure streaks, so a loop that fails intermittently
+	// ends even though no streak reaches MaxRetries. Nil means no budget.
+	RetryBudget *RetryBudget
+
+	// Limiter, if set, is waited on before each request, including retries
+	// and reconnects. It keeps loops against endpoints that answer
+	// immediately from turning into a request storm. A limiter shared
+	// between clients bounds their combined request rate.
+	Limiter ratelimit.Limiter
+
+	// MaxConcurrentPolls limits how many of the loops started by PollMany
+	// run at the same time. Zero means no limit.
+	MaxConcurrentPolls int
+
 	// HTTPClient is the underlying HTTP client to use.
 	// If nil, a default client will be created.
 	HTTPClient *http.Client
 
+	// ProxyURL is the proxy used by the internally created HTTP client.
+	// User info in the URL is sent as proxy basic authentication. If nil,
+	// the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables are
+	// used. Ignored when HTTPClient is set.
+	ProxyURL *url.URL
+
+	// TLSConfig is the TLS configuration of the internally created HTTP
+	// client, e.g. for custom root CAs or client certificates.
+	// Ignored when HTTPClient is set.
+	TLSConfig *tls.Config
+
+	// Transport tunes timeouts and connection pooling of the internally
+	// created HTTP client. Ignored when HTTPClient is set.
+	Transport TransportOptions
+
+	// ConnReuse forces poll requests to reuse keep-alive connections or to
+	// use a fresh connection each. Whether a request reused its connection
+	// is logged at debug level and reported to Observability and to Metrics
+	// hooks that implement ConnMetrics. Default: ConnReuseDefault
+	ConnReuse ConnReuse
+
 	// Logger is an optional logger for debugging.
 	Logger *slog.Logger
 
+	// Metrics receives per-poll statistics: requests, retries, latency,
+	// bytes received and consecutive-failure streaks.
+	Metrics Metrics
+
+	// Observability receives a span and metrics for each poll request.
+	// Its Logger is used when Logger is nil.
+	Observability *observability.Config
+
 	// Headers are additional headers to include in each request.
 	Headers map[string]string
 
 	// Method is the HTTP method to use for requests. Default: GET
 	Method string
 
-	// BodyBuilder returns the request body for each poll.
+	// Decompress sends Accept-Encoding: gzip, deflate and decodes compressed
+	// responses before the handler sees them. Unlike the automatic gzip
+	// support of http.Transport it also works with transports that have
+	// DisableCompression set, and it handles deflate.
+	Decompress bool
+
+	// BodyBuilder returns the request body and its content type for each
+	// request. iteration counts the requests already made by the poll loop,
+	// starting at 0, and last describes the outcome of the previous one, so
+	// the body can vary with earlier results. An empty content type keeps the
+	// configured Content-Type header, or application/x-www-form-urlencoded
+	// for POST requests.
 	// If nil, no body is sent.
-	BodyBuilder func() (io.Reader, error)
+	BodyBuilder BodyBuilder
+
+	// OnRequest is called before each request. attempt is 1 for the first
+	// request after a success and grows with each consecutive failure.
+	OnRequest func(url string, attempt int)
+
+	// OnResponse is called when the server answered a request, with any
+	// status code, before the handler runs.
+	OnResponse func(url string, attempt int, statusCode int)
+
+	// OnError is called when a request failed, either at the transport level
+	// or with a non-2xx status (see StatusError and ConnectError).
+	OnError func(url string, attempt int, err error)
+
+	// OnRetry is called before sleeping for a retry. attempt is the number of
+	// the upcoming attempt.
+	OnRetry func(url string, attempt int, delay time.Duration)
+
+	// Signer signs each request after it is fully built.
+	// The body is buffered in memory to compute its digest.
+	Signer Signer
+
+	// TokenSource supplies a bearer token for the Authorization header.
+	// A 401 or 403 response makes the client refresh the token and send the
+	// request once more before treating it as a failure.
+	TokenSource TokenSource
 }
 
 // Client is a long polling HTTP client.
@@ -62,14 +198,35 @@ type Client struct {
 	httpClient *http.Client
 	logger     *slog.Logger
 	headers    map[string]string
+	metrics    Metrics
 	mu         sync.Mutex
 	active     map[*pollContext]struct{}
+	named      map[string]*pollContext
+	closed     bool
+	drained    chan struct{} // closed by the last poll to end after Shutdown
+	bus        *Bus          // created by Events
 }
 
 // pollContext tracks an active polling operation.
 type pollContext struct {
-	ctx    context.Context
-	cancel context.CancelFunc
+	ctx     context.Context
+	cancel  context.CancelCauseFunc
+	name    string
+	started time.Time
+
+	// method and body, if set, replace Config.Method and Config.BodyBuilder
+	// for this poll. They are set by adapters such as PollJSONRPC before the
+	// loop starts.
+	method string
+	body   BodyBuilder
+
+	mu          sync.Mutex
+	url         string
+	lastSuccess time.Time
+	lastError   error
+	retries     int
+	responses   int
+	resumed     chan struct{} // non-nil while paused, closed by Resume
 }
 
 // New creates a new long polling client with default settings.
@@ -90,7 +247,8 @@ func NewWithConfig(cfg Config) *Client {
 	}
 	if cfg.HTTPClient == nil {
 		cfg.HTTPClient = &http.Client{
-			Timeout: cfg.PollTimeout,
+			Transport: newTransport(cfg),
+			Timeout:   cfg.PollTimeout,
 		}
 	} else {
 		if cfg.HTTPClient.Timeout == 0 {
@@ -100,22 +258,35 @@ func NewWithConfig(cfg Config) *Client {
 	if cfg.Headers == nil {
 		cfg.Headers = make(map[string]string)
 	}
+	if cfg.Logger == nil {
+		cfg.Logger = cfg.Observability.Log()
+	}
+	var metrics Metrics = nopMetrics{}
+	if cfg.Metrics != nil {
+		metrics = cfg.Metrics
+	}
 
 	return &Client{
 		config:     cfg,
 		httpClient: cfg.HTTPClient,
 		logger:     cfg.Logger,
 		headers:    cfg.Headers,
+		metrics:    metrics,
 		active:     make(map[*pollContext]struct{}),
+		named:      make(map[string]*pollContext),
 	}
 }
 
 // Poll starts a long polling loop that continuously polls the given URL.
 // The handler function is called for each response. Polling continues until:
-// - The context is cancelled
-// - The handler returns shouldContinue=false
-// - The handler returns an error
-// - MaxRetries is exceeded (if set)
+// - The context is cancelled (the context error is returned)
+// - The poll is stopped with Stop, StopAll or Shutdown (ErrStoppedByClient)
+// - The handler returns shouldContinue=false (nil is returned)
+// - The handler returns an error (ErrHandlerStopped)
+// - RetryPolicy rejects a retry (ErrRetryRejected)
+// - MaxRetries is exceeded, if set (ErrMaxRetriesExceeded)
+// - Failures outlast MaxElapsedTime (ErrMaxElapsedTime)
+// - Failures use up the RetryBudget (ErrRetryBudgetExceeded)
 //
 // The handler can return a new URL for the next request, or an empty string
 // to reuse the same URL. This is useful for APIs like Telegram Bot API that
@@ -124,25 +295,55 @@ func NewWithConfig(cfg Config) *Client {
 // This method blocks until polling stops. To poll in the background, call it
 // in a goroutine.
 func (c *Client) Poll(ctx context.Context, url string, handler ResponseHandler) error {
-	pollCtx, cancel := context.WithCancel(ctx)
-	defer cancel()
-
-	pc := &pollContext{
-		ctx:    pollCtx,
-		cancel: cancel,
+	pc, done, err := c.track(ctx, "", url)
+	if err != nil {
+		return err
 	}
+	defer done()
+
+	return pc.result(c.pollLoop(pc, url, handler))
+}
 
+// track registers a polling operation so StopAll and Shutdown can cancel it,
+// and, if name is not empty, so Stop and Status can find it.
+// The returned function must be called when the operation ends.
+// It returns ErrClientClosed after Shutdown and ErrPollExists if a poll with
+// the same name is running.
+func (c *Client) track(ctx context.Context, name, url string) (*pollContext, func(), error) {
 	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return nil, nil, ErrClientClosed
+	}
+	if _, ok := c.named[name]; ok && name != "" {
+		return nil, nil, fmt.Errorf("%w: %q", ErrPollExists, name)
+	}
+
+	pollCtx, cancel := context.WithCancelCause(ctx)
+	pc := &pollContext{
+		ctx:     pollCtx,
+		cancel:  cancel,
+		name:    name,
+		started: time.Now(),
+		url:     url,
+	}
 	c.active[pc] = struct{}{}
-	c.mu.Unlock()
+	if name != "" {
+		c.named[name] = pc
+	}
 
-	defer func() {
+	return pc, func() {
+		cancel(nil)
 		c.mu.Lock()
 		delete(c.active, pc)
+		if name != "" {
+			delete(c.named, name)
+		}
+		if c.closed && len(c.active) == 0 {
+			close(c.drained)
+		}
 		c.mu.Unlock()
-	}()
-
-	return c.pollLoop(pollCtx, url, handler)
+	}, nil
 }
 
 // PollSimple is a convenience method that uses a SimpleResponseHandler.
@@ -154,9 +355,32 @@ func (c *Client) PollSimple(ctx context.Context, url string, handler SimpleRespo
 	})
 }
 
+// BodyBuilder builds the body of a poll request. See Config.BodyBuilder.
+type BodyBuilder func(ctx context.Context, iteration int, last Meta) (body io.Reader, contentType string, err error)
+
+// Meta describes the previous request of a poll loop.
+type Meta struct {
+	// URL is the URL of the previous request, empty before the first one.
+	URL string
+
+	// StatusCode is the status of the previous response, or 0 if there was
+	// none.
+	StatusCode int
+
+	// Header contains the headers of the previous response, or nil.
+	Header http.Header
+
+	// Err is the error of the previous request, nil if it succeeded.
+	Err error
+}
+
 // pollLoop performs the actual polling loop.
-func (c *Client) pollLoop(ctx context.Context, url string, handler ResponseHandler) error {
-	retries := 0
+func (c *Client) pollLoop(pc *pollContext, url string, handler ResponseHandler) error {
+	ctx := pc.ctx
+	st := newLoopState(pc, url)
+	if c.config.AdaptiveTimeout != nil {
+		st.adaptive = newAdaptiveState(*c.config.AdaptiveTimeout, c.config.PollTimeout)
+	}
 	currentURL := url
 
 	for {
@@ -166,41 +390,41 @@ func (c *Client) pollLoop(ctx context.Context, url string, handler ResponseHandl
 		default:
 		}
 
-		resp, err := c.makeRequest(ctx, currentURL)
-		if err != nil {
-			if c.logger != nil {
-				c.logger.Warn("long poll request failed", "url", currentURL, "error", err)
-			}
-
-			if c.config.MaxRetries >= 0 && retries >= c.config.MaxRetries {
-				return fmt.Errorf("max retries exceeded: %w", err)
-			}
-
-			retries++
-			if c.logger != nil {
-				c.logger.Debug("retrying long poll", "url", currentURL, "retry", retries)
-			}
+		if err := c.throttle(pc); err != nil {
+			return err
+		}
 
-			select {
-			case <-ctx.Done():
-				return ctx.Err()
-			case <-time.After(c.config.RetryDelay):
-				continue
+		reqURL, opts := currentURL, st.requestOptions()
+		if st.adaptive != nil {
+			reqURL, opts.timeout = st.adaptive.url(currentURL), st.adaptive.timeout()
+		}
+		start := time.Now()
+		resp, err := c.doRequest(ctx, reqURL, opts)
+		st.observe(reqURL, resp, err)
+		if st.adaptive != nil && st.adaptive.observe(time.Since(start), err) && c.logger != nil {
+			c.logger.Debug("adjusted poll timeout", "url", currentURL, "hold", st.adaptive.hold, "timeout", st.adaptive.timeout())
+		}
+		if err != nil {
+			if err := c.waitRetry(ctx, reqURL, resp, err, &st); err != nil {
+				return err
 			}
+			continue
 		}
 
-		retries = 0
+		c.succeeded(&st)
 
+		body := &countingBody{ReadCloser: resp.Body}
+		resp.Body = body
 		nextURL, shouldContinue, err := handler(resp)
+		resp.Body.Close()
+		c.metrics.ObserveBytes(st.name, body.n.Load())
 		if err != nil {
-			resp.Body.Close()
-			return fmt.Errorf("handler error: %w", err)
+			return fmt.Errorf("%w: %w", ErrHandlerStopped, err)
 		}
 
-		resp.Body.Close()
-
 		if nextURL != "" {
 			currentURL = nextURL
+			pc.setURL(currentURL)
 			if c.logger != nil {
 				c.logger.Debug("handler updated URL", "new_url", currentURL)
 			}
@@ -221,18 +445,342 @@ func (c *Client) pollLoop(ctx context.Context, url string, handler ResponseHandl
 	}
 }
 
+// loopState holds the consecutive failure counters of one poll loop.
+type loopState struct {
+	name           string
+	pc             *pollContext
+	retries        int
+	connectRetries int
+	failures       int
+	iteration      int
+	last           Meta
+	adaptive       *adaptiveState
+	failingSince   time.Time
+	retryTimes     []time.Time // retries within the RetryBudget window
+}
+
+// newLoopState returns the state of a loop tracked by pc that starts at url.
+// Named polls report metrics under their name, others under the URL host.
+func newLoopState(pc *pollContext, url string) loopState {
+	name := pc.name
+	if name == "" {
+		name = pollName(url)
+	}
+	return loopState{name: name, pc: pc}
+}
+
+// requestOptions returns the options of the next request of the loop.
+func (st *loopState) requestOptions() requestOptions {
+	return requestOptions{
+		poll:      st.name,
+		attempt:   st.failures + 1,
+		iteration: st.iteration,
+		last:      st.last,
+		method:    st.pc.method,
+		body:      st.pc.body,
+		info:      RequestInfo{Name: st.pc.name, Iteration: st.iteration, RetryStreak: st.failures, Started: st.pc.started},
+	}
+}
+
+// observe records the outcome of a request for the next BodyBuilder call
+// and counts responses for ActivePolls.
+func (st *loopState) observe(url string, resp *http.Response, err error) {
+	st.iteration++
+	st.last = Meta{URL: url, Err: err}
+	if resp != nil {
+		st.pc.recordResponse()
+		st.last.StatusCode = resp.StatusCode
+		st.last.Header = resp.Header
+	}
+}
+
+// succeeded resets the failure counters after a successful request.
+func (c *Client) succeeded(st *loopState) {
+	if st.failures > 0 {
+		c.metrics.SetFailureStreak(st.name, 0)
+	}
+	st.retries, st.connectRetries, st.failures = 0, 0, 0
+	st.pc.recordSuccess()
+}
+
+// waitRetry decides whether a failed request is retried and, if so, sleeps
+// before the next attempt and increments the matching counter in st. It returns a non-nil
+// error when polling must stop.
+func (c *Client) waitRetry(ctx context.Context, url string, resp *http.Response, err error, st *loopState) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	if st.failures == 0 {
+		st.failingSince = time.Now()
+	}
+	st.failures++
+	c.metrics.SetFailureStreak(st.name, st.failures)
+	st.pc.recordFailure(st.failures, err)
+
+	kind := errorKind(err)
+	if c.logger != nil {
+		msg := "long poll request failed"
+		if kind == "connect" {
+			msg = "long poll connection failed"
+		}
+		c.logger.Warn(msg, "url", url, "kind", kind, "error", err)
+	}
+
+	shouldRetry, delay := true, time.Duration(0)
+	if c.config.RetryPolicy != nil {
+		shouldRetry, delay = c.config.RetryPolicy(resp, err)
+	}
+	if !shouldRetry {
+		return fmt.Errorf("%w: %w", ErrRetryRejected, err)
+	}
+
+	// connection failures and other failures keep separate streaks; reaching
+	// the server at all ends a streak of connection failures
+	retries, maxRetries, backoff := &st.retries, c.config.MaxRetries, c.config.Backoff
+	if kind == "connect" {
+		retries = &st.connectRetries
+		if c.config.MaxConnectRetries != 0 {
+			maxRetries = c.config.MaxConnectRetries
+		}
+		if c.config.ConnectBackoff != nil {
+			backoff = c.config.ConnectBackoff
+		}
+	} else {
+		st.connectRetries = 0
+	}
+
+	if maxRetries >= 0 && *retries >= maxRetries {
+		return fmt.Errorf("%w: %w", ErrMaxRetriesExceeded, err)
+	}
+	if c.config.MaxElapsedTime > 0 && time.Since(st.failingSince) >= c.config.MaxElapsedTime {
+		return fmt.Errorf("%w: %w", ErrMaxElapsedTime, err)
+	}
+	if !c.config.RetryBudget.take(&st.retryTimes, time.Now()) {
+		return fmt.Errorf("%w: %w", ErrRetryBudgetExceeded, err)
+	}
+
+	if delay <= 0 {
+		delay = c.retryAfter(err)
+	}
+	if delay <= 0 {
+		if backoff != nil {
+			delay = backoff.Delay(*retries)
+		} else {
+			delay = c.config.RetryDelay
+		}
+	}
+	*retries++
+	c.metrics.ObserveRetry(st.name, kind, delay)
+	if c.config.OnRetry != nil {
+		c.config.OnRetry(url, st.failures+1, delay)
+	}
+	c.config.Observability.Count("longpoll_retries_total", "kind", kind)
+	if c.logger != nil {
+		c.logger.Debug("retrying long poll", "url", url, "kind", kind, "retry", *retries, "delay", delay)
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(delay):
+		return nil
+	}
+}
+
+// throttle waits, before a request, until the poll is resumed if it is
+// paused, and for the Limiter, if any.
+func (c *Client) throttle(pc *pollContext) error {
+	if err := pc.waitResumed(); err != nil {
+		return err
+	}
+	if c.config.Limiter == nil {
+		return nil
+	}
+	return c.config.Limiter.Wait(pc.ctx)
+}
+
+// RetryBudget caps the number of retries of a poll loop within a rolling
+// window. See Config.RetryBudget.
+type RetryBudget struct {
+	// Max is the number of retries allowed within Window.
+	Max int
+
+	// Window is the length of the rolling window.
+	Window time.Duration
+}
+
+// take records a retry at now in times, the retries made so far, and
+// reports whether the budget allowed it. A nil budget allows every retry.
+func (b *RetryBudget) take(times *[]time.Time, now time.Time) bool {
+	if b == nil {
+		return true
+	}
+	recent := (*times)[:0]
+	for _, t := range *times {
+		if now.Sub(t) < b.Window {
+			recent = append(recent, t)
+		}
+	}
+	*times = recent
+	if len(recent) >= b.Max {
+		return false
+	}
+	*times = append(recent, now)
+	return true
+}
+
+// retryDelay returns the delay before the given retry attempt (0-based).
+func (c *Client) retryDelay(attempt int) time.Duration {
+	if c.config.Backoff != nil {
+		return c.config.Backoff.Delay(attempt)
+	}
+	return c.config.RetryDelay
+}
+
+// requestOptions adjusts a single request.
+type requestOptions struct {
+	// header is added to the configured headers.
+	header http.Header
+
+	// poll is the metrics name of the poll loop.
+	poll string
+
+	// attempt is reported to the lifecycle callbacks.
+	attempt int
+
+	// iteration and last are passed to the BodyBuilder.
+	iteration int
+	last      Meta
+
+	// streaming disables the client timeout so the response body can stay
+	// open indefinitely, as needed for server-sent events.
+	streaming bool
+
+	// timeout, if positive, replaces the client timeout.
+	timeout time.Duration
+
+	// info is attached to the request context; its URL is set by doRequest.
+	info RequestInfo
+
+	// method and body override Config.Method and Config.BodyBuilder.
+	method string
+	body   BodyBuilder
+}
+
+// doRequest performs one poll request and records its span and metrics.
+func (c *Client) doRequest(ctx context.Context, url string, opts requestOptions) (*http.Response, error) {
+	obs := c.config.Observability
+	ctx, span := obs.StartSpan(ctx, "longpoll.request",
+		slog.String("http.method", cmp.Or(opts.method, c.config.Method)),
+		slog.String("http.url", url),
+	)
+	defer span.End()
+
+	opts.info.URL = url
+	ctx = withRequestInfo(ctx, opts.info)
+	ctx = c.traceConn(ctx, url, opts.poll)
+
+	if c.config.OnRequest != nil {
+		c.config.OnRequest(url, opts.attempt)
+	}
+
+	start := time.Now()
+	resp, err := c.makeRequest(ctx, url, opts)
+	if c.needsRefresh(err) {
+		if c.logger != nil {
+			c.logger.Debug("refreshing token", "url", url, "status", resp.StatusCode)
+		}
+		if _, refreshErr := c.config.TokenSource.Refresh(withRejectedToken(ctx, resp.Request)); refreshErr != nil {
+			err = fmt.Errorf("refresh token: %w: %w", refreshErr, err)
+		} else {
+			resp, err = c.makeRequest(ctx, url, opts)
+		}
+	}
+	if resp != nil && c.config.OnResponse != nil {
+		c.config.OnResponse(url, opts.attempt, resp.StatusCode)
+	}
+	if err != nil && c.config.OnError != nil {
+		c.config.OnError(url, opts.attempt, err)
+	}
+	status := "error"
+	if resp != nil {
+		status = strconv.Itoa(resp.StatusCode)
+		span.SetAttributes(slog.Int("http.status_code", resp.StatusCode))
+	}
+	if err != nil {
+		span.RecordError(err)
+	}
+	latency := time.Since(start)
+	obs.Count("longpoll_requests_total", "status", status)
+	obs.ObserveDuration("longpoll_request_duration_seconds", latency)
+
+	statusCode := 0
+	if resp != nil {
+		statusCode = resp.StatusCode
+	}
+	c.metrics.ObserveRequest(opts.poll, statusCode, latency, err)
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		c.metrics.ObserveBytes(opts.poll, int64(len(statusErr.Body)))
+	}
+	return resp, err
+}
+
+// retryAfter returns the server-requested delay for err, capped at
+// MaxRetryAfter, or zero if the server did not ask for one.
+func (c *Client) retryAfter(err error) time.Duration {
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) {
+		return 0
+	}
+	delay, ok := statusErr.RetryAfter()
+	if !ok {
+		return 0
+	}
+	if c.config.MaxRetryAfter > 0 {
+		delay = min(delay, c.config.MaxRetryAfter)
+	}
+	return delay
+}
+
 // makeRequest creates and executes a single long polling HTTP request.
-func (c *Client) makeRequest(ctx context.Context, url string) (*http.Response, error) {
+// For non-2xx responses it returns the response, with its body buffered,
+// together with a *StatusError.
+func (c *Client) makeRequest(ctx context.Context, url string, opts requestOptions) (*http.Response, error) {
 	var bodyReader io.Reader
-	if c.config.BodyBuilder != nil {
+	var contentType string
+	buildBody := c.config.BodyBuilder
+	if opts.body != nil {
+		buildBody = opts.body
+	}
+	if buildBody != nil {
 		var err error
-		bodyReader, err = c.config.BodyBuilder()
+		bodyReader, contentType, err = buildBody(ctx, opts.iteration, opts.last)
 		if err != nil {
 			return nil, fmt.Errorf("build request body: %w", err)
 		}
 	}
 
+	// the signer needs a digest of the body, so buffer it
+	var bodyDigest []byte
+	if c.config.Signer != nil {
+		var body []byte
+		if bodyReader != nil {
+			var err error
+			if body, err = io.ReadAll(bodyReader); err != nil {
+				return nil, fmt.Errorf("read request body: %w", err)
+			}
+			bodyReader = bytes.NewReader(body)
+		}
+		sum := sha256.Sum256(body)
+		bodyDigest = sum[:]
+	}
+
 	method := c.config.Method
+	if opts.method != "" {
+		method = opts.method
+	}
 	if method == "" {
 		method = http.MethodGet
 	}
@@ -246,22 +794,73 @@ func (c *Client) makeRequest(ctx context.Context, url string) (*http.Response, e
 	for k, v := range c.headers {
 		req.Header.Set(k, v)
 	}
+	for k, v := range opts.header {
+		req.Header[k] = v
+	}
 
-	if bodyReader != nil && method == http.MethodPost {
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	} else if bodyReader != nil && method == http.MethodPost {
 		if req.Header.Get("Content-Type") == "" {
 			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 		}
 	}
 
-	resp, err := c.httpClient.Do(req)
+	if c.config.ConnReuse == ConnReuseNever {
+		req.Close = true
+	}
+
+	if c.config.Decompress && req.Header.Get("Accept-Encoding") == "" {
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+
+	if err := c.authorize(ctx, req); err != nil {
+		return nil, err
+	}
+
+	if c.config.Signer != nil {
+		if err := c.config.Signer.Sign(req, bodyDigest); err != nil {
+			return nil, fmt.Errorf("sign request: %w", err)
+		}
+	}
+
+	client := c.httpClient
+	timeout := client.Timeout
+	if opts.timeout > 0 {
+		timeout = opts.timeout
+	}
+	if opts.streaming {
+		timeout = 0
+	}
+	if timeout != client.Timeout {
+		reqClient := *client
+		reqClient.Timeout = timeout
+		client = &reqClient
+	}
+
+	resp, err := client.Do(req)
 	if err != nil {
+		if connErr := classifyConnectError(err); connErr != nil {
+			return nil, fmt.Errorf("http request: %w", connErr)
+		}
 		return nil, fmt.Errorf("http request: %w", err)
 	}
 
+	if c.config.ConnReuse == ConnReuseForce && !opts.streaming {
+		resp.Body = drainBody{resp.Body}
+	}
+
+	if c.config.Decompress {
+		if err := decompress(resp); err != nil {
+			return nil, err
+		}
+	}
+
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		body, _ := io.ReadAll(resp.Body)
 		resp.Body.Close()
-		return nil, fmt.Errorf("http error %d: %s", resp.StatusCode, string(body))
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		return resp, &StatusError{StatusCode: resp.StatusCode, Header: resp.Header, Body: body}
 	}
 
 	return resp, nil
@@ -273,7 +872,43 @@ func (c *Client) StopAll() {
 	defer c.mu.Unlock()
 
 	for pc := range c.active {
-		pc.cancel()
+		pc.cancel(ErrStoppedByClient)
+	}
+}
+
+// Shutdown stops all active polling operations and waits for them to end,
+// including any handler still running, or for ctx to expire, whichever comes
+// first. It returns ctx.Err() if the context expired before every poll ended.
+// Like http.Server.Shutdown, the client cannot be reused: polling methods
+// called afterwards return ErrClientClosed. Once every poll has ended, the
+// event bus returned by Events is closed. Shutdown may be called more than
+// once.
+func (c *Client) Shutdown(ctx context.Context) error {
+	c.mu.Lock()
+	if !c.closed {
+		c.closed = true
+		c.drained = make(chan struct{})
+		if len(c.active) == 0 {
+			close(c.drained)
+		}
+	}
+	for pc := range c.active {
+		pc.cancel(ErrStoppedByClient)
+	}
+	drained := c.drained
+	c.mu.Unlock()
+
+	select {
+	case <-drained:
+		c.mu.Lock()
+		bus := c.bus
+		c.mu.Unlock()
+		if bus != nil {
+			bus.close()
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
 	}
 }
 
@@ -302,6 +937,26 @@ func (c *Client) WithLogger(logger *slog.Logger) *Client {
 	return c
 }
 
+// WithObservability sets the observability configuration.
+// Its Logger is used if no logger has been set.
+func (c *Client) WithObservability(obs *observability.Config) *Client {
+	c.config.Observability = obs
+	if c.logger == nil {
+		c.logger = obs.Log()
+	}
+	return c
+}
+
+// WithMetrics sets the hook that receives per-poll statistics.
+func (c *Client) WithMetrics(metrics Metrics) *Client {
+	c.config.Metrics = metrics
+	if metrics == nil {
+		metrics = nopMetrics{}
+	}
+	c.metrics = metrics
+	return c
+}
+
 // WithMethod sets the HTTP method for polling requests (GET, POST, etc.).
 func (c *Client) WithMethod(method string) *Client {
 	c.config.Method = method
@@ -309,7 +964,49 @@ func (c *Client) WithMethod(method string) *Client {
 }
 
 // WithBodyBuilder sets a function that builds the request body for each poll.
-func (c *Client) WithBodyBuilder(builder func() (io.Reader, error)) *Client {
+func (c *Client) WithBodyBuilder(builder BodyBuilder) *Client {
 	c.config.BodyBuilder = builder
 	return c
 }
+
+// WithSigner sets the signer invoked for every request.
+func (c *Client) WithSigner(signer Signer) *Client {
+	c.config.Signer = signer
+	return c
+}
+
+// WithDecompress enables transparent gzip and deflate response decompression.
+func (c *Client) WithDecompress(enabled bool) *Client {
+	c.config.Decompress = enabled
+	return c
+}
+
+// WithConnReuse sets whether requests reuse keep-alive connections.
+func (c *Client) WithConnReuse(reuse ConnReuse) *Client {
+	c.config.ConnReuse = reuse
+	return c
+}
+
+// WithTokenSource sets the source of the bearer token sent with every request.
+func (c *Client) WithTokenSource(source TokenSource) *Client {
+	c.config.TokenSource = source
+	return c
+}
+
+// WithLimiter sets the rate limiter waited on before each request.
+func (c *Client) WithLimiter(limiter ratelimit.Limiter) *Client {
+	c.config.Limiter = limiter
+	return c
+}
+
+// WithBackoff sets an exponential backoff policy for failed requests.
+func (c *Client) WithBackoff(strategy *retry.Strategy) *Client {
+	c.config.Backoff = strategy
+	return c
+}
+
+// WithRetryPolicy sets a function that decides whether and when failed requests are retried.
+func (c *Client) WithRetryPolicy(policy func(*http.Response, error) (bool, time.Duration)) *Client {
+	c.config.RetryPolicy = policy
+	return c
+}
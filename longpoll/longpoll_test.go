@@ -2,16 +2,24 @@ package longpoll
 
 import (
 	"context"
+	"crypto/x509"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
+
+	"github.com/en9inerd/go-pkgs/observability/observabilitytest"
+	"github.com/en9inerd/go-pkgs/ratelimit"
+	"github.com/en9inerd/go-pkgs/retry"
 )
 
 func TestClient_Poll(t *testing.T) {
@@ -249,6 +257,353 @@ func TestClient_Poll_MaxRetriesZero(t *testing.T) {
 	}
 }
 
+func TestClient_Poll_Backoff(t *testing.T) {
+	var mu sync.Mutex
+	var times []time.Time
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		times = append(times, time.Now())
+		attempt := len(times)
+		mu.Unlock()
+
+		if attempt <= 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewWithConfig(Config{
+		PollTimeout: 1 * time.Second,
+		RetryDelay:  time.Hour, // must be ignored when Backoff is set
+		MaxRetries:  5,
+		Backoff: &retry.Strategy{
+			InitialDelay: 20 * time.Millisecond,
+			MaxDelay:     time.Second,
+			Multiplier:   2.0,
+		},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	err := client.Poll(ctx, server.URL, func(resp *http.Response) (string, bool, error) {
+		return "", false, nil
+	})
+	if err != nil {
+		t.Fatalf("Poll failed: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(times) != 4 {
+		t.Fatalf("expected 4 attempts, got %d", len(times))
+	}
+	for i, want := range []time.Duration{20 * time.Millisecond, 40 * time.Millisecond, 80 * time.Millisecond} {
+		if gap := times[i+1].Sub(times[i]); gap < want {
+			t.Errorf("gap before attempt %d = %v, want >= %v", i+2, gap, want)
+		}
+	}
+}
+
+func TestClient_Poll_RetryPolicy(t *testing.T) {
+	var mu sync.Mutex
+	statuses := []int{http.StatusTooManyRequests, http.StatusUnauthorized}
+	attempts := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		status := statuses[min(attempts, len(statuses)-1)]
+		attempts++
+		mu.Unlock()
+		w.WriteHeader(status)
+		io.WriteString(w, "denied")
+	}))
+	defer server.Close()
+
+	var seen []int
+	client := NewWithConfig(Config{
+		PollTimeout: 1 * time.Second,
+		RetryDelay:  time.Hour, // overridden by the policy delay
+		MaxRetries:  -1,
+		RetryPolicy: func(resp *http.Response, err error) (bool, time.Duration) {
+			if resp == nil {
+				return true, 0
+			}
+			seen = append(seen, resp.StatusCode)
+			if body, _ := io.ReadAll(resp.Body); string(body) != "denied" {
+				t.Errorf("policy body = %q, want %q", body, "denied")
+			}
+			if resp.StatusCode == http.StatusUnauthorized {
+				return false, 0
+			}
+			return true, 10 * time.Millisecond
+		},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	err := client.Poll(ctx, server.URL, func(resp *http.Response) (string, bool, error) {
+		return "", true, nil
+	})
+
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected 401 StatusError, got %v", err)
+	}
+	if len(seen) != 2 || seen[0] != http.StatusTooManyRequests {
+		t.Errorf("policy saw %v, want [429 401]", seen)
+	}
+}
+
+func TestClient_Poll_RetryAfter(t *testing.T) {
+	var mu sync.Mutex
+	attempts := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		attempts++
+		n := attempts
+		mu.Unlock()
+		switch n {
+		case 1:
+			w.Header().Set("Retry-After", "3600")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			w.Header().Set("Retry-After", "3600")
+			w.WriteHeader(http.StatusInternalServerError) // not honored for 500
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+
+	client := NewWithConfig(Config{
+		PollTimeout:   time.Second,
+		RetryDelay:    10 * time.Millisecond,
+		MaxRetryAfter: 50 * time.Millisecond,
+		MaxRetries:    -1,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	err := client.Poll(ctx, server.URL, func(resp *http.Response) (string, bool, error) {
+		return "", false, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 60*time.Millisecond {
+		t.Errorf("elapsed = %v, expected the capped Retry-After delay to be applied", elapsed)
+	}
+	if attempts != 3 {
+		t.Errorf("attempts = %d, want 3", attempts)
+	}
+}
+
+func TestClassifyConnectError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{&net.DNSError{Err: "no such host", Name: "example.invalid"}, "dns"},
+		{&net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}, "dial"},
+		{&net.OpError{Op: "read", Net: "tcp", Err: errors.New("reset")}, ""},
+		{fmt.Errorf("wrapped: %w", x509.UnknownAuthorityError{}), "tls"},
+		{errors.New("other"), ""},
+	}
+	for _, tt := range tests {
+		got := classifyConnectError(tt.err)
+		if tt.want == "" {
+			if got != nil {
+				t.Errorf("classifyConnectError(%v) = %v, want nil", tt.err, got)
+			}
+			continue
+		}
+		if got == nil || got.Op != tt.want {
+			t.Errorf("classifyConnectError(%v) = %v, want op %q", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestClient_Poll_ConnectRetriesSeparate(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+
+	obs, metrics, _ := observabilitytest.New()
+	client := NewWithConfig(Config{
+		PollTimeout:       time.Second,
+		RetryDelay:        time.Hour,
+		ConnectBackoff:    &retry.Strategy{InitialDelay: time.Millisecond, Multiplier: 2},
+		MaxRetries:        0,
+		MaxConnectRetries: 2,
+		Observability:     obs,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	err := client.Poll(ctx, url, func(resp *http.Response) (string, bool, error) {
+		return "", true, nil
+	})
+	if !IsConnectError(err) {
+		t.Fatalf("expected connect error, got %v", err)
+	}
+	var connErr *ConnectError
+	if errors.As(err, &connErr); connErr.Op != "dial" {
+		t.Errorf("Op = %q, want dial", connErr.Op)
+	}
+	if v := metrics.Value("longpoll_retries_total", "kind", "connect"); v != 2 {
+		t.Errorf("connect retries = %v, want 2", v)
+	}
+}
+
+func TestClient_Poll_Observability(t *testing.T) {
+	var calls int
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls++
+		n := calls
+		mu.Unlock()
+		if n == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	obs, metrics, tracer := observabilitytest.New()
+	client := NewWithConfig(Config{
+		PollTimeout:   time.Second,
+		RetryDelay:    10 * time.Millisecond,
+		MaxRetries:    -1,
+		Observability: obs,
+	})
+
+	err := client.Poll(context.Background(), server.URL, func(resp *http.Response) (string, bool, error) {
+		return "", false, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v := metrics.Value("longpoll_requests_total", "status", "502"); v != 1 {
+		t.Errorf("longpoll_requests_total{502} = %v, want 1", v)
+	}
+	if v := metrics.Value("longpoll_requests_total", "status", "200"); v != 1 {
+		t.Errorf("longpoll_requests_total{200} = %v, want 1", v)
+	}
+	if v := metrics.Value("longpoll_retries_total", "kind", "status"); v != 1 {
+		t.Errorf("longpoll_retries_total = %v, want 1", v)
+	}
+	spans := tracer.Spans("longpoll.request")
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+	if spans[0].Err == nil {
+		t.Error("expected error recorded on failed request span")
+	}
+}
+
+func TestClient_Poll_LifecycleCallbacks(t *testing.T) {
+	var mu sync.Mutex
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		attempts++
+		n := attempts
+		mu.Unlock()
+		if n <= 2 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	var events []string
+	client := NewWithConfig(Config{
+		PollTimeout: time.Second,
+		RetryDelay:  time.Millisecond,
+		MaxRetries:  -1,
+		OnRequest: func(url string, attempt int) {
+			events = append(events, fmt.Sprintf("request %d", attempt))
+		},
+		OnResponse: func(url string, attempt int, statusCode int) {
+			events = append(events, fmt.Sprintf("response %d %d", attempt, statusCode))
+		},
+		OnError: func(url string, attempt int, err error) {
+			events = append(events, fmt.Sprintf("error %d", attempt))
+		},
+		OnRetry: func(url string, attempt int, delay time.Duration) {
+			events = append(events, fmt.Sprintf("retry %d %v", attempt, delay))
+		},
+	})
+
+	err := client.Poll(context.Background(), server.URL, func(*http.Response) (string, bool, error) {
+		events = append(events, "handler")
+		return "", false, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"request 1", "response 1 502", "error 1", "retry 2 1ms",
+		"request 2", "response 2 502", "error 2", "retry 3 1ms",
+		"request 3", "response 3 200", "handler",
+	}
+	if strings.Join(events, ", ") != strings.Join(want, ", ") {
+		t.Errorf("events = %v\nwant     %v", events, want)
+	}
+}
+
+func TestClient_Poll_Limiter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewWithConfig(Config{PollTimeout: time.Second}).
+		WithLimiter(ratelimit.NewTokenBucket(1, 20)) // one request per 50ms
+
+	requests := 0
+	start := time.Now()
+	err := client.Poll(context.Background(), server.URL, func(*http.Response) (string, bool, error) {
+		requests++
+		return "", requests < 5, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 180*time.Millisecond {
+		t.Errorf("5 requests took %v, want at least 200ms with the limiter", elapsed)
+	}
+}
+
+func TestClient_Poll_LimiterCancelled(t *testing.T) {
+	client := NewWithConfig(Config{PollTimeout: time.Second}).
+		WithLimiter(ratelimit.NewFixedWindow(0, time.Hour))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := client.Poll(ctx, "http://127.0.0.1:1/", func(*http.Response) (string, bool, error) {
+		t.Error("handler called")
+		return "", false, nil
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Poll = %v, want context.DeadlineExceeded", err)
+	}
+}
+
 func TestClient_StopAll(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Hold connection open
@@ -295,6 +650,81 @@ func TestClient_StopAll(t *testing.T) {
 	}
 }
 
+func TestClient_Shutdown(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewWithConfig(Config{PollTimeout: 5 * time.Second})
+
+	entered := make(chan struct{})
+	var handlerDone atomic.Bool
+	go client.Poll(context.Background(), server.URL, func(resp *http.Response) (string, bool, error) {
+		close(entered)
+		<-resp.Request.Context().Done()
+		time.Sleep(100 * time.Millisecond) // simulate finishing in-flight work
+		handlerDone.Store(true)
+		return "", false, nil
+	})
+	<-entered
+
+	if err := client.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	if !handlerDone.Load() {
+		t.Error("Shutdown returned before the handler finished")
+	}
+	if n := client.ActiveCount(); n != 0 {
+		t.Errorf("ActiveCount = %d after Shutdown", n)
+	}
+
+	err := client.Poll(context.Background(), server.URL, func(*http.Response) (string, bool, error) {
+		t.Error("handler called after Shutdown")
+		return "", false, nil
+	})
+	if !errors.Is(err, ErrClientClosed) {
+		t.Errorf("Poll after Shutdown = %v, want ErrClientClosed", err)
+	}
+	if err := client.Shutdown(context.Background()); err != nil {
+		t.Errorf("second Shutdown: %v", err)
+	}
+}
+
+func TestClient_Shutdown_ContextExpires(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewWithConfig(Config{PollTimeout: 5 * time.Second})
+
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		client.Poll(context.Background(), server.URL, func(*http.Response) (string, bool, error) {
+			close(entered)
+			<-release // ignores cancellation
+			return "", false, nil
+		})
+	}()
+	<-entered
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := client.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Shutdown = %v, want context.DeadlineExceeded", err)
+	}
+
+	close(release)
+	<-stopped
+	if err := client.Shutdown(context.Background()); err != nil {
+		t.Errorf("Shutdown after handler returned: %v", err)
+	}
+}
+
 func TestClient_WithHeader(t *testing.T) {
 	var receivedHeader string
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -436,9 +866,9 @@ func TestClient_Poll_POST(t *testing.T) {
 	client := NewWithConfig(Config{
 		PollTimeout: 1 * time.Second,
 		Method:      http.MethodPost,
-		BodyBuilder: func() (io.Reader, error) {
+		BodyBuilder: func(ctx context.Context, iteration int, last Meta) (io.Reader, string, error) {
 			bodyCount++
-			return strings.NewReader(fmt.Sprintf("data=%d", bodyCount)), nil
+			return strings.NewReader(fmt.Sprintf("data=%d", bodyCount)), "", nil
 		},
 	})
 
@@ -458,6 +888,252 @@ func TestClient_Poll_POST(t *testing.T) {
 	}
 }
 
+func TestClient_Poll_BodyBuilderState(t *testing.T) {
+	var requests atomic.Int32
+	var mu sync.Mutex
+	var bodies, contentTypes []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		bodies = append(bodies, string(body))
+		contentTypes = append(contentTypes, r.Header.Get("Content-Type"))
+		mu.Unlock()
+		n := requests.Add(1)
+		if n == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("X-Cursor", fmt.Sprintf("c%d", n))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	type call struct {
+		iteration int
+		last      Meta
+	}
+	var calls []call
+	client := NewWithConfig(Config{
+		PollTimeout: time.Second,
+		RetryDelay:  time.Millisecond,
+		MaxRetries:  1,
+		Method:      http.MethodPost,
+		BodyBuilder: func(ctx context.Context, iteration int, last Meta) (io.Reader, string, error) {
+			if ctx == nil {
+				t.Error("BodyBuilder got nil context")
+			}
+			calls = append(calls, call{iteration, last})
+			cursor := ""
+			if last.Header != nil {
+				cursor = last.Header.Get("X-Cursor")
+			}
+			return strings.NewReader(fmt.Sprintf(`{"cursor":%q}`, cursor)), "application/json", nil
+		},
+	})
+
+	handled := 0
+	err := client.Poll(context.Background(), server.URL, func(resp *http.Response) (string, bool, error) {
+		handled++
+		return "", handled < 2, nil
+	})
+	if err != nil {
+		t.Fatalf("Poll: %v", err)
+	}
+
+	if len(calls) != 3 {
+		t.Fatalf("BodyBuilder calls = %d, want 3", len(calls))
+	}
+	if calls[0].iteration != 0 || calls[0].last.URL != "" || calls[0].last.StatusCode != 0 {
+		t.Errorf("first call = %+v, want zero Meta", calls[0])
+	}
+	var statusErr *StatusError
+	if calls[1].iteration != 1 || calls[1].last.StatusCode != http.StatusServiceUnavailable || !errors.As(calls[1].last.Err, &statusErr) {
+		t.Errorf("second call = %+v, want failed 503", calls[1])
+	}
+	if calls[2].iteration != 2 || calls[2].last.StatusCode != http.StatusOK || calls[2].last.Err != nil || calls[2].last.URL != server.URL {
+		t.Errorf("third call = %+v, want successful 200", calls[2])
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if want := `{"cursor":"c2"}`; bodies[2] != want {
+		t.Errorf("third body = %q, want %q", bodies[2], want)
+	}
+	for i, ct := range contentTypes {
+		if ct != "application/json" {
+			t.Errorf("request %d Content-Type = %q, want application/json", i, ct)
+		}
+	}
+}
+
+func TestClient_Poll_TerminationErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	errHandler := errors.New("bad payload")
+	tests := []struct {
+		name    string
+		cfg     Config
+		path    string
+		handler ResponseHandler
+		want    error
+	}{
+		{
+			name: "max retries",
+			cfg:  Config{MaxRetries: 1},
+			path: "/fail",
+			want: ErrMaxRetriesExceeded,
+		},
+		{
+			name: "retry rejected",
+			cfg: Config{MaxRetries: -1, RetryPolicy: func(*http.Response, error) (bool, time.Duration) {
+				return false, 0
+			}},
+			path: "/fail",
+			want: ErrRetryRejected,
+		},
+		{
+			name: "handler error",
+			path: "/ok",
+			handler: func(*http.Response) (string, bool, error) {
+				return "", false, errHandler
+			},
+			want: ErrHandlerStopped,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cfg.PollTimeout, tt.cfg.RetryDelay = time.Second, time.Millisecond
+			client := NewWithConfig(tt.cfg)
+			err := client.Poll(context.Background(), server.URL+tt.path, tt.handler)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Poll error = %v, want %v", err, tt.want)
+			}
+			var statusErr *StatusError
+			if tt.path == "/fail" && !errors.As(err, &statusErr) {
+				t.Errorf("error %v does not wrap the last StatusError", err)
+			}
+			if tt.handler != nil && !errors.Is(err, errHandler) {
+				t.Errorf("error %v does not wrap the handler error", err)
+			}
+		})
+	}
+}
+
+func TestClient_Poll_StoppedByClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewWithConfig(Config{PollTimeout: time.Second})
+	handler := func(*http.Response) (string, bool, error) {
+		time.Sleep(time.Millisecond)
+		return "", true, nil
+	}
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- client.Poll(context.Background(), server.URL, handler) }()
+	for client.ActiveCount() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	client.StopAll()
+	if err := <-errCh; !errors.Is(err, ErrStoppedByClient) || !errors.Is(err, context.Canceled) {
+		t.Errorf("Poll error after StopAll = %v, want ErrStoppedByClient wrapping context.Canceled", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() { errCh <- client.Poll(ctx, server.URL, handler) }()
+	time.Sleep(5 * time.Millisecond)
+	cancel()
+	if err := <-errCh; errors.Is(err, ErrStoppedByClient) || !errors.Is(err, context.Canceled) {
+		t.Errorf("Poll error after cancel = %v, want plain context.Canceled", err)
+	}
+}
+
+func TestClient_Poll_RetryBudget(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1)%2 == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// every streak is a single failure, so MaxRetries alone never ends the loop
+	client := NewWithConfig(Config{
+		PollTimeout: time.Second,
+		RetryDelay:  time.Millisecond,
+		MaxRetries:  1,
+		RetryBudget: &RetryBudget{Max: 3, Window: time.Minute},
+	})
+
+	handled := 0
+	err := client.Poll(context.Background(), server.URL, func(*http.Response) (string, bool, error) {
+		handled++
+		return "", true, nil
+	})
+	var statusErr *StatusError
+	if !errors.Is(err, ErrRetryBudgetExceeded) || !errors.As(err, &statusErr) {
+		t.Fatalf("Poll error = %v, want ErrRetryBudgetExceeded wrapping the StatusError", err)
+	}
+	if handled != 3 || requests.Load() != 7 {
+		t.Errorf("handled = %d, requests = %d; want 3 and 7", handled, requests.Load())
+	}
+}
+
+func TestRetryBudget_Window(t *testing.T) {
+	b := &RetryBudget{Max: 2, Window: time.Minute}
+	var times []time.Time
+	now := time.Now()
+	if !b.take(&times, now) || !b.take(&times, now.Add(time.Second)) {
+		t.Fatal("budget rejected retries within Max")
+	}
+	if b.take(&times, now.Add(2*time.Second)) {
+		t.Error("budget allowed a third retry within the window")
+	}
+	if !b.take(&times, now.Add(time.Minute)) {
+		t.Error("budget rejected a retry after the first one left the window")
+	}
+	var nilBudget *RetryBudget
+	if !nilBudget.take(&times, now) {
+		t.Error("nil budget rejected a retry")
+	}
+}
+
+func TestClient_Poll_MaxElapsedTime(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := NewWithConfig(Config{
+		PollTimeout:    time.Second,
+		RetryDelay:     10 * time.Millisecond,
+		MaxRetries:     -1,
+		MaxElapsedTime: 50 * time.Millisecond,
+	})
+
+	start := time.Now()
+	err := client.Poll(context.Background(), server.URL, func(*http.Response) (string, bool, error) {
+		return "", true, nil
+	})
+	if !errors.Is(err, ErrMaxElapsedTime) {
+		t.Fatalf("Poll error = %v, want ErrMaxElapsedTime", err)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond || elapsed > time.Second {
+		t.Errorf("Poll ended after %v, want about 50ms", elapsed)
+	}
+}
+
 func ExampleClient_Poll() {
 	// Create a long polling client
 	client := NewWithConfig(Config{
@@ -0,0 +1,175 @@
+// Package longpolltest provides a scriptable fake long polling server for
+// testing code built on the longpoll client.
+//
+//	srv := longpolltest.NewServer(
+//		longpolltest.Response{Hold: 50 * time.Millisecond, Body: `{"updates":[1,2]}`},
+//		longpolltest.Response{Status: http.StatusServiceUnavailable},
+//		longpolltest.Response{Drop: true},
+//		longpolltest.Response{Body: `{"offset":{offset}}`},
+//	)
+//	defer srv.Close()
+//
+//	err := client.Poll(ctx, srv.URL+"?offset=3", handler)
+//	reqs := srv.Requests()
+package longpolltest
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Response is one scripted reply of a Server.
+type Response struct {
+	// Status is the status code. Default: 200
+	Status int
+
+	// Header is added to the response headers.
+	Header http.Header
+
+	// Body is the response body. Placeholders of the form {name} are
+	// replaced with the value of the request's name query parameter, if
+	// present, so a response can echo back an offset or cursor.
+	Body string
+
+	// Hold is how long the request is held before replying, as a long
+	// polling server does while waiting for data. A request abandoned by
+	// the client ends the hold early.
+	Hold time.Duration
+
+	// Drop closes the connection without replying, after Hold, to simulate
+	// a network failure.
+	Drop bool
+}
+
+// Request is a request received by a Server.
+type Request struct {
+	Method string
+	URL    *url.URL
+	Header http.Header
+	Body   []byte
+	Time   time.Time
+}
+
+// Server is a fake long polling server. It replies to each request with the
+// next scripted Response, then with the response set by SetDefault once the
+// script is exhausted. It is safe for concurrent use.
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	script   []Response
+	fallback Response
+	requests []Request
+}
+
+// NewServer starts a Server that replies with responses in order.
+// The caller must call Close when finished.
+func NewServer(responses ...Response) *Server {
+	s := &Server{script: slices.Clone(responses)}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+// Enqueue appends responses to the script.
+func (s *Server) Enqueue(responses ...Response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.script = append(s.script, responses...)
+}
+
+// SetDefault sets the reply used once the script is exhausted. Until it is
+// set, such requests get 200 OK with an empty body.
+func (s *Server) SetDefault(r Response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fallback = r
+}
+
+// Requests returns the requests received so far, in order.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.requests)
+}
+
+// Count returns the number of requests received so far.
+func (s *Server) Count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.requests)
+}
+
+// Pending returns the number of scripted responses not yet used.
+func (s *Server) Pending() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.script)
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{
+		Method: r.Method,
+		URL:    r.URL,
+		Header: r.Header.Clone(),
+		Body:   body,
+		Time:   time.Now(),
+	})
+	resp := s.fallback
+	if len(s.script) > 0 {
+		resp = s.script[0]
+		s.script = s.script[1:]
+	}
+	s.mu.Unlock()
+
+	if resp.Hold > 0 {
+		timer := time.NewTimer(resp.Hold)
+		select {
+		case <-timer.C:
+		case <-r.Context().Done():
+			timer.Stop()
+			return
+		}
+	}
+
+	if resp.Drop {
+		if hj, ok := w.(http.Hijacker); ok {
+			if conn, _, err := hj.Hijack(); err == nil {
+				conn.Close()
+				return
+			}
+		}
+		panic(http.ErrAbortHandler)
+	}
+
+	for k, vs := range resp.Header {
+		w.Header()[k] = vs
+	}
+	status := resp.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	w.WriteHeader(status)
+	io.WriteString(w, expand(resp.Body, r.URL.Query()))
+}
+
+var placeholder = regexp.MustCompile(`\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// expand replaces {name} placeholders in body with query values.
+func expand(body string, query url.Values) string {
+	return placeholder.ReplaceAllStringFunc(body, func(m string) string {
+		name := m[1 : len(m)-1]
+		if !query.Has(name) {
+			return m
+		}
+		return query.Get(name)
+	})
+}
@@ -0,0 +1,71 @@
+package longpolltest
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/en9inerd/go-pkgs/longpoll"
+)
+
+func TestServer_Script(t *testing.T) {
+	srv := NewServer(
+		Response{Hold: 20 * time.Millisecond, Body: `{"offset":{offset}}`, Header: http.Header{"X-Test": {"1"}}},
+		Response{Status: http.StatusServiceUnavailable},
+		Response{Drop: true},
+	)
+	defer srv.Close()
+	srv.SetDefault(Response{Body: "default"})
+
+	client := longpoll.NewWithConfig(longpoll.Config{PollTimeout: time.Second, RetryDelay: time.Millisecond, MaxRetries: 2})
+
+	var bodies []string
+	start := time.Now()
+	err := client.Poll(context.Background(), srv.URL+"?offset=7", func(resp *http.Response) (string, bool, error) {
+		b, _ := io.ReadAll(resp.Body)
+		bodies = append(bodies, string(b))
+		if len(bodies) == 1 && resp.Header.Get("X-Test") != "1" {
+			t.Error("scripted header missing")
+		}
+		return "", len(bodies) < 2, nil
+	})
+	if err != nil {
+		t.Fatalf("Poll: %v", err)
+	}
+
+	if len(bodies) != 2 || bodies[0] != `{"offset":7}` || bodies[1] != "default" {
+		t.Errorf("bodies = %q", bodies)
+	}
+	if time.Since(start) < 20*time.Millisecond {
+		t.Error("Hold was not applied")
+	}
+	if srv.Count() != 4 || srv.Pending() != 0 {
+		t.Errorf("Count = %d, Pending = %d; want 4 and 0", srv.Count(), srv.Pending())
+	}
+	if reqs := srv.Requests(); reqs[0].URL.Query().Get("offset") != "7" || reqs[0].Method != http.MethodGet {
+		t.Errorf("first request = %+v", reqs[0])
+	}
+}
+
+func TestServer_DropIsAFailure(t *testing.T) {
+	srv := NewServer(Response{Drop: true})
+	defer srv.Close()
+
+	client := longpoll.NewWithConfig(longpoll.Config{PollTimeout: time.Second})
+	err := client.Poll(context.Background(), srv.URL, func(*http.Response) (string, bool, error) {
+		return "", false, nil
+	})
+	if !errors.Is(err, longpoll.ErrMaxRetriesExceeded) {
+		t.Errorf("Poll error = %v, want ErrMaxRetriesExceeded", err)
+	}
+}
+
+func TestExpand(t *testing.T) {
+	got := expand(`{"offset":{offset},"ok":true,"missing":"{cursor}"}`, map[string][]string{"offset": {"42"}})
+	if want := `{"offset":42,"ok":true,"missing":"{cursor}"}`; got != want {
+		t.Errorf("expand = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,102 @@
+package longpoll
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// PollSpec describes one poll loop run by PollMany.
+type PollSpec struct {
+	// Name identifies the loop in errors. If set, the loop is started with
+	// PollNamed, so Stop and Status work on it and names must be unique.
+	// Default: URL
+	Name string
+
+	// URL is the initial URL to poll.
+	URL string
+
+	// Handler processes the responses of this loop.
+	Handler ResponseHandler
+}
+
+// PollError reports why one of the loops started by PollMany stopped.
+type PollError struct {
+	// Name is the PollSpec name of the loop.
+	Name string
+
+	// Err is the error returned by the loop.
+	Err error
+}
+
+// Error implements the error interface
+func (e *PollError) Error() string {
+	return fmt.Sprintf("poll %s: %v", e.Name, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *PollError) Unwrap() error { return e.Err }
+
+// PollMany runs one poll loop per spec in the background, each with its own
+// handler and all sharing the client's configuration. At most
+// MaxConcurrentPolls loops run at the same time; the remaining ones start as
+// running loops finish.
+//
+// A loop that stops with an error is reported on the returned channel as a
+// *PollError naming the loop; loops that stop because their handler returned
+// shouldContinue=false are not reported. The loops are independent: one
+// failing does not stop the others. The channel is buffered for every spec,
+// so loops never block on it, and it is closed once all loops have ended.
+//
+//	errs := client.PollMany(ctx, []longpoll.PollSpec{
+//		{Name: "orders", URL: ordersURL, Handler: handleOrders},
+//		{Name: "users", URL: usersURL, Handler: handleUsers},
+//	})
+//	for err := range errs {
+//		log.Printf("loop stopped: %v", err)
+//	}
+func (c *Client) PollMany(ctx context.Context, specs []PollSpec) <-chan error {
+	errs := make(chan error, len(specs))
+
+	var sem chan struct{}
+	if c.config.MaxConcurrentPolls > 0 {
+		sem = make(chan struct{}, c.config.MaxConcurrentPolls)
+	}
+
+	var wg sync.WaitGroup
+	for _, spec := range specs {
+		name := spec.Name
+		if name == "" {
+			name = spec.URL
+		}
+
+		wg.Go(func() {
+			if sem != nil {
+				select {
+				case sem <- struct{}{}:
+					defer func() { <-sem }()
+				case <-ctx.Done():
+					errs <- &PollError{Name: name, Err: ctx.Err()}
+					return
+				}
+			}
+
+			var err error
+			if spec.Name != "" {
+				err = c.PollNamed(ctx, spec.Name, spec.URL, spec.Handler)
+			} else {
+				err = c.Poll(ctx, spec.URL, spec.Handler)
+			}
+			if err != nil {
+				errs <- &PollError{Name: name, Err: err}
+			}
+		})
+	}
+
+	go func() {
+		wg.Wait()
+		close(errs)
+	}()
+
+	return errs
+}
@@ -0,0 +1,134 @@
+package longpoll
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClient_PollMany(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/broken" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewWithConfig(Config{PollTimeout: time.Second, RetryDelay: time.Millisecond, MaxRetries: 1})
+
+	var okCalls atomic.Int32
+	errHandler := errors.New("boom")
+	errs := client.PollMany(context.Background(), []PollSpec{
+		{Name: "ok", URL: server.URL + "/ok", Handler: func(*http.Response) (string, bool, error) {
+			return "", okCalls.Add(1) < 3, nil
+		}},
+		{Name: "handler", URL: server.URL + "/handler", Handler: func(*http.Response) (string, bool, error) {
+			return "", false, errHandler
+		}},
+		{URL: server.URL + "/broken", Handler: func(*http.Response) (string, bool, error) {
+			return "", true, nil
+		}},
+	})
+
+	got := map[string]error{}
+	for err := range errs {
+		var pollErr *PollError
+		if !errors.As(err, &pollErr) {
+			t.Fatalf("expected *PollError, got %T", err)
+		}
+		got[pollErr.Name] = pollErr.Err
+	}
+
+	if len(got) != 2 {
+		t.Fatalf("expected 2 failed loops, got %v", got)
+	}
+	if !errors.Is(got["handler"], errHandler) {
+		t.Errorf("handler loop error = %v", got["handler"])
+	}
+	var statusErr *StatusError
+	if !errors.As(got[server.URL+"/broken"], &statusErr) || statusErr.StatusCode != http.StatusInternalServerError {
+		t.Errorf("broken loop error = %v", got[server.URL+"/broken"])
+	}
+	if okCalls.Load() != 3 {
+		t.Errorf("ok handler called %d times, want 3", okCalls.Load())
+	}
+	if n := client.ActiveCount(); n != 0 {
+		t.Errorf("ActiveCount = %d after all loops ended", n)
+	}
+}
+
+func TestClient_PollMany_MaxConcurrentPolls(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewWithConfig(Config{PollTimeout: time.Second, MaxConcurrentPolls: 2})
+
+	var running, peak atomic.Int32
+	handler := func(*http.Response) (string, bool, error) {
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		running.Add(-1)
+		return "", false, nil
+	}
+
+	specs := make([]PollSpec, 5)
+	for i := range specs {
+		specs[i] = PollSpec{URL: server.URL, Handler: handler}
+	}
+	for err := range client.PollMany(context.Background(), specs) {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if p := peak.Load(); p != 2 {
+		t.Errorf("peak concurrency = %d, want 2", p)
+	}
+}
+
+func TestClient_PollMany_CancelWhileQueued(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewWithConfig(Config{PollTimeout: time.Second, MaxConcurrentPolls: 1})
+	ctx, cancel := context.WithCancel(context.Background())
+
+	started := make(chan struct{})
+	handler := func(resp *http.Response) (string, bool, error) {
+		close(started)
+		<-resp.Request.Context().Done()
+		return "", false, resp.Request.Context().Err()
+	}
+
+	errs := client.PollMany(ctx, []PollSpec{
+		{Name: "first", URL: server.URL, Handler: handler},
+		{Name: "second", URL: server.URL, Handler: handler},
+	})
+	<-started
+	cancel()
+
+	var names []string
+	for err := range errs {
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected context.Canceled, got %v", err)
+		}
+		names = append(names, err.(*PollError).Name)
+	}
+	if len(names) != 2 {
+		t.Errorf("expected both loops to report cancellation, got %v", names)
+	}
+}
@@ -0,0 +1,58 @@
+package longpoll
+
+import (
+	"io"
+	"net/url"
+	"sync/atomic"
+	"time"
+)
+
+// Metrics receives statistics from poll loops, for example to export them to
+// Prometheus. Every method gets the poll name: the name given to PollNamed
+// or, for other polls, the host of the polled URL so that tokens embedded in
+// paths or queries never become label values. Implementations must be safe
+// for concurrent use.
+type Metrics interface {
+	// ObserveRequest is called after every request. statusCode is 0 when no
+	// response was received; err is nil for 2xx responses.
+	ObserveRequest(poll string, statusCode int, latency time.Duration, err error)
+
+	// ObserveRetry is called before each retry with the failure kind
+	// ("connect", "status" or "transport") and the delay before the retry.
+	ObserveRetry(poll, kind string, delay time.Duration)
+
+	// ObserveBytes is called with the number of response body bytes received.
+	ObserveBytes(poll string, n int64)
+
+	// SetFailureStreak reports the number of consecutive failed requests.
+	// It is reset to 0 by the first successful request.
+	SetFailureStreak(poll string, n int)
+}
+
+type nopMetrics struct{}
+
+func (nopMetrics) ObserveRequest(string, int, time.Duration, error) {}
+func (nopMetrics) ObserveRetry(string, string, time.Duration)       {}
+func (nopMetrics) ObserveBytes(string, int64)                       {}
+func (nopMetrics) SetFailureStreak(string, int)                     {}
+
+// pollName returns the metrics name of a poll loop for rawURL.
+func pollName(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return "unknown"
+	}
+	return u.Host
+}
+
+// countingBody counts the bytes read from a response body.
+type countingBody struct {
+	io.ReadCloser
+	n atomic.Int64
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n.Add(int64(n))
+	return n, err
+}
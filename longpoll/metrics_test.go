@@ -0,0 +1,100 @@
+package longpoll
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recordingMetrics struct {
+	mu       sync.Mutex
+	polls    map[string]bool
+	statuses []int
+	retries  []string
+	bytes    int64
+	streaks  []int
+}
+
+func (m *recordingMetrics) ObserveRequest(poll string, statusCode int, latency time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.polls[poll] = true
+	m.statuses = append(m.statuses, statusCode)
+}
+
+func (m *recordingMetrics) ObserveRetry(poll, kind string, delay time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.retries = append(m.retries, kind)
+}
+
+func (m *recordingMetrics) ObserveBytes(poll string, n int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.bytes += n
+}
+
+func (m *recordingMetrics) SetFailureStreak(poll string, n int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.streaks = append(m.streaks, n)
+}
+
+func TestClient_Poll_Metrics(t *testing.T) {
+	var mu sync.Mutex
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		attempts++
+		n := attempts
+		mu.Unlock()
+		if n <= 2 {
+			w.WriteHeader(http.StatusInternalServerError)
+			io.WriteString(w, "err")
+			return
+		}
+		io.WriteString(w, "hello")
+	}))
+	defer server.Close()
+
+	metrics := &recordingMetrics{polls: make(map[string]bool)}
+	client := NewWithConfig(Config{
+		PollTimeout: time.Second,
+		RetryDelay:  time.Millisecond,
+		MaxRetries:  -1,
+	}).WithMetrics(metrics)
+
+	err := client.Poll(context.Background(), server.URL+"/bot-secret/updates?token=x", func(resp *http.Response) (string, bool, error) {
+		io.ReadAll(resp.Body)
+		return "", false, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	metrics.mu.Lock()
+	defer metrics.mu.Unlock()
+
+	if len(metrics.statuses) != 3 || metrics.statuses[0] != 500 || metrics.statuses[2] != 200 {
+		t.Errorf("statuses = %v, want [500 500 200]", metrics.statuses)
+	}
+	if strings.Join(metrics.retries, ",") != "status,status" {
+		t.Errorf("retries = %v", metrics.retries)
+	}
+	if metrics.bytes != int64(len("err")*2+len("hello")) {
+		t.Errorf("bytes = %d, want 11", metrics.bytes)
+	}
+	if len(metrics.streaks) != 3 || metrics.streaks[0] != 1 || metrics.streaks[1] != 2 || metrics.streaks[2] != 0 {
+		t.Errorf("streaks = %v, want [1 2 0]", metrics.streaks)
+	}
+	for poll := range metrics.polls {
+		if strings.Contains(poll, "secret") || strings.Contains(poll, "token") {
+			t.Errorf("poll name %q leaks the URL path or query", poll)
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package longpoll
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// PollStatus is a snapshot of a running poll.
+type PollStatus struct {
+	// Name is the name the poll was started with, empty for unnamed polls.
+	Name string
+
+	// URL is the URL of the current or next request.
+	URL string
+
+	// StartedAt is when the poll was started.
+	StartedAt time.Time
+
+	// LastSuccess is when the last successful response was received.
+	// It is zero if no request has succeeded yet.
+	LastSuccess time.Time
+
+	// Retries is the number of consecutive failed requests since the last
+	// successful one.
+	Retries int
+
+	// LastError is the error of the most recent failed request.
+	// It is nil after a successful request.
+	LastError error
+
+	// Responses is the total number of responses received, with any status.
+	Responses int
+
+	// Paused reports whether the poll is paused with Pause.
+	Paused bool
+}
+
+// PollNamed is like Poll but registers the poll under name, so it can be
+// stopped individually with Stop and inspected with Status while it runs.
+// Named polls report Metrics under their name instead of the URL host.
+// It returns ErrPollExists if a poll with the same name is already running.
+func (c *Client) PollNamed(ctx context.Context, name, url string, handler ResponseHandler) error {
+	pc, done, err := c.track(ctx, name, url)
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	return pc.result(c.pollLoop(pc, url, handler))
+}
+
+// Stop stops the named poll. It reports whether a poll with that name was
+// running. Like StopAll, it does not wait for the poll to end.
+func (c *Client) Stop(name string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	pc, ok := c.named[name]
+	if ok {
+		pc.cancel(ErrStoppedByClient)
+	}
+	return ok
+}
+
+// Pause suspends the named poll: once the request in flight, if any, has
+// been handled, no further request is made until Resume is called. The
+// poll keeps its state, including the current URL, and still ends when it
+// is stopped or its context is cancelled. Pause reports whether a poll with
+// that name is running.
+func (c *Client) Pause(name string) bool {
+	c.mu.Lock()
+	pc, ok := c.named[name]
+	c.mu.Unlock()
+	if !ok {
+		return false
+	}
+
+	pc.mu.Lock()
+	if pc.resumed == nil {
+		pc.resumed = make(chan struct{})
+	}
+	pc.mu.Unlock()
+	return true
+}
+
+// Resume resumes the named poll after Pause. It reports whether a poll with
+// that name is running.
+func (c *Client) Resume(name string) bool {
+	c.mu.Lock()
+	pc, ok := c.named[name]
+	c.mu.Unlock()
+	if !ok {
+		return false
+	}
+
+	pc.mu.Lock()
+	if pc.resumed != nil {
+		close(pc.resumed)
+		pc.resumed = nil
+	}
+	pc.mu.Unlock()
+	return true
+}
+
+// Status returns a snapshot of the named poll and reports whether a poll with
+// that name is running.
+func (c *Client) Status(name string) (PollStatus, bool) {
+	c.mu.Lock()
+	pc, ok := c.named[name]
+	c.mu.Unlock()
+	if !ok {
+		return PollStatus{}, false
+	}
+
+	return pc.status(), true
+}
+
+// ActivePolls returns a snapshot of every running poll, named or not, in
+// the order they were started. It is meant for health and diagnostics
+// endpoints.
+func (c *Client) ActivePolls() []PollStatus {
+	c.mu.Lock()
+	polls := make([]*pollContext, 0, len(c.active))
+	for pc := range c.active {
+		polls = append(polls, pc)
+	}
+	c.mu.Unlock()
+
+	statuses := make([]PollStatus, 0, len(polls))
+	for _, pc := range polls {
+		statuses = append(statuses, pc.status())
+	}
+	slices.SortStableFunc(statuses, func(a, b PollStatus) int {
+		if c := a.StartedAt.Compare(b.StartedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	return statuses
+}
+
+// status returns a snapshot of the poll.
+func (pc *pollContext) status() PollStatus {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	return PollStatus{
+		Name:        pc.name,
+		URL:         pc.url,
+		StartedAt:   pc.started,
+		LastSuccess: pc.lastSuccess,
+		Retries:     pc.retries,
+		LastError:   pc.lastError,
+		Responses:   pc.responses,
+		Paused:      pc.resumed != nil,
+	}
+}
+
+// waitResumed blocks while the poll is paused.
+func (pc *pollContext) waitResumed() error {
+	pc.mu.Lock()
+	resumed := pc.resumed
+	pc.mu.Unlock()
+	if resumed == nil {
+		return nil
+	}
+
+	select {
+	case <-resumed:
+		return nil
+	case <-pc.ctx.Done():
+		return pc.ctx.Err()
+	}
+}
+
+// setURL records the URL of the next request.
+func (pc *pollContext) setURL(url string) {
+	pc.mu.Lock()
+	pc.url = url
+	pc.mu.Unlock()
+}
+
+// recordSuccess records a successful request.
+func (pc *pollContext) recordSuccess() {
+	pc.mu.Lock()
+	pc.lastSuccess = time.Now()
+	pc.retries = 0
+	pc.lastError = nil
+	pc.mu.Unlock()
+}
+
+// recordResponse counts a response received from the server.
+func (pc *pollContext) recordResponse() {
+	pc.mu.Lock()
+	pc.responses++
+	pc.mu.Unlock()
+}
+
+// recordFailure records the streak of failed requests ending with err.
+func (pc *pollContext) recordFailure(streak int, err error) {
+	pc.mu.Lock()
+	pc.retries = streak
+	pc.lastError = err
+	pc.mu.Unlock()
+}
+
+// result translates the error a loop ended with: a cancellation caused by
+// Stop, StopAll or Shutdown is reported as ErrStoppedByClient.
+func (pc *pollContext) result(err error) error {
+	if errors.Is(err, context.Canceled) && errors.Is(context.Cause(pc.ctx), ErrStoppedByClient) {
+		return fmt.Errorf("%w: %w", ErrStoppedByClient, err)
+	}
+	return err
+}
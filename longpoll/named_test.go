@@ -0,0 +1,251 @@
+package longpoll
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClient_PollNamed_StatusAndStop(t *testing.T) {
+	var fail atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewWithConfig(Config{PollTimeout: time.Second, RetryDelay: 10 * time.Millisecond, MaxRetries: -1})
+
+	firstDone := make(chan struct{})
+	var calls atomic.Int32
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- client.PollNamed(context.Background(), "orders", server.URL+"/v1", func(resp *http.Response) (string, bool, error) {
+			if calls.Add(1) == 1 {
+				fail.Store(true)
+				defer close(firstDone)
+			}
+			return server.URL + "/v2", true, nil
+		})
+	}()
+	<-firstDone
+
+	var st PollStatus
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		var ok bool
+		st, ok = client.Status("orders")
+		if !ok {
+			t.Fatal("Status: poll not found")
+		}
+		if st.Retries >= 2 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if st.Name != "orders" || st.URL != server.URL+"/v2" {
+		t.Errorf("status = %+v", st)
+	}
+	if st.LastSuccess.IsZero() || st.StartedAt.After(st.LastSuccess) {
+		t.Errorf("LastSuccess = %v, StartedAt = %v", st.LastSuccess, st.StartedAt)
+	}
+	if st.Retries < 2 {
+		t.Errorf("Retries = %d, want >= 2", st.Retries)
+	}
+	var statusErr *StatusError
+	if !errors.As(st.LastError, &statusErr) || statusErr.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("LastError = %v", st.LastError)
+	}
+
+	if !client.Stop("orders") {
+		t.Error("Stop returned false for a running poll")
+	}
+	if err := <-errCh; !errors.Is(err, context.Canceled) {
+		t.Errorf("PollNamed = %v, want context.Canceled", err)
+	}
+	if _, ok := client.Status("orders"); ok {
+		t.Error("Status found a stopped poll")
+	}
+	if client.Stop("orders") {
+		t.Error("Stop returned true for a stopped poll")
+	}
+}
+
+func TestClient_PollNamed_Duplicate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewWithConfig(Config{PollTimeout: time.Second})
+
+	entered := make(chan struct{})
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- client.PollNamed(context.Background(), "a", server.URL, func(resp *http.Response) (string, bool, error) {
+			close(entered)
+			<-resp.Request.Context().Done()
+			return "", false, nil
+		})
+	}()
+	<-entered
+
+	err := client.PollNamed(context.Background(), "a", server.URL, func(*http.Response) (string, bool, error) {
+		t.Error("duplicate poll ran")
+		return "", false, nil
+	})
+	if !errors.Is(err, ErrPollExists) {
+		t.Errorf("duplicate PollNamed = %v, want ErrPollExists", err)
+	}
+
+	client.Stop("a")
+	if err := <-errCh; err != nil {
+		t.Errorf("PollNamed = %v", err)
+	}
+}
+
+func TestClient_PollNamed_MetricsName(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	m := &recordingMetrics{polls: make(map[string]bool)}
+	client := NewWithConfig(Config{PollTimeout: time.Second, Metrics: m})
+	client.PollNamed(context.Background(), "orders", server.URL, func(*http.Response) (string, bool, error) {
+		return "", false, nil
+	})
+
+	if !m.polls["orders"] || len(m.polls) != 1 {
+		t.Errorf("metrics polls = %v, want only %q", m.polls, "orders")
+	}
+}
+
+func TestClient_PauseResume(t *testing.T) {
+	var requests atomic.Int32
+	var lastPath atomic.Value
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		lastPath.Store(r.URL.RawQuery)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewWithConfig(Config{PollTimeout: time.Second})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var cursor atomic.Int32
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- client.PollNamed(ctx, "feed", server.URL+"?cursor=0", func(resp *http.Response) (string, bool, error) {
+			time.Sleep(time.Millisecond)
+			return fmt.Sprintf("%s?cursor=%d", server.URL, cursor.Add(1)), true, nil
+		})
+	}()
+
+	waitFor(t, func() bool { return requests.Load() >= 3 })
+	if !client.Pause("feed") {
+		t.Fatal("Pause returned false")
+	}
+	time.Sleep(10 * time.Millisecond) // let the request in flight finish
+	paused := requests.Load()
+	time.Sleep(50 * time.Millisecond)
+	if got := requests.Load(); got != paused {
+		t.Errorf("requests while paused: %d -> %d", paused, got)
+	}
+	if st, _ := client.Status("feed"); !st.Paused {
+		t.Error("Status.Paused = false while paused")
+	}
+
+	if !client.Resume("feed") {
+		t.Fatal("Resume returned false")
+	}
+	waitFor(t, func() bool { return requests.Load() > paused+2 })
+	if st, _ := client.Status("feed"); st.Paused {
+		t.Error("Status.Paused = true after Resume")
+	}
+	// the cursor survived the pause
+	if q := lastPath.Load().(string); q == "cursor=0" {
+		t.Errorf("last query = %q, want an advanced cursor", q)
+	}
+
+	if client.Pause("missing") || client.Resume("missing") {
+		t.Error("Pause/Resume of unknown poll returned true")
+	}
+
+	// a paused poll still stops when its context is cancelled
+	client.Pause("feed")
+	cancel()
+	select {
+	case err := <-errCh:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("PollNamed = %v, want context.Canceled", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("paused poll did not stop on cancel")
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestClient_ActivePolls(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewWithConfig(Config{PollTimeout: time.Second, RetryDelay: time.Millisecond, MaxRetries: -1})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	handler := func(resp *http.Response) (string, bool, error) {
+		time.Sleep(time.Millisecond)
+		return "", true, nil
+	}
+	go client.Poll(ctx, server.URL+"/ok", handler)
+	waitFor(t, func() bool { return client.ActiveCount() == 1 })
+	go client.PollNamed(ctx, "failing", server.URL+"/fail", handler)
+
+	waitFor(t, func() bool {
+		polls := client.ActivePolls()
+		return len(polls) == 2 && polls[0].Responses >= 2 && polls[1].Retries >= 2
+	})
+
+	polls := client.ActivePolls()
+	ok, failing := polls[0], polls[1]
+	if ok.Name != "" || ok.URL != server.URL+"/ok" || ok.LastSuccess.IsZero() || ok.Retries != 0 {
+		t.Errorf("healthy poll = %+v", ok)
+	}
+	if failing.Name != "failing" || failing.LastError == nil || !failing.LastSuccess.IsZero() || failing.Responses < 2 {
+		t.Errorf("failing poll = %+v", failing)
+	}
+	if ok.StartedAt.After(failing.StartedAt) {
+		t.Error("ActivePolls not ordered by start time")
+	}
+
+	cancel()
+	waitFor(t, func() bool { return len(client.ActivePolls()) == 0 })
+}
@@ -0,0 +1,59 @@
+package longpoll
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	neturl "net/url"
+)
+
+// QueryHandler is a handler that changes query parameters of the next
+// request instead of returning a full URL. Keys in next replace the same
+// keys of the current URL; a key mapped to an empty slice removes it. A nil
+// or empty next reuses the current URL.
+type QueryHandler func(*http.Response) (next neturl.Values, shouldContinue bool, err error)
+
+// PollQuery is like Poll but the handler returns query parameter changes,
+// which are merged into the current URL with MergeQuery. It saves handlers
+// of offset-style APIs from building URL strings by hand:
+//
+//	err := client.PollQuery(ctx, "https://api.telegram.org/bot<token>/getUpdates?timeout=50",
+//		func(resp *http.Response) (url.Values, bool, error) {
+//			...
+//			return url.Values{"offset": {strconv.Itoa(lastID + 1)}}, true, nil
+//		})
+func (c *Client) PollQuery(ctx context.Context, url string, handler QueryHandler) error {
+	current := url
+	return c.Poll(ctx, url, func(resp *http.Response) (string, bool, error) {
+		next, shouldContinue, err := handler(resp)
+		if err != nil || len(next) == 0 {
+			return "", shouldContinue, err
+		}
+		merged, err := MergeQuery(current, next)
+		if err != nil {
+			return "", false, err
+		}
+		current = merged
+		return merged, shouldContinue, nil
+	})
+}
+
+// MergeQuery returns rawURL with the query parameters in delta applied:
+// each key replaces the same key of rawURL, and a key mapped to an empty
+// slice is removed. Other parameters are kept.
+func MergeQuery(rawURL string, delta neturl.Values) (string, error) {
+	u, err := neturl.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("merge query: %w", err)
+	}
+	q := u.Query()
+	for k, vs := range delta {
+		if len(vs) == 0 {
+			q.Del(k)
+			continue
+		}
+		q[k] = vs
+	}
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
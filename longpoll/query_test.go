@@ -0,0 +1,73 @@
+package longpoll
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestMergeQuery(t *testing.T) {
+	tests := []struct {
+		name  string
+		url   string
+		delta url.Values
+		want  string
+	}{
+		{"set new key", "https://x.test/u?timeout=50", url.Values{"offset": {"3"}}, "https://x.test/u?offset=3&timeout=50"},
+		{"replace key", "https://x.test/u?offset=1&timeout=50", url.Values{"offset": {"2"}}, "https://x.test/u?offset=2&timeout=50"},
+		{"remove key", "https://x.test/u?offset=1&timeout=50", url.Values{"offset": nil}, "https://x.test/u?timeout=50"},
+		{"multiple values", "https://x.test/u", url.Values{"type": {"a", "b"}}, "https://x.test/u?type=a&type=b"},
+		{"escaping", "https://x.test/u", url.Values{"q": {"a b&c"}}, "https://x.test/u?q=a+b%26c"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MergeQuery(tt.url, tt.delta)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("MergeQuery = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := MergeQuery("://bad", url.Values{"a": {"1"}}); err == nil {
+		t.Error("MergeQuery accepted an invalid URL")
+	}
+}
+
+func TestClient_PollQuery(t *testing.T) {
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.RawQuery)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewWithConfig(Config{PollTimeout: time.Second})
+	calls := 0
+	err := client.PollQuery(context.Background(), server.URL+"?timeout=50", func(*http.Response) (url.Values, bool, error) {
+		calls++
+		if calls == 2 {
+			return nil, true, nil // keep the URL
+		}
+		return url.Values{"offset": {strconv.Itoa(calls * 10)}}, calls < 3, nil
+	})
+	if err != nil {
+		t.Fatalf("PollQuery: %v", err)
+	}
+
+	want := []string{"timeout=50", "offset=10&timeout=50", "offset=10&timeout=50"}
+	if len(queries) != len(want) {
+		t.Fatalf("queries = %v, want %v", queries, want)
+	}
+	for i := range want {
+		if queries[i] != want[i] {
+			t.Errorf("query %d = %q, want %q", i, queries[i], want[i])
+		}
+	}
+}
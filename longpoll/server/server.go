@@ -0,0 +1,297 @@
+// Package server provides the server half of long polling: an in-process
+// publish/subscribe Hub and an http.Handler that holds client requests open
+// until events arrive or a timeout expires.
+//
+// Every topic keeps a bounded backlog of events with increasing IDs. A
+// client passes the ID of the last event it has seen as its cursor and gets
+// every later event, so events published between two polls are not lost:
+//
+//	GET /events?topic=orders&cursor=41&timeout=30
+//
+//	{"cursor":43,"events":[{"id":42,...},{"id":43,...}]}
+//
+// The response cursor is the one to send with the next request. Without a
+// cursor, only events published after the request arrived are returned.
+// When the wait times out the response has no events and the cursor is
+// unchanged, so the client simply polls again. The topic may also come from
+// a {topic} path wildcard:
+//
+//	hub := server.New()
+//	mux.Handle("GET /events/{topic}", hub)
+//
+//	hub.Publish("orders", order)
+//
+// The longpoll client consumes this format directly: the handler decodes
+// the response and returns a URL carrying the new cursor.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/en9inerd/go-pkgs/httperrors"
+	"github.com/en9inerd/go-pkgs/httpjson"
+)
+
+// ErrHubClosed is returned by Publish after Close.
+var ErrHubClosed = errors.New("longpoll/server: hub closed")
+
+// Config configures a Hub.
+type Config struct {
+	// Timeout is how long a request is held when it does not ask for a
+	// timeout. Default: 30 seconds
+	Timeout time.Duration
+
+	// MaxTimeout caps the timeout a client may request with the timeout
+	// query parameter. Default: 60 seconds
+	MaxTimeout time.Duration
+
+	// BufferSize is the number of recent events kept per topic for clients
+	// that are between polls. Default: 1000
+	BufferSize int
+
+	// MaxBatch is the maximum number of events returned by one request.
+	// Default: 100
+	MaxBatch int
+}
+
+// Event is a published event.
+type Event struct {
+	// ID is the position of the event in its topic, starting at 1.
+	ID uint64 `json:"id"`
+
+	// Topic is the topic the event was published to.
+	Topic string `json:"topic"`
+
+	// Time is when the event was published.
+	Time time.Time `json:"time"`
+
+	// Data is the JSON encoded payload.
+	Data json.RawMessage `json:"data"`
+}
+
+// Response is the body returned by the Hub handler.
+type Response struct {
+	// Cursor is the cursor to send with the next request.
+	Cursor uint64 `json:"cursor"`
+
+	// Events are the events after the request cursor, oldest first.
+	Events []Event `json:"events"`
+
+	// Missed is true when events after the request cursor had already been
+	// dropped from the backlog.
+	Missed bool `json:"missed,omitempty"`
+}
+
+// Hub is an in-process publish/subscribe hub for long polling clients.
+// It is safe for concurrent use.
+type Hub struct {
+	cfg Config
+
+	mu     sync.Mutex
+	topics map[string]*topic
+	closed bool
+	done   chan struct{}
+}
+
+// topic holds the backlog of one topic.
+type topic struct {
+	events []Event // oldest first, at most BufferSize
+	last   uint64
+	notify chan struct{} // closed and replaced on every publish
+
+	// waiters counts the requests waiting on the topic. A topic without
+	// waiters that was never published to is removed, so requests for
+	// arbitrary topic names do not grow the hub.
+	waiters int
+}
+
+// New creates a Hub with the default configuration.
+func New() *Hub {
+	return NewWithConfig(Config{})
+}
+
+// NewWithConfig creates a Hub with the given configuration.
+func NewWithConfig(cfg Config) *Hub {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 30 * time.Second
+	}
+	if cfg.MaxTimeout <= 0 {
+		cfg.MaxTimeout = 60 * time.Second
+	}
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = 1000
+	}
+	if cfg.MaxBatch <= 0 {
+		cfg.MaxBatch = 100
+	}
+	return &Hub{
+		cfg:    cfg,
+		topics: make(map[string]*topic),
+		done:   make(chan struct{}),
+	}
+}
+
+// Publish encodes data as JSON and appends it to topic, waking up every
+// request waiting on it.
+func (h *Hub) Publish(topicName string, data any) (Event, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return Event{}, err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return Event{}, ErrHubClosed
+	}
+
+	t := h.topic(topicName)
+	t.last++
+	ev := Event{ID: t.last, Topic: topicName, Time: time.Now(), Data: raw}
+	if len(t.events) == h.cfg.BufferSize {
+		copy(t.events, t.events[1:])
+		t.events = t.events[:len(t.events)-1]
+	}
+	t.events = append(t.events, ev)
+
+	close(t.notify)
+	t.notify = make(chan struct{})
+	return ev, nil
+}
+
+// Cursor returns the ID of the last event published to topic, or 0.
+func (h *Hub) Cursor(topicName string) uint64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if t, ok := h.topics[topicName]; ok {
+		return t.last
+	}
+	return 0
+}
+
+// Wait returns the events of topic after cursor, waiting until at least one
+// is published, ctx is done or the hub is closed. It never returns an error;
+// on timeout the response has no events and the cursor is unchanged.
+func (h *Hub) Wait(ctx context.Context, topicName string, cursor uint64) Response {
+	h.mu.Lock()
+	t := h.topic(topicName)
+	t.waiters++
+	h.mu.Unlock()
+	defer func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if t.waiters--; t.waiters == 0 && t.last == 0 {
+			delete(h.topics, topicName)
+		}
+	}()
+
+	for {
+		h.mu.Lock()
+		if cursor > t.last {
+			cursor = t.last // the client saw a previous incarnation of the hub
+		}
+		if cursor < t.last {
+			resp := h.collect(t, cursor)
+			h.mu.Unlock()
+			return resp
+		}
+		notify, closed := t.notify, h.closed
+		h.mu.Unlock()
+
+		if closed {
+			return Response{Cursor: cursor, Events: []Event{}}
+		}
+		select {
+		case <-notify:
+		case <-ctx.Done():
+			return Response{Cursor: cursor, Events: []Event{}}
+		case <-h.done:
+		}
+	}
+}
+
+// Close wakes up every waiting request and makes Publish fail. Requests
+// received afterwards return immediately.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.closed {
+		h.closed = true
+		close(h.done)
+	}
+}
+
+// ServeHTTP holds the request until events arrive on the requested topic or
+// the timeout expires, and writes a Response. The topic is taken from the
+// {topic} path wildcard or the topic query parameter, the cursor from the
+// cursor query parameter and the timeout, in seconds, from the timeout query
+// parameter.
+func (h *Hub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	topicName := r.PathValue("topic")
+	if topicName == "" {
+		topicName = r.URL.Query().Get("topic")
+	}
+	if topicName == "" {
+		httperrors.WriteError(w, httperrors.NewError(http.StatusBadRequest, "missing topic"))
+		return
+	}
+
+	q := r.URL.Query()
+	cursor := h.Cursor(topicName)
+	if v := q.Get("cursor"); v != "" {
+		n, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			httperrors.WriteError(w, httperrors.NewErrorWithDetails(http.StatusBadRequest, "invalid cursor", err.Error()))
+			return
+		}
+		cursor = n
+	}
+
+	timeout := h.cfg.Timeout
+	if v := q.Get("timeout"); v != "" {
+		secs, err := strconv.Atoi(v)
+		if err != nil || secs < 0 {
+			httperrors.WriteError(w, httperrors.NewError(http.StatusBadRequest, "invalid timeout"))
+			return
+		}
+		timeout = time.Duration(secs) * time.Second
+	}
+	timeout = min(timeout, h.cfg.MaxTimeout)
+
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	w.Header().Set("Cache-Control", "no-store")
+	httpjson.WriteJSON(w, h.Wait(ctx, topicName, cursor))
+}
+
+// topic returns the named topic, creating it. h.mu must be held.
+func (h *Hub) topic(name string) *topic {
+	t, ok := h.topics[name]
+	if !ok {
+		t = &topic{notify: make(chan struct{})}
+		h.topics[name] = t
+	}
+	return t
+}
+
+// collect returns up to MaxBatch events of t after cursor. h.mu must be held.
+func (h *Hub) collect(t *topic, cursor uint64) Response {
+	resp := Response{Events: []Event{}}
+	first := t.events[0].ID
+	if cursor+1 < first {
+		resp.Missed = true
+		cursor = first - 1
+	}
+	start := int(cursor + 1 - first)
+	end := min(start+h.cfg.MaxBatch, len(t.events))
+	resp.Events = append(resp.Events, t.events[start:end]...)
+	resp.Cursor = resp.Events[len(resp.Events)-1].ID
+	return resp
+}
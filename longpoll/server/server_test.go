@@ -0,0 +1,169 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/en9inerd/go-pkgs/longpoll"
+)
+
+func TestHub_WaitReturnsBacklog(t *testing.T) {
+	hub := New()
+	for i := range 3 {
+		if _, err := hub.Publish("orders", i); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	resp := hub.Wait(context.Background(), "orders", 1)
+	if resp.Cursor != 3 || len(resp.Events) != 2 || resp.Missed {
+		t.Fatalf("resp = %+v, want events 2..3", resp)
+	}
+	if resp.Events[0].ID != 2 || string(resp.Events[0].Data) != "1" || resp.Events[0].Topic != "orders" {
+		t.Errorf("first event = %+v", resp.Events[0])
+	}
+}
+
+func TestHub_WaitBlocksUntilPublish(t *testing.T) {
+	hub := New()
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		hub.Publish("orders", "new")
+	}()
+
+	start := time.Now()
+	resp := hub.Wait(context.Background(), "orders", 0)
+	if len(resp.Events) != 1 || resp.Cursor != 1 {
+		t.Fatalf("resp = %+v, want one event", resp)
+	}
+	if time.Since(start) < 10*time.Millisecond {
+		t.Error("Wait returned before the event was published")
+	}
+}
+
+func TestHub_WaitTimeout(t *testing.T) {
+	hub := New()
+	hub.Publish("orders", "old")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	resp := hub.Wait(ctx, "orders", 1)
+	if resp.Cursor != 1 || len(resp.Events) != 0 {
+		t.Errorf("resp = %+v, want no events with cursor 1", resp)
+	}
+}
+
+func TestHub_UnknownTopicsAreNotKept(t *testing.T) {
+	hub := New()
+	hub.Publish("orders", 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for i := range 100 {
+		hub.Wait(ctx, fmt.Sprintf("random-%d", i), 0)
+	}
+	if n := len(hub.topics); n != 1 {
+		t.Errorf("hub has %d topics, want 1", n)
+	}
+}
+
+func TestHub_BufferOverflowAndBatch(t *testing.T) {
+	hub := NewWithConfig(Config{BufferSize: 3, MaxBatch: 2})
+	for i := range 5 {
+		hub.Publish("orders", i)
+	}
+
+	resp := hub.Wait(context.Background(), "orders", 0)
+	if !resp.Missed {
+		t.Error("Missed = false, want true after dropped events")
+	}
+	if len(resp.Events) != 2 || resp.Events[0].ID != 3 || resp.Cursor != 4 {
+		t.Errorf("resp = %+v, want events 3..4", resp)
+	}
+}
+
+func TestHub_Close(t *testing.T) {
+	hub := New()
+	done := make(chan Response)
+	go func() { done <- hub.Wait(context.Background(), "orders", 0) }()
+
+	time.Sleep(10 * time.Millisecond)
+	hub.Close()
+	select {
+	case resp := <-done:
+		if len(resp.Events) != 0 {
+			t.Errorf("resp = %+v, want no events", resp)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Wait did not return after Close")
+	}
+	if _, err := hub.Publish("orders", 1); err != ErrHubClosed {
+		t.Errorf("Publish after Close error = %v, want ErrHubClosed", err)
+	}
+}
+
+func TestHub_ServeHTTPErrors(t *testing.T) {
+	hub := New()
+	tests := []struct {
+		name string
+		url  string
+	}{
+		{"missing topic", "/events"},
+		{"invalid cursor", "/events?topic=a&cursor=x"},
+		{"invalid timeout", "/events?topic=a&timeout=-1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			hub.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.url, nil))
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want 400", rec.Code)
+			}
+		})
+	}
+}
+
+func TestHub_WithLongpollClient(t *testing.T) {
+	hub := New()
+	mux := http.NewServeMux()
+	mux.Handle("GET /events/{topic}", hub)
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	hub.Publish("orders", map[string]int{"n": 0})
+	go func() {
+		for i := 1; i < 5; i++ {
+			time.Sleep(5 * time.Millisecond)
+			hub.Publish("orders", map[string]int{"n": i})
+		}
+	}()
+
+	client := longpoll.NewWithConfig(longpoll.Config{PollTimeout: 5 * time.Second})
+	var got []int
+	err := client.Poll(context.Background(), srv.URL+"/events/orders?cursor=0&timeout=2", func(resp *http.Response) (string, bool, error) {
+		var body Response
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+			return "", false, err
+		}
+		for _, ev := range body.Events {
+			var payload struct{ N int }
+			if err := json.Unmarshal(ev.Data, &payload); err != nil {
+				return "", false, err
+			}
+			got = append(got, payload.N)
+		}
+		next := fmt.Sprintf("%s/events/orders?cursor=%d&timeout=2", srv.URL, body.Cursor)
+		return next, len(got) < 5, nil
+	})
+	if err != nil {
+		t.Fatalf("Poll: %v", err)
+	}
+	if fmt.Sprint(got) != "[0 1 2 3 4]" {
+		t.Errorf("events = %v, want [0 1 2 3 4]", got)
+	}
+}
@@ -0,0 +1,80 @@
+package longpoll
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHMACSigner_Poll(t *testing.T) {
+	signer := &HMACSigner{
+		KeyID:   "key-1",
+		Secret:  []byte("s3cret"),
+		Headers: []string{"Content-Type"},
+		Now:     func() time.Time { return time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC) },
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		digest := sha256.Sum256(body)
+
+		if got := r.Header.Get("X-Content-SHA256"); got != hex.EncodeToString(digest[:]) {
+			t.Errorf("X-Content-SHA256 = %q", got)
+		}
+		if got := r.Header.Get("X-Signature-Date"); got != "20250102T030405Z" {
+			t.Errorf("X-Signature-Date = %q", got)
+		}
+
+		// recompute the signature from what the server received
+		signed := []string{"content-type", "host", "x-signature-date"}
+		mac := hmac.New(sha256.New, []byte("s3cret"))
+		mac.Write([]byte(signer.canonical(r, signed, digest[:])))
+		want := "HMAC-SHA256 KeyId=key-1, SignedHeaders=content-type;host;x-signature-date, Signature=" +
+			hex.EncodeToString(mac.Sum(nil))
+		if got := r.Header.Get("Authorization"); got != want {
+			t.Errorf("Authorization = %q, want %q", got, want)
+		}
+	}))
+	defer server.Close()
+
+	client := NewWithConfig(Config{
+		PollTimeout: time.Second,
+		Method:      http.MethodPost,
+		BodyBuilder: func(ctx context.Context, iteration int, last Meta) (io.Reader, string, error) {
+			return strings.NewReader("offset=10"), "", nil
+		},
+	}).WithSigner(signer)
+
+	err := client.Poll(context.Background(), server.URL+"/updates?limit=5", func(resp *http.Response) (string, bool, error) {
+		return "", false, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestClient_Poll_SignerError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request should not be sent")
+	}))
+	defer server.Close()
+
+	signErr := errors.New("no credentials")
+	client := NewWithConfig(Config{PollTimeout: time.Second, MaxRetries: 0}).
+		WithSigner(SignerFunc(func(*http.Request, []byte) error { return signErr }))
+
+	err := client.Poll(context.Background(), server.URL, func(resp *http.Response) (string, bool, error) {
+		return "", false, nil
+	})
+	if !errors.Is(err, signErr) {
+		t.Errorf("expected signer error, got %v", err)
+	}
+}
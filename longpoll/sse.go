@@ -0,0 +1,244 @@
+package longpoll
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EventHandler processes a single event. Return shouldContinue=false to stop,
+// or an error to stop with that error.
+type EventHandler func(Event) (shouldContinue bool, err error)
+
+// PollSSE connects to a Server-Sent Events endpoint and calls handler for
+// each event until the context is cancelled or the handler stops.
+//
+// The connection is kept open and parsed as a text/event-stream: data lines
+// are joined with newlines into Event.Body, and the event and id fields are
+// reported as Event.Type and Event.ID. When the stream ends it reconnects,
+// sending Last-Event-ID so the server can resume, after the delay requested
+// by the server's retry field or, if none was sent, RetryDelay/Backoff.
+// A 204 No Content response stops polling without error, as the SSE
+// specification requires.
+//
+// Connection failures follow the same rules as Poll: RetryPolicy,
+// Retry-After, MaxRetries and Backoff all apply. PollTimeout is used as an
+// idle timeout: if nothing, not even a comment line, arrives within it, the
+// connection is dropped and re-established.
+func (c *Client) PollSSE(ctx context.Context, url string, handler EventHandler) error {
+	pc, done, err := c.track(ctx, "", url)
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	return pc.result(c.sseLoop(pc, url, handler))
+}
+
+// SubscribeSSE is like Subscribe but for a Server-Sent Events endpoint.
+// Events are delivered as parsed by PollSSE. opts.NextURL is not used because
+// an event stream is resumed with Last-Event-ID instead of a new URL.
+func (c *Client) SubscribeSSE(ctx context.Context, url string, opts SubscribeOptions) (<-chan Event, <-chan error) {
+	events := make(chan Event, max(opts.BufferSize, 0))
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(errs)
+		defer close(events)
+
+		pc, done, err := c.track(ctx, "", url)
+		if err != nil {
+			errs <- err
+			return
+		}
+		defer done()
+
+		err = c.sseLoop(pc, url, func(ev Event) (bool, error) {
+			select {
+			case events <- ev:
+				return true, nil
+			case <-pc.ctx.Done():
+				return false, pc.ctx.Err()
+			}
+		})
+		if err != nil {
+			errs <- pc.result(err)
+		}
+	}()
+
+	return events, errs
+}
+
+// sseState is carried across reconnects of one event stream.
+type sseState struct {
+	lastEventID string
+	retry       time.Duration
+}
+
+// sseLoop connects, reads the stream and reconnects until stopped.
+func (c *Client) sseLoop(pc *pollContext, url string, handler EventHandler) error {
+	ctx := pc.ctx
+	var state sseState
+	st := newLoopState(pc, url)
+
+	for {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		header := http.Header{
+			"Accept":        {"text/event-stream"},
+			"Cache-Control": {"no-cache"},
+		}
+		if state.lastEventID != "" {
+			header.Set("Last-Event-ID", state.lastEventID)
+		}
+
+		if err := c.throttle(pc); err != nil {
+			return err
+		}
+
+		connCtx, cancelConn := context.WithCancel(ctx)
+		opts := st.requestOptions()
+		opts.header, opts.streaming = header, true
+		resp, err := c.doRequest(connCtx, url, opts)
+		st.observe(url, resp, err)
+		if err != nil {
+			cancelConn()
+			if err := c.waitRetry(ctx, url, resp, err, &st); err != nil {
+				return err
+			}
+			continue
+		}
+		c.succeeded(&st)
+
+		if resp.StatusCode == http.StatusNoContent {
+			resp.Body.Close()
+			cancelConn()
+			if c.logger != nil {
+				c.logger.Debug("event stream closed by server", "url", url)
+			}
+			return nil
+		}
+
+		body := &countingBody{ReadCloser: resp.Body}
+		resp.Body = body
+		stop, err := c.readSSE(resp, cancelConn, &state, handler)
+		resp.Body.Close()
+		cancelConn()
+		c.metrics.ObserveBytes(st.name, body.n.Load())
+		if err != nil {
+			return err
+		}
+		if stop {
+			if c.logger != nil {
+				c.logger.Debug("handler requested stop", "url", url)
+			}
+			return nil
+		}
+
+		delay := state.retry
+		if delay <= 0 {
+			delay = c.retryDelay(0)
+		}
+		if c.logger != nil {
+			c.logger.Debug("reconnecting event stream", "url", url, "last_event_id", state.lastEventID, "delay", delay)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// readSSE parses an event stream and dispatches events to handler.
+// It returns stop=true when the handler stopped polling, and returns
+// stop=false with a nil error when the stream ended and should be resumed.
+func (c *Client) readSSE(resp *http.Response, cancelConn context.CancelFunc, state *sseState, handler EventHandler) (stop bool, err error) {
+	idle := time.AfterFunc(c.config.PollTimeout, cancelConn)
+	defer idle.Stop()
+
+	var (
+		data      strings.Builder
+		hasData   bool
+		eventType string
+	)
+
+	br := bufio.NewReader(resp.Body)
+	for {
+		line, err := br.ReadString('\n')
+		if err != nil {
+			// an incomplete trailing event is discarded, as the spec requires
+			if c.logger != nil {
+				c.logger.Debug("event stream ended", "url", resp.Request.URL.String(), "error", err)
+			}
+			return false, nil
+		}
+		idle.Reset(c.config.PollTimeout)
+
+		line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+		if line == "" {
+			if !hasData {
+				eventType = ""
+				continue
+			}
+			ev := Event{
+				URL:        resp.Request.URL.String(),
+				StatusCode: resp.StatusCode,
+				Header:     resp.Header,
+				Body:       []byte(data.String()),
+				ID:         state.lastEventID,
+				Type:       eventType,
+			}
+			if ev.Type == "" {
+				ev.Type = "message"
+			}
+			data.Reset()
+			hasData = false
+			eventType = ""
+
+			// the handler may block on backpressure; don't count that as idle
+			idle.Stop()
+			shouldContinue, err := handler(ev)
+			if err != nil {
+				return true, fmt.Errorf("%w: %w", ErrHandlerStopped, err)
+			}
+			if !shouldContinue {
+				return true, nil
+			}
+			idle.Reset(c.config.PollTimeout)
+			continue
+		}
+
+		if line[0] == ':' {
+			continue // comment, typically a keep-alive
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			eventType = value
+		case "data":
+			if hasData {
+				data.WriteByte('\n')
+			}
+			data.WriteString(value)
+			hasData = true
+		case "id":
+			if !strings.ContainsRune(value, 0) {
+				state.lastEventID = value
+			}
+		case "retry":
+			if ms, err := strconv.Atoi(value); err == nil && ms >= 0 {
+				state.retry = time.Duration(ms) * time.Millisecond
+			}
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package longpoll
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestClient_PollSSE(t *testing.T) {
+	var mu sync.Mutex
+	var lastIDs []string
+	conns := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		conns++
+		n := conns
+		lastIDs = append(lastIDs, r.Header.Get("Last-Event-ID"))
+		mu.Unlock()
+
+		if r.Header.Get("Accept") != "text/event-stream" {
+			t.Errorf("Accept = %q", r.Header.Get("Accept"))
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		switch n {
+		case 1:
+			fmt.Fprint(w, ": keep-alive\n\n")
+			fmt.Fprint(w, "retry: 10\n")
+			fmt.Fprint(w, "id: 1\ndata: hello\n\n")
+			fmt.Fprint(w, "event: update\nid: 2\ndata: line one\ndata: line two\r\n\r\n")
+			fmt.Fprint(w, "data: incomplete") // dropped: stream ends mid-event
+		case 2:
+			fmt.Fprint(w, "id: 3\ndata:\n\n")
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	client := NewWithConfig(Config{PollTimeout: time.Second, RetryDelay: time.Hour, MaxRetries: -1})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	var got []Event
+	err := client.PollSSE(ctx, server.URL, func(ev Event) (bool, error) {
+		got = append(got, ev)
+		return true, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct{ id, typ, data string }{
+		{"1", "message", "hello"},
+		{"2", "update", "line one\nline two"},
+		{"3", "message", ""},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d events, want %d: %+v", len(got), len(want), got)
+	}
+	for i, w := range want {
+		if got[i].ID != w.id || got[i].Type != w.typ || string(got[i].Body) != w.data {
+			t.Errorf("event %d = {%q %q %q}, want {%q %q %q}", i, got[i].ID, got[i].Type, got[i].Body, w.id, w.typ, w.data)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(lastIDs) != 3 || lastIDs[0] != "" || lastIDs[1] != "2" || lastIDs[2] != "3" {
+		t.Errorf("Last-Event-ID per connection = %q, want [\"\" 2 3]", lastIDs)
+	}
+}
+
+func TestClient_SubscribeSSE(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for i := range 3 {
+			fmt.Fprintf(w, "id: %d\ndata: event %d\n\n", i, i)
+		}
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	client := NewWithConfig(Config{PollTimeout: time.Second, MaxRetries: -1})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, errs := client.SubscribeSSE(ctx, server.URL, SubscribeOptions{})
+	for i := range 3 {
+		ev := <-events
+		if string(ev.Body) != fmt.Sprintf("event %d", i) {
+			t.Errorf("event %d body = %q", i, ev.Body)
+		}
+	}
+	client.StopAll()
+
+	for range events {
+	}
+	if err := <-errs; !errors.Is(err, ErrStoppedByClient) || !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want ErrStoppedByClient wrapping context.Canceled", err)
+	}
+}
+
+func TestClient_PollSSE_IdleTimeoutReconnects(t *testing.T) {
+	var mu sync.Mutex
+	conns := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		conns++
+		n := conns
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		if n == 1 {
+			w.(http.Flusher).Flush()
+			<-r.Context().Done() // silent connection
+			return
+		}
+		fmt.Fprint(w, "data: after reconnect\n\n")
+	}))
+	defer server.Close()
+
+	client := NewWithConfig(Config{PollTimeout: 50 * time.Millisecond, RetryDelay: 10 * time.Millisecond, MaxRetries: -1})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	err := client.PollSSE(ctx, server.URL, func(ev Event) (bool, error) {
+		if string(ev.Body) != "after reconnect" {
+			t.Errorf("body = %q", ev.Body)
+		}
+		return false, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
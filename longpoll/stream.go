@@ -0,0 +1,164 @@
+package longpoll
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrStopStream can be returned by a StreamHandler to stop PollStream
+// without an error.
+var ErrStopStream = errors.New("longpoll: stop stream")
+
+// ErrStreamIdle is returned by the reader passed to a StreamHandler when a
+// read waited longer than the idle timeout for data.
+var ErrStreamIdle = errors.New("longpoll: stream idle")
+
+// StreamHandler consumes one streamed response body. It returns nil when the
+// body is exhausted to reconnect, ErrStopStream to stop polling, or another
+// error to stop with that error. The reader must not be used after the
+// handler returns.
+type StreamHandler func(body io.Reader) error
+
+// PollStream requests url and passes the live response body to handler, for
+// servers that keep a single response open for minutes and stream data over
+// it, such as newline-delimited JSON. When the handler returns nil the
+// request is made again.
+//
+// The client timeout does not apply to streamed bodies. Instead PollTimeout
+// is used as an idle timeout: if a read waits longer than that for data, it
+// fails with ErrStreamIdle and the stream is re-established. Any bytes,
+// including blank keep-alive lines, count as activity, and time the handler
+// spends between reads is not counted. If the handler returns an error
+// caused by a failed read of the body, the stream is retried like a failed
+// request; RetryPolicy, MaxRetries and Backoff apply.
+func (c *Client) PollStream(ctx context.Context, url string, handler StreamHandler) error {
+	pc, done, err := c.track(ctx, "", url)
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	return pc.result(c.streamLoop(pc, url, handler))
+}
+
+// streamLoop requests the stream and hands it to handler until stopped.
+func (c *Client) streamLoop(pc *pollContext, url string, handler StreamHandler) error {
+	ctx := pc.ctx
+	st := newLoopState(pc, url)
+
+	for {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if err := c.throttle(pc); err != nil {
+			return err
+		}
+
+		connCtx, cancelConn := context.WithCancel(ctx)
+		opts := st.requestOptions()
+		opts.streaming = true
+		resp, err := c.doRequest(connCtx, url, opts)
+		st.observe(url, resp, err)
+		if err != nil {
+			cancelConn()
+			if err := c.waitRetry(ctx, url, resp, err, &st); err != nil {
+				return err
+			}
+			continue
+		}
+		c.succeeded(&st)
+
+		body := newIdleReader(resp.Body, c.config.PollTimeout, cancelConn)
+		err = handler(body)
+		resp.Body.Close()
+		cancelConn()
+		c.metrics.ObserveBytes(st.name, body.n.Load())
+
+		readErr := body.err()
+		switch {
+		case errors.Is(err, ErrStopStream):
+			if c.logger != nil {
+				c.logger.Debug("handler requested stop", "url", url)
+			}
+			return nil
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case body.idle.Load():
+			if c.logger != nil {
+				c.logger.Debug("stream idle, reconnecting", "url", url)
+			}
+		case err != nil && readErr != nil && errors.Is(err, readErr):
+			if err := c.waitRetry(ctx, url, nil, fmt.Errorf("read stream: %w", readErr), &st); err != nil {
+				return err
+			}
+			continue
+		case err != nil:
+			return fmt.Errorf("%w: %w", ErrHandlerStopped, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(c.retryDelay(0)):
+		}
+	}
+}
+
+// idleReader fails a read with ErrStreamIdle, by cancelling the connection,
+// once it has waited timeout for data. It also counts the bytes read.
+type idleReader struct {
+	r       io.Reader
+	timeout time.Duration
+	cancel  context.CancelFunc
+	timer   *time.Timer
+	idle    atomic.Bool
+	n       atomic.Int64
+
+	mu      sync.Mutex
+	readErr error
+}
+
+// newIdleReader wraps r; cancel must abort a blocked read of r.
+func newIdleReader(r io.Reader, timeout time.Duration, cancel context.CancelFunc) *idleReader {
+	ir := &idleReader{r: r, timeout: timeout, cancel: cancel}
+	ir.timer = time.AfterFunc(timeout, ir.expire)
+	ir.timer.Stop()
+	return ir
+}
+
+func (r *idleReader) Read(p []byte) (int, error) {
+	r.timer.Reset(r.timeout)
+	n, err := r.r.Read(p)
+	r.timer.Stop()
+	r.n.Add(int64(n))
+	if err != nil && err != io.EOF {
+		if r.idle.Load() {
+			err = ErrStreamIdle
+		}
+		r.mu.Lock()
+		if r.readErr == nil {
+			r.readErr = err
+		}
+		r.mu.Unlock()
+	}
+	return n, err
+}
+
+// expire marks the stream idle and drops the connection.
+func (r *idleReader) expire() {
+	r.idle.Store(true)
+	r.cancel()
+}
+
+// err returns the first failed read, or nil.
+func (r *idleReader) err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.readErr
+}
@@ -0,0 +1,106 @@
+package longpoll
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClient_PollStream_NDJSON(t *testing.T) {
+	var conns atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := conns.Add(1)
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		for i := range 3 {
+			fmt.Fprintf(w, "{\"conn\":%d,\"seq\":%d}\n", n, i)
+			w.(http.Flusher).Flush()
+			time.Sleep(40 * time.Millisecond)
+		}
+	}))
+	defer server.Close()
+
+	// the whole stream outlives PollTimeout; only idle gaps are limited
+	client := NewWithConfig(Config{PollTimeout: 100 * time.Millisecond, RetryDelay: time.Millisecond})
+
+	var lines []string
+	err := client.PollStream(context.Background(), server.URL, func(body io.Reader) error {
+		sc := bufio.NewScanner(body)
+		for sc.Scan() {
+			lines = append(lines, sc.Text())
+			if len(lines) == 4 {
+				return ErrStopStream
+			}
+		}
+		return sc.Err()
+	})
+	if err != nil {
+		t.Fatalf("PollStream: %v", err)
+	}
+
+	want := []string{`{"conn":1,"seq":0}`, `{"conn":1,"seq":1}`, `{"conn":1,"seq":2}`, `{"conn":2,"seq":0}`}
+	if fmt.Sprint(lines) != fmt.Sprint(want) {
+		t.Errorf("lines = %v, want %v", lines, want)
+	}
+}
+
+func TestClient_PollStream_IdleReconnects(t *testing.T) {
+	var conns atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		if conns.Add(1) == 1 {
+			<-r.Context().Done() // silent connection
+			return
+		}
+		fmt.Fprintln(w, "hello")
+	}))
+	defer server.Close()
+
+	client := NewWithConfig(Config{PollTimeout: 50 * time.Millisecond, RetryDelay: time.Millisecond})
+
+	var readErrs []error
+	err := client.PollStream(context.Background(), server.URL, func(body io.Reader) error {
+		sc := bufio.NewScanner(body)
+		if sc.Scan() {
+			if sc.Text() != "hello" {
+				t.Errorf("line = %q", sc.Text())
+			}
+			return ErrStopStream
+		}
+		readErrs = append(readErrs, sc.Err())
+		return sc.Err()
+	})
+	if err != nil {
+		t.Fatalf("PollStream: %v", err)
+	}
+	if len(readErrs) != 1 || !errors.Is(readErrs[0], ErrStreamIdle) {
+		t.Errorf("read errors = %v, want one ErrStreamIdle", readErrs)
+	}
+	if got := conns.Load(); got != 2 {
+		t.Errorf("connections = %d, want 2", got)
+	}
+}
+
+func TestClient_PollStream_HandlerError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "not json")
+	}))
+	defer server.Close()
+
+	client := NewWithConfig(Config{PollTimeout: time.Second})
+
+	errDecode := errors.New("decode failed")
+	err := client.PollStream(context.Background(), server.URL, func(body io.Reader) error {
+		return errDecode
+	})
+	if !errors.Is(err, errDecode) {
+		t.Fatalf("PollStream error = %v, want handler error", err)
+	}
+}
@@ -0,0 +1,111 @@
+package longpoll
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Event is a single long polling response delivered by Subscribe, or a single
+// server-sent event delivered by PollSSE and SubscribeSSE.
+// The response body is fully read so the event can be passed between goroutines.
+type Event struct {
+	// URL is the URL the response was received from.
+	URL string
+
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+
+	// Header contains the response headers.
+	Header http.Header
+
+	// Body is the full response body, or the event data for server-sent events.
+	Body []byte
+
+	// ID is the last event ID of a server-sent event.
+	ID string
+
+	// Type is the event type of a server-sent event ("message" by default).
+	Type string
+}
+
+// SubscribeOptions configures a channel-based subscription.
+type SubscribeOptions struct {
+	// BufferSize is the capacity of the events channel.
+	// The poll loop blocks while the buffer is full, which provides backpressure.
+	// Default: 0 (unbuffered)
+	BufferSize int
+
+	// NextURL derives the URL for the next request from a received event.
+	// Return an empty string to reuse the current URL.
+	// If nil, the URL stays constant across all requests.
+	NextURL func(Event) (string, error)
+}
+
+// Subscribe starts polling the given URL in the background and delivers each
+// response as an Event on the returned channel.
+//
+// The error channel receives at most one value: the reason polling stopped.
+// Both channels are closed when polling ends, so consumers can simply range
+// over the events channel and then check the error channel:
+//
+//	events, errs := client.Subscribe(ctx, url, longpoll.SubscribeOptions{BufferSize: 16})
+//	for ev := range events {
+//		// process ev.Body...
+//	}
+//	if err := <-errs; err != nil {
+//		log.Printf("subscription ended: %v", err)
+//	}
+func (c *Client) Subscribe(ctx context.Context, url string, opts SubscribeOptions) (<-chan Event, <-chan error) {
+	events := make(chan Event, max(opts.BufferSize, 0))
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(errs)
+		defer close(events)
+
+		pc, done, err := c.track(ctx, "", url)
+		if err != nil {
+			errs <- err
+			return
+		}
+		defer done()
+
+		err = c.pollLoop(pc, url, func(resp *http.Response) (string, bool, error) {
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				return "", false, fmt.Errorf("read body: %w", err)
+			}
+
+			ev := Event{
+				URL:        resp.Request.URL.String(),
+				StatusCode: resp.StatusCode,
+				Header:     resp.Header,
+				Body:       body,
+			}
+
+			var nextURL string
+			if opts.NextURL != nil {
+				if nextURL, err = opts.NextURL(ev); err != nil {
+					return "", false, err
+				}
+			}
+
+			// Wait on the poll context, which the caller's context and
+			// StopAll cancel, not on the request context, which also
+			// expires with PollTimeout while a slow consumer catches up.
+			select {
+			case events <- ev:
+				return nextURL, true, nil
+			case <-pc.ctx.Done():
+				return "", false, pc.ctx.Err()
+			}
+		})
+		if err != nil {
+			errs <- pc.result(err)
+		}
+	}()
+
+	return events, errs
+}
@@ -0,0 +1,136 @@
+package longpoll
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestClient_Subscribe(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, "offset=%s", r.URL.Query().Get("offset"))
+	}))
+	defer server.Close()
+
+	client := NewWithConfig(Config{PollTimeout: 1 * time.Second})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	offset := 0
+	events, errs := client.Subscribe(ctx, server.URL+"?offset=0", SubscribeOptions{
+		BufferSize: 1,
+		NextURL: func(ev Event) (string, error) {
+			offset++
+			return server.URL + "?offset=" + strconv.Itoa(offset), nil
+		},
+	})
+
+	var bodies []string
+	for ev := range events {
+		if ev.StatusCode != http.StatusOK {
+			t.Errorf("StatusCode = %d, want 200", ev.StatusCode)
+		}
+		bodies = append(bodies, string(ev.Body))
+		if len(bodies) == 3 {
+			cancel()
+			break
+		}
+	}
+
+	// drain remaining buffered events so the goroutine can finish
+	for range events {
+	}
+
+	if err := <-errs; !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+
+	want := []string{"offset=0", "offset=1", "offset=2"}
+	for i, w := range want {
+		if bodies[i] != w {
+			t.Errorf("event %d body = %q, want %q", i, bodies[i], w)
+		}
+	}
+}
+
+func TestClient_Subscribe_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	client := NewWithConfig(Config{
+		PollTimeout: 1 * time.Second,
+		RetryDelay:  10 * time.Millisecond,
+		MaxRetries:  1,
+	})
+
+	events, errs := client.Subscribe(context.Background(), server.URL, SubscribeOptions{})
+
+	for range events {
+		t.Error("expected no events")
+	}
+
+	if err := <-errs; err == nil {
+		t.Error("expected error after max retries")
+	}
+}
+
+func TestClient_Subscribe_StopAllUnblocksSend(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	client := NewWithConfig(Config{PollTimeout: 1 * time.Second})
+
+	// nobody reads events, so the poll blocks on send until StopAll
+	_, errs := client.Subscribe(context.Background(), server.URL, SubscribeOptions{})
+
+	time.Sleep(100 * time.Millisecond)
+	client.StopAll()
+
+	select {
+	case err := <-errs:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected context.Canceled, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("subscription did not stop after StopAll")
+	}
+}
+
+func TestClient_Subscribe_SlowConsumer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewWithConfig(Config{PollTimeout: 50 * time.Millisecond})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, errs := client.Subscribe(ctx, server.URL, SubscribeOptions{})
+	for i := range 3 {
+		time.Sleep(150 * time.Millisecond) // longer than PollTimeout
+		if _, ok := <-events; !ok {
+			t.Fatalf("events closed after %d events: %v", i, <-errs)
+		}
+	}
+	cancel()
+
+	for range events {
+	}
+	if err := <-errs; !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,304 @@
+package longpoll
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/en9inerd/go-pkgs/retry"
+)
+
+// Supervised poll states reported by SupervisedStatus.State.
+const (
+	StateRunning = "running" // the poll loop is running
+	StateBackoff = "backoff" // the poll exited and waits to be restarted
+	StateStopped = "stopped" // the poll ended normally or was stopped
+	StateFailed  = "failed"  // the poll ended with a fatal error or too many restarts
+)
+
+// SupervisorConfig configures a Supervisor.
+type SupervisorConfig struct {
+	// RestartBackoff computes the delay before each restart of a poll that
+	// exited with an error. MaxAttempts and RetryableErrors are ignored.
+	// Default: retry.DefaultStrategy()
+	RestartBackoff *retry.Strategy
+
+	// MaxRestarts is the maximum number of consecutive restarts before a poll
+	// is marked failed. Zero means unlimited.
+	MaxRestarts int
+
+	// ResetAfter is how long a poll must run before its consecutive restart
+	// count is reset. Default: 1 minute
+	ResetAfter time.Duration
+
+	// IsFatal reports whether an error ends a poll for good instead of
+	// restarting it. ErrClientClosed is always fatal, and so is
+	// ErrPollExists, returned when the client already runs another poll
+	// with the same name.
+	// If nil, every other error is restarted.
+	IsFatal func(error) bool
+}
+
+// Supervisor runs a set of named polls on a Client and keeps them running:
+// a poll that exits with an error is restarted with backoff, resuming from
+// the last URL its handler returned, until it succeeds, fails fatally (see
+// SupervisorConfig.IsFatal) or exceeds MaxRestarts. A poll whose handler
+// stops it, or that is stopped with Stop or by cancelling the context, is not
+// restarted.
+//
+//	sup := longpoll.NewSupervisor(client, longpoll.SupervisorConfig{MaxRestarts: 10})
+//	sup.Add(longpoll.PollSpec{Name: "orders", URL: ordersURL, Handler: handleOrders})
+//	sup.Add(longpoll.PollSpec{Name: "users", URL: usersURL, Handler: handleUsers})
+//	err := sup.Run(ctx) // blocks until every poll has ended
+type Supervisor struct {
+	client *Client
+	cfg    SupervisorConfig
+
+	mu     sync.Mutex
+	polls  map[string]*supervised
+	runCtx context.Context
+	wg     sync.WaitGroup
+}
+
+// supervised is the state of one poll owned by a Supervisor.
+type supervised struct {
+	spec PollSpec
+
+	mu       sync.Mutex
+	url      string
+	state    string
+	restarts int
+	lastErr  error
+	cancel   context.CancelFunc
+}
+
+// SupervisedStatus is a snapshot of a poll owned by a Supervisor.
+type SupervisedStatus struct {
+	// Name is the poll name.
+	Name string
+
+	// State is one of StateRunning, StateBackoff, StateStopped and StateFailed.
+	State string
+
+	// URL is the URL the poll is using or will resume from.
+	URL string
+
+	// Restarts is the total number of restarts.
+	Restarts int
+
+	// LastError is the error the poll last exited with.
+	LastError error
+
+	// Poll is the live status of the poll loop. It is only set while State
+	// is StateRunning.
+	Poll *PollStatus
+}
+
+// NewSupervisor creates a Supervisor that runs polls on client.
+func NewSupervisor(client *Client, cfg SupervisorConfig) *Supervisor {
+	if cfg.RestartBackoff == nil {
+		cfg.RestartBackoff = retry.DefaultStrategy()
+	}
+	if cfg.ResetAfter == 0 {
+		cfg.ResetAfter = time.Minute
+	}
+	return &Supervisor{
+		client: client,
+		cfg:    cfg,
+		polls:  make(map[string]*supervised),
+	}
+}
+
+// Add registers a poll. spec.Name is required and must be unique. If Run is
+// already running, the poll starts immediately.
+func (s *Supervisor) Add(spec PollSpec) error {
+	if spec.Name == "" {
+		return errors.New("supervisor: poll name is required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.polls[spec.Name]; ok {
+		return fmt.Errorf("supervisor: %w: %q", ErrPollExists, spec.Name)
+	}
+	p := &supervised{spec: spec, url: spec.URL}
+	s.polls[spec.Name] = p
+	if s.runCtx != nil {
+		s.start(s.runCtx, p)
+	}
+	return nil
+}
+
+// Run starts every registered poll and blocks until all of them have ended,
+// either because ctx was cancelled or because each one stopped or failed.
+// It returns the fatal errors of failed polls, joined, or nil.
+func (s *Supervisor) Run(ctx context.Context) error {
+	s.mu.Lock()
+	if s.runCtx != nil {
+		s.mu.Unlock()
+		return errors.New("supervisor: already running")
+	}
+	s.runCtx = ctx
+	for _, p := range s.polls {
+		s.start(ctx, p)
+	}
+	s.mu.Unlock()
+
+	s.wg.Wait()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.runCtx = nil
+
+	var errs []error
+	for _, name := range slices.Sorted(maps.Keys(s.polls)) {
+		p := s.polls[name]
+		p.mu.Lock()
+		if p.state == StateFailed {
+			errs = append(errs, &PollError{Name: name, Err: p.lastErr})
+		}
+		p.mu.Unlock()
+	}
+	return errors.Join(errs...)
+}
+
+// Stop stops the named poll without restarting it. It reports whether the
+// supervisor owns a poll with that name.
+func (s *Supervisor) Stop(name string) bool {
+	s.mu.Lock()
+	p, ok := s.polls[name]
+	s.mu.Unlock()
+	if !ok {
+		return false
+	}
+
+	p.mu.Lock()
+	if p.cancel != nil {
+		p.cancel()
+	}
+	p.mu.Unlock()
+	return true
+}
+
+// Status returns a snapshot of every poll, sorted by name.
+func (s *Supervisor) Status() []SupervisedStatus {
+	s.mu.Lock()
+	polls := make([]*supervised, 0, len(s.polls))
+	for _, p := range s.polls {
+		polls = append(polls, p)
+	}
+	s.mu.Unlock()
+
+	statuses := make([]SupervisedStatus, 0, len(polls))
+	for _, p := range polls {
+		p.mu.Lock()
+		st := SupervisedStatus{
+			Name:      p.spec.Name,
+			State:     p.state,
+			URL:       p.url,
+			Restarts:  p.restarts,
+			LastError: p.lastErr,
+		}
+		p.mu.Unlock()
+		if st.State == StateRunning {
+			if ps, ok := s.client.Status(st.Name); ok {
+				st.Poll = &ps
+				st.URL = ps.URL
+			}
+		}
+		statuses = append(statuses, st)
+	}
+	slices.SortFunc(statuses, func(a, b SupervisedStatus) int { return strings.Compare(a.Name, b.Name) })
+	return statuses
+}
+
+// start runs p in a new goroutine. s.mu must be held.
+func (s *Supervisor) start(ctx context.Context, p *supervised) {
+	ctx, cancel := context.WithCancel(ctx)
+	p.mu.Lock()
+	p.state = StateRunning
+	p.cancel = cancel
+	p.mu.Unlock()
+
+	s.wg.Go(func() {
+		defer cancel()
+		s.supervise(ctx, p)
+	})
+}
+
+// supervise runs p until it ends for good.
+func (s *Supervisor) supervise(ctx context.Context, p *supervised) {
+	// remember the cursor so a restart resumes where the handler left off
+	handler := func(resp *http.Response) (string, bool, error) {
+		nextURL, shouldContinue, err := p.spec.Handler(resp)
+		if nextURL != "" {
+			p.mu.Lock()
+			p.url = nextURL
+			p.mu.Unlock()
+		}
+		return nextURL, shouldContinue, err
+	}
+
+	streak := 0
+	for {
+		p.mu.Lock()
+		url := p.url
+		p.state = StateRunning
+		p.mu.Unlock()
+
+		started := time.Now()
+		err := s.client.PollNamed(ctx, p.spec.Name, url, handler)
+
+		switch {
+		case err == nil, ctx.Err() != nil, errors.Is(err, ErrStoppedByClient):
+			p.finish(StateStopped, err)
+			return
+		case errors.Is(err, ErrClientClosed), errors.Is(err, ErrPollExists),
+			s.cfg.IsFatal != nil && s.cfg.IsFatal(err):
+			p.finish(StateFailed, err)
+			return
+		}
+
+		if time.Since(started) >= s.cfg.ResetAfter {
+			streak = 0
+		}
+		if s.cfg.MaxRestarts > 0 && streak >= s.cfg.MaxRestarts {
+			p.finish(StateFailed, fmt.Errorf("max restarts exceeded: %w", err))
+			return
+		}
+
+		delay := s.cfg.RestartBackoff.Delay(streak)
+		streak++
+		p.mu.Lock()
+		p.state = StateBackoff
+		p.lastErr = err
+		p.restarts++
+		p.mu.Unlock()
+		if logger := s.client.logger; logger != nil {
+			logger.Warn("restarting long poll", "name", p.spec.Name, "error", err, "delay", delay, "restart", streak)
+		}
+
+		select {
+		case <-ctx.Done():
+			p.finish(StateStopped, err)
+			return
+		case <-time.After(delay):
+		}
+	}
+}
+
+// finish records that p ended in state with err.
+func (p *supervised) finish(state string, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.state = state
+	if err != nil {
+		p.lastErr = err
+	}
+}
@@ -0,0 +1,242 @@
+package longpoll
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/en9inerd/go-pkgs/retry"
+)
+
+func testSupervisorConfig() SupervisorConfig {
+	return SupervisorConfig{
+		RestartBackoff: &retry.Strategy{InitialDelay: time.Millisecond, MaxDelay: time.Millisecond, Multiplier: 1},
+	}
+}
+
+func TestSupervisor_RestartResumesFromCursor(t *testing.T) {
+	var mu sync.Mutex
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewWithConfig(Config{PollTimeout: time.Second, RetryDelay: time.Millisecond})
+	sup := NewSupervisor(client, testSupervisorConfig())
+
+	var calls atomic.Int32
+	err := sup.Add(PollSpec{Name: "orders", URL: server.URL + "/v1", Handler: func(resp *http.Response) (string, bool, error) {
+		switch calls.Add(1) {
+		case 1:
+			return server.URL + "/v2", true, nil
+		case 2:
+			return "", false, errors.New("temporary failure")
+		default:
+			return "", false, nil
+		}
+	}})
+	if err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+
+	if err := sup.Run(context.Background()); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	mu.Lock()
+	got := append([]string(nil), paths...)
+	mu.Unlock()
+	want := []string{"/v1", "/v2", "/v2"}
+	if len(got) != len(want) {
+		t.Fatalf("paths = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("paths = %v, want %v", got, want)
+		}
+	}
+
+	st := sup.Status()
+	if len(st) != 1 {
+		t.Fatalf("len(Status) = %d, want 1", len(st))
+	}
+	if st[0].State != StateStopped || st[0].Restarts != 1 || st[0].LastError == nil {
+		t.Errorf("status = %+v", st[0])
+	}
+}
+
+func TestSupervisor_FatalError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	errFatal := errors.New("bad credentials")
+	cfg := testSupervisorConfig()
+	cfg.IsFatal = func(err error) bool { return errors.Is(err, errFatal) }
+
+	client := NewWithConfig(Config{PollTimeout: time.Second})
+	sup := NewSupervisor(client, cfg)
+	sup.Add(PollSpec{Name: "auth", URL: server.URL, Handler: func(resp *http.Response) (string, bool, error) {
+		return "", false, errFatal
+	}})
+
+	err := sup.Run(context.Background())
+	var pollErr *PollError
+	if !errors.As(err, &pollErr) || pollErr.Name != "auth" || !errors.Is(err, errFatal) {
+		t.Fatalf("Run error = %v, want PollError for auth wrapping errFatal", err)
+	}
+
+	st := sup.Status()[0]
+	if st.State != StateFailed || st.Restarts != 0 {
+		t.Errorf("status = %+v, want failed without restarts", st)
+	}
+}
+
+func TestSupervisor_NameTakenIsFatal(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewWithConfig(Config{PollTimeout: time.Second})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	started := make(chan struct{})
+	go client.PollNamed(ctx, "events", server.URL, func(resp *http.Response) (string, bool, error) {
+		select {
+		case <-started:
+		default:
+			close(started)
+		}
+		<-ctx.Done()
+		return "", false, nil
+	})
+	<-started
+
+	sup := NewSupervisor(client, testSupervisorConfig())
+	sup.Add(PollSpec{Name: "events", URL: server.URL, Handler: func(resp *http.Response) (string, bool, error) {
+		return "", false, nil
+	}})
+
+	if err := sup.Run(context.Background()); !errors.Is(err, ErrPollExists) {
+		t.Fatalf("Run error = %v, want ErrPollExists", err)
+	}
+	if st := sup.Status()[0]; st.State != StateFailed || st.Restarts != 0 {
+		t.Errorf("status = %+v, want failed without restarts", st)
+	}
+}
+
+func TestSupervisor_MaxRestarts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := testSupervisorConfig()
+	cfg.MaxRestarts = 2
+
+	client := NewWithConfig(Config{PollTimeout: time.Second})
+	sup := NewSupervisor(client, cfg)
+	var calls atomic.Int32
+	sup.Add(PollSpec{Name: "flaky", URL: server.URL, Handler: func(resp *http.Response) (string, bool, error) {
+		calls.Add(1)
+		return "", false, errors.New("boom")
+	}})
+
+	if err := sup.Run(context.Background()); err == nil {
+		t.Fatal("Run: expected error after max restarts")
+	}
+	if got := calls.Load(); got != 3 {
+		t.Errorf("handler calls = %d, want 3", got)
+	}
+	st := sup.Status()[0]
+	if st.State != StateFailed || st.Restarts != 2 {
+		t.Errorf("status = %+v, want failed after 2 restarts", st)
+	}
+}
+
+func TestSupervisor_RestartsAfterClientTimeouts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer server.Close()
+
+	cfg := testSupervisorConfig()
+	cfg.MaxRestarts = 2
+
+	client := NewWithConfig(Config{PollTimeout: 20 * time.Millisecond, RetryDelay: time.Millisecond, MaxRetries: 1})
+	sup := NewSupervisor(client, cfg)
+	sup.Add(PollSpec{Name: "slow", URL: server.URL, Handler: func(resp *http.Response) (string, bool, error) {
+		return "", true, nil
+	}})
+
+	err := sup.Run(context.Background())
+	if !errors.Is(err, ErrMaxRetriesExceeded) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Run error = %v, want max retries exceeded after client timeouts", err)
+	}
+	if st := sup.Status()[0]; st.State != StateFailed || st.Restarts != 2 {
+		t.Errorf("status = %+v, want failed after 2 restarts", st)
+	}
+}
+
+func TestSupervisor_StopAndStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewWithConfig(Config{PollTimeout: time.Second})
+	sup := NewSupervisor(client, testSupervisorConfig())
+
+	started := make(chan struct{})
+	var once sync.Once
+	sup.Add(PollSpec{Name: "events", URL: server.URL, Handler: func(resp *http.Response) (string, bool, error) {
+		once.Do(func() { close(started) })
+		time.Sleep(time.Millisecond)
+		return "", true, nil
+	}})
+	if err := sup.Add(PollSpec{Name: "events", URL: server.URL}); !errors.Is(err, ErrPollExists) {
+		t.Errorf("duplicate Add error = %v, want ErrPollExists", err)
+	}
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- sup.Run(context.Background()) }()
+	<-started
+
+	st := sup.Status()[0]
+	if st.State != StateRunning || st.Poll == nil || st.Poll.Name != "events" {
+		t.Errorf("running status = %+v", st)
+	}
+
+	if !sup.Stop("events") {
+		t.Fatal("Stop returned false")
+	}
+	if sup.Stop("missing") {
+		t.Error("Stop of unknown poll returned true")
+	}
+
+	select {
+	case err := <-errCh:
+		if err != nil {
+			t.Errorf("Run: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Run did not return after Stop")
+	}
+	if st := sup.Status()[0]; st.State != StateStopped || st.Restarts != 0 {
+		t.Errorf("stopped status = %+v", st)
+	}
+}
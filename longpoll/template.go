@@ -0,0 +1,92 @@
+package longpoll
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	neturl "net/url"
+	"regexp"
+	"strings"
+)
+
+// Params holds the values substituted into a URL template by PollTemplate.
+type Params map[string]string
+
+// TemplateHandler processes a response and may change params, which are
+// substituted into the URL template of the next request.
+type TemplateHandler func(resp *http.Response, params Params) (shouldContinue bool, err error)
+
+// PollTemplate polls a URL template such as
+//
+//	https://api.telegram.org/bot<token>/getUpdates?offset={offset}&timeout={timeout}
+//
+// replacing each {name} placeholder with params[name] before every request.
+// Values are escaped for their position: path escaping before the '?' and
+// query escaping after it. The handler receives params and may change them
+// to move the next request forward, so offset-style APIs need no URL string
+// manipulation:
+//
+//	params := longpoll.Params{"offset": "0", "timeout": "50"}
+//	err := client.PollTemplate(ctx, tmpl, params, func(resp *http.Response, p longpoll.Params) (bool, error) {
+//		...
+//		p["offset"] = strconv.Itoa(lastID + 1)
+//		return true, nil
+//	})
+//
+// params is used by the loop and must not be changed elsewhere while it
+// runs. A placeholder without a value is an error.
+func (c *Client) PollTemplate(ctx context.Context, template string, params Params, handler TemplateHandler) error {
+	url, err := expandTemplate(template, params)
+	if err != nil {
+		return err
+	}
+	return c.Poll(ctx, url, func(resp *http.Response) (string, bool, error) {
+		shouldContinue, err := handler(resp, params)
+		if err != nil {
+			return "", shouldContinue, err
+		}
+		next, err := expandTemplate(template, params)
+		if err != nil {
+			return "", false, err
+		}
+		return next, shouldContinue, nil
+	})
+}
+
+var templateParam = regexp.MustCompile(`\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// expandTemplate substitutes params into template.
+func expandTemplate(template string, params Params) (string, error) {
+	queryStart := strings.IndexByte(template, '?')
+	var missing string
+	url := expandIndexed(template, func(start int, name string) string {
+		value, ok := params[name]
+		if !ok {
+			if missing == "" {
+				missing = name
+			}
+			return ""
+		}
+		if queryStart >= 0 && start > queryStart {
+			return neturl.QueryEscape(value)
+		}
+		return neturl.PathEscape(value)
+	})
+	if missing != "" {
+		return "", fmt.Errorf("longpoll: URL template parameter %q not set", missing)
+	}
+	return url, nil
+}
+
+// expandIndexed replaces every placeholder in s with repl(offset, name).
+func expandIndexed(s string, repl func(start int, name string) string) string {
+	var b strings.Builder
+	last := 0
+	for _, m := range templateParam.FindAllStringSubmatchIndex(s, -1) {
+		b.WriteString(s[last:m[0]])
+		b.WriteString(repl(m[0], s[m[2]:m[3]]))
+		last = m[1]
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
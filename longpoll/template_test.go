@@ -0,0 +1,68 @@
+package longpoll
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExpandTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		params   Params
+		want     string
+		wantErr  bool
+	}{
+		{"query", "https://x.test/u?offset={offset}&timeout={timeout}", Params{"offset": "5", "timeout": "50"}, "https://x.test/u?offset=5&timeout=50", false},
+		{"path escaping", "https://x.test/chats/{chat}/updates", Params{"chat": "a b/c"}, "https://x.test/chats/a%20b%2Fc/updates", false},
+		{"query escaping", "https://x.test/u?q={q}", Params{"q": "a b&c"}, "https://x.test/u?q=a+b%26c", false},
+		{"no placeholders", "https://x.test/u", nil, "https://x.test/u", false},
+		{"missing param", "https://x.test/u?offset={offset}", Params{}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := expandTemplate(tt.template, tt.params)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("expandTemplate = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_PollTemplate(t *testing.T) {
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.RawQuery)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewWithConfig(Config{PollTimeout: time.Second})
+	params := Params{"offset": "0", "timeout": "50"}
+	err := client.PollTemplate(context.Background(), server.URL+"/getUpdates?offset={offset}&timeout={timeout}", params,
+		func(resp *http.Response, p Params) (bool, error) {
+			n, _ := strconv.Atoi(p["offset"])
+			p["offset"] = strconv.Itoa(n + 3)
+			return n < 6, nil
+		})
+	if err != nil {
+		t.Fatalf("PollTemplate: %v", err)
+	}
+
+	if got := strings.Join(queries, " "); got != "offset=0&timeout=50 offset=3&timeout=50 offset=6&timeout=50" {
+		t.Errorf("queries = %s", got)
+	}
+
+	err = client.PollTemplate(context.Background(), server.URL+"?a={a}", Params{}, nil)
+	if err == nil {
+		t.Error("PollTemplate accepted a template with an unset parameter")
+	}
+}
@@ -0,0 +1,112 @@
+package longpoll
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// TokenSource supplies the bearer token sent in the Authorization header of
+// every poll request. Token returns the current token. Refresh is called when
+// the server rejects a request with 401 Unauthorized or 403 Forbidden and
+// must return a new token; the request is then sent once more before the
+// rejection counts as a failure. RejectedToken(ctx) tells Refresh which token
+// was rejected, so it can return a newer one another poll already fetched.
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+	Refresh(ctx context.Context) (string, error)
+}
+
+// CachedToken returns a TokenSource that calls fetch for the first token and
+// on every refresh, and reuses the last token in between. Concurrent polls
+// rejected with the same token share one refresh: only the first fetches,
+// the others get the new token.
+func CachedToken(fetch func(ctx context.Context) (string, error)) TokenSource {
+	return &cachedToken{fetch: fetch}
+}
+
+// cachedToken is the TokenSource returned by CachedToken.
+type cachedToken struct {
+	fetch func(ctx context.Context) (string, error)
+
+	mu      sync.Mutex
+	token   string
+	fetched bool
+}
+
+// Token returns the cached token, fetching it on first use.
+func (t *cachedToken) Token(ctx context.Context) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.fetched {
+		return t.refreshLocked(ctx)
+	}
+	return t.token, nil
+}
+
+// Refresh fetches a new token, unless the cached one is already newer than
+// the rejected token.
+func (t *cachedToken) Refresh(ctx context.Context) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if rejected, ok := RejectedToken(ctx); ok && t.fetched && t.token != rejected {
+		return t.token, nil
+	}
+	return t.refreshLocked(ctx)
+}
+
+func (t *cachedToken) refreshLocked(ctx context.Context) (string, error) {
+	token, err := t.fetch(ctx)
+	if err != nil {
+		return "", err
+	}
+	t.token = token
+	t.fetched = true
+	return token, nil
+}
+
+// rejectedTokenKey is the context key of the token passed to Refresh.
+type rejectedTokenKey struct{}
+
+// RejectedToken returns the token the server rejected, from the context
+// passed to TokenSource.Refresh.
+func RejectedToken(ctx context.Context) (string, bool) {
+	token, ok := ctx.Value(rejectedTokenKey{}).(string)
+	return token, ok
+}
+
+// withRejectedToken returns ctx carrying the bearer token sent with req.
+func withRejectedToken(ctx context.Context, req *http.Request) context.Context {
+	token, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return ctx
+	}
+	return context.WithValue(ctx, rejectedTokenKey{}, token)
+}
+
+// authorize sets the Authorization header from the TokenSource, if any.
+func (c *Client) authorize(ctx context.Context, req *http.Request) error {
+	if c.config.TokenSource == nil {
+		return nil
+	}
+	token, err := c.config.TokenSource.Token(ctx)
+	if err != nil {
+		return fmt.Errorf("get token: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+// needsRefresh reports whether err is an authentication failure that a
+// token refresh may fix.
+func (c *Client) needsRefresh(err error) bool {
+	if c.config.TokenSource == nil {
+		return false
+	}
+	var statusErr *StatusError
+	return errors.As(err, &statusErr) &&
+		(statusErr.StatusCode == http.StatusUnauthorized || statusErr.StatusCode == http.StatusForbidden)
+}
@@ -0,0 +1,139 @@
+package longpoll
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClient_Poll_TokenRefresh(t *testing.T) {
+	var current atomic.Value
+	current.Store("t1")
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.Header.Get("Authorization") != "Bearer "+current.Load().(string) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	var fetches atomic.Int32
+	tokens := CachedToken(func(ctx context.Context) (string, error) {
+		return fmt.Sprintf("t%d", fetches.Add(1)), nil
+	})
+
+	var retries atomic.Int32
+	client := NewWithConfig(Config{
+		PollTimeout: time.Second,
+		TokenSource: tokens,
+		OnRetry:     func(string, int, time.Duration) { retries.Add(1) },
+	})
+
+	handled := 0
+	err := client.Poll(context.Background(), server.URL, func(resp *http.Response) (string, bool, error) {
+		handled++
+		if handled == 1 {
+			current.Store("t2") // the server revokes the token
+		}
+		return "", handled < 2, nil
+	})
+	if err != nil {
+		t.Fatalf("Poll: %v", err)
+	}
+
+	if got := fetches.Load(); got != 2 {
+		t.Errorf("token fetches = %d, want 2", got)
+	}
+	if got := requests.Load(); got != 3 {
+		t.Errorf("requests = %d, want 3", got)
+	}
+	if got := retries.Load(); got != 0 {
+		t.Errorf("retries = %d, want 0: a successful refresh is not a failure", got)
+	}
+}
+
+func TestClient_Poll_TokenRefreshFails(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	errRefresh := errors.New("refresh denied")
+	var calls atomic.Int32
+	tokens := CachedToken(func(ctx context.Context) (string, error) {
+		if calls.Add(1) > 1 {
+			return "", errRefresh
+		}
+		return "t1", nil
+	})
+
+	client := NewWithConfig(Config{PollTimeout: time.Second, TokenSource: tokens})
+	err := client.Poll(context.Background(), server.URL, func(resp *http.Response) (string, bool, error) {
+		t.Error("handler called for rejected request")
+		return "", false, nil
+	})
+
+	var statusErr *StatusError
+	if !errors.Is(err, errRefresh) || !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusForbidden {
+		t.Fatalf("Poll error = %v, want refresh error wrapping 403", err)
+	}
+}
+
+func TestClient_Poll_TokenSourceError(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+	}))
+	defer server.Close()
+
+	errToken := errors.New("no credentials")
+	client := NewWithConfig(Config{PollTimeout: time.Second}).
+		WithTokenSource(CachedToken(func(ctx context.Context) (string, error) { return "", errToken }))
+
+	err := client.Poll(context.Background(), server.URL, func(resp *http.Response) (string, bool, error) {
+		return "", false, nil
+	})
+	if !errors.Is(err, errToken) {
+		t.Fatalf("Poll error = %v, want token error", err)
+	}
+	if requests.Load() != 0 {
+		t.Error("request sent without a token")
+	}
+}
+
+func TestCachedToken_ConcurrentRefresh(t *testing.T) {
+	var fetches atomic.Int32
+	tokens := CachedToken(func(ctx context.Context) (string, error) {
+		n := fetches.Add(1)
+		time.Sleep(10 * time.Millisecond)
+		return fmt.Sprintf("t%d", n), nil
+	})
+	if _, err := tokens.Token(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// polls rejected with t1 at the same time
+	rejected := context.WithValue(context.Background(), rejectedTokenKey{}, "t1")
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Go(func() {
+			if token, err := tokens.Refresh(rejected); err != nil || token != "t2" {
+				t.Errorf("Refresh = %q, %v, want t2", token, err)
+			}
+		})
+	}
+	wg.Wait()
+
+	if got := fetches.Load(); got != 2 {
+		t.Errorf("token fetches = %d, want 2", got)
+	}
+}
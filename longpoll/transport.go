@@ -0,0 +1,71 @@
+package longpoll
+
+import (
+	"net"
+	"net/http"
+	"time"
+)
+
+// TransportOptions tunes the transport of the internally created HTTP
+// client. Long polling holds one connection per loop for a long time, which
+// the defaults of http.DefaultTransport are not tuned for. Zero fields keep
+// the http.DefaultTransport values.
+type TransportOptions struct {
+	// DialTimeout limits how long establishing a TCP connection may take.
+	DialTimeout time.Duration
+
+	// KeepAlive is the interval of TCP keep-alive probes on open
+	// connections. Negative disables them.
+	KeepAlive time.Duration
+
+	// TLSHandshakeTimeout limits the TLS handshake.
+	TLSHandshakeTimeout time.Duration
+
+	// ResponseHeaderTimeout limits the wait for response headers after the
+	// request was sent. It must exceed the time the server holds a poll.
+	ResponseHeaderTimeout time.Duration
+
+	// MaxIdleConnsPerHost is the number of idle connections kept per host.
+	// Set it to the number of concurrent polls against a host so each loop
+	// can reuse its connection.
+	MaxIdleConnsPerHost int
+
+	// IdleConnTimeout is how long an idle connection is kept.
+	IdleConnTimeout time.Duration
+}
+
+// newTransport builds the transport of the internally created HTTP client.
+func newTransport(cfg Config) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if cfg.ProxyURL != nil {
+		transport.Proxy = http.ProxyURL(cfg.ProxyURL)
+	}
+	if cfg.TLSConfig != nil {
+		transport.TLSClientConfig = cfg.TLSConfig.Clone()
+	}
+
+	opts := cfg.Transport
+	if opts.DialTimeout != 0 || opts.KeepAlive != 0 {
+		dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+		if opts.DialTimeout != 0 {
+			dialer.Timeout = opts.DialTimeout
+		}
+		if opts.KeepAlive != 0 {
+			dialer.KeepAlive = opts.KeepAlive
+		}
+		transport.DialContext = dialer.DialContext
+	}
+	if opts.TLSHandshakeTimeout != 0 {
+		transport.TLSHandshakeTimeout = opts.TLSHandshakeTimeout
+	}
+	if opts.ResponseHeaderTimeout != 0 {
+		transport.ResponseHeaderTimeout = opts.ResponseHeaderTimeout
+	}
+	if opts.MaxIdleConnsPerHost != 0 {
+		transport.MaxIdleConnsPerHost = opts.MaxIdleConnsPerHost
+	}
+	if opts.IdleConnTimeout != 0 {
+		transport.IdleConnTimeout = opts.IdleConnTimeout
+	}
+	return transport
+}
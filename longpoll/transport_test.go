@@ -0,0 +1,93 @@
+package longpoll
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestClient_ProxyURL(t *testing.T) {
+	var gotURL, gotAuth string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotURL = r.URL.String()
+		gotAuth = r.Header.Get("Proxy-Authorization")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer proxy.Close()
+
+	proxyURL, _ := url.Parse(proxy.URL)
+	proxyURL.User = url.UserPassword("poller", "s3cret")
+
+	client := NewWithConfig(Config{PollTimeout: time.Second, ProxyURL: proxyURL})
+	err := client.Poll(context.Background(), "http://updates.example/poll?offset=1", func(*http.Response) (string, bool, error) {
+		return "", false, nil
+	})
+	if err != nil {
+		t.Fatalf("Poll: %v", err)
+	}
+
+	if gotURL != "http://updates.example/poll?offset=1" {
+		t.Errorf("proxied URL = %q", gotURL)
+	}
+	want := "Basic " + base64.StdEncoding.EncodeToString([]byte("poller:s3cret"))
+	if gotAuth != want {
+		t.Errorf("Proxy-Authorization = %q, want %q", gotAuth, want)
+	}
+}
+
+func TestClient_TLSConfig(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	handler := func(*http.Response) (string, bool, error) { return "", false, nil }
+
+	untrusted := NewWithConfig(Config{PollTimeout: time.Second})
+	if err := untrusted.Poll(context.Background(), server.URL, handler); !IsConnectError(err) {
+		t.Fatalf("Poll without the test CA = %v, want a ConnectError", err)
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(server.Certificate())
+	client := NewWithConfig(Config{PollTimeout: time.Second, TLSConfig: &tls.Config{RootCAs: roots}})
+	if err := client.Poll(context.Background(), server.URL, handler); err != nil {
+		t.Fatalf("Poll with TLSConfig: %v", err)
+	}
+}
+
+func TestClient_TransportOptions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewWithConfig(Config{
+		PollTimeout: time.Second,
+		Transport:   TransportOptions{ResponseHeaderTimeout: 20 * time.Millisecond},
+	})
+	err := client.Poll(context.Background(), server.URL, func(*http.Response) (string, bool, error) {
+		return "", false, nil
+	})
+	if err == nil {
+		t.Fatal("Poll succeeded despite ResponseHeaderTimeout")
+	}
+
+	transport := newTransport(Config{Transport: TransportOptions{
+		TLSHandshakeTimeout: time.Second,
+		MaxIdleConnsPerHost: 32,
+		IdleConnTimeout:     5 * time.Minute,
+		DialTimeout:         time.Second,
+	}})
+	if transport.TLSHandshakeTimeout != time.Second || transport.MaxIdleConnsPerHost != 32 ||
+		transport.IdleConnTimeout != 5*time.Minute || transport.DialContext == nil {
+		t.Errorf("transport not configured: %+v", transport)
+	}
+}
@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/en9inerd/go-pkgs/conditional"
+)
+
+// etagWriter buffers a response so its entity tag can be computed.
+type etagWriter struct {
+	http.ResponseWriter
+	buf    bytes.Buffer
+	status int
+}
+
+func (w *etagWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *etagWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.buf.Write(b)
+}
+
+// ETag middleware buffers successful GET and HEAD responses, adds an ETag
+// derived from the body unless the handler set one, and answers conditional
+// requests with 304 Not Modified using the conditional package, the same
+// rules httpjson.WriteJSONWithETag applies. A Last-Modified header set by the
+// handler is honored for If-Modified-Since.
+// When weak is true the generated tag is weak (W/"...").
+//
+// HEAD requests are served by running the handler as GET and discarding the
+// body, so they get the same ETag and Content-Length as GET.
+//
+// Responses are held in memory, so avoid it on streaming or very large responses.
+func ETag(weak bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet && r.Method != http.MethodHead {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			head := r.Method == http.MethodHead
+			inner := r
+			if head {
+				inner = r.Clone(r.Context())
+				inner.Method = http.MethodGet
+			}
+
+			ew := &etagWriter{ResponseWriter: w}
+			next.ServeHTTP(ew, inner)
+			if ew.status == 0 {
+				ew.status = http.StatusOK
+			}
+
+			if ew.status != http.StatusOK {
+				w.WriteHeader(ew.status)
+				if !head {
+					w.Write(ew.buf.Bytes())
+				}
+				return
+			}
+
+			etag := w.Header().Get("ETag")
+			if etag == "" {
+				if weak {
+					etag = conditional.WeakETag(ew.buf.Bytes())
+				} else {
+					etag = conditional.StrongETag(ew.buf.Bytes())
+				}
+			}
+			var lastModified time.Time
+			if lm, err := http.ParseTime(w.Header().Get("Last-Modified")); err == nil {
+				lastModified = lm
+			}
+
+			if conditional.Check(w, r, etag, lastModified) {
+				return
+			}
+			if w.Header().Get("Content-Length") == "" {
+				w.Header().Set("Content-Length", strconv.Itoa(ew.buf.Len()))
+			}
+			w.WriteHeader(ew.status)
+			if !head {
+				w.Write(ew.buf.Bytes())
+			}
+		})
+	}
+}
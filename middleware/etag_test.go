@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestETag(t *testing.T) {
+	handler := ETag(false)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		io.WriteString(w, "hello")
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	etag := w.Header().Get("ETag")
+	if w.Code != http.StatusOK || w.Body.String() != "hello" || etag == "" {
+		t.Fatalf("first response: %d %q etag=%q", w.Code, w.Body.String(), etag)
+	}
+	if w.Header().Get("Content-Length") != "5" {
+		t.Errorf("Content-Length = %q, want 5", w.Header().Get("Content-Length"))
+	}
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	if w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+		t.Errorf("conditional response: %d %q, want 304 with empty body", w.Code, w.Body.String())
+	}
+}
+
+func TestETag_Head(t *testing.T) {
+	handler := ETag(false)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			return // handlers may skip the body for HEAD
+		}
+		io.WriteString(w, "hello")
+	}))
+
+	get := httptest.NewRecorder()
+	handler.ServeHTTP(get, httptest.NewRequest(http.MethodGet, "/", nil))
+	head := httptest.NewRecorder()
+	handler.ServeHTTP(head, httptest.NewRequest(http.MethodHead, "/", nil))
+
+	if etag := head.Header().Get("ETag"); etag == "" || etag != get.Header().Get("ETag") {
+		t.Errorf("HEAD ETag = %q, want the GET ETag %q", etag, get.Header().Get("ETag"))
+	}
+	if cl := head.Header().Get("Content-Length"); cl != "5" {
+		t.Errorf("HEAD Content-Length = %q, want 5", cl)
+	}
+	if head.Body.Len() != 0 {
+		t.Errorf("HEAD body = %q, want empty", head.Body.String())
+	}
+}
+
+func TestETag_Weak(t *testing.T) {
+	handler := ETag(true)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "hello")
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if etag := w.Header().Get("ETag"); !strings.HasPrefix(etag, `W/"`) {
+		t.Errorf("ETag = %q, want weak tag", etag)
+	}
+}
+
+func TestETag_HandlerETagAndNonOK(t *testing.T) {
+	handler := ETag(false)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.Error(w, "nope", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		io.WriteString(w, "data")
+	}))
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("If-None-Match", `"v1"`)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	if w.Code != http.StatusNotModified {
+		t.Errorf("status = %d, want 304 using handler ETag", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/missing", nil))
+	if w.Code != http.StatusNotFound || w.Header().Get("ETag") != "" {
+		t.Errorf("404 response: %d etag=%q", w.Code, w.Header().Get("ETag"))
+	}
+}
+
+func TestETag_SkipsUnsafeMethods(t *testing.T) {
+	handler := ETag(false)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("POST", "/", nil))
+	if w.Code != http.StatusCreated || w.Header().Get("ETag") != "" {
+		t.Errorf("POST response: %d etag=%q", w.Code, w.Header().Get("ETag"))
+	}
+}
@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/en9inerd/go-pkgs/authtoken"
+)
+
+// JWT returns a middleware that requires a valid bearer token.
+// Verified claims are stored in the request context and can be retrieved
+// with authtoken.FromContext. Requests without a valid token receive 401
+// with a WWW-Authenticate challenge.
+func JWT(verifier *authtoken.Verifier) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, err := verifier.VerifyRequest(r)
+			if err != nil {
+				if errors.Is(err, authtoken.ErrMissingToken) {
+					w.Header().Set("WWW-Authenticate", `Bearer`)
+				} else {
+					w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+				}
+				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(authtoken.NewContext(r.Context(), claims)))
+		})
+	}
+}
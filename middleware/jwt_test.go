@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/en9inerd/go-pkgs/authtoken"
+)
+
+func TestJWT(t *testing.T) {
+	key, err := authtoken.NewHMACKey("k", authtoken.HS256, []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := authtoken.NewKeySet(key)
+	verifier := authtoken.NewVerifier(authtoken.VerifierConfig{Keys: keys})
+
+	handler := JWT(verifier)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := authtoken.FromContext(r.Context())
+		if !ok {
+			t.Error("claims not found in context")
+			return
+		}
+		w.Write([]byte(claims.Subject))
+	}))
+
+	token, _ := keys.Sign(authtoken.Claims{Subject: "alice"})
+
+	tests := []struct {
+		name       string
+		auth       string
+		wantStatus int
+		wantBody   string
+	}{
+		{"valid", "Bearer " + token, http.StatusOK, "alice"},
+		{"missing", "", http.StatusUnauthorized, ""},
+		{"invalid", "Bearer not.a.token", http.StatusUnauthorized, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.auth != "" {
+				req.Header.Set("Authorization", tt.auth)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+				t.Error("expected WWW-Authenticate header")
+			}
+			if tt.wantBody != "" && w.Body.String() != tt.wantBody {
+				t.Errorf("body = %q, want %q", w.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
@@ -2,49 +2,40 @@ package middleware
 
 import (
 	"log/slog"
-	"net"
 	"net/http"
 	"time"
 )
 
-type statusWriter struct {
-	http.ResponseWriter
-	status int
-}
-
-func (w *statusWriter) WriteHeader(status int) {
-	w.status = status
-	w.ResponseWriter.WriteHeader(status)
-}
-
-func (w *statusWriter) Unwrap() http.ResponseWriter {
-	return w.ResponseWriter
-}
-
 // Logger middleware logs each request with method, path, client IP,
-// response status code, and duration.
+// response status code, and duration. If RequestLogger runs before it, the
+// request-scoped logger is used instead of logger, so the record carries the
+// request ID and route.
 func Logger(logger *slog.Logger) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+			ww := WrapResponseWriter(w)
 			start := time.Now()
 
-			next.ServeHTTP(sw, r)
+			next.ServeHTTP(ww, r)
 
 			duration := time.Since(start)
 
-			// RemoteAddr may be a bare IP (no port) if RealIP middleware
-			// already processed the request, or host:port from the stdlib.
-			remoteIP := r.RemoteAddr
-			if host, _, err := net.SplitHostPort(remoteIP); err == nil {
-				remoteIP = host
+			if scoped, ok := scopedLogger(r); ok {
+				// the scoped logger already carries the client IP
+				scoped.Info("http request",
+					"method", r.Method,
+					"path", r.URL.Path,
+					"status", ww.Status(),
+					"duration", duration,
+				)
+				return
 			}
 
 			logger.Info("http request",
 				"method", r.Method,
 				"path", r.URL.Path,
-				"ip", remoteIP,
-				"status", sw.status,
+				"ip", remoteIP(r),
+				"status", ww.Status(),
 				"duration", duration,
 			)
 		})
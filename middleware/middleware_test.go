@@ -97,7 +97,7 @@ func TestRecoverer_PanicAfterWrite(t *testing.T) {
 }
 
 func TestLogger_StatusWriterUnwrap(t *testing.T) {
-	sw := &statusWriter{ResponseWriter: httptest.NewRecorder(), status: http.StatusOK}
+	sw := WrapResponseWriter(httptest.NewRecorder())
 	if sw.Unwrap() == nil {
 		t.Error("Unwrap() should return the underlying ResponseWriter")
 	}
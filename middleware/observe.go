@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/en9inerd/go-pkgs/observability"
+)
+
+// Observe middleware instruments each request with the given observability
+// configuration: a server span, request count and duration metrics labelled
+// by method, route pattern and status, and a log record like Logger's,
+// written with the request-scoped logger if RequestLogger runs before it.
+// The route label is the matched ServeMux pattern, so unmatched paths do
+// not create unbounded label values.
+func Observe(obs *observability.Config) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, span := obs.StartSpan(r.Context(), "http.server.request",
+				slog.String("http.method", r.Method),
+				slog.String("http.path", r.URL.Path),
+			)
+			defer span.End()
+			r = r.WithContext(ctx)
+
+			ww := WrapResponseWriter(w)
+			start := time.Now()
+
+			next.ServeHTTP(ww, r)
+
+			duration := time.Since(start)
+			route := r.Pattern
+			if route == "" {
+				route = "unmatched"
+			}
+			status := strconv.Itoa(ww.Status())
+
+			span.SetAttributes(slog.String("http.route", route), slog.Int("http.status_code", ww.Status()))
+			obs.Count("http_server_requests_total", "method", r.Method, "route", route, "status", status)
+			obs.ObserveDuration("http_server_request_duration_seconds", duration, "method", r.Method, "route", route)
+
+			if scoped, ok := scopedLogger(r); ok && obs.Log() != nil {
+				scoped.Info("http request",
+					"method", r.Method,
+					"path", r.URL.Path,
+					"status", ww.Status(),
+					"duration", duration,
+				)
+			} else if logger := obs.Log(); logger != nil {
+				logger.Info("http request",
+					"method", r.Method,
+					"path", r.URL.Path,
+					"ip", remoteIP(r),
+					"status", ww.Status(),
+					"duration", duration,
+				)
+			}
+		})
+	}
+}
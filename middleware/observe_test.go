@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/en9inerd/go-pkgs/observability/observabilitytest"
+)
+
+func TestObserve(t *testing.T) {
+	obs, metrics, tracer := observabilitytest.New()
+	logger, entries := captureLogger()
+	obs.Logger = logger
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	})
+	handler := Observe(obs)(mux)
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/users/42", nil))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/missing", nil))
+
+	if v := metrics.Value("http_server_requests_total", "method", "GET", "route", "GET /users/{id}", "status", "201"); v != 1 {
+		t.Errorf("requests_total for matched route = %v, want 1", v)
+	}
+	if v := metrics.Value("http_server_requests_total", "method", "GET", "route", "unmatched", "status", "404"); v != 1 {
+		t.Errorf("requests_total for unmatched route = %v, want 1", v)
+	}
+	if s := metrics.Samples("http_server_request_duration_seconds", "method", "GET", "route", "GET /users/{id}"); len(s) != 1 {
+		t.Errorf("expected one duration sample, got %d", len(s))
+	}
+
+	spans := tracer.Spans("http.server.request")
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+	if v, _ := spans[0].Attr("http.route"); v.String() != "GET /users/{id}" {
+		t.Errorf("http.route = %q", v.String())
+	}
+
+	if len(*entries) != 2 || (*entries)[0].Status != http.StatusCreated {
+		t.Errorf("unexpected log entries: %+v", *entries)
+	}
+}
+
+func TestObserve_NilConfig(t *testing.T) {
+	handler := Observe(nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if w.Code != http.StatusNoContent {
+		t.Errorf("status = %d, want 204", w.Code)
+	}
+}
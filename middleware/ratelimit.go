@@ -1,10 +1,10 @@
 package middleware
 
 import (
+	"math"
 	"net"
 	"net/http"
-	"sync"
-	"time"
+	"strconv"
 
 	"github.com/en9inerd/go-pkgs/ratelimit"
 )
@@ -16,45 +16,9 @@ type RateLimitConfig struct {
 	// Burst is the maximum number of requests allowed in a burst above the
 	// sustained rate. When zero, defaults to max(1, int(RPS)).
 	Burst int
-}
-
-type ipEntry struct {
-	bucket   *ratelimit.TokenBucket
-	lastSeen time.Time
-}
-
-type ipStore struct {
-	mu      sync.Mutex
-	entries map[string]*ipEntry
-	rps     float64
-	burst   float64
-}
-
-func (s *ipStore) allow(ip string) bool {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	e, ok := s.entries[ip]
-	if !ok {
-		e = &ipEntry{bucket: ratelimit.NewTokenBucket(s.burst, s.rps)}
-		s.entries[ip] = e
-	}
-	e.lastSeen = time.Now()
-	return e.bucket.Allow()
-}
-
-func (s *ipStore) cleanup() {
-	t := time.NewTicker(time.Minute)
-	defer t.Stop()
-	for range t.C {
-		s.mu.Lock()
-		for ip, e := range s.entries {
-			if time.Since(e.lastSeen) > 3*time.Minute {
-				delete(s.entries, ip)
-			}
-		}
-		s.mu.Unlock()
-	}
+	// Headers adds X-RateLimit-* and RateLimit-* quota headers to every
+	// response (see ratelimit.SetHeaders).
+	Headers bool
 }
 
 // extractIP handles both "host:port" and bare IP formats. The latter appears
@@ -68,24 +32,29 @@ func extractIP(addr string) string {
 
 // RateLimit returns middleware that enforces per-IP rate limiting using a token
 // bucket algorithm. Each unique client IP gets its own bucket. Stale entries
-// are cleaned up automatically.
+// are cleaned up automatically. Rejected requests get 429 Too Many Requests
+// with a Retry-After header.
 func RateLimit(cfg RateLimitConfig) func(http.Handler) http.Handler {
 	burst := cfg.Burst
 	if burst <= 0 {
 		burst = max(1, int(cfg.RPS))
 	}
 
-	store := &ipStore{
-		entries: make(map[string]*ipEntry),
-		rps:     cfg.RPS,
-		burst:   float64(burst),
-	}
-	go store.cleanup()
+	limiter := ratelimit.NewKeyed(float64(burst), cfg.RPS)
 
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			ip := extractIP(r.RemoteAddr)
-			if !store.allow(ip) {
+			allowed, quota := limiter.Allow(extractIP(r.RemoteAddr))
+			if cfg.Headers {
+				ratelimit.SetHeaders(w.Header(), quota)
+			}
+			if !allowed {
+				// time until the next token, not until the bucket is full
+				retryAfter := 1
+				if cfg.RPS > 0 {
+					retryAfter = max(1, int(math.Ceil(1/cfg.RPS)))
+				}
+				w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
 				http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
 				return
 			}
@@ -51,6 +51,44 @@ func TestRateLimit_RejectsOverBurst(t *testing.T) {
 	}
 }
 
+func TestRateLimit_Headers(t *testing.T) {
+	handler := RateLimit(RateLimitConfig{RPS: 1, Burst: 2, Headers: true})(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}),
+	)
+
+	var w *httptest.ResponseRecorder
+	for _, want := range []string{"1", "0", "0"} {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = "192.0.2.1:1234"
+		w = httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if got := w.Header().Get("X-RateLimit-Remaining"); got != want {
+			t.Errorf("X-RateLimit-Remaining = %q, want %q", got, want)
+		}
+		if got := w.Header().Get("RateLimit-Limit"); got != "2" {
+			t.Errorf("RateLimit-Limit = %q, want 2", got)
+		}
+	}
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "1" {
+		t.Errorf("rejected response: status %d, Retry-After %q", w.Code, w.Header().Get("Retry-After"))
+	}
+}
+
+func TestRateLimit_NoHeadersByDefault(t *testing.T) {
+	handler := RateLimit(RateLimitConfig{RPS: 1, Burst: 1})(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+	)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if got := w.Header().Get("X-RateLimit-Limit"); got != "" {
+		t.Errorf("X-RateLimit-Limit = %q without Headers", got)
+	}
+}
+
 func TestRateLimit_SeparateIPsIndependent(t *testing.T) {
 	handler := RateLimit(RateLimitConfig{RPS: 1, Burst: 1})(
 		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
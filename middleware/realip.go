@@ -1,10 +1,7 @@
 package middleware
 
 import (
-	"net"
 	"net/http"
-	"slices"
-	"strings"
 
 	"github.com/en9inerd/go-pkgs/realip"
 )
@@ -49,50 +46,10 @@ func RealIP(h http.Handler) http.Handler {
 //	// Trust all private IPs (safe if behind reverse proxy)
 //	middleware.RealIPWithTrustedProxies(nil, handler)
 func RealIPWithTrustedProxies(trustedProxies []string, h http.Handler) http.Handler {
-	var trustedNets []*net.IPNet
-	var trustedIPs []net.IP
-
-	for _, proxy := range trustedProxies {
-		if strings.Contains(proxy, "/") {
-			_, network, err := net.ParseCIDR(proxy)
-			if err == nil {
-				trustedNets = append(trustedNets, network)
-			}
-		} else {
-			ip := net.ParseIP(proxy)
-			if ip != nil {
-				trustedIPs = append(trustedIPs, ip)
-			}
-		}
-	}
+	trusted := realip.ParseTrusted(trustedProxies)
 
 	fn := func(w http.ResponseWriter, r *http.Request) {
-		remoteIPStr := r.RemoteAddr
-		if host, _, err := net.SplitHostPort(remoteIPStr); err == nil {
-			remoteIPStr = host
-		}
-		remoteIP := net.ParseIP(remoteIPStr)
-		if remoteIP == nil {
-			h.ServeHTTP(w, r)
-			return
-		}
-
-		isTrusted := false
-
-		if len(trustedProxies) == 0 {
-			isTrusted = realip.IsPrivateIP(remoteIP)
-		} else {
-			isTrusted = slices.ContainsFunc(trustedIPs, func(trustedIP net.IP) bool {
-				return remoteIP.Equal(trustedIP)
-			})
-			if !isTrusted {
-				isTrusted = slices.ContainsFunc(trustedNets, func(network *net.IPNet) bool {
-					return network.Contains(remoteIP)
-				})
-			}
-		}
-
-		if isTrusted {
+		if trusted.Contains(r.RemoteAddr) {
 			if rip, err := realip.Get(r); err == nil {
 				r.RemoteAddr = rip
 			}
@@ -0,0 +1,125 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+)
+
+// RequestIDHeader is the header RequestLogger reads an incoming request ID
+// from and echoes the request ID in.
+const RequestIDHeader = "X-Request-Id"
+
+type requestLoggerKey struct{}
+
+type requestIDKey struct{}
+
+// RequestLogger middleware derives a request-scoped logger from base with
+// request_id, route and ip attributes and stores it in the request context,
+// where handlers retrieve it with LoggerFromContext.
+//
+// The request ID is taken from the X-Request-Id header if it is present and
+// well formed, and generated otherwise; it is set on the response as well.
+// The route is the matched ServeMux pattern; it is looked up when the logger
+// is retrieved, so it is filled in even when RequestLogger wraps the whole mux.
+//
+// Logger, Recoverer and Observe log with the request-scoped logger when it
+// is present, so place RequestLogger before them (and after RealIP).
+func RequestLogger(base *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := r.Header.Get(RequestIDHeader)
+			if !validRequestID(id) {
+				id = rand.Text()
+			}
+			w.Header().Set(RequestIDHeader, id)
+
+			rl := &requestLog{
+				base: base.With(slog.String("request_id", id), slog.String("ip", remoteIP(r))),
+			}
+			ctx := context.WithValue(r.Context(), requestIDKey{}, id)
+			r = r.WithContext(context.WithValue(ctx, requestLoggerKey{}, rl))
+			// the mux sets Pattern on this request, so the logger can see it
+			rl.r = r
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// LoggerFromContext returns the request-scoped logger stored by
+// RequestLogger, or slog.Default() if there is none.
+func LoggerFromContext(ctx context.Context) *slog.Logger {
+	if rl, ok := ctx.Value(requestLoggerKey{}).(*requestLog); ok {
+		return rl.logger()
+	}
+	return slog.Default()
+}
+
+// RequestIDFromContext returns the request ID assigned by RequestLogger,
+// or an empty string if there is none.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// scopedLogger returns the logger stored by RequestLogger, if any.
+func scopedLogger(r *http.Request) (*slog.Logger, bool) {
+	rl, ok := r.Context().Value(requestLoggerKey{}).(*requestLog)
+	if !ok {
+		return nil, false
+	}
+	return rl.logger(), true
+}
+
+// requestLog is the request-scoped logger state stored by RequestLogger.
+type requestLog struct {
+	base *slog.Logger
+	r    *http.Request
+
+	mu      sync.Mutex
+	pattern string
+	routed  *slog.Logger
+}
+
+// logger returns base with the route attribute once the request is routed.
+func (rl *requestLog) logger() *slog.Logger {
+	pattern := rl.r.Pattern
+	if pattern == "" {
+		return rl.base
+	}
+
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	if rl.routed == nil || rl.pattern != pattern {
+		rl.pattern = pattern
+		rl.routed = rl.base.With(slog.String("route", pattern))
+	}
+	return rl.routed
+}
+
+// validRequestID reports whether an incoming request ID is safe to log.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if c := id[i]; c < 0x21 || c > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// remoteIP returns the client IP of r without the port.
+// RemoteAddr may be a bare IP (no port) if RealIP middleware
+// already processed the request, or host:port from the stdlib.
+func remoteIP(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func jsonLogger() (*slog.Logger, *bytes.Buffer) {
+	var buf bytes.Buffer
+	return slog.New(slog.NewJSONHandler(&buf, nil)), &buf
+}
+
+func decodeLogLines(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var lines []map[string]any
+	for line := range strings.Lines(buf.String()) {
+		var m map[string]any
+		if err := json.Unmarshal([]byte(line), &m); err != nil {
+			t.Fatalf("decode log line %q: %v", line, err)
+		}
+		lines = append(lines, m)
+	}
+	return lines
+}
+
+func TestRequestLogger(t *testing.T) {
+	base, buf := jsonLogger()
+
+	var ctxID string
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		ctxID = RequestIDFromContext(r.Context())
+		LoggerFromContext(r.Context()).Info("loading user")
+	})
+	handler := RequestLogger(base)(Logger(slog.New(slog.DiscardHandler))(mux))
+
+	req := httptest.NewRequest(http.MethodGet, "/users/42", nil)
+	req.RemoteAddr = "192.0.2.1:1234"
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	id := rec.Header().Get(RequestIDHeader)
+	if id == "" || id != ctxID {
+		t.Fatalf("response ID %q, context ID %q", id, ctxID)
+	}
+
+	lines := decodeLogLines(t, buf)
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 log lines, got %d: %s", len(lines), buf)
+	}
+	for _, line := range lines {
+		if line["request_id"] != id || line["route"] != "GET /users/{id}" || line["ip"] != "192.0.2.1" {
+			t.Errorf("log line missing request attributes: %v", line)
+		}
+	}
+	if lines[1]["msg"] != "http request" || lines[1]["status"] != float64(200) {
+		t.Errorf("Logger did not use the request-scoped logger: %v", lines[1])
+	}
+}
+
+func TestRequestLogger_IncomingID(t *testing.T) {
+	handler := RequestLogger(slog.New(slog.DiscardHandler))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	tests := []struct {
+		header string
+		keep   bool
+	}{
+		{"abc-123", true},
+		{"", false},
+		{"bad id", false},
+		{"bad\nid", false},
+		{strings.Repeat("a", 129), false},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(RequestIDHeader, tt.header)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		got := rec.Header().Get(RequestIDHeader)
+		if got == "" || (got == tt.header) != tt.keep {
+			t.Errorf("header %q: response ID = %q, keep = %v", tt.header, got, tt.keep)
+		}
+	}
+}
+
+func TestRequestLogger_Recoverer(t *testing.T) {
+	base, buf := jsonLogger()
+	handler := RequestLogger(base)(Recoverer(slog.New(slog.DiscardHandler), false)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	lines := decodeLogLines(t, buf)
+	if len(lines) != 1 || lines[0]["msg"] != "panic recovered" || lines[0]["request_id"] != rec.Header().Get(RequestIDHeader) {
+		t.Errorf("unexpected log output: %s", buf)
+	}
+}
+
+func TestLoggerFromContext_Default(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if LoggerFromContext(req.Context()) != slog.Default() {
+		t.Error("expected slog.Default() without RequestLogger")
+	}
+	if id := RequestIDFromContext(req.Context()); id != "" {
+		t.Errorf("RequestIDFromContext = %q, want empty", id)
+	}
+}
@@ -0,0 +1,148 @@
+package middleware
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+)
+
+// ResponseWriter wraps an http.ResponseWriter to record the response status
+// and size for middleware, without hiding what the underlying writer can do.
+// It implements http.Flusher, http.Hijacker, http.Pusher and io.ReaderFrom
+// by delegating to the wrapped writer, so streaming (server-sent events),
+// connection upgrades (WebSocket) and the sendfile path of io.Copy keep
+// working through any number of middleware layers. When the wrapped writer
+// lacks a capability, Flush is a no-op, Hijack and Push return
+// http.ErrNotSupported, and ReadFrom falls back to a plain copy.
+// Unwrap exposes the wrapped writer to http.ResponseController.
+//
+// Every middleware in this package that needs to observe a response uses it,
+// and custom middleware should too:
+//
+//	func audit(next http.Handler) http.Handler {
+//		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+//			ww := middleware.WrapResponseWriter(w)
+//			next.ServeHTTP(ww, r)
+//			log.Println(r.URL.Path, ww.Status(), ww.BytesWritten())
+//		})
+//	}
+type ResponseWriter struct {
+	http.ResponseWriter
+	status      int
+	bytes       int64
+	wroteHeader bool
+}
+
+// WrapResponseWriter returns w wrapped in a ResponseWriter.
+func WrapResponseWriter(w http.ResponseWriter) *ResponseWriter {
+	return &ResponseWriter{ResponseWriter: w}
+}
+
+// Status returns the response status code. It is http.StatusOK when the
+// handler wrote a body without calling WriteHeader, and also when nothing
+// has been written yet, since that is what the server will send.
+func (w *ResponseWriter) Status() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
+
+// BytesWritten returns the number of body bytes written.
+func (w *ResponseWriter) BytesWritten() int64 {
+	return w.bytes
+}
+
+// WroteHeader reports whether the response header has been sent, after
+// which the status can no longer be changed.
+func (w *ResponseWriter) WroteHeader() bool {
+	return w.wroteHeader
+}
+
+// WriteHeader records the status and forwards it. Informational (1xx)
+// responses other than 101 Switching Protocols are forwarded without being
+// recorded, because a final status follows them.
+func (w *ResponseWriter) WriteHeader(status int) {
+	if status >= 100 && status < 200 && status != http.StatusSwitchingProtocols {
+		w.ResponseWriter.WriteHeader(status)
+		return
+	}
+	if !w.wroteHeader {
+		w.status = status
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Write records the implicit 200 status and the body size.
+func (w *ResponseWriter) Write(b []byte) (int, error) {
+	w.writeImplicitHeader()
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += int64(n)
+	return n, err
+}
+
+// ReadFrom lets io.Copy use the wrapped writer's io.ReaderFrom, which for
+// the server's own writer sends files with sendfile.
+func (w *ResponseWriter) ReadFrom(r io.Reader) (int64, error) {
+	w.writeImplicitHeader()
+	var n int64
+	var err error
+	if rf, ok := w.ResponseWriter.(io.ReaderFrom); ok {
+		n, err = rf.ReadFrom(r)
+	} else {
+		// hide our own ReadFrom from io.Copy to avoid recursion
+		n, err = io.Copy(writerOnly{w.ResponseWriter}, r)
+	}
+	w.bytes += n
+	return n, err
+}
+
+// Flush sends any buffered data to the client.
+func (w *ResponseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		w.writeImplicitHeader()
+		f.Flush()
+	}
+}
+
+// Hijack lets the handler take over the connection. A successful hijack is
+// recorded as 101 Switching Protocols.
+func (w *ResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+	conn, rw, err := h.Hijack()
+	if err == nil && !w.wroteHeader {
+		w.status = http.StatusSwitchingProtocols
+		w.wroteHeader = true
+	}
+	return conn, rw, err
+}
+
+// Push initiates an HTTP/2 server push.
+func (w *ResponseWriter) Push(target string, opts *http.PushOptions) error {
+	if p, ok := w.ResponseWriter.(http.Pusher); ok {
+		return p.Push(target, opts)
+	}
+	return http.ErrNotSupported
+}
+
+// Unwrap returns the wrapped writer, for http.ResponseController.
+func (w *ResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *ResponseWriter) writeImplicitHeader() {
+	if !w.wroteHeader {
+		w.status = http.StatusOK
+		w.wroteHeader = true
+	}
+}
+
+// writerOnly hides every method of a writer but Write.
+type writerOnly struct {
+	io.Writer
+}
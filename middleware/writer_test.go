@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestResponseWriter_Status(t *testing.T) {
+	ww := WrapResponseWriter(httptest.NewRecorder())
+	if ww.Status() != http.StatusOK || ww.WroteHeader() {
+		t.Errorf("before writing: status %d, wroteHeader %v", ww.Status(), ww.WroteHeader())
+	}
+
+	ww.WriteHeader(http.StatusCreated)
+	ww.WriteHeader(http.StatusTeapot) // superfluous, ignored like the server does
+	ww.Write([]byte("hello"))
+
+	if ww.Status() != http.StatusCreated || !ww.WroteHeader() || ww.BytesWritten() != 5 {
+		t.Errorf("status %d, wroteHeader %v, bytes %d", ww.Status(), ww.WroteHeader(), ww.BytesWritten())
+	}
+}
+
+func TestResponseWriter_Informational(t *testing.T) {
+	ww := WrapResponseWriter(httptest.NewRecorder())
+	ww.WriteHeader(http.StatusEarlyHints)
+	if ww.WroteHeader() || ww.Status() != http.StatusOK {
+		t.Errorf("1xx response recorded as final: status %d", ww.Status())
+	}
+}
+
+// readerFromRecorder records whether io.Copy reached its ReadFrom.
+type readerFromRecorder struct {
+	*httptest.ResponseRecorder
+	readFrom bool
+}
+
+func (r *readerFromRecorder) ReadFrom(src io.Reader) (int64, error) {
+	r.readFrom = true
+	return io.Copy(r.ResponseRecorder, src)
+}
+
+func TestResponseWriter_ReadFrom(t *testing.T) {
+	inner := &readerFromRecorder{ResponseRecorder: httptest.NewRecorder()}
+	ww := WrapResponseWriter(WrapResponseWriter(inner))
+
+	n, err := io.Copy(ww, struct{ io.Reader }{strings.NewReader("file contents")})
+	if err != nil || n != 13 {
+		t.Fatalf("io.Copy = %d, %v", n, err)
+	}
+	if !inner.readFrom {
+		t.Error("io.Copy did not reach the wrapped ReaderFrom")
+	}
+	if ww.BytesWritten() != 13 || ww.Status() != http.StatusOK || inner.Body.String() != "file contents" {
+		t.Errorf("bytes %d, status %d, body %q", ww.BytesWritten(), ww.Status(), inner.Body.String())
+	}
+
+	// without a ReaderFrom underneath it falls back to a plain copy
+	plain := WrapResponseWriter(httptest.NewRecorder())
+	if n, err := io.Copy(plain, strings.NewReader("abc")); err != nil || n != 3 {
+		t.Errorf("fallback io.Copy = %d, %v", n, err)
+	}
+}
+
+func TestResponseWriter_Flush(t *testing.T) {
+	rec := httptest.NewRecorder()
+	ww := WrapResponseWriter(rec)
+
+	f, ok := http.ResponseWriter(ww).(http.Flusher)
+	if !ok {
+		t.Fatal("ResponseWriter does not implement http.Flusher")
+	}
+	f.Flush()
+	if !rec.Flushed || !ww.WroteHeader() {
+		t.Errorf("flushed %v, wroteHeader %v", rec.Flushed, ww.WroteHeader())
+	}
+}
+
+type hijackRecorder struct {
+	*httptest.ResponseRecorder
+}
+
+func (hijackRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	c1, _ := net.Pipe()
+	return c1, nil, nil
+}
+
+func TestResponseWriter_Hijack(t *testing.T) {
+	ww := WrapResponseWriter(hijackRecorder{httptest.NewRecorder()})
+	conn, _, err := ww.Hijack()
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+	if ww.Status() != http.StatusSwitchingProtocols {
+		t.Errorf("status after hijack = %d, want 101", ww.Status())
+	}
+
+	unsupported := WrapResponseWriter(httptest.NewRecorder())
+	if _, _, err := unsupported.Hijack(); err != http.ErrNotSupported {
+		t.Errorf("Hijack = %v, want http.ErrNotSupported", err)
+	}
+	if err := unsupported.Push("/style.css", nil); err != http.ErrNotSupported {
+		t.Errorf("Push = %v, want http.ErrNotSupported", err)
+	}
+}
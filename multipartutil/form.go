@@ -0,0 +1,184 @@
+package multipartutil
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/en9inerd/go-pkgs/validator"
+)
+
+// File is an uploaded file held in memory or in a temporary file.
+type File struct {
+	// FieldName is the form field name.
+	FieldName string
+
+	// FileName is the client-supplied file name.
+	FileName string
+
+	// ContentType is the sniffed content type.
+	ContentType string
+
+	// Size is the file size in bytes.
+	Size int64
+
+	data []byte
+	path string
+}
+
+// Open returns a reader for the file contents.
+func (f *File) Open() (io.ReadCloser, error) {
+	if f.path != "" {
+		return os.Open(f.path)
+	}
+	return io.NopCloser(bytes.NewReader(f.data)), nil
+}
+
+// InMemory reports whether the file contents are held in memory.
+func (f *File) InMemory() bool { return f.path == "" }
+
+// Remove deletes the temporary file backing f, if any.
+func (f *File) Remove() error {
+	if f.path == "" {
+		return nil
+	}
+	err := os.Remove(f.path)
+	f.path = ""
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// Form is a fully parsed multipart form.
+type Form struct {
+	Values url.Values
+	Files  map[string][]*File
+}
+
+// File returns the first file uploaded under name, or nil.
+func (f *Form) File(name string) *File {
+	if files := f.Files[name]; len(files) > 0 {
+		return files[0]
+	}
+	return nil
+}
+
+// RemoveAll deletes all temporary files created for the form.
+func (f *Form) RemoveAll() error {
+	var errs []error
+	for _, files := range f.Files {
+		for _, file := range files {
+			errs = append(errs, file.Remove())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Parse reads the whole multipart form. Files larger than cfg.MemoryThreshold
+// are written to temporary files; callers must call Form.RemoveAll when done.
+// On error, any temporary files already created are removed.
+func Parse(r *http.Request, cfg Config) (*Form, error) {
+	mr, err := NewReader(r, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	form := &Form{Values: make(url.Values), Files: make(map[string][]*File)}
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			return form, nil
+		}
+		if err != nil {
+			form.RemoveAll()
+			return nil, err
+		}
+
+		if !part.IsFile() {
+			value, err := io.ReadAll(part)
+			if err != nil {
+				form.RemoveAll()
+				return nil, fmt.Errorf("read field %q: %w", part.FieldName, err)
+			}
+			form.Values.Add(part.FieldName, string(value))
+			continue
+		}
+
+		file, err := storeFile(part, mr.cfg)
+		if err != nil {
+			form.RemoveAll()
+			return nil, fmt.Errorf("read file %q: %w", part.FieldName, err)
+		}
+		form.Files[part.FieldName] = append(form.Files[part.FieldName], file)
+	}
+}
+
+// storeFile buffers a file part in memory up to the threshold and spills
+// the remainder to a temporary file.
+func storeFile(part *Part, cfg Config) (*File, error) {
+	file := &File{FieldName: part.FieldName, FileName: part.FileName, ContentType: part.ContentType}
+
+	var buf bytes.Buffer
+	n, err := io.CopyN(&buf, part, cfg.MemoryThreshold+1)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	if n <= cfg.MemoryThreshold {
+		file.data = buf.Bytes()
+		file.Size = n
+		return file, nil
+	}
+
+	tmp, err := os.CreateTemp(cfg.TempDir, "multipart-")
+	if err != nil {
+		return nil, err
+	}
+	file.path = tmp.Name()
+
+	size, err := io.Copy(tmp, io.MultiReader(&buf, part))
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		file.Remove()
+		return nil, err
+	}
+	file.Size = size
+	return file, nil
+}
+
+// FileRules describes constraints for uploaded files, checked with the
+// validator package's file rules.
+type FileRules struct {
+	// MaxSize is the maximum file size in bytes. Zero means no limit.
+	MaxSize int64
+
+	// AllowedTypes lists permitted sniffed content types, e.g. "image/*".
+	// Empty means any type.
+	AllowedTypes []string
+
+	// AllowedExtensions lists permitted file name extensions, e.g. ".png".
+	// Empty means any extension.
+	AllowedExtensions []string
+}
+
+// Check adds a field error to v, keyed by the file's field name, for each rule f violates.
+func (rules FileRules) Check(v *validator.Validator, f *File) {
+	if rules.MaxSize > 0 {
+		v.CheckField(validator.MaxFileSize(f.Size, rules.MaxSize), f.FieldName,
+			fmt.Sprintf("file must not be larger than %d bytes", rules.MaxSize))
+	}
+	if len(rules.AllowedTypes) > 0 {
+		v.CheckField(validator.PermittedMIMEType(f.ContentType, rules.AllowedTypes...), f.FieldName,
+			fmt.Sprintf("file type %s is not permitted", f.ContentType))
+	}
+	if len(rules.AllowedExtensions) > 0 {
+		v.CheckField(validator.PermittedFileExtension(f.FileName, rules.AllowedExtensions...), f.FieldName,
+			"file extension is not permitted")
+	}
+}
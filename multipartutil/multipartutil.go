@@ -0,0 +1,243 @@
+// Package multipartutil provides streaming helpers for multipart/form-data requests.
+//
+// Unlike http.Request.ParseMultipartForm, the helpers here enforce per-part
+// size limits while streaming, sniff the real content type of uploaded files,
+// and spill large files to temporary files that are cleaned up on error.
+// They complement middleware.SizeLimit, which bounds the request as a whole.
+//
+// Streaming usage:
+//
+//	mr, err := multipartutil.NewReader(r, multipartutil.Config{MaxPartSize: 10 << 20})
+//	if err != nil {
+//		// not a multipart request
+//	}
+//	for {
+//		part, err := mr.NextPart()
+//		if err == io.EOF {
+//			break
+//		}
+//		if err != nil {
+//			return err
+//		}
+//		// part.ContentType is sniffed from the content for file parts
+//		io.Copy(dst, part)
+//	}
+//
+// Buffered usage with temp-file spillover:
+//
+//	form, err := multipartutil.Parse(r, multipartutil.Config{})
+//	if err != nil {
+//		return err
+//	}
+//	defer form.RemoveAll()
+package multipartutil
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+)
+
+var (
+	// ErrNotMultipart is returned when the request is not multipart/form-data.
+	ErrNotMultipart = errors.New("multipartutil: request is not multipart/form-data")
+	// ErrPartTooLarge is returned when a part exceeds its size limit.
+	ErrPartTooLarge = errors.New("multipartutil: part too large")
+	// ErrTooManyParts is returned when the request contains more than MaxParts parts.
+	ErrTooManyParts = errors.New("multipartutil: too many parts")
+)
+
+// sniffLen is the number of bytes inspected by http.DetectContentType.
+const sniffLen = 512
+
+// Config holds limits for multipart processing.
+type Config struct {
+	// MaxFileSize is the maximum size of a single file part in bytes.
+	// Default: 32 MiB
+	MaxFileSize int64
+
+	// MaxFieldSize is the maximum size of a single non-file field in bytes.
+	// Default: 1 MiB
+	MaxFieldSize int64
+
+	// MaxParts is the maximum number of parts in a request.
+	// Default: 1000
+	MaxParts int
+
+	// MemoryThreshold is the file size above which Parse spills file contents
+	// to a temporary file instead of keeping them in memory.
+	// Default: 1 MiB
+	MemoryThreshold int64
+
+	// TempDir is the directory for spilled files. Default: os.TempDir()
+	TempDir string
+}
+
+func (c *Config) setDefaults() {
+	if c.MaxFileSize == 0 {
+		c.MaxFileSize = 32 << 20
+	}
+	if c.MaxFieldSize == 0 {
+		c.MaxFieldSize = 1 << 20
+	}
+	if c.MaxParts == 0 {
+		c.MaxParts = 1000
+	}
+	if c.MemoryThreshold == 0 {
+		c.MemoryThreshold = 1 << 20
+	}
+}
+
+// Reader iterates over the parts of a multipart request.
+type Reader struct {
+	mr    *multipart.Reader
+	cfg   Config
+	count int
+	prev  *Part
+}
+
+// NewReader creates a streaming reader for a multipart/form-data request.
+func NewReader(r *http.Request, cfg Config) (*Reader, error) {
+	mediaType, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/form-data" {
+		return nil, ErrNotMultipart
+	}
+	boundary := params["boundary"]
+	if boundary == "" {
+		return nil, fmt.Errorf("%w: missing boundary", ErrNotMultipart)
+	}
+
+	cfg.setDefaults()
+	return &Reader{mr: multipart.NewReader(r.Body, boundary), cfg: cfg}, nil
+}
+
+// NextPart returns the next part, or io.EOF when there are no more parts.
+// The previous part is closed automatically.
+func (r *Reader) NextPart() (*Part, error) {
+	if r.prev != nil {
+		r.prev.Close()
+		r.prev = nil
+	}
+
+	p, err := r.mr.NextPart()
+	if err != nil {
+		return nil, err
+	}
+
+	r.count++
+	if r.count > r.cfg.MaxParts {
+		p.Close()
+		return nil, ErrTooManyParts
+	}
+
+	part := &Part{
+		FieldName: p.FormName(),
+		FileName:  p.FileName(),
+		Header:    p.Header,
+		raw:       p,
+	}
+
+	limit := r.cfg.MaxFieldSize
+	if part.IsFile() {
+		limit = r.cfg.MaxFileSize
+	}
+	lr := &limitReader{r: p, remaining: limit}
+
+	if part.IsFile() {
+		// sniff the real content type instead of trusting the client header
+		head := make([]byte, sniffLen)
+		n, err := io.ReadFull(lr, head)
+		if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+			p.Close()
+			return nil, err
+		}
+		head = head[:n]
+		part.ContentType = http.DetectContentType(head)
+		part.r = io.MultiReader(bytes.NewReader(head), lr)
+	} else {
+		part.ContentType = p.Header.Get("Content-Type")
+		part.r = lr
+	}
+
+	r.prev = part
+	return part, nil
+}
+
+// Each calls fn for every part of a multipart request.
+// Iteration stops at the first error returned by fn.
+func Each(r *http.Request, cfg Config, fn func(*Part) error) error {
+	mr, err := NewReader(r, cfg)
+	if err != nil {
+		return err
+	}
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := fn(part); err != nil {
+			return err
+		}
+	}
+}
+
+// Part is a single size-limited part of a multipart request.
+type Part struct {
+	// FieldName is the form field name.
+	FieldName string
+
+	// FileName is the client-supplied file name, empty for regular fields.
+	FileName string
+
+	// ContentType is the sniffed content type for file parts and the
+	// declared content type (possibly empty) for regular fields.
+	ContentType string
+
+	// Header holds the raw part headers.
+	Header textproto.MIMEHeader
+
+	raw *multipart.Part
+	r   io.Reader
+}
+
+// IsFile reports whether the part is a file upload.
+func (p *Part) IsFile() bool { return p.FileName != "" }
+
+// DeclaredContentType returns the content type sent by the client.
+func (p *Part) DeclaredContentType() string { return p.Header.Get("Content-Type") }
+
+// Read reads the part body. It returns ErrPartTooLarge once the part's limit is exceeded.
+func (p *Part) Read(b []byte) (int, error) { return p.r.Read(b) }
+
+// Close discards the rest of the part.
+func (p *Part) Close() error { return p.raw.Close() }
+
+// limitReader fails with ErrPartTooLarge instead of silently truncating.
+type limitReader struct {
+	r         io.Reader
+	remaining int64
+}
+
+func (l *limitReader) Read(b []byte) (int, error) {
+	if l.remaining < 0 {
+		return 0, ErrPartTooLarge
+	}
+	// read one extra byte to detect overflow
+	if int64(len(b)) > l.remaining+1 {
+		b = b[:l.remaining+1]
+	}
+	n, err := l.r.Read(b)
+	l.remaining -= int64(n)
+	if l.remaining < 0 {
+		return n + int(l.remaining), ErrPartTooLarge
+	}
+	return n, err
+}
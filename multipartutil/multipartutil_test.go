@@ -0,0 +1,187 @@
+package multipartutil
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/en9inerd/go-pkgs/validator"
+)
+
+var pngHeader = []byte("\x89PNG\r\n\x1a\n")
+
+// newMultipartRequest builds a request with one text field and one file.
+func newMultipartRequest(t *testing.T, fileContent []byte) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	if err := mw.WriteField("title", "holiday"); err != nil {
+		t.Fatal(err)
+	}
+	fw, err := mw.CreateFormFile("photo", "beach.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fw.Write(fileContent)
+	mw.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/upload", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req
+}
+
+func TestReader_StreamsPartsWithSniffedType(t *testing.T) {
+	content := append(pngHeader, bytes.Repeat([]byte{0}, 1000)...)
+	req := newMultipartRequest(t, content)
+
+	var fields, files int
+	err := Each(req, Config{}, func(p *Part) error {
+		data, err := io.ReadAll(p)
+		if err != nil {
+			return err
+		}
+		if p.IsFile() {
+			files++
+			if p.ContentType != "image/png" {
+				t.Errorf("ContentType = %q, want image/png", p.ContentType)
+			}
+			if !bytes.Equal(data, content) {
+				t.Errorf("file content mismatch: got %d bytes", len(data))
+			}
+		} else {
+			fields++
+			if string(data) != "holiday" {
+				t.Errorf("field value = %q", data)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fields != 1 || files != 1 {
+		t.Errorf("fields=%d files=%d, want 1/1", fields, files)
+	}
+}
+
+func TestReader_PartTooLarge(t *testing.T) {
+	req := newMultipartRequest(t, bytes.Repeat([]byte("x"), 2048))
+
+	err := Each(req, Config{MaxFileSize: 1024}, func(p *Part) error {
+		_, err := io.ReadAll(p)
+		return err
+	})
+	if !errors.Is(err, ErrPartTooLarge) {
+		t.Errorf("expected ErrPartTooLarge, got %v", err)
+	}
+}
+
+func TestReader_TooManyParts(t *testing.T) {
+	req := newMultipartRequest(t, []byte("data"))
+
+	err := Each(req, Config{MaxParts: 1}, func(p *Part) error { return nil })
+	if !errors.Is(err, ErrTooManyParts) {
+		t.Errorf("expected ErrTooManyParts, got %v", err)
+	}
+}
+
+func TestNewReader_NotMultipart(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("{}"))
+	req.Header.Set("Content-Type", "application/json")
+
+	if _, err := NewReader(req, Config{}); !errors.Is(err, ErrNotMultipart) {
+		t.Errorf("expected ErrNotMultipart, got %v", err)
+	}
+}
+
+func TestParse_SpillsToTempFile(t *testing.T) {
+	content := append(pngHeader, bytes.Repeat([]byte{1}, 4096)...)
+	req := newMultipartRequest(t, content)
+
+	form, err := Parse(req, Config{MemoryThreshold: 1024, TempDir: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if form.Values.Get("title") != "holiday" {
+		t.Errorf("title = %q", form.Values.Get("title"))
+	}
+
+	f := form.File("photo")
+	if f == nil {
+		t.Fatal("photo not found")
+	}
+	if f.InMemory() {
+		t.Error("expected file to spill to disk")
+	}
+	if f.Size != int64(len(content)) {
+		t.Errorf("Size = %d, want %d", f.Size, len(content))
+	}
+
+	rc, err := f.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(rc)
+	rc.Close()
+	if !bytes.Equal(data, content) {
+		t.Error("spilled content mismatch")
+	}
+
+	path := f.path
+	if err := form.RemoveAll(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("temp file not removed")
+	}
+}
+
+func TestParse_CleansUpOnError(t *testing.T) {
+	dir := t.TempDir()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	fw, _ := mw.CreateFormFile("a", "a.bin")
+	fw.Write(bytes.Repeat([]byte("a"), 2048))
+	fw, _ = mw.CreateFormFile("b", "b.bin")
+	fw.Write(bytes.Repeat([]byte("b"), 8192))
+	mw.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+
+	_, err := Parse(req, Config{MemoryThreshold: 1024, MaxFileSize: 4096, TempDir: dir})
+	if !errors.Is(err, ErrPartTooLarge) {
+		t.Fatalf("expected ErrPartTooLarge, got %v", err)
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 0 {
+		t.Errorf("expected temp dir to be empty, found %d files", len(entries))
+	}
+}
+
+func TestFileRules_Check(t *testing.T) {
+	f := &File{FieldName: "photo", FileName: "doc.pdf", ContentType: "application/pdf", Size: 2048}
+	rules := FileRules{MaxSize: 1024, AllowedTypes: []string{"image/*"}, AllowedExtensions: []string{".png"}}
+
+	v := &validator.Validator{}
+	rules.Check(v, f)
+
+	if got := len(v.FieldErrors["photo"]); got != 3 {
+		t.Errorf("expected 3 errors for photo, got %d: %v", got, v.FieldErrors)
+	}
+
+	ok := &File{FieldName: "photo", FileName: "a.png", ContentType: "image/png", Size: 10}
+	v = &validator.Validator{}
+	rules.Check(v, ok)
+	if !v.Valid() {
+		t.Errorf("expected valid file, got %v", v.FieldErrors)
+	}
+}
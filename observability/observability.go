@@ -0,0 +1,149 @@
+// Package observability provides a single configuration object for logging,
+// metrics and tracing that is accepted by longpoll, httpclient, middleware
+// and retry.
+//
+// Instrumenting a service built from these packages only requires building
+// one Config and handing the same value to each of them:
+//
+//	obs := &observability.Config{
+//		Logger:  logger,
+//		Metrics: promAdapter, // implements observability.Metrics
+//		Tracer:  otelAdapter, // implements observability.Tracer
+//	}
+//
+//	client := httpclient.NewWithConfig(httpclient.Config{Observability: obs})
+//	poller := longpoll.NewWithConfig(longpoll.Config{Observability: obs})
+//	strategy := retry.DefaultStrategy()
+//	strategy.Observability = obs
+//	r.Use(middleware.Observe(obs))
+//
+// Every field is optional and all methods are safe to call on a nil *Config,
+// so packages can instrument unconditionally.
+//
+// Labels are passed as alternating key/value strings, like slog attributes:
+//
+//	obs.Count("http_client_requests_total", "method", "GET", "status", "200")
+package observability
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// Metrics records numeric measurements. Implementations adapt it to a
+// metrics backend such as Prometheus or OpenTelemetry. Labels are
+// alternating key/value pairs.
+type Metrics interface {
+	// Add increments the counter name by delta.
+	Add(name string, delta float64, labels ...string)
+
+	// Observe records value in the histogram or summary name.
+	Observe(name string, value float64, labels ...string)
+
+	// Set sets the gauge name to value.
+	Set(name string, value float64, labels ...string)
+}
+
+// Tracer starts spans. Implementations adapt it to a tracing backend.
+type Tracer interface {
+	// Start starts a span named name as a child of any span in ctx and
+	// returns a context carrying the new span.
+	Start(ctx context.Context, name string, attrs ...slog.Attr) (context.Context, Span)
+}
+
+// Propagator is implemented by tracers that propagate trace context across
+// services. Instrumented clients call Inject to add the trace context of
+// ctx to outgoing request headers, e.g. as W3C traceparent and tracestate.
+type Propagator interface {
+	Inject(ctx context.Context, header http.Header)
+}
+
+// Span is a unit of traced work.
+type Span interface {
+	// SetAttributes adds attributes to the span.
+	SetAttributes(attrs ...slog.Attr)
+
+	// RecordError marks the span as failed with err.
+	RecordError(err error)
+
+	// End completes the span.
+	End()
+}
+
+// Config holds the logger, metrics recorder and tracer shared by all
+// instrumented packages.
+type Config struct {
+	// Logger receives log records. Nil disables logging.
+	Logger *slog.Logger
+
+	// Metrics receives measurements. Nil disables metrics.
+	Metrics Metrics
+
+	// Tracer creates spans. Nil disables tracing.
+	Tracer Tracer
+}
+
+// Log returns the configured logger, or nil.
+func (c *Config) Log() *slog.Logger {
+	if c == nil {
+		return nil
+	}
+	return c.Logger
+}
+
+// StartSpan starts a span using the configured tracer. Without a tracer it
+// returns ctx unchanged and a span that does nothing.
+func (c *Config) StartSpan(ctx context.Context, name string, attrs ...slog.Attr) (context.Context, Span) {
+	if c == nil || c.Tracer == nil {
+		return ctx, nopSpan{}
+	}
+	return c.Tracer.Start(ctx, name, attrs...)
+}
+
+// Inject adds the trace context of ctx to header if the configured tracer
+// implements Propagator.
+func (c *Config) Inject(ctx context.Context, header http.Header) {
+	if c == nil {
+		return
+	}
+	if p, ok := c.Tracer.(Propagator); ok {
+		p.Inject(ctx, header)
+	}
+}
+
+// Count increments the counter name by one.
+func (c *Config) Count(name string, labels ...string) {
+	if c == nil || c.Metrics == nil {
+		return
+	}
+	c.Metrics.Add(name, 1, labels...)
+}
+
+// Observe records value in the histogram name.
+func (c *Config) Observe(name string, value float64, labels ...string) {
+	if c == nil || c.Metrics == nil {
+		return
+	}
+	c.Metrics.Observe(name, value, labels...)
+}
+
+// ObserveDuration records d in seconds in the histogram name.
+func (c *Config) ObserveDuration(name string, d time.Duration, labels ...string) {
+	c.Observe(name, d.Seconds(), labels...)
+}
+
+// Gauge sets the gauge name to value.
+func (c *Config) Gauge(name string, value float64, labels ...string) {
+	if c == nil || c.Metrics == nil {
+		return
+	}
+	c.Metrics.Set(name, value, labels...)
+}
+
+type nopSpan struct{}
+
+func (nopSpan) SetAttributes(...slog.Attr) {}
+func (nopSpan) RecordError(error)          {}
+func (nopSpan) End()                       {}
@@ -0,0 +1,54 @@
+package observability_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/en9inerd/go-pkgs/observability"
+	"github.com/en9inerd/go-pkgs/observability/observabilitytest"
+)
+
+func TestConfig_NilSafe(t *testing.T) {
+	var obs *observability.Config
+
+	if obs.Log() != nil {
+		t.Error("expected nil logger")
+	}
+	ctx := context.Background()
+	gotCtx, span := obs.StartSpan(ctx, "op")
+	if gotCtx != ctx {
+		t.Error("expected context to be unchanged")
+	}
+	span.RecordError(errors.New("x"))
+	span.End()
+	obs.Count("c")
+	obs.Observe("h", 1)
+	obs.Gauge("g", 1)
+}
+
+func TestConfig_Records(t *testing.T) {
+	obs, metrics, tracer := observabilitytest.New()
+
+	_, span := obs.StartSpan(context.Background(), "op")
+	span.End()
+	obs.Count("requests_total", "status", "200", "method", "GET")
+	obs.Count("requests_total", "method", "GET", "status", "200")
+	obs.ObserveDuration("duration_seconds", 1500*time.Millisecond)
+	obs.Gauge("active", 3)
+
+	if v := metrics.Value("requests_total", "method", "GET", "status", "200"); v != 2 {
+		t.Errorf("requests_total = %v, want 2", v)
+	}
+	if s := metrics.Samples("duration_seconds"); len(s) != 1 || s[0] != 1.5 {
+		t.Errorf("duration samples = %v, want [1.5]", s)
+	}
+	if v := metrics.Value("active"); v != 3 {
+		t.Errorf("active = %v, want 3", v)
+	}
+	spans := tracer.Spans("op")
+	if len(spans) != 1 || !spans[0].Ended {
+		t.Errorf("expected one ended span, got %+v", spans)
+	}
+}
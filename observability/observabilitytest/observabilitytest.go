@@ -0,0 +1,170 @@
+// Package observabilitytest provides in-memory observability implementations
+// for asserting instrumentation in tests.
+package observabilitytest
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/en9inerd/go-pkgs/observability"
+)
+
+// Metrics records measurements in memory. It is safe for concurrent use.
+type Metrics struct {
+	mu      sync.Mutex
+	values  map[string]float64
+	samples map[string][]float64
+}
+
+// NewMetrics creates an empty Metrics recorder.
+func NewMetrics() *Metrics {
+	return &Metrics{values: make(map[string]float64), samples: make(map[string][]float64)}
+}
+
+// Add implements observability.Metrics.
+func (m *Metrics) Add(name string, delta float64, labels ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.values[key(name, labels)] += delta
+}
+
+// Observe implements observability.Metrics.
+func (m *Metrics) Observe(name string, value float64, labels ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	k := key(name, labels)
+	m.samples[k] = append(m.samples[k], value)
+}
+
+// Set implements observability.Metrics.
+func (m *Metrics) Set(name string, value float64, labels ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.values[key(name, labels)] = value
+}
+
+// Value returns the current value of a counter or gauge.
+func (m *Metrics) Value(name string, labels ...string) float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.values[key(name, labels)]
+}
+
+// Samples returns the values observed for a histogram.
+func (m *Metrics) Samples(name string, labels ...string) []float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return slices.Clone(m.samples[key(name, labels)])
+}
+
+// key builds a map key from a metric name and its labels, sorted by label name.
+func key(name string, labels []string) string {
+	pairs := make([]string, 0, len(labels)/2)
+	for i := 0; i+1 < len(labels); i += 2 {
+		pairs = append(pairs, labels[i]+"="+labels[i+1])
+	}
+	slices.Sort(pairs)
+	return name + "{" + strings.Join(pairs, ",") + "}"
+}
+
+// Span is a recorded span.
+type Span struct {
+	// ID numbers the spans of a Tracer from 1 in start order.
+	ID int
+
+	Name  string
+	Attrs []slog.Attr
+	Err   error
+	Ended bool
+
+	mu *sync.Mutex
+}
+
+// SetAttributes implements observability.Span.
+func (s *Span) SetAttributes(attrs ...slog.Attr) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Attrs = append(s.Attrs, attrs...)
+}
+
+// RecordError implements observability.Span.
+func (s *Span) RecordError(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Err = err
+}
+
+// End implements observability.Span.
+func (s *Span) End() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Ended = true
+}
+
+// Attr returns the value of the attribute key and whether it was set.
+func (s *Span) Attr(key string) (slog.Value, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, a := range slices.Backward(s.Attrs) {
+		if a.Key == key {
+			return a.Value, true
+		}
+	}
+	return slog.Value{}, false
+}
+
+// Tracer records spans in memory. It is safe for concurrent use.
+type Tracer struct {
+	mu    sync.Mutex
+	spans []*Span
+}
+
+// spanKey is the context key of the current span
+type spanKey struct{}
+
+// Start implements observability.Tracer. The returned context carries the
+// new span.
+func (t *Tracer) Start(ctx context.Context, name string, attrs ...slog.Attr) (context.Context, observability.Span) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s := &Span{Name: name, Attrs: attrs, ID: len(t.spans) + 1, mu: &t.mu}
+	t.spans = append(t.spans, s)
+	return context.WithValue(ctx, spanKey{}, s), s
+}
+
+// Inject implements observability.Propagator, setting a W3C traceparent
+// header whose parent ID is the ID of the span in ctx.
+func (t *Tracer) Inject(ctx context.Context, header http.Header) {
+	if s, ok := ctx.Value(spanKey{}).(*Span); ok {
+		header.Set("Traceparent", TraceParent(s.ID))
+	}
+}
+
+// TraceParent returns the traceparent header Inject sets for the span id.
+func TraceParent(id int) string {
+	return fmt.Sprintf("00-%032x-%016x-01", 1, id)
+}
+
+// Spans returns the spans started so far, optionally filtered by name.
+func (t *Tracer) Spans(name string) []*Span {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var out []*Span
+	for _, s := range t.spans {
+		if name == "" || s.Name == name {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// New returns an observability.Config wired to fresh in-memory recorders.
+func New() (*observability.Config, *Metrics, *Tracer) {
+	m, t := NewMetrics(), &Tracer{}
+	return &observability.Config{Metrics: m, Tracer: t}, m, t
+}
@@ -0,0 +1,326 @@
+// Package proxyutil provides a reverse proxy built on httputil.ReverseProxy
+// with defaults suited to services built from this module:
+//
+//   - X-Forwarded-* headers are set for the upstream, and incoming forwarding
+//     headers are only preserved when the peer is a trusted proxy (same trust
+//     rules as middleware.RealIPWithTrustedProxies)
+//   - Path prefix stripping and custom path rewriting
+//   - Failover across multiple upstreams when connecting fails
+//   - WebSocket and other protocol upgrades are passed through
+//
+// A Proxy is an http.Handler, so it can be mounted with the router:
+//
+//	p, err := proxyutil.New(proxyutil.Config{
+//		Upstreams:   []string{"http://10.0.0.5:8080", "http://10.0.0.6:8080"},
+//		StripPrefix: "/api",
+//	})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	r.Handle("/api/", p)
+package proxyutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/en9inerd/go-pkgs/realip"
+)
+
+// Config configures a Proxy.
+type Config struct {
+	// Upstreams are the backend base URLs, e.g. "http://10.0.0.5:8080".
+	// Requests go to the first upstream; if connecting fails, the next
+	// upstream is tried. At least one is required.
+	Upstreams []string
+
+	// StripPrefix is removed from the request path before forwarding.
+	StripPrefix string
+
+	// RewritePath optionally rewrites the path after StripPrefix is applied.
+	RewritePath func(path string) string
+
+	// PreserveHost sends the client's Host header to the upstream instead
+	// of the upstream's own host, for upstreams that route by virtual host
+	// or build absolute URLs from it. The original host is always passed in
+	// X-Forwarded-Host.
+	PreserveHost bool
+
+	// TrustedProxies lists IPs or CIDR blocks of proxies in front of this one.
+	// Incoming X-Forwarded-For/Host/Proto and X-Real-IP headers are preserved
+	// only when the peer is trusted; otherwise they are replaced.
+	// Empty trusts private IPs, matching middleware.RealIPWithTrustedProxies.
+	TrustedProxies []string
+
+	// Transport is the round tripper used to reach upstreams.
+	// Default: http.DefaultTransport
+	Transport http.RoundTripper
+
+	// FlushInterval is passed to httputil.ReverseProxy.
+	// Default: -1 (flush immediately, which is what streaming responses need)
+	FlushInterval time.Duration
+
+	// ModifyResponse is passed to httputil.ReverseProxy.
+	ModifyResponse func(*http.Response) error
+
+	// Logger is an optional logger for upstream failures.
+	Logger *slog.Logger
+}
+
+// Proxy is a reverse proxy handler.
+type Proxy struct {
+	rp        *httputil.ReverseProxy
+	upstreams []*url.URL
+	trusted   *realip.TrustedProxies
+	cfg       Config
+}
+
+// New creates a Proxy from cfg.
+func New(cfg Config) (*Proxy, error) {
+	if len(cfg.Upstreams) == 0 {
+		return nil, errors.New("proxyutil: at least one upstream is required")
+	}
+
+	upstreams := make([]*url.URL, 0, len(cfg.Upstreams))
+	for _, raw := range cfg.Upstreams {
+		u, err := url.Parse(raw)
+		if err != nil {
+			return nil, fmt.Errorf("proxyutil: invalid upstream %q: %w", raw, err)
+		}
+		if u.Scheme == "" || u.Host == "" {
+			return nil, fmt.Errorf("proxyutil: upstream %q must be an absolute URL", raw)
+		}
+		upstreams = append(upstreams, u)
+	}
+
+	if cfg.Transport == nil {
+		cfg.Transport = http.DefaultTransport
+	}
+	if cfg.FlushInterval == 0 {
+		cfg.FlushInterval = -1
+	}
+
+	p := &Proxy{
+		upstreams: upstreams,
+		trusted:   realip.ParseTrusted(cfg.TrustedProxies),
+		cfg:       cfg,
+	}
+	p.rp = &httputil.ReverseProxy{
+		Rewrite:        p.rewrite,
+		Transport:      &failoverTransport{next: cfg.Transport, upstreams: upstreams, logger: cfg.Logger},
+		FlushInterval:  cfg.FlushInterval,
+		ModifyResponse: cfg.ModifyResponse,
+		ErrorHandler:   p.errorHandler,
+	}
+	return p, nil
+}
+
+// ServeHTTP implements http.Handler.
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p.rp.ServeHTTP(w, r)
+}
+
+// rewrite prepares the outbound request for the first upstream.
+func (p *Proxy) rewrite(pr *httputil.ProxyRequest) {
+	path := pr.In.URL.Path
+	if p.cfg.StripPrefix != "" {
+		path = strings.TrimPrefix(path, p.cfg.StripPrefix)
+		if !strings.HasPrefix(path, "/") {
+			path = "/" + path
+		}
+	}
+	if p.cfg.RewritePath != nil {
+		path = p.cfg.RewritePath(path)
+	}
+	pr.Out.URL.Path = path
+	pr.Out.URL.RawPath = ""
+
+	// remember the upstream-relative URL so failover can retarget it
+	rel := *pr.Out.URL
+	pr.Out = pr.Out.WithContext(context.WithValue(pr.Out.Context(), relURLKey{}, &rel))
+	pr.SetURL(p.upstreams[0])
+	if p.cfg.PreserveHost {
+		pr.Out.Host = pr.In.Host
+	}
+
+	p.setForwarded(pr)
+}
+
+// setForwarded sets X-Forwarded-* and X-Real-IP headers on the outbound request.
+func (p *Proxy) setForwarded(pr *httputil.ProxyRequest) {
+	clientIP := pr.In.RemoteAddr
+	if host, _, err := net.SplitHostPort(clientIP); err == nil {
+		clientIP = host
+	}
+
+	trusted := p.trusted.Contains(pr.In.RemoteAddr)
+
+	forwardedFor := clientIP
+	if prior := pr.In.Header.Values("X-Forwarded-For"); trusted && len(prior) > 0 {
+		forwardedFor = strings.Join(prior, ", ") + ", " + clientIP
+	}
+	pr.Out.Header.Set("X-Forwarded-For", forwardedFor)
+
+	host, proto := pr.In.Host, "http"
+	if pr.In.TLS != nil {
+		proto = "https"
+	}
+	realIP := clientIP
+	if trusted {
+		if h := pr.In.Header.Get("X-Forwarded-Host"); h != "" {
+			host = h
+		}
+		if fp := pr.In.Header.Get("X-Forwarded-Proto"); fp != "" {
+			proto = fp
+		}
+		if ip, err := realip.Get(pr.In); err == nil {
+			realIP = ip
+		}
+	}
+	pr.Out.Header.Set("X-Forwarded-Host", host)
+	pr.Out.Header.Set("X-Forwarded-Proto", proto)
+	pr.Out.Header.Set("X-Real-IP", realIP)
+}
+
+func (p *Proxy) errorHandler(w http.ResponseWriter, r *http.Request, err error) {
+	if p.cfg.Logger != nil {
+		p.cfg.Logger.Error("proxy upstream error", "method", r.Method, "path", r.URL.Path, "error", err)
+	}
+	w.WriteHeader(http.StatusBadGateway)
+}
+
+// relURLKey is the context key for the upstream-relative request URL.
+type relURLKey struct{}
+
+// failoverTransport retries requests against the next upstream when
+// connecting fails. Requests are only retried while their body is unread,
+// so no request is ever sent twice, and the transport closing the body of a
+// failed attempt is deferred so the next attempt can still send it.
+type failoverTransport struct {
+	next      http.RoundTripper
+	upstreams []*url.URL
+	logger    *slog.Logger
+}
+
+func (t *failoverTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rel, _ := req.Context().Value(relURLKey{}).(*url.URL)
+	if rel == nil || len(t.upstreams) == 1 {
+		return t.next.RoundTrip(req)
+	}
+
+	var body *replayBody
+	if req.Body != nil && req.Body != http.NoBody {
+		body = &replayBody{ReadCloser: req.Body}
+		defer body.release()
+	}
+
+	var lastErr error
+	for i, upstream := range t.upstreams {
+		out := req.Clone(req.Context())
+		if body != nil {
+			body.hold()
+			out.Body = body
+		}
+		if i > 0 {
+			out.URL = joinURL(upstream, rel)
+		}
+
+		resp, err := t.next.RoundTrip(out)
+		if err == nil {
+			return resp, nil
+		}
+		lastErr = err
+
+		if !isConnectError(err) || (body != nil && body.read.Load()) {
+			return nil, err
+		}
+		if t.logger != nil {
+			t.logger.Warn("proxy upstream unreachable, trying next", "upstream", upstream.String(), "error", err)
+		}
+	}
+	return nil, lastErr
+}
+
+// joinURL places rel under upstream, as ProxyRequest.SetURL does.
+func joinURL(upstream, rel *url.URL) *url.URL {
+	u := *rel
+	u.Scheme = upstream.Scheme
+	u.Host = upstream.Host
+	u.Path = strings.TrimSuffix(upstream.Path, "/") + "/" + strings.TrimPrefix(rel.Path, "/")
+	u.RawPath = ""
+	if upstream.RawQuery != "" {
+		if u.RawQuery == "" {
+			u.RawQuery = upstream.RawQuery
+		} else {
+			u.RawQuery = upstream.RawQuery + "&" + u.RawQuery
+		}
+	}
+	return &u
+}
+
+// isConnectError reports whether err happened while establishing the connection.
+func isConnectError(err error) bool {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr)
+}
+
+// replayBody is a request body shared by the attempts of a failover. It
+// records whether an attempt started reading it, and defers closing it
+// until release, so an attempt that failed to connect does not close it
+// for the next one.
+type replayBody struct {
+	io.ReadCloser
+	read atomic.Bool
+
+	mu       sync.Mutex
+	closed   bool // the current attempt closed the body
+	released bool // Close closes the body right away
+}
+
+func (b *replayBody) Read(p []byte) (int, error) {
+	b.read.Store(true)
+	return b.ReadCloser.Read(p)
+}
+
+func (b *replayBody) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.released {
+		return b.ReadCloser.Close()
+	}
+	b.closed = true
+	return nil
+}
+
+// hold starts an attempt, forgetting that a previous one closed the body.
+func (b *replayBody) hold() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = false
+}
+
+// release ends the failover: the body is closed now if the last attempt
+// already closed it, or as soon as that attempt does.
+func (b *replayBody) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.released = true
+	if b.closed {
+		b.ReadCloser.Close()
+	}
+}
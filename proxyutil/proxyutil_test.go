@@ -0,0 +1,261 @@
+package proxyutil
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// closedURL returns the URL of a server that is no longer listening.
+func closedURL(t *testing.T) string {
+	t.Helper()
+	srv := httptest.NewServer(http.NotFoundHandler())
+	url := srv.URL
+	srv.Close()
+	return url
+}
+
+func TestNew_Validation(t *testing.T) {
+	if _, err := New(Config{}); err == nil {
+		t.Error("expected error with no upstreams")
+	}
+	if _, err := New(Config{Upstreams: []string{"/relative"}}); err == nil {
+		t.Error("expected error for relative upstream")
+	}
+}
+
+func TestProxy_StripPrefixAndRewrite(t *testing.T) {
+	var gotPath, gotQuery string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotQuery = r.URL.Path, r.URL.RawQuery
+	}))
+	defer upstream.Close()
+
+	p, err := New(Config{
+		Upstreams:   []string{upstream.URL + "/base"},
+		StripPrefix: "/api",
+		RewritePath: func(path string) string { return strings.Replace(path, "/v1/", "/", 1) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := httptest.NewRecorder()
+	p.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/users?id=7", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	if gotPath != "/base/users" {
+		t.Errorf("path = %q, want /base/users", gotPath)
+	}
+	if gotQuery != "id=7" {
+		t.Errorf("query = %q, want id=7", gotQuery)
+	}
+}
+
+func TestProxy_ForwardedHeaders(t *testing.T) {
+	var got http.Header
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+	}))
+	defer upstream.Close()
+
+	p, err := New(Config{Upstreams: []string{upstream.URL}, TrustedProxies: []string{"10.0.0.0/8"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		wantXFF    string
+		wantProto  string
+		wantRealIP string
+	}{
+		{"trusted peer keeps chain", "10.0.0.1:1234", "203.0.113.9, 10.0.0.1", "https", "203.0.113.9"},
+		{"untrusted peer is replaced", "198.51.100.2:1234", "198.51.100.2", "http", "198.51.100.2"},
+		{"bare IP remote addr", "198.51.100.3", "198.51.100.3", "http", "198.51.100.3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			req.Header.Set("X-Forwarded-For", "203.0.113.9")
+			req.Header.Set("X-Forwarded-Proto", "https")
+			req.Header.Set("X-Real-IP", "203.0.113.9")
+
+			p.ServeHTTP(httptest.NewRecorder(), req)
+
+			if v := got.Get("X-Forwarded-For"); v != tt.wantXFF {
+				t.Errorf("X-Forwarded-For = %q, want %q", v, tt.wantXFF)
+			}
+			if v := got.Get("X-Forwarded-Proto"); v != tt.wantProto {
+				t.Errorf("X-Forwarded-Proto = %q, want %q", v, tt.wantProto)
+			}
+			if v := got.Get("X-Real-IP"); v != tt.wantRealIP {
+				t.Errorf("X-Real-IP = %q, want %q", v, tt.wantRealIP)
+			}
+			if v := got.Get("X-Forwarded-Host"); v != "example.com" {
+				t.Errorf("X-Forwarded-Host = %q, want example.com", v)
+			}
+		})
+	}
+}
+
+func TestProxy_FailoverOnConnectError(t *testing.T) {
+	var gotBody string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		gotBody = string(b)
+		w.Write([]byte("ok " + r.URL.Path))
+	}))
+	defer upstream.Close()
+
+	p, err := New(Config{Upstreams: []string{closedURL(t), upstream.URL}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := httptest.NewRecorder()
+	p.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/items", strings.NewReader("payload")))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	if rec.Body.String() != "ok /items" {
+		t.Errorf("body = %q", rec.Body.String())
+	}
+	if gotBody != "payload" {
+		t.Errorf("upstream body = %q, want payload", gotBody)
+	}
+}
+
+func TestProxy_FailoverThroughServer(t *testing.T) {
+	var gotBody string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		gotBody = string(b)
+		w.Write([]byte("ok"))
+	}))
+	defer upstream.Close()
+
+	p, err := New(Config{Upstreams: []string{closedURL(t), upstream.URL}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(p)
+	defer srv.Close()
+
+	resp, err := http.Post(srv.URL+"/items", "text/plain", strings.NewReader("payload"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || string(body) != "ok" {
+		t.Fatalf("got %d %q, want 200 ok", resp.StatusCode, body)
+	}
+	if gotBody != "payload" {
+		t.Errorf("upstream body = %q, want payload", gotBody)
+	}
+}
+
+func TestProxy_PreserveHost(t *testing.T) {
+	var gotHost string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHost = r.Host
+	}))
+	defer upstream.Close()
+	upstreamHost := strings.TrimPrefix(upstream.URL, "http://")
+
+	for _, preserve := range []bool{false, true} {
+		p, err := New(Config{Upstreams: []string{closedURL(t), upstream.URL}, PreserveHost: preserve})
+		if err != nil {
+			t.Fatal(err)
+		}
+		p.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+		want := upstreamHost
+		if preserve {
+			want = "example.com"
+		}
+		if gotHost != want {
+			t.Errorf("PreserveHost %v: Host = %q, want %q", preserve, gotHost, want)
+		}
+	}
+}
+
+func TestProxy_AllUpstreamsDown(t *testing.T) {
+	p, err := New(Config{Upstreams: []string{closedURL(t), closedURL(t)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := httptest.NewRecorder()
+	p.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if rec.Code != http.StatusBadGateway {
+		t.Errorf("status = %d, want 502", rec.Code)
+	}
+}
+
+func TestProxy_WebSocketUpgrade(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Upgrade") != "websocket" {
+			http.Error(w, "expected upgrade", http.StatusBadRequest)
+			return
+		}
+		conn, brw, err := http.NewResponseController(w).Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+		brw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n\r\n")
+		brw.Flush()
+		// echo one line back
+		line, _ := brw.ReadString('\n')
+		brw.WriteString(line)
+		brw.Flush()
+	}))
+	defer upstream.Close()
+
+	p, err := New(Config{Upstreams: []string{upstream.URL}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	front := httptest.NewServer(p)
+	defer front.Close()
+
+	conn, err := net.Dial("tcp", strings.TrimPrefix(front.URL, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	io.WriteString(conn, "GET /ws HTTP/1.1\r\nHost: example.com\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n\r\n")
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("status = %d, want 101", resp.StatusCode)
+	}
+
+	io.WriteString(conn, "hello\n")
+	line, err := br.ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	if line != "hello\n" {
+		t.Errorf("echo = %q, want hello", line)
+	}
+}
@@ -0,0 +1,85 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// Keyed maintains one token bucket per key, such as a client IP address or
+// API key. Buckets unused for IdleTTL are dropped.
+type Keyed struct {
+	// IdleTTL is how long an unused bucket is kept. Default: 3 minutes
+	IdleTTL time.Duration
+
+	mu         sync.Mutex
+	capacity   float64
+	refillRate float64
+	entries    map[string]*keyedEntry
+	lastSweep  time.Time
+}
+
+type keyedEntry struct {
+	bucket   *TokenBucket
+	lastSeen time.Time
+}
+
+// NewKeyed creates a keyed limiter whose buckets hold capacity tokens and
+// refill at refillRate tokens per second.
+func NewKeyed(capacity, refillRate float64) *Keyed {
+	return &Keyed{
+		IdleTTL:    3 * time.Minute,
+		capacity:   capacity,
+		refillRate: refillRate,
+		entries:    make(map[string]*keyedEntry),
+		lastSweep:  time.Now(),
+	}
+}
+
+// Allow reports whether a request for key is allowed, consuming a token if
+// so, and returns the key's quota after the decision.
+func (k *Keyed) Allow(key string) (bool, Quota) {
+	b := k.bucket(key)
+	ok := b.Allow()
+	return ok, b.Quota()
+}
+
+// Quota returns the current quota of key without consuming a token.
+func (k *Keyed) Quota(key string) Quota {
+	return k.bucket(key).Quota()
+}
+
+// Len returns the number of tracked keys.
+func (k *Keyed) Len() int {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return len(k.entries)
+}
+
+// bucket returns the bucket for key, creating it if needed. Idle buckets are
+// swept at most once per IdleTTL, so no background goroutine is needed.
+func (k *Keyed) bucket(key string) *TokenBucket {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	now := time.Now()
+	ttl := k.IdleTTL
+	if ttl <= 0 {
+		ttl = 3 * time.Minute
+	}
+	if now.Sub(k.lastSweep) >= ttl {
+		for key, e := range k.entries {
+			if now.Sub(e.lastSeen) > ttl {
+				delete(k.entries, key)
+			}
+		}
+		k.lastSweep = now
+	}
+
+	e, ok := k.entries[key]
+	if !ok {
+		e = &keyedEntry{bucket: NewTokenBucket(k.capacity, k.refillRate)}
+		k.entries[key] = e
+	}
+	e.lastSeen = now
+	return e.bucket
+}
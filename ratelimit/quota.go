@@ -0,0 +1,145 @@
+package ratelimit
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Quota is a snapshot of a limiter's state, for telling clients how much of
+// their allowance is left.
+type Quota struct {
+	// Limit is the number of requests allowed when the quota is full.
+	Limit int
+
+	// Remaining is the number of requests allowed right now.
+	Remaining int
+
+	// Reset is the time until the quota is full again.
+	Reset time.Duration
+}
+
+// Quota returns the current state of the bucket. Reset is the time until
+// the bucket is refilled to capacity.
+func (tb *TokenBucket) Quota() Quota {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	tb.refill()
+	var reset time.Duration
+	if missing := tb.capacity - tb.tokens; missing > 0 && tb.refillRate > 0 {
+		reset = time.Duration(missing / tb.refillRate * float64(time.Second))
+	}
+	return Quota{
+		Limit:     int(tb.capacity),
+		Remaining: int(tb.tokens),
+		Reset:     reset,
+	}
+}
+
+// Quota returns the current state of the window. Reset is the time until
+// the current window ends.
+func (fw *FixedWindow) Quota() Quota {
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+
+	elapsed := time.Since(fw.windowStart)
+	if elapsed >= fw.window {
+		return Quota{Limit: fw.limit, Remaining: fw.limit}
+	}
+	return Quota{
+		Limit:     fw.limit,
+		Remaining: max(fw.limit-fw.count, 0),
+		Reset:     fw.window - elapsed,
+	}
+}
+
+// SetHeaders sets quota headers on h in both common forms:
+//
+//	X-RateLimit-Limit, X-RateLimit-Remaining  request counts
+//	X-RateLimit-Reset                         Unix time in seconds when the quota is full
+//	RateLimit-Limit, RateLimit-Remaining      request counts (IETF draft)
+//	RateLimit-Reset                           seconds until the quota is full (IETF draft)
+//
+// Reset values are rounded up to whole seconds.
+func SetHeaders(h http.Header, q Quota) {
+	reset := int64(math.Ceil(q.Reset.Seconds()))
+	limit := strconv.Itoa(q.Limit)
+	remaining := strconv.Itoa(q.Remaining)
+
+	h.Set("X-RateLimit-Limit", limit)
+	h.Set("X-RateLimit-Remaining", remaining)
+	h.Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Unix()+reset, 10))
+	h.Set("RateLimit-Limit", limit)
+	h.Set("RateLimit-Remaining", remaining)
+	h.Set("RateLimit-Reset", strconv.FormatInt(reset, 10))
+}
+
+// ParseRetryAfter parses a Retry-After header value, either delay-seconds
+// or an HTTP date. A date in the past yields a zero delay. It reports false
+// if value is empty or malformed.
+func ParseRetryAfter(value string) (time.Duration, bool) {
+	return parseRetryAfter(value, time.Now())
+}
+
+// parseRetryAfter parses a Retry-After value, in seconds or an HTTP date,
+// relative to now. A date in the past yields a zero delay.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if secs, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return max(t.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+// ParseHeaders reads a quota from response headers set in either form
+// described for SetHeaders, preferring the IETF draft form. X-RateLimit-Reset
+// is taken as a Unix time if it is one, and as seconds from now otherwise,
+// as services differ. It reports false if h holds no quota headers.
+func ParseHeaders(h http.Header) (Quota, bool) {
+	return parseHeaders(h, time.Now())
+}
+
+// parseHeaders implements ParseHeaders relative to now.
+func parseHeaders(h http.Header, now time.Time) (Quota, bool) {
+	var q Quota
+	found := false
+	parse := func(names ...string) (int64, bool) {
+		for _, name := range names {
+			if n, err := strconv.ParseInt(h.Get(name), 10, 64); err == nil && n >= 0 {
+				found = true
+				return n, true
+			}
+		}
+		return 0, false
+	}
+
+	if n, ok := parse("RateLimit-Limit", "X-RateLimit-Limit"); ok {
+		q.Limit = int(n)
+	}
+	if n, ok := parse("RateLimit-Remaining", "X-RateLimit-Remaining"); ok {
+		q.Remaining = int(n)
+	}
+	if n, ok := parse("RateLimit-Reset"); ok {
+		q.Reset = time.Duration(n) * time.Second
+	} else if n, ok := parse("X-RateLimit-Reset"); ok {
+		// a Unix time is far larger than any sensible window
+		if n >= 1_000_000_000 {
+			q.Reset = max(time.Unix(n, 0).Sub(now), 0)
+		} else {
+			q.Reset = time.Duration(n) * time.Second
+		}
+	}
+	return q, found
+}
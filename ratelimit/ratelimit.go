@@ -45,6 +45,11 @@ func (tb *TokenBucket) refill() {
 	tb.lastRefill = now
 }
 
+// refillTime returns how long it takes to refill the given number of tokens
+func (tb *TokenBucket) refillTime(tokens float64) time.Duration {
+	return time.Duration(tokens / tb.refillRate * float64(time.Second))
+}
+
 // Allow checks if a request is allowed without blocking
 func (tb *TokenBucket) Allow() bool {
 	tb.mu.Lock()
@@ -68,8 +73,7 @@ func (tb *TokenBucket) Wait(ctx context.Context) error {
 			tb.mu.Unlock()
 			return nil
 		}
-		needed := 1.0 - tb.tokens
-		waitTime := time.Duration(needed/tb.refillRate) * time.Second
+		waitTime := tb.refillTime(1.0 - tb.tokens)
 		tb.mu.Unlock()
 
 		select {
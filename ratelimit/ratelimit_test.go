@@ -2,6 +2,8 @@ package ratelimit
 
 import (
 	"context"
+	"net/http"
+	"strconv"
 	"testing"
 	"time"
 )
@@ -72,6 +74,17 @@ func TestTokenBucket_Wait(t *testing.T) {
 	}
 }
 
+func TestTokenBucket_RefillTimeKeepsFractionalSeconds(t *testing.T) {
+	tb := NewTokenBucket(1, 4)
+
+	if got := tb.refillTime(1); got != 250*time.Millisecond {
+		t.Errorf("refillTime(1) = %v, want 250ms", got)
+	}
+	if got := tb.refillTime(0.5); got != 125*time.Millisecond {
+		t.Errorf("refillTime(0.5) = %v, want 125ms", got)
+	}
+}
+
 func TestTokenBucket_WaitContextCancelled(t *testing.T) {
 	tb := NewTokenBucket(1, 0.001) // very slow refill
 	tb.Allow()                     // exhaust
@@ -158,3 +171,139 @@ func TestFixedWindow_ImplementsLimiter(t *testing.T) {
 	var _ Limiter = NewFixedWindow(1, time.Second)
 }
 
+func TestTokenBucket_Quota(t *testing.T) {
+	tb := NewTokenBucket(5, 1)
+	tb.Allow()
+	tb.Allow()
+
+	q := tb.Quota()
+	if q.Limit != 5 || q.Remaining != 3 {
+		t.Errorf("quota = %+v, want limit 5, remaining 3", q)
+	}
+	if q.Reset <= time.Second || q.Reset > 2*time.Second {
+		t.Errorf("reset = %v, want ~2s", q.Reset)
+	}
+
+	if q := NewTokenBucket(5, 1).Quota(); q.Remaining != 5 || q.Reset != 0 {
+		t.Errorf("full bucket quota = %+v", q)
+	}
+}
+
+func TestFixedWindow_Quota(t *testing.T) {
+	fw := NewFixedWindow(3, time.Minute)
+	fw.Allow()
+
+	q := fw.Quota()
+	if q.Limit != 3 || q.Remaining != 2 || q.Reset <= 59*time.Second || q.Reset > time.Minute {
+		t.Errorf("quota = %+v", q)
+	}
+}
+
+func TestSetHeaders(t *testing.T) {
+	h := http.Header{}
+	SetHeaders(h, Quota{Limit: 10, Remaining: 4, Reset: 1500 * time.Millisecond})
+
+	want := map[string]string{
+		"X-RateLimit-Limit":     "10",
+		"X-RateLimit-Remaining": "4",
+		"RateLimit-Limit":       "10",
+		"RateLimit-Remaining":   "4",
+		"RateLimit-Reset":       "2",
+	}
+	for k, v := range want {
+		if got := h.Get(k); got != v {
+			t.Errorf("%s = %q, want %q", k, got, v)
+		}
+	}
+	reset, err := strconv.ParseInt(h.Get("X-RateLimit-Reset"), 10, 64)
+	if now := time.Now().Unix(); err != nil || reset < now+1 || reset > now+3 {
+		t.Errorf("X-RateLimit-Reset = %q, want ~now+2", h.Get("X-RateLimit-Reset"))
+	}
+}
+
+func TestParseHeaders(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	tests := []struct {
+		name   string
+		header http.Header
+		want   Quota
+		wantOK bool
+	}{
+		{"none", http.Header{}, Quota{}, false},
+		{"ietf", http.Header{"Ratelimit-Limit": {"10"}, "Ratelimit-Remaining": {"3"}, "Ratelimit-Reset": {"5"}},
+			Quota{Limit: 10, Remaining: 3, Reset: 5 * time.Second}, true},
+		{"x unix reset", http.Header{"X-Ratelimit-Remaining": {"0"}, "X-Ratelimit-Reset": {"1700000030"}},
+			Quota{Reset: 30 * time.Second}, true},
+		{"x delta reset", http.Header{"X-Ratelimit-Reset": {"12"}}, Quota{Reset: 12 * time.Second}, true},
+		{"ietf preferred", http.Header{"Ratelimit-Remaining": {"1"}, "X-Ratelimit-Remaining": {"9"}}, Quota{Remaining: 1}, true},
+		{"malformed", http.Header{"Ratelimit-Limit": {"many"}}, Quota{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseHeaders(tt.header, now)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("%s: parseHeaders = %+v, %v; want %+v, %v", tt.name, got, ok, tt.want, tt.wantOK)
+		}
+	}
+
+	// round trip with SetHeaders
+	h := http.Header{}
+	SetHeaders(h, Quota{Limit: 5, Remaining: 2, Reset: 3 * time.Second})
+	if got, ok := ParseHeaders(h); !ok || got != (Quota{Limit: 5, Remaining: 2, Reset: 3 * time.Second}) {
+		t.Errorf("ParseHeaders(SetHeaders(q)) = %+v, %v", got, ok)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{"", 0, false},
+		{"5", 5 * time.Second, true},
+		{" 0 ", 0, true},
+		{"-1", 0, false},
+		{"soon", 0, false},
+		{now.Add(30 * time.Second).Format(http.TimeFormat), 30 * time.Second, true},
+		{now.Add(-time.Minute).Format(http.TimeFormat), 0, true},
+	}
+	for _, tt := range tests {
+		got, ok := parseRetryAfter(tt.value, now)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("parseRetryAfter(%q) = %v, %v; want %v, %v", tt.value, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestKeyed(t *testing.T) {
+	k := NewKeyed(2, 1)
+
+	for i := range 2 {
+		if ok, q := k.Allow("a"); !ok || q.Remaining != 1-i {
+			t.Errorf("request %d: allowed %v, quota %+v", i, ok, q)
+		}
+	}
+	if ok, q := k.Allow("a"); ok || q.Remaining != 0 {
+		t.Errorf("over limit: allowed %v, quota %+v", ok, q)
+	}
+	if ok, _ := k.Allow("b"); !ok {
+		t.Error("separate key was limited")
+	}
+	if q := k.Quota("b"); q.Remaining != 1 {
+		t.Errorf("Quota consumed a token: %+v", q)
+	}
+}
+
+func TestKeyed_DropsIdleKeys(t *testing.T) {
+	k := NewKeyed(1, 1)
+	k.IdleTTL = 20 * time.Millisecond
+
+	k.Allow("a")
+	time.Sleep(30 * time.Millisecond)
+	k.Allow("b")
+
+	if n := k.Len(); n != 1 {
+		t.Errorf("Len = %d, want 1 after the idle key expired", n)
+	}
+}
@@ -106,3 +106,55 @@ func isPrivateSubnet(ip net.IP) bool {
 func IsPrivateIP(ip net.IP) bool {
 	return isPrivateSubnet(ip)
 }
+
+// TrustedProxies decides whether a request's direct peer is a trusted proxy
+// whose forwarding headers may be believed.
+type TrustedProxies struct {
+	nets []*net.IPNet
+	ips  []net.IP
+	// privateOnly is set when no proxies were configured.
+	privateOnly bool
+}
+
+// ParseTrusted builds a TrustedProxies from a list of IP addresses and CIDR blocks.
+// Invalid entries are ignored. An empty list trusts any private IP, which is
+// appropriate when the server is only reachable through a reverse proxy.
+func ParseTrusted(proxies []string) *TrustedProxies {
+	t := &TrustedProxies{privateOnly: len(proxies) == 0}
+	for _, proxy := range proxies {
+		if strings.Contains(proxy, "/") {
+			if _, network, err := net.ParseCIDR(proxy); err == nil {
+				t.nets = append(t.nets, network)
+			}
+		} else if ip := net.ParseIP(proxy); ip != nil {
+			t.ips = append(t.ips, ip)
+		}
+	}
+	return t
+}
+
+// Contains reports whether remoteAddr ("host:port" or a bare IP) is trusted.
+func (t *TrustedProxies) Contains(remoteAddr string) bool {
+	if host, _, err := net.SplitHostPort(remoteAddr); err == nil {
+		remoteAddr = host
+	}
+	ip := net.ParseIP(remoteAddr)
+	if ip == nil {
+		return false
+	}
+
+	if t.privateOnly {
+		return IsPrivateIP(ip)
+	}
+	for _, trusted := range t.ips {
+		if ip.Equal(trusted) {
+			return true
+		}
+	}
+	for _, network := range t.nets {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
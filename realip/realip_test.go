@@ -78,3 +78,27 @@ func TestIsPrivateSubnet(t *testing.T) {
 		}
 	}
 }
+
+func TestParseTrusted(t *testing.T) {
+	tests := []struct {
+		name       string
+		proxies    []string
+		remoteAddr string
+		want       bool
+	}{
+		{"EmptyTrustsPrivate", nil, "10.1.2.3:80", true},
+		{"EmptyRejectsPublic", nil, "8.8.8.8:80", false},
+		{"ExactIP", []string{"203.0.113.7"}, "203.0.113.7:443", true},
+		{"CIDR", []string{"203.0.113.0/24"}, "203.0.113.99", true},
+		{"ConfiguredRejectsPrivate", []string{"203.0.113.7"}, "10.0.0.1:80", false},
+		{"InvalidEntriesTrustNothing", []string{"not-an-ip"}, "10.0.0.1:80", false},
+		{"UnparsableRemote", nil, "garbage", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseTrusted(tt.proxies).Contains(tt.remoteAddr); got != tt.want {
+				t.Errorf("Contains(%q) = %v, want %v", tt.remoteAddr, got, tt.want)
+			}
+		})
+	}
+}
@@ -5,9 +5,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
 	"math"
 	"math/rand"
 	"time"
+
+	"github.com/en9inerd/go-pkgs/observability"
 )
 
 // Strategy defines a retry strategy
@@ -18,6 +21,10 @@ type Strategy struct {
 	Multiplier      float64
 	Jitter          bool
 	RetryableErrors func(error) bool
+
+	// Observability optionally receives a span per Do call, retry metrics
+	// and debug logs for each failed attempt.
+	Observability *observability.Config
 }
 
 // DefaultStrategy returns a default retry strategy with exponential backoff
@@ -37,9 +44,64 @@ func DefaultStrategy() *Strategy {
 
 // Do executes a function with retry logic
 func Do(ctx context.Context, strategy *Strategy, fn func() error) error {
+	return run(ctx, strategy, fn, sleep)
+}
+
+// DoDryRun executes fn with the retry logic of Do but without sleeping
+// between attempts, and returns the delays Do would have waited. It is meant
+// for tests that assert a backoff policy without depending on time, and for
+// estimating how long an operation may take to give up. Observability is not
+// recorded for dry runs.
+func DoDryRun(ctx context.Context, strategy *Strategy, fn func() error) ([]time.Duration, error) {
 	if strategy == nil {
 		strategy = DefaultStrategy()
 	}
+	dry := *strategy
+	dry.Observability = nil
+
+	var schedule []time.Duration
+	err := run(ctx, &dry, fn, func(ctx context.Context, delay time.Duration) error {
+		schedule = append(schedule, delay)
+		return ctx.Err()
+	})
+	return schedule, err
+}
+
+// Preview returns the first n delays Do would wait between attempts, in
+// order, without running anything. The full schedule of a strategy is
+// Preview(MaxAttempts-1). With Jitter enabled every call returns a
+// different sample.
+func (s *Strategy) Preview(n int) []time.Duration {
+	if n <= 0 {
+		return nil
+	}
+	schedule := make([]time.Duration, n)
+	delay := s.InitialDelay
+	for i := range schedule {
+		schedule[i] = delay
+		delay = calculateDelay(delay, s)
+	}
+	return schedule
+}
+
+// sleep waits for delay or until ctx is done.
+func sleep(ctx context.Context, delay time.Duration) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(delay):
+		return nil
+	}
+}
+
+// run implements Do, using wait between attempts.
+func run(ctx context.Context, strategy *Strategy, fn func() error, wait func(context.Context, time.Duration) error) error {
+	if strategy == nil {
+		strategy = DefaultStrategy()
+	}
+
+	_, span := strategy.Observability.StartSpan(ctx, "retry.Do")
+	defer span.End()
 
 	var lastErr error
 	delay := strategy.InitialDelay
@@ -53,6 +115,7 @@ func Do(ctx context.Context, strategy *Strategy, fn func() error) error {
 
 		err := fn()
 		if err == nil {
+			strategy.recordSuccess(span, attempt)
 			return nil
 		}
 
@@ -60,24 +123,46 @@ func Do(ctx context.Context, strategy *Strategy, fn func() error) error {
 
 		// Check if error is retryable
 		if !strategy.RetryableErrors(err) {
+			strategy.recordFailure(span, attempt, err, "non_retryable")
 			return err
 		}
+		strategy.recordAttemptFailed(attempt, err, delay)
 
 		// Don't sleep after the last attempt
 		if attempt < strategy.MaxAttempts-1 {
 			// Wait with context cancellation support
-			select {
-			case <-ctx.Done():
-				return ctx.Err()
-			case <-time.After(delay):
+			if err := wait(ctx, delay); err != nil {
+				return err
 			}
 			delay = calculateDelay(delay, strategy)
 		}
 	}
 
+	strategy.recordFailure(span, strategy.MaxAttempts-1, lastErr, "exhausted")
 	return fmt.Errorf("max attempts (%d) reached: %w", strategy.MaxAttempts, lastErr)
 }
 
+// recordAttemptFailed reports a failed, retryable attempt.
+func (s *Strategy) recordAttemptFailed(attempt int, err error, delay time.Duration) {
+	s.Observability.Count("retry_attempts_failed_total")
+	if logger := s.Observability.Log(); logger != nil && attempt < s.MaxAttempts-1 {
+		logger.Debug("retrying operation", "attempt", attempt+1, "delay", delay, "error", err)
+	}
+}
+
+// recordSuccess reports that the operation succeeded after attempt+1 tries.
+func (s *Strategy) recordSuccess(span observability.Span, attempt int) {
+	span.SetAttributes(slog.Int("retry.attempts", attempt+1))
+	s.Observability.Count("retry_operations_total", "outcome", "success")
+}
+
+// recordFailure reports that the operation gave up with err.
+func (s *Strategy) recordFailure(span observability.Span, attempt int, err error, outcome string) {
+	span.SetAttributes(slog.Int("retry.attempts", attempt+1))
+	span.RecordError(err)
+	s.Observability.Count("retry_operations_total", "outcome", outcome)
+}
+
 // calculateDelay calculates the next delay with exponential backoff and optional jitter
 func calculateDelay(delay time.Duration, strategy *Strategy) time.Duration {
 	calculatedDelay := min(time.Duration(float64(delay)*strategy.Multiplier), strategy.MaxDelay)
@@ -90,45 +175,42 @@ func calculateDelay(delay time.Duration, strategy *Strategy) time.Duration {
 	return calculatedDelay
 }
 
-// DoWithResult executes a function that returns a result with retry logic
-func DoWithResult[T any](ctx context.Context, strategy *Strategy, fn func() (T, error)) (T, error) {
-	var zero T
-	if strategy == nil {
-		strategy = DefaultStrategy()
+// Delay returns the delay before the given retry attempt (0-based) without
+// sleeping: InitialDelay * Multiplier^attempt, capped at MaxDelay, plus up to
+// 10% jitter when Jitter is enabled. It lets other packages reuse a Strategy
+// as a backoff policy for their own retry loops.
+func (s *Strategy) Delay(attempt int) time.Duration {
+	multiplier := s.Multiplier
+	if multiplier <= 0 {
+		multiplier = 1
 	}
+	// compute in float64 so large attempts saturate instead of overflowing
+	d := float64(s.InitialDelay) * math.Pow(multiplier, float64(max(attempt, 0)))
+	if s.MaxDelay > 0 {
+		d = min(d, float64(s.MaxDelay))
+	}
+	delay := time.Duration(min(d, float64(math.MaxInt64/2)))
 
-	var lastErr error
-	delay := strategy.InitialDelay
-
-	for attempt := 0; attempt < strategy.MaxAttempts; attempt++ {
-		select {
-		case <-ctx.Done():
-			return zero, ctx.Err()
-		default:
-		}
-
-		result, err := fn()
-		if err == nil {
-			return result, nil
-		}
-
-		lastErr = err
+	if s.Jitter {
+		delay += time.Duration(rand.Float64() * float64(delay) * 0.1)
+	}
 
-		if !strategy.RetryableErrors(err) {
-			return zero, err
-		}
+	return delay
+}
 
-		if attempt < strategy.MaxAttempts-1 {
-			select {
-			case <-ctx.Done():
-				return zero, ctx.Err()
-			case <-time.After(delay):
-			}
-			delay = calculateDelay(delay, strategy)
-		}
+// DoWithResult executes a function that returns a result with retry logic
+func DoWithResult[T any](ctx context.Context, strategy *Strategy, fn func() (T, error)) (T, error) {
+	var result T
+	err := Do(ctx, strategy, func() error {
+		var err error
+		result, err = fn()
+		return err
+	})
+	if err != nil {
+		var zero T
+		return zero, err
 	}
-
-	return zero, fmt.Errorf("max attempts (%d) reached: %w", strategy.MaxAttempts, lastErr)
+	return result, nil
 }
 
 // ExponentialBackoff calculates the delay for exponential backoff.
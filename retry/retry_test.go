@@ -5,6 +5,8 @@ import (
 	"errors"
 	"testing"
 	"time"
+
+	"github.com/en9inerd/go-pkgs/observability/observabilitytest"
 )
 
 func TestDo_SucceedsFirstAttempt(t *testing.T) {
@@ -210,6 +212,33 @@ func TestExponentialBackoff(t *testing.T) {
 	}
 }
 
+func TestStrategy_Delay(t *testing.T) {
+	s := &Strategy{InitialDelay: 100 * time.Millisecond, MaxDelay: time.Second, Multiplier: 2.0}
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{0, 100 * time.Millisecond},
+		{1, 200 * time.Millisecond},
+		{3, 800 * time.Millisecond},
+		{4, time.Second},    // capped at MaxDelay
+		{5000, time.Second}, // no overflow for huge attempts
+	}
+	for _, tt := range tests {
+		if got := s.Delay(tt.attempt); got != tt.want {
+			t.Errorf("attempt=%d: got %v, want %v", tt.attempt, got, tt.want)
+		}
+	}
+
+	s.Jitter = true
+	for range 100 {
+		got := s.Delay(1)
+		if got < 200*time.Millisecond || got > 220*time.Millisecond {
+			t.Fatalf("jittered delay %v outside [200ms, 220ms]", got)
+		}
+	}
+}
+
 func TestIsRetryableError(t *testing.T) {
 	if IsRetryableError(nil) {
 		t.Error("nil should not be retryable")
@@ -259,6 +288,86 @@ func TestDo_FirstDelayIsInitialDelay(t *testing.T) {
 	}
 }
 
+func TestStrategy_Preview(t *testing.T) {
+	s := &Strategy{
+		MaxAttempts:  5,
+		InitialDelay: 100 * time.Millisecond,
+		MaxDelay:     500 * time.Millisecond,
+		Multiplier:   2.0,
+	}
+
+	got := s.Preview(4)
+	want := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 500 * time.Millisecond}
+	if len(got) != len(want) {
+		t.Fatalf("Preview(4) = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Preview(4)[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+
+	if got := s.Preview(0); got != nil {
+		t.Errorf("Preview(0) = %v, want nil", got)
+	}
+
+	s.Jitter = true
+	for i, d := range s.Preview(4) {
+		if hi := s.MaxDelay + s.MaxDelay/10; d < want[i] || d > hi {
+			t.Errorf("jittered delay %d = %v, want within [%v, %v]", i, d, want[i], hi)
+		}
+	}
+}
+
+func TestDoDryRun(t *testing.T) {
+	obs, metrics, _ := observabilitytest.New()
+	s := &Strategy{
+		MaxAttempts:     4,
+		InitialDelay:    time.Hour,
+		MaxDelay:        3 * time.Hour,
+		Multiplier:      2.0,
+		RetryableErrors: func(error) bool { return true },
+		Observability:   obs,
+	}
+
+	calls := 0
+	start := time.Now()
+	schedule, err := DoDryRun(context.Background(), s, func() error {
+		calls++
+		return errors.New("fail")
+	})
+	if time.Since(start) > time.Second {
+		t.Error("DoDryRun slept")
+	}
+	if err == nil || calls != 4 {
+		t.Errorf("err = %v, calls = %d, want error after 4 calls", err, calls)
+	}
+	want := []time.Duration{time.Hour, 2 * time.Hour, 3 * time.Hour}
+	if len(schedule) != len(want) {
+		t.Fatalf("schedule = %v, want %v", schedule, want)
+	}
+	for i := range want {
+		if schedule[i] != want[i] {
+			t.Errorf("schedule[%d] = %v, want %v", i, schedule[i], want[i])
+		}
+	}
+	if v := metrics.Value("retry_operations_total", "outcome", "exhausted"); v != 0 {
+		t.Errorf("dry run recorded metrics: %v", v)
+	}
+
+	calls = 0
+	schedule, err = DoDryRun(context.Background(), s, func() error {
+		calls++
+		if calls < 2 {
+			return errors.New("fail")
+		}
+		return nil
+	})
+	if err != nil || len(schedule) != 1 {
+		t.Errorf("schedule = %v, err = %v, want one delay and success", schedule, err)
+	}
+}
+
 func TestDefaultStrategy(t *testing.T) {
 	s := DefaultStrategy()
 	if s.MaxAttempts != 3 {
@@ -280,3 +389,53 @@ func TestDefaultStrategy(t *testing.T) {
 		t.Error("RetryableErrors should not be nil")
 	}
 }
+
+func TestDo_Observability(t *testing.T) {
+	obs, metrics, tracer := observabilitytest.New()
+	s := &Strategy{
+		MaxAttempts:     3,
+		InitialDelay:    time.Millisecond,
+		MaxDelay:        time.Millisecond,
+		Multiplier:      1,
+		RetryableErrors: func(error) bool { return true },
+		Observability:   obs,
+	}
+
+	calls := 0
+	err := Do(context.Background(), s, func() error {
+		calls++
+		if calls < 3 {
+			return errors.New("fail")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v := metrics.Value("retry_attempts_failed_total"); v != 2 {
+		t.Errorf("retry_attempts_failed_total = %v, want 2", v)
+	}
+	if v := metrics.Value("retry_operations_total", "outcome", "success"); v != 1 {
+		t.Errorf("retry_operations_total{success} = %v, want 1", v)
+	}
+
+	_, err = DoWithResult(context.Background(), s, func() (int, error) { return 0, errors.New("fail") })
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if v := metrics.Value("retry_operations_total", "outcome", "exhausted"); v != 1 {
+		t.Errorf("retry_operations_total{exhausted} = %v, want 1", v)
+	}
+
+	spans := tracer.Spans("retry.Do")
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+	if v, _ := spans[0].Attr("retry.attempts"); v.Int64() != 3 {
+		t.Errorf("retry.attempts = %v, want 3", v)
+	}
+	if spans[1].Err == nil {
+		t.Error("expected error recorded on failed span")
+	}
+}
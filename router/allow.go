@@ -0,0 +1,51 @@
+package router
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/en9inerd/go-pkgs/httperrors"
+)
+
+// MethodNotAllowedJSON is a 405 handler for MethodNotAllowedHandler that
+// writes an httperrors JSON body listing the allowed methods, for APIs whose
+// clients expect JSON errors.
+func MethodNotAllowedJSON(w http.ResponseWriter, r *http.Request) {
+	allow := w.Header().Get("Allow")
+	httperrors.NewErrorWithDetails(http.StatusMethodNotAllowed,
+		http.StatusText(http.StatusMethodNotAllowed), "allowed methods: "+allow).WriteJSON(w)
+}
+
+// allowedMethods returns the Allow header value for r: the methods of the
+// registered routes matching its path, HEAD implied by GET. It is empty when
+// no route matches the path with another method.
+func (g *Group) allowedMethods(mux *http.ServeMux, r *http.Request) string {
+	root := g.rootGroup()
+	root.mu.Lock()
+	var methods []string
+	for _, rt := range root.routes {
+		if method, _, ok := strings.Cut(rt.pattern, " "); ok {
+			methods = append(methods, method)
+			if method == http.MethodGet {
+				methods = append(methods, http.MethodHead)
+			}
+		}
+	}
+	root.mu.Unlock()
+	slices.Sort(methods)
+	methods = slices.Compact(methods)
+
+	var allow []string
+	for _, method := range methods {
+		if method == r.Method || method == http.MethodConnect {
+			continue
+		}
+		r2 := *r
+		r2.Method = method
+		if _, pattern := mux.Handler(&r2); pattern != "" {
+			allow = append(allow, method)
+		}
+	}
+	return strings.Join(allow, ", ")
+}
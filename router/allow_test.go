@@ -0,0 +1,56 @@
+package router
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAllowHeader(t *testing.T) {
+	ok := func(w http.ResponseWriter, r *http.Request) {}
+	root := New(http.NewServeMux())
+	api := root.Mount("/api")
+	api.Get("/items", ok)
+	api.Post("/items", ok)
+	api.HandleFunc("PURGE /items", ok)
+	api.Delete("/items/{id}", ok)
+
+	tests := []struct {
+		method, path string
+		status       int
+		allow        string
+	}{
+		{http.MethodDelete, "/api/items", http.StatusMethodNotAllowed, "GET, HEAD, POST, PURGE"},
+		{http.MethodGet, "/api/items/1", http.StatusMethodNotAllowed, "DELETE"},
+		{http.MethodHead, "/api/items", http.StatusOK, ""},
+		{http.MethodGet, "/api/missing", http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		root.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+		if rec.Code != tt.status {
+			t.Errorf("%s %s status = %d, want %d", tt.method, tt.path, rec.Code, tt.status)
+		}
+		if got := rec.Header().Get("Allow"); got != tt.allow {
+			t.Errorf("%s %s Allow = %q, want %q", tt.method, tt.path, got, tt.allow)
+		}
+	}
+
+	root.MethodNotAllowedHandler(MethodNotAllowedJSON)
+	rec := httptest.NewRecorder()
+	root.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/api/items", nil))
+	var body struct {
+		Code    int    `json:"code"`
+		Details string `json:"details"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusMethodNotAllowed || body.Code != http.StatusMethodNotAllowed {
+		t.Errorf("status = %d, body code = %d, want 405", rec.Code, body.Code)
+	}
+	if body.Details != "allowed methods: GET, HEAD, POST, PURGE" {
+		t.Errorf("details = %q", body.Details)
+	}
+}
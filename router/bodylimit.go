@@ -0,0 +1,73 @@
+package router
+
+import (
+	"io"
+	"net/http"
+)
+
+// WithBodyLimit returns a new group whose routes read at most n bytes of
+// request body, as with http.MaxBytesReader: reading past the limit fails
+// with *http.MaxBytesError, and requests declaring a larger Content-Length
+// get 413 Request Entity Too Large without reaching the handler. The limit
+// applies inside the group's middlewares; WithBodyLimit(0) returns a
+// subgroup without a limit. When limits are nested, through subgroups or
+// BodyLimit, the innermost one applies, so a route can allow more than its
+// group:
+//
+//	api := r.Mount("/api").WithBodyLimit(1 << 20)
+//	api.Post("/users", createUser)
+//	api.Post("/uploads", upload, router.BodyLimit(100<<20))
+func (g *Group) WithBodyLimit(n int64) *Group {
+	ng := g.clone()
+	ng.bodyLimit = n
+	return ng
+}
+
+// BodyLimit returns per-route middleware with the semantics of
+// WithBodyLimit, for passing after the handler to Handle and HandleFunc.
+// There it replaces the group's limit, which is then not applied at all.
+func BodyLimit(n int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return &bodyLimitHandler{next: limitBody(next, n), n: n}
+	}
+}
+
+// bodyLimitHandler is the handler of BodyLimit, recognized by wrapRoute.
+type bodyLimitHandler struct {
+	next http.Handler
+	n    int64
+}
+
+func (h *bodyLimitHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.next.ServeHTTP(w, r)
+}
+
+// limitedRoute is a route handler with its own BodyLimit; limit is the
+// innermost one.
+type limitedRoute struct {
+	http.Handler
+	limit int64
+}
+
+// limitedBody is a request body limited by limitBody; orig is the body
+// before the limit, so an inner limit can replace it.
+type limitedBody struct {
+	io.ReadCloser
+	orig io.ReadCloser
+}
+
+func limitBody(next http.Handler, n int64) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength > n {
+			http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+			return
+		}
+		body := r.Body
+		if lb, ok := body.(*limitedBody); ok {
+			body = lb.orig
+		}
+		r2 := *r
+		r2.Body = &limitedBody{ReadCloser: http.MaxBytesReader(w, body, n), orig: body}
+		next.ServeHTTP(w, &r2)
+	})
+}
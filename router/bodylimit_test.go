@@ -0,0 +1,58 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWithBodyLimit(t *testing.T) {
+	read := func(w http.ResponseWriter, r *http.Request) {
+		b, err := io.ReadAll(r.Body)
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			http.Error(w, "too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		w.Write([]byte(fmt.Sprintf("read %d", len(b))))
+	}
+	root := New(http.NewServeMux())
+	api := root.Mount("/api").WithBodyLimit(8)
+	api.Post("/small", read)
+	api.Post("/upload", read, BodyLimit(32))
+	api.Post("/upload-mw", read, func(next http.Handler) http.Handler { return next }, BodyLimit(32))
+	api.WithBodyLimit(0).Post("/unlimited", read)
+
+	tests := []struct {
+		path    string
+		size    int
+		chunked bool
+		status  int
+	}{
+		{"/api/small", 8, false, http.StatusOK},
+		{"/api/small", 9, false, http.StatusRequestEntityTooLarge},
+		{"/api/small", 9, true, http.StatusRequestEntityTooLarge},
+		{"/api/upload", 32, true, http.StatusOK},
+		{"/api/upload", 32, false, http.StatusOK},
+		{"/api/upload", 33, true, http.StatusRequestEntityTooLarge},
+		{"/api/upload", 33, false, http.StatusRequestEntityTooLarge},
+		{"/api/upload-mw", 32, false, http.StatusOK},
+		{"/api/upload-mw", 33, false, http.StatusRequestEntityTooLarge},
+		{"/api/unlimited", 1024, true, http.StatusOK},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(strings.Repeat("a", tt.size)))
+		if tt.chunked {
+			req.ContentLength = -1
+		}
+		rec := httptest.NewRecorder()
+		root.ServeHTTP(rec, req)
+		if rec.Code != tt.status {
+			t.Errorf("POST %s with %d bytes (chunked %v) status = %d, want %d", tt.path, tt.size, tt.chunked, rec.Code, tt.status)
+		}
+	}
+}
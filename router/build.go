@@ -0,0 +1,54 @@
+package router
+
+import (
+	"net/http"
+	"sync"
+)
+
+// route is a registered handler whose middleware chain is assembled when
+// the router is built, so middleware may be added after the route.
+type route struct {
+	group   *Group
+	pattern string
+	handler http.Handler
+	once    sync.Once
+	chain   http.Handler
+}
+
+func (rt *route) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rt.group.rootGroup().Build()
+	rt.build()
+	if rt.group.websocket {
+		r = withUpgradeWriter(w, r)
+	}
+	rt.chain.ServeHTTP(w, r)
+}
+
+func (rt *route) build() {
+	rt.once.Do(func() {
+		chain := rt.group.wrapMiddleware(rt.handler)
+		if instrument := rt.group.rootGroup().instrument; instrument != nil {
+			chain = instrument(rt.pattern, chain)
+		}
+		rt.chain = chain
+	})
+}
+
+// Build assembles the middleware chains of all routes and freezes the
+// router: Use panics afterwards. Routes and middleware may be declared in
+// any order before that. Build runs on the first request served through
+// the router; calling it explicitly at startup moves that work, and any
+// panic from Use, out of the request path. Build may be called more than
+// once and on any group; it always builds the whole router.
+func (g *Group) Build() {
+	root := g.rootGroup()
+	root.once.Do(func() {
+		root.built.Store(true)
+		root.mu.Lock()
+		routes := root.routes
+		root.mu.Unlock()
+		for _, rt := range routes {
+			rt.build()
+		}
+	})
+}
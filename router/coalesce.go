@@ -0,0 +1,164 @@
+package router
+
+import (
+	"bytes"
+	"context"
+	"maps"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// CoalesceConfig configures request coalescing.
+type CoalesceConfig struct {
+	// KeyFunc returns the caller identity used in the coalescing key, so
+	// responses are only shared between requests made with the same
+	// credentials. Default: the Authorization and Cookie headers.
+	KeyFunc func(r *http.Request) string
+}
+
+// Coalesce returns middleware that collapses concurrent identical GET
+// requests into a single handler execution. Requests are identical when they
+// share the host, path, query string and identity key (see
+// CoalesceConfig.KeyFunc). The first request runs the handler; requests
+// arriving while it is in flight wait for it and receive a replay of its
+// buffered response. Requests with a Range header, event-stream requests
+// (Accept: text/event-stream) and connection upgrades are never coalesced,
+// since their responses cannot be buffered and replayed.
+//
+// It protects expensive read endpoints from thundering herds:
+//
+//	api := r.Mount("/api")
+//	reports := api.With(router.Coalesce(router.CoalesceConfig{}))
+//	reports.HandleFunc("GET /reports/{id}", expensiveReport)
+//
+// The shared execution runs with a context that is not cancelled when the
+// first caller disconnects, so the remaining callers still get a response.
+// Responses are held in memory; avoid it on streaming endpoints.
+func Coalesce(cfg CoalesceConfig) func(http.Handler) http.Handler {
+	if cfg.KeyFunc == nil {
+		cfg.KeyFunc = func(r *http.Request) string {
+			return r.Header.Get("Authorization") + "\x00" + strings.Join(r.Header.Values("Cookie"), "; ")
+		}
+	}
+
+	group := &flightGroup{calls: make(map[string]*flight)}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet || r.Header.Get("Range") != "" || isStreaming(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			key := r.Host + "\x00" + r.URL.Path + "?" + r.URL.RawQuery + "\x00" + cfg.KeyFunc(r)
+			f, leader := group.join(key)
+			if leader {
+				group.run(key, f, next, r)
+			} else {
+				select {
+				case <-f.done:
+				case <-r.Context().Done():
+					return
+				}
+			}
+			f.resp.replay(w)
+		})
+	}
+}
+
+// isStreaming reports whether r asks for a response that must be written
+// directly to the connection.
+func isStreaming(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), "text/event-stream") ||
+		r.Header.Get("Upgrade") != ""
+}
+
+// flight is one in-progress handler execution.
+type flight struct {
+	done chan struct{}
+	resp *bufferedResponse
+}
+
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flight
+}
+
+// join returns the flight for key, creating it if needed.
+// leader reports whether the caller must run the handler.
+func (g *flightGroup) join(key string) (f *flight, leader bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if f, ok := g.calls[key]; ok {
+		return f, false
+	}
+	f = &flight{done: make(chan struct{}), resp: newBufferedResponse()}
+	g.calls[key] = f
+	return f, true
+}
+
+// run executes the handler for the flight and releases the waiters.
+// A panic is re-raised for the leader; waiters receive a 500.
+func (g *flightGroup) run(key string, f *flight, next http.Handler, r *http.Request) {
+	defer func() {
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+
+		if p := recover(); p != nil {
+			f.resp.failed()
+			close(f.done)
+			panic(p)
+		}
+		close(f.done)
+	}()
+
+	next.ServeHTTP(f.resp, r.WithContext(context.WithoutCancel(r.Context())))
+}
+
+// bufferedResponse records a response so it can be written to several clients.
+type bufferedResponse struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func newBufferedResponse() *bufferedResponse {
+	return &bufferedResponse{header: make(http.Header)}
+}
+
+func (b *bufferedResponse) Header() http.Header { return b.header }
+
+func (b *bufferedResponse) WriteHeader(status int) {
+	if b.status == 0 {
+		b.status = status
+	}
+}
+
+func (b *bufferedResponse) Write(p []byte) (int, error) {
+	if b.status == 0 {
+		b.status = http.StatusOK
+	}
+	return b.body.Write(p)
+}
+
+// failed replaces the response with a 500 after a handler panic.
+func (b *bufferedResponse) failed() {
+	b.header = http.Header{"Content-Type": {"text/plain; charset=utf-8"}}
+	b.status = http.StatusInternalServerError
+	b.body.Reset()
+	b.body.WriteString(http.StatusText(http.StatusInternalServerError) + "\n")
+}
+
+// replay writes the recorded response to w. It must only be called after
+// the handler has finished.
+func (b *bufferedResponse) replay(w http.ResponseWriter) {
+	maps.Copy(w.Header(), b.header.Clone())
+	status := b.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	w.WriteHeader(status)
+	w.Write(b.body.Bytes())
+}
@@ -0,0 +1,110 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCoalesce_SharesInFlightGET(t *testing.T) {
+	var executions atomic.Int32
+	entered := make(chan struct{})
+	release := make(chan struct{})
+
+	mux := http.NewServeMux()
+	r := New(mux)
+	g := r.With(Coalesce(CoalesceConfig{}))
+	g.HandleFunc("GET /report", func(w http.ResponseWriter, r *http.Request) {
+		if executions.Add(1) == 1 {
+			close(entered)
+		}
+		<-release
+		w.Header().Set("X-Report", "yes")
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte("report"))
+	})
+
+	const n = 5
+	recs := make([]*httptest.ResponseRecorder, n)
+	var wg sync.WaitGroup
+	for i := range n {
+		recs[i] = httptest.NewRecorder()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.ServeHTTP(recs[i], httptest.NewRequest(http.MethodGet, "/report?x=1", nil))
+		}()
+		if i == 0 {
+			<-entered
+		}
+	}
+	time.Sleep(50 * time.Millisecond) // let the followers join the flight
+	close(release)
+	wg.Wait()
+
+	if got := executions.Load(); got != 1 {
+		t.Errorf("handler ran %d times, want 1", got)
+	}
+	for i, rec := range recs {
+		if rec.Code != http.StatusAccepted || rec.Body.String() != "report" || rec.Header().Get("X-Report") != "yes" {
+			t.Errorf("response %d = %d %q %v", i, rec.Code, rec.Body.String(), rec.Header())
+		}
+	}
+}
+
+func TestCoalesce_KeysDiffer(t *testing.T) {
+	var executions atomic.Int32
+	release := make(chan struct{})
+
+	mux := http.NewServeMux()
+	r := New(mux)
+	g := r.With(Coalesce(CoalesceConfig{}))
+	g.HandleFunc("GET /items/{id}", func(w http.ResponseWriter, r *http.Request) {
+		executions.Add(1)
+		<-release
+		w.Write([]byte(r.PathValue("id")))
+	})
+
+	reqs := []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/items/1", nil),
+		httptest.NewRequest(http.MethodGet, "/items/2", nil),
+		httptest.NewRequest(http.MethodGet, "/items/1?page=2", nil),
+		httptest.NewRequest(http.MethodGet, "/items/1", nil),
+	}
+	reqs[3].Header.Set("Authorization", "Bearer other")
+
+	var wg sync.WaitGroup
+	for _, req := range reqs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.ServeHTTP(httptest.NewRecorder(), req)
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if got := executions.Load(); got != int32(len(reqs)) {
+		t.Errorf("handler ran %d times, want %d", got, len(reqs))
+	}
+}
+
+func TestCoalesce_SkipsNonGET(t *testing.T) {
+	var executions atomic.Int32
+	h := Coalesce(CoalesceConfig{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		executions.Add(1)
+	}))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/", nil))
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Range", "bytes=0-10")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	if got := executions.Load(); got != 2 {
+		t.Errorf("handler ran %d times, want 2", got)
+	}
+}
@@ -0,0 +1,79 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+)
+
+// routerDir is the directory of this package, used to find the call site of
+// a registration outside the router.
+var routerDir = func() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Dir(file)
+}()
+
+// conflictPattern extracts the existing pattern from a ServeMux conflict panic.
+var conflictPattern = regexp.MustCompile(`conflicts with pattern "([^"]*)"`)
+
+// handle registers a full pattern on the mux. ServeMux panics on invalid or
+// conflicting patterns with the call site of its own caller, which is always
+// this package; handle panics instead with the group, both full patterns
+// and the call sites of both registrations in the application.
+func (g *Group) handle(pattern string, handler http.Handler) {
+	root := g.rootGroup()
+	site := callSite()
+
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+		reason := fmt.Sprint(r)
+		m := conflictPattern.FindStringSubmatch(reason)
+		if m == nil {
+			panic(fmt.Sprintf("router: invalid pattern %q in group %q (registered at %s): %s", pattern, g.basePath, site, reason))
+		}
+		if _, explanation, ok := strings.Cut(reason, "\n"); ok {
+			reason = explanation
+		}
+		existing := m[1]
+		root.mu.Lock()
+		existingSite, ok := root.sites[existing]
+		root.mu.Unlock()
+		if !ok {
+			existingSite = "unknown"
+		}
+		panic(fmt.Sprintf("router: route conflict in group %q:\n\t%s (registered at %s)\n\tconflicts with %s (registered at %s)\n\t%s",
+			g.basePath, pattern, site, existing, existingSite, reason))
+	}()
+
+	rt := &route{group: g, pattern: pattern, handler: handler}
+	g.serveMux().Handle(pattern, rt)
+	root.mu.Lock()
+	defer root.mu.Unlock()
+	root.routes = append(root.routes, rt)
+	if root.sites == nil {
+		root.sites = make(map[string]string)
+	}
+	root.sites[pattern] = site
+}
+
+// callSite returns the file:line of the innermost caller outside the router
+// package, tests excluded.
+func callSite() string {
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+	for {
+		frame, more := frames.Next()
+		if filepath.Dir(frame.File) != routerDir || strings.HasSuffix(frame.File, "_test.go") {
+			return fmt.Sprintf("%s:%d", frame.File, frame.Line)
+		}
+		if !more {
+			return "unknown"
+		}
+	}
+}
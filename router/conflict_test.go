@@ -0,0 +1,50 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func registerPanic(fn func()) (msg string) {
+	defer func() { msg = fmt.Sprint(recover()) }()
+	fn()
+	return ""
+}
+
+func TestRouteConflictDiagnostics(t *testing.T) {
+	root := New(http.NewServeMux())
+	h := func(http.ResponseWriter, *http.Request) {}
+
+	root.Mount("/api").Get("/items/{id}", h)
+	msg := registerPanic(func() {
+		root.Mount("/api").Mount("/items").Get("/{name}", h)
+	})
+
+	for _, want := range []string{
+		`route conflict in group "/api/items"`,
+		"GET /api/items/{name} (registered at ",
+		"conflicts with GET /api/items/{id} (registered at ",
+		"matches the same requests as",
+	} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("panic message missing %q:\n%s", want, msg)
+		}
+	}
+	sites := regexp.MustCompile(`registered at (\S+)conflict_test\.go:\d+\)`).FindAllString(msg, -1)
+	if len(sites) != 2 {
+		t.Errorf("panic message should name both call sites in this file:\n%s", msg)
+	}
+}
+
+func TestInvalidPatternDiagnostics(t *testing.T) {
+	root := New(http.NewServeMux())
+	msg := registerPanic(func() {
+		root.Mount("/api").HandleFunc("GET /items/{id", func(http.ResponseWriter, *http.Request) {})
+	})
+	if !strings.Contains(msg, `invalid pattern "GET /api/items/{id" in group "/api"`) || !strings.Contains(msg, "conflict_test.go:") {
+		t.Errorf("unexpected panic message:\n%s", msg)
+	}
+}
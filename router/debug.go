@@ -0,0 +1,128 @@
+package router
+
+import (
+	"encoding/json"
+	"html/template"
+	"maps"
+	"net/http"
+	"path"
+	"reflect"
+	"regexp"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+// debugRoute is a row of the route table rendered by DebugHandler.
+type debugRoute struct {
+	Pattern      string   `json:"pattern"`
+	Method       string   `json:"method,omitempty"`
+	Path         string   `json:"path"`
+	BasePath     string   `json:"basePath"`
+	Middlewares  []string `json:"middlewares"`
+	Timeout      string   `json:"timeout,omitempty"`
+	BodyLimit    int64    `json:"bodyLimit,omitempty"`
+	RegisteredAt string   `json:"registeredAt"`
+}
+
+// DebugHandler returns a handler rendering the route table of g's router:
+// every route's pattern, method, path, group base path, group middleware
+// chain (outermost first, root middlewares included), timeout, body limit
+// and the call site that registered it. It renders JSON when the request
+// accepts application/json or has format=json in the query, HTML otherwise.
+// Middlewares passed to Handle for a single route are part of its handler
+// and not listed.
+//
+// The table exposes the application's internals, so mount it in
+// development builds only:
+//
+//	if dev {
+//		r.Handle("GET /debug/routes", router.DebugHandler(r))
+//	}
+func DebugHandler(g *Group) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		routes := debugRoutes(g.rootGroup())
+		if r.URL.Query().Get("format") == "json" || strings.Contains(r.Header.Get("Accept"), "application/json") {
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			enc := json.NewEncoder(w)
+			enc.SetIndent("", "  ")
+			enc.Encode(routes)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		debugTemplate.Execute(w, routes)
+	})
+}
+
+// debugRoutes returns the route table of root sorted by path and method.
+func debugRoutes(root *Group) []debugRoute {
+	root.mu.Lock()
+	routes := slices.Clone(root.routes)
+	sites := maps.Clone(root.sites)
+	root.mu.Unlock()
+
+	table := make([]debugRoute, 0, len(routes))
+	for _, rt := range routes {
+		dr := debugRoute{
+			Pattern:      rt.pattern,
+			Path:         rt.pattern,
+			BasePath:     rt.group.basePath,
+			Middlewares:  []string{},
+			RegisteredAt: sites[rt.pattern],
+		}
+		if method, p, ok := strings.Cut(rt.pattern, " "); ok {
+			dr.Method, dr.Path = method, p
+		}
+		var chain []*Group
+		for g := rt.group; g.parent != nil; g = g.parent {
+			chain = append(chain, g)
+		}
+		chain = append(chain, root)
+		for i := len(chain) - 1; i >= 0; i-- {
+			for _, mw := range chain[i].middlewares {
+				dr.Middlewares = append(dr.Middlewares, funcName(mw))
+			}
+		}
+		if rt.group.timeout > 0 {
+			dr.Timeout = rt.group.timeout.String()
+		}
+		dr.BodyLimit = rt.group.bodyLimit
+		if lr, ok := rt.handler.(*limitedRoute); ok {
+			dr.BodyLimit = lr.limit
+		}
+		table = append(table, dr)
+	}
+	slices.SortStableFunc(table, func(a, b debugRoute) int {
+		if c := strings.Compare(a.Path, b.Path); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Method, b.Method)
+	})
+	return table
+}
+
+// closureSuffix matches the suffix the compiler gives closures, e.g.
+// ".func1" or ".func2.1".
+var closureSuffix = regexp.MustCompile(`(\.func\d+)(\.\d+)*$`)
+
+// funcName returns a readable name of fn such as "middleware.Logger", the
+// function that created fn if it is a closure.
+func funcName(fn any) string {
+	f := runtime.FuncForPC(reflect.ValueOf(fn).Pointer())
+	if f == nil {
+		return "unknown"
+	}
+	return path.Base(closureSuffix.ReplaceAllString(f.Name(), ""))
+}
+
+var debugTemplate = template.Must(template.New("routes").Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>Routes</title>
+<style>body{font-family:sans-serif}table{border-collapse:collapse}td,th{border:1px solid #ccc;padding:4px 8px;text-align:left;vertical-align:top}</style>
+</head><body>
+<h1>Routes ({{len .}})</h1>
+<table>
+<tr><th>Method</th><th>Path</th><th>Base path</th><th>Middlewares</th><th>Timeout</th><th>Body limit</th><th>Registered at</th></tr>
+{{range .}}<tr><td>{{or .Method "any"}}</td><td>{{.Path}}</td><td>{{.BasePath}}</td><td>{{range $i, $m := .Middlewares}}{{if $i}} → {{end}}{{$m}}{{end}}</td><td>{{.Timeout}}</td><td>{{if .BodyLimit}}{{.BodyLimit}}{{end}}</td><td>{{.RegisteredAt}}</td></tr>
+{{end}}</table>
+</body></html>
+`))
@@ -0,0 +1,54 @@
+package router
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDebugHandler(t *testing.T) {
+	ok := func(w http.ResponseWriter, r *http.Request) {}
+	root := New(http.NewServeMux())
+	root.Use(writeBeforeMiddleware("root;"))
+	api := root.Mount("/api").WithTimeout(time.Second).WithBodyLimit(1 << 20)
+	api.Use(Timeout(time.Minute))
+	api.Get("/users/{id}", ok)
+	root.HandleFunc("/about", ok)
+	root.Handle("GET /debug/routes", DebugHandler(root))
+
+	req := httptest.NewRequest(http.MethodGet, "/debug/routes?format=json", nil)
+	rec := httptest.NewRecorder()
+	root.ServeHTTP(rec, req)
+	body := strings.TrimPrefix(rec.Body.String(), "root;")
+
+	var routes []debugRoute
+	if err := json.Unmarshal([]byte(body), &routes); err != nil {
+		t.Fatalf("decode %q: %v", body, err)
+	}
+	if len(routes) != 3 {
+		t.Fatalf("got %d routes, want 3: %+v", len(routes), routes)
+	}
+	if r := routes[0]; r.Path != "/about" || r.Method != "" {
+		t.Errorf("first route = %+v, want /about for any method", r)
+	}
+	user := routes[1]
+	if user.Pattern != "GET /api/users/{id}" || user.BasePath != "/api" || user.Timeout != "1s" || user.BodyLimit != 1<<20 {
+		t.Errorf("user route = %+v", user)
+	}
+	want := []string{"router.writeBeforeMiddleware", "router.Timeout"}
+	if strings.Join(user.Middlewares, ",") != strings.Join(want, ",") {
+		t.Errorf("middlewares = %v, want %v", user.Middlewares, want)
+	}
+	if !strings.Contains(user.RegisteredAt, "debug_test.go:") {
+		t.Errorf("registered at %q, want this test file", user.RegisteredAt)
+	}
+
+	rec = httptest.NewRecorder()
+	root.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/routes", nil))
+	if !strings.Contains(rec.Body.String(), "<td>/api/users/{id}</td>") {
+		t.Errorf("HTML table misses the user route:\n%s", rec.Body.String())
+	}
+}
@@ -1,11 +1,33 @@
 // Package router provides a simple way to group routes and apply middleware
 // using Go's standard http.ServeMux (Go 1.22+). It supports:
 //
-//   - Grouping routes under a common base path
-//   - Attaching middleware stacks at the root or per group
-//   - Mounting static file handlers
-//   - Registering handlers with or without HTTP method prefixes
-//   - Defining custom NotFound (404) handlers
+//   - Grouping routes under a common base path, declared as fluent chains
+//     such as r.Mount("/api").With(auth).Get("/me", me)
+//   - Attaching middleware stacks at the root or per group, including
+//     request context values (see UseValue)
+//   - Skipping middleware for some routes of a group (see UseExcept and UseUnless)
+//   - Request timeouts per group or per route (see WithTimeout)
+//   - Request body size limits per group or per route (see WithBodyLimit)
+//   - Mounting static file handlers and single-page applications (see HandleSPA)
+//   - Rendering html/template views with a layout and development reloads
+//     (see Views and HandleView)
+//   - Serving fs.FS trees such as embed.FS with cache headers for fingerprinted
+//     assets and precompressed variants (see HandleFilesFS)
+//   - Registering handlers with or without HTTP method prefixes, or with
+//     the Get, Post, Put, Patch, Delete, Options and Head helpers
+//   - Per-route middleware passed after the handler to Handle and HandleFunc
+//   - WebSocket routes shielded from hijack-breaking middleware (see HandleWebSocket)
+//   - Error-returning handlers with per-group error rendering (see HandleFuncE)
+//   - Defining custom NotFound (404) and MethodNotAllowed (405) handlers
+//   - Choosing how trailing slash mismatches are handled (see TrailingSlash)
+//   - Coalescing concurrent identical GET requests (see Coalesce)
+//   - Reusing route modules across several muxes (see Module and Registry)
+//   - Serving with signal handling and graceful shutdown (see Serve)
+//   - Serving over HTTPS with ACME certificates and an HTTP redirect (see ServeTLS)
+//   - Labeling metrics by route pattern rather than raw path (see Instrument)
+//   - Asserting the routing table in tests without running handlers (see Match)
+//   - Inspecting the route table in development (see DebugHandler)
+//   - Overriding or removing registered routes (see Replace and Deregister)
 //
 // Example usage:
 //
@@ -27,9 +49,23 @@
 // Middleware added to the root group executes for every request. Middleware
 // added to a subgroup executes only for that group's routes. The order of
 // middleware application is the same as the order they are added, i.e. first
-// added runs outermost.
+// added runs outermost, and a group's middleware runs inside its parent's.
+//
+// Routes and middleware may be declared in any order: each route's handler
+// chain is assembled when the router is built, on the first request or an
+// explicit Build call. After that the router is frozen and Use panics.
+//
+// Middleware that wraps the http.ResponseWriter must keep its optional
+// interfaces (http.Flusher, http.Hijacker, http.Pusher, io.ReaderFrom)
+// reachable, or streaming responses, WebSocket upgrades and sendfile break
+// for every route behind it. The middleware package's ResponseWriter does
+// this and is used by all of its wrapping middleware; custom middleware
+// should wrap with middleware.WrapResponseWriter rather than embedding the
+// writer in its own struct.
 //
 // Route patterns may be plain paths ("/foo") or include an HTTP method prefix
 // ("GET /foo"). Root "/" patterns are normalized to "/{$}" to avoid acting as
-// a catch-all.
+// a catch-all. Conflicting or invalid patterns panic at registration, like
+// with ServeMux, but the message names the group, both full patterns and the
+// application call sites that registered them.
 package router
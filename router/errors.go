@@ -0,0 +1,54 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/en9inerd/go-pkgs/httperrors"
+)
+
+// HandlerFuncE is a handler that returns an error instead of writing it.
+// The error is rendered by the ErrorHandler of the handler's group.
+type HandlerFuncE func(w http.ResponseWriter, r *http.Request) error
+
+// HandleFuncE registers a handler that returns an error. A non-nil error is
+// passed to the group's ErrorHandler, so handlers should return it before
+// writing anything. Trailing middlewares apply only to this route, as for
+// Handle.
+//
+//	api.HandleFuncE("GET /users/{id}", func(w http.ResponseWriter, r *http.Request) error {
+//		user, err := store.User(r.PathValue("id"))
+//		if err != nil {
+//			return httperrors.NewErrorWithErr(http.StatusNotFound, "user not found", err)
+//		}
+//		httpjson.WriteJSON(w, user)
+//		return nil
+//	})
+func (g *Group) HandleFuncE(pattern string, handler HandlerFuncE, mws ...func(http.Handler) http.Handler) {
+	g.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		if err := handler(w, r); err != nil {
+			g.errorHandler()(w, r, err)
+		}
+	}, mws...)
+}
+
+// ErrorHandler sets the function that renders errors returned by the
+// HandleFuncE handlers of the group and its subgroups, unless a subgroup
+// sets its own. Without one, errors are written by httperrors.WriteError:
+// *httperrors.Error and *httperrors.ValidationError with their own status,
+// anything else as a 500 that does not expose the message. It returns g.
+func (g *Group) ErrorHandler(handler func(w http.ResponseWriter, r *http.Request, err error)) *Group {
+	g.onError = handler
+	return g
+}
+
+// errorHandler returns the error handler of the group or its nearest parent.
+func (g *Group) errorHandler() func(http.ResponseWriter, *http.Request, error) {
+	for ; g != nil; g = g.parent {
+		if g.onError != nil {
+			return g.onError
+		}
+	}
+	return func(w http.ResponseWriter, _ *http.Request, err error) {
+		httperrors.WriteError(w, err)
+	}
+}
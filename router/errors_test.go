@@ -0,0 +1,60 @@
+package router
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/en9inerd/go-pkgs/httperrors"
+)
+
+func TestHandleFuncE(t *testing.T) {
+	root := New(http.NewServeMux())
+	api := root.Mount("/api")
+	api.HandleFuncE("GET /ok", func(w http.ResponseWriter, r *http.Request) error {
+		w.Write([]byte("ok"))
+		return nil
+	})
+	api.HandleFuncE("GET /missing", func(w http.ResponseWriter, r *http.Request) error {
+		return httperrors.NewError(http.StatusNotFound, "item not found")
+	})
+	api.HandleFuncE("GET /fail", func(w http.ResponseWriter, r *http.Request) error {
+		return errors.New("database password is hunter2")
+	})
+
+	custom := root.Mount("/v2")
+	custom.ErrorHandler(func(w http.ResponseWriter, r *http.Request, err error) {
+		w.WriteHeader(http.StatusTeapot)
+		w.Write([]byte("custom: " + err.Error()))
+	})
+	custom.Group().HandleFuncE("GET /fail", func(w http.ResponseWriter, r *http.Request) error {
+		return errors.New("boom")
+	})
+
+	tests := []struct {
+		path   string
+		status int
+		body   string
+	}{
+		{"/api/ok", http.StatusOK, "ok"},
+		{"/api/missing", http.StatusNotFound, `"item not found"`},
+		{"/api/fail", http.StatusInternalServerError, "Internal Server Error"},
+		{"/v2/fail", http.StatusTeapot, "custom: boom"},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		root.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if rec.Code != tt.status {
+			t.Errorf("GET %s status = %d, want %d", tt.path, rec.Code, tt.status)
+		}
+		body := rec.Body.String()
+		if !strings.Contains(body, tt.body) {
+			t.Errorf("GET %s body = %q, want it to contain %q", tt.path, body, tt.body)
+		}
+		if strings.Contains(body, "hunter2") {
+			t.Errorf("GET %s exposed the error message: %q", tt.path, body)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package router
+
+import (
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// FilesConfig configures HandleFilesFS.
+type FilesConfig struct {
+	// Dir is the subdirectory of the file system to serve, e.g. "static"
+	// for an embed.FS declared with //go:embed static. Default: the root
+	Dir string
+
+	// CacheControl is the Cache-Control header of files that are not
+	// fingerprinted. Default: none
+	CacheControl string
+
+	// AssetCacheControl is the Cache-Control header of fingerprinted files.
+	// Default: "public, max-age=31536000, immutable"
+	AssetCacheControl string
+
+	// IsFingerprinted reports whether a file name contains a content hash.
+	// Default: IsFingerprinted
+	IsFingerprinted func(name string) bool
+
+	// Precompressed serves name.br or name.gz instead of name when it exists
+	// and the client accepts that encoding, preferring Brotli, so assets can
+	// be compressed once at build time.
+	Precompressed bool
+}
+
+// HandleFilesFS serves the files of fsys, such as an embed.FS, under
+// pattern. Unlike HandleFiles, directories are never listed: a directory is
+// served by its index.html, if any, and is not found otherwise.
+//
+//	//go:embed static
+//	var static embed.FS
+//
+//	r.HandleFilesFS("/static/", static, router.FilesConfig{Dir: "static", Precompressed: true})
+//
+// It panics if Dir is not a valid path.
+func (g *Group) HandleFilesFS(pattern string, fsys fs.FS, cfg FilesConfig) {
+	if cfg.Dir != "" && cfg.Dir != "." {
+		sub, err := fs.Sub(fsys, cfg.Dir)
+		if err != nil {
+			panic("router: HandleFilesFS: " + err.Error())
+		}
+		fsys = sub
+	}
+	if cfg.AssetCacheControl == "" {
+		cfg.AssetCacheControl = "public, max-age=31536000, immutable"
+	}
+	if cfg.IsFingerprinted == nil {
+		cfg.IsFingerprinted = IsFingerprinted
+	}
+
+	files := &fileServer{
+		root:          http.FS(fsys),
+		cacheControl:  cfg.CacheControl,
+		assetCache:    cfg.AssetCacheControl,
+		isAsset:       cfg.IsFingerprinted,
+		precompressed: cfg.Precompressed,
+	}
+	g.handleFiles(pattern, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := path.Clean("/" + r.URL.Path)
+		if !files.serve(w, r, name) && !files.serve(w, r, path.Join(name, "index.html")) {
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+// handleFiles registers a GET handler for the subtree pattern, with the
+// prefix stripped from the request path.
+func (g *Group) handleFiles(pattern string, handler http.Handler) {
+	if !strings.HasSuffix(pattern, "/") {
+		pattern += "/"
+	}
+	full := g.basePath + pattern
+	g.handle(http.MethodGet+" "+full, http.StripPrefix(strings.TrimSuffix(full, "/"), handler))
+}
+
+// fileServer serves single files with cache headers and, optionally,
+// precompressed variants.
+type fileServer struct {
+	root          http.FileSystem
+	cacheControl  string
+	assetCache    string
+	isAsset       func(name string) bool
+	precompressed bool
+}
+
+// precompressedEncodings are the variants looked for, in order of preference.
+var precompressedEncodings = []struct{ encoding, ext string }{
+	{"br", ".br"},
+	{"gzip", ".gz"},
+}
+
+// serve serves the regular file name, if it exists, and reports whether it
+// did.
+func (s *fileServer) serve(w http.ResponseWriter, r *http.Request, name string) bool {
+	return s.serveAs(w, r, name, "")
+}
+
+// serveAs is like serve but uses cacheControl, if not empty, instead of the
+// configured Cache-Control header.
+func (s *fileServer) serveAs(w http.ResponseWriter, r *http.Request, name, cacheControl string) bool {
+	f, err := s.root.Open(name)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil || info.IsDir() {
+		return false
+	}
+
+	if cacheControl == "" {
+		cacheControl = s.cacheControl
+		if s.isAsset(path.Base(name)) {
+			cacheControl = s.assetCache
+		}
+	}
+	h := w.Header()
+	if cacheControl != "" {
+		h.Set("Cache-Control", cacheControl)
+	}
+
+	content, modTime := http.File(f), info.ModTime()
+	if s.precompressed {
+		h.Add("Vary", "Accept-Encoding")
+		for _, p := range precompressedEncodings {
+			if !acceptsEncoding(r, p.encoding) {
+				continue
+			}
+			cf, err := s.root.Open(name + p.ext)
+			if err != nil {
+				continue
+			}
+			defer cf.Close()
+			if cinfo, err := cf.Stat(); err == nil && !cinfo.IsDir() {
+				if ctype := mime.TypeByExtension(path.Ext(name)); ctype != "" {
+					h.Set("Content-Type", ctype)
+				}
+				h.Set("Content-Encoding", p.encoding)
+				content, modTime = cf, cinfo.ModTime()
+				break
+			}
+		}
+	}
+	http.ServeContent(w, r, info.Name(), modTime, content)
+	return true
+}
+
+// acceptsEncoding reports whether the Accept-Encoding header of r allows
+// encoding.
+func acceptsEncoding(r *http.Request, encoding string) bool {
+	for _, header := range r.Header.Values("Accept-Encoding") {
+		for part := range strings.SplitSeq(header, ",") {
+			name, params, _ := strings.Cut(part, ";")
+			if !strings.EqualFold(strings.TrimSpace(name), encoding) {
+				continue
+			}
+			if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+				weight, err := strconv.ParseFloat(q, 64)
+				return err == nil && weight > 0
+			}
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+)
+
+func TestHandleFilesFS(t *testing.T) {
+	files := fstest.MapFS{
+		"static/index.html":         {Data: []byte("home")},
+		"static/app.3f9a1c2b.js":    {Data: []byte("js")},
+		"static/app.3f9a1c2b.js.br": {Data: []byte("js-br")},
+		"static/app.3f9a1c2b.js.gz": {Data: []byte("js-gz")},
+		"static/style.css":          {Data: []byte("css")},
+		"static/docs/readme.txt":    {Data: []byte("readme")},
+		"private/secret.txt":        {Data: []byte("secret")},
+	}
+	root := New(http.NewServeMux())
+	root.HandleFilesFS("/static", files, FilesConfig{Dir: "static", CacheControl: "no-cache", Precompressed: true})
+
+	tests := []struct {
+		path, acceptEncoding string
+		status               int
+		body                 string
+		encoding             string
+		cacheControl         string
+	}{
+		{"/static/", "", http.StatusOK, "home", "", "no-cache"},
+		{"/static/style.css", "gzip, br", http.StatusOK, "css", "", "no-cache"},
+		{"/static/app.3f9a1c2b.js", "", http.StatusOK, "js", "", "public, max-age=31536000, immutable"},
+		{"/static/app.3f9a1c2b.js", "gzip, br", http.StatusOK, "js-br", "br", "public, max-age=31536000, immutable"},
+		{"/static/app.3f9a1c2b.js", "gzip, br;q=0", http.StatusOK, "js-gz", "gzip", "public, max-age=31536000, immutable"},
+		{"/static/docs/", "", http.StatusNotFound, "", "", ""},
+		{"/static/docs/readme.txt", "", http.StatusOK, "readme", "", "no-cache"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		if tt.acceptEncoding != "" {
+			req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+		}
+		rec := httptest.NewRecorder()
+		root.ServeHTTP(rec, req)
+
+		if rec.Code != tt.status {
+			t.Errorf("GET %s status = %d, want %d", tt.path, rec.Code, tt.status)
+			continue
+		}
+		if tt.status != http.StatusOK {
+			continue
+		}
+		if rec.Body.String() != tt.body {
+			t.Errorf("GET %s (%s) body = %q, want %q", tt.path, tt.acceptEncoding, rec.Body.String(), tt.body)
+		}
+		if got := rec.Header().Get("Content-Encoding"); got != tt.encoding {
+			t.Errorf("GET %s (%s) Content-Encoding = %q, want %q", tt.path, tt.acceptEncoding, got, tt.encoding)
+		}
+		if got := rec.Header().Get("Cache-Control"); got != tt.cacheControl {
+			t.Errorf("GET %s Cache-Control = %q, want %q", tt.path, got, tt.cacheControl)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/static/app.3f9a1c2b.js", nil)
+	req.Header.Set("Accept-Encoding", "br")
+	rec := httptest.NewRecorder()
+	root.ServeHTTP(rec, req)
+	if got := rec.Header().Get("Content-Type"); got != "text/javascript; charset=utf-8" {
+		t.Errorf("Content-Type of a precompressed file = %q", got)
+	}
+	if got := rec.Header().Get("Vary"); got != "Accept-Encoding" {
+		t.Errorf("Vary = %q, want Accept-Encoding", got)
+	}
+}
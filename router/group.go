@@ -4,27 +4,64 @@ package router
 
 import (
 	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // Group represents a collection of routes with optional middleware.
 type Group struct {
-	mux         *http.ServeMux
-	basePath    string
+	mux      *http.ServeMux
+	basePath string
+
+	// middlewares added to this group; a subgroup inherits those of its
+	// parent when the router is built
 	middlewares []func(http.Handler) http.Handler
 
 	// optional custom 404 handler
 	notFound http.HandlerFunc
 
+	// optional custom 405 handler
+	methodNotAllowed http.HandlerFunc
+
+	// optional renderer of errors returned by HandleFuncE handlers
+	onError func(http.ResponseWriter, *http.Request, error)
+
+	// trailing slash policies by group base path, kept on the root group
+	slashPolicies map[string]TrailingSlashPolicy
+
+	// templates rendered by HandleView, kept on the root group
+	views *views
+
+	// per-route instrumentation, kept on the root group, see Instrument
+	instrument func(route string, next http.Handler) http.Handler
+
+	// timeout applied to the group's routes, see WithTimeout
+	timeout time.Duration
+
+	// request body limit of the group's routes, see WithBodyLimit
+	bodyLimit int64
+
+	// websocket marks the groups HandleWebSocket registers through
+	websocket bool
+
+	// call sites of the registered full patterns, kept on the root group
+	sites map[string]string
+
 	// root points to the root group for global middleware application.
 	root *Group
 
-	// routesLocked indicates that routes have been registered on the root group
-	// and no further root-level middlewares may be added.
-	routesLocked bool
+	// parent is the group this subgroup was created from, nil for the root.
+	parent *Group
+
+	// routes registered through the root group, wrapped by Build
+	mu     sync.Mutex
+	routes []*route
+	once   sync.Once
+	built  atomic.Bool
 
-	// rootCount captures how many root middlewares were present when this group
-	// was created. Used to avoid double-applying root middlewares.
-	rootCount int
+	// current replaces mux once Deregister rebuilt it
+	current atomic.Pointer[http.ServeMux]
 }
 
 // New creates a new root Group bound to the given mux.
@@ -44,8 +81,13 @@ func (g *Group) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		root = g.root
 	}
 
+	root.Build()
+	r = withUpgradeWriter(w, r)
+
 	// resolve the handler and pattern from mux
-	_, pattern := g.mux.Handler(r)
+	mux := g.serveMux()
+	_, pattern := mux.Handler(r)
+	r, pattern, slashHandler := root.slashRoute(r, pattern)
 
 	if pattern != "" {
 		r2 := *r
@@ -54,18 +96,26 @@ func (g *Group) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 
 	muxHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if pattern == "" && root.notFound != nil {
-			probe := &statusRecorder{status: http.StatusOK}
-			g.mux.ServeHTTP(probe, r)
-
-			if probe.status == http.StatusMethodNotAllowed {
-				g.mux.ServeHTTP(w, r)
+		if slashHandler != nil {
+			slashHandler.ServeHTTP(w, r)
+			return
+		}
+		if pattern == "" {
+			if allow := root.allowedMethods(mux, r); allow != "" {
+				w.Header().Set("Allow", allow)
+				if root.methodNotAllowed != nil {
+					root.methodNotAllowed.ServeHTTP(w, r)
+				} else {
+					http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+				}
+				return
+			}
+			if root.notFound != nil {
+				root.notFound.ServeHTTP(w, r)
 				return
 			}
-			root.notFound.ServeHTTP(w, r)
-			return
 		}
-		g.mux.ServeHTTP(w, r)
+		mux.ServeHTTP(w, r)
 	})
 
 	root.wrapGlobal(muxHandler).ServeHTTP(w, r)
@@ -86,11 +136,17 @@ func (g *Group) Mount(basePath string) *Group {
 // Route configures the group inside the provided function.
 func (g *Group) Route(fn func(*Group)) { fn(g) }
 
-// NotFoundHandler sets a custom 404 handler on the root group.
-func (g *Group) NotFoundHandler(handler http.HandlerFunc) {
-	if g.root != nil {
-		g.root.notFound = handler
-		return
-	}
-	g.notFound = handler
+// NotFoundHandler sets a custom 404 handler on the root group and returns g.
+func (g *Group) NotFoundHandler(handler http.HandlerFunc) *Group {
+	g.rootGroup().notFound = handler
+	return g
+}
+
+// MethodNotAllowedHandler sets a custom 405 handler on the root group. It
+// runs when a path matches routes for other methods only, with the Allow
+// header already set to the methods the path accepts, and is responsible for
+// writing the 405 status; see MethodNotAllowedJSON. It returns g.
+func (g *Group) MethodNotAllowedHandler(handler http.HandlerFunc) *Group {
+	g.rootGroup().methodNotAllowed = handler
+	return g
 }
@@ -127,22 +127,44 @@ func TestWithDoesNotMutateOriginalStack(t *testing.T) {
 	if len(root.middlewares) != 1 {
 		t.Fatalf("root middlewares mutated; want 1 got %d", len(root.middlewares))
 	}
-	if len(newGroup.middlewares) != 2 {
-		t.Fatalf("expected newGroup to have 2 middlewares, got %d", len(newGroup.middlewares))
+	if len(newGroup.middlewares) != 1 || newGroup.parent != root {
+		t.Fatalf("expected newGroup to add 1 middleware to root's, got %d", len(newGroup.middlewares))
 	}
 }
 
-func TestUsePanicsIfRoutesLocked(t *testing.T) {
+func TestUseAfterRoutes(t *testing.T) {
 	mux := http.NewServeMux()
 	g := New(mux)
+	api := g.Mount("/api")
+	admin := api.With(writeBeforeMiddleware("admin;"))
 
-	// Register a route to lock the group
-	g.HandleFunc("/x", func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("x")) })
+	// routes first, middleware afterwards, including on parent groups
+	admin.HandleFunc("/x", func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("x")) })
+	api.Use(writeBeforeMiddleware("api;"))
+	g.Use(writeBeforeMiddleware("root;"))
 
-	// subsequent Use should panic
+	rec := httptest.NewRecorder()
+	g.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/x", nil))
+	if got := rec.Body.String(); got != "root;api;admin;x" {
+		t.Fatalf("unexpected body: %q", got)
+	}
+
+	// the first request built the router, so Use now panics
 	defer func() {
 		if r := recover(); r == nil {
-			t.Fatalf("expected panic from Use after routes registered")
+			t.Fatalf("expected panic from Use after the router was built")
+		}
+	}()
+	api.Use(writeBeforeMiddleware("should-panic;"))
+}
+
+func TestBuildFreezesRouter(t *testing.T) {
+	g := New(http.NewServeMux())
+	g.Mount("/api").Build()
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatalf("expected panic from Use after Build")
 		}
 	}()
 	g.Use(writeBeforeMiddleware("should-panic;"))
@@ -352,3 +374,94 @@ func TestStatusRecorder(t *testing.T) {
 		t.Fatalf("expected Write to return (0,nil), got (%d,%v)", n, err)
 	}
 }
+
+func TestPerRouteMiddlewares(t *testing.T) {
+	mux := http.NewServeMux()
+	root := New(mux)
+	root.Use(writeBeforeMiddleware("root;"))
+
+	api := root.Mount("/api").With(writeBeforeMiddleware("group;"))
+	api.HandleFunc("GET /x", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("x;"))
+	}, writeBeforeMiddleware("auth;"), writeBeforeMiddleware("audit;"))
+	api.Handle("GET /y/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("y;"))
+	}), writeBeforeMiddleware("auth;"))
+	api.HandleFunc("GET /z", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("z;"))
+	})
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/api/x", "root;group;auth;audit;x;"},
+		{"/api/y/1", "root;group;auth;y;"},
+		{"/api/z", "root;group;z;"},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		root.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if got := rec.Body.String(); got != tt.want {
+			t.Errorf("GET %s = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestMethodNotAllowedHandler(t *testing.T) {
+	mux := http.NewServeMux()
+	root := New(mux)
+	api := root.Mount("/api")
+	api.Get("/items", func(w http.ResponseWriter, r *http.Request) {})
+	api.Post("/items", func(w http.ResponseWriter, r *http.Request) {})
+
+	api.MethodNotAllowedHandler(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		w.Write([]byte(`{"error":"method not allowed"}`))
+	})
+
+	rec := httptest.NewRecorder()
+	root.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/api/items", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("status = %d, want 405", rec.Code)
+	}
+	if got := rec.Body.String(); got != `{"error":"method not allowed"}` {
+		t.Errorf("body = %q", got)
+	}
+	if got := rec.Header().Get("Allow"); got != "GET, HEAD, POST" {
+		t.Errorf("Allow = %q, want %q", got, "GET, HEAD, POST")
+	}
+
+	// unknown paths still get the default 404
+	rec = httptest.NewRecorder()
+	root.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/missing", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status for unknown path = %d, want 404", rec.Code)
+	}
+}
+
+func TestFluentChaining(t *testing.T) {
+	root := New(http.NewServeMux()).
+		Use(writeBeforeMiddleware("root;")).
+		NotFoundHandler(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte("custom 404"))
+		})
+	root.Mount("/api").
+		Use(writeBeforeMiddleware("api;")).
+		With(writeBeforeMiddleware("auth;")).
+		Get("/me", func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("me")) })
+
+	rec := httptest.NewRecorder()
+	root.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/me", nil))
+	if got := rec.Body.String(); got != "root;api;auth;me" {
+		t.Errorf("body = %q, want %q", got, "root;api;auth;me")
+	}
+
+	rec = httptest.NewRecorder()
+	root.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/nope", nil))
+	if got := rec.Body.String(); got != "root;custom 404" {
+		t.Errorf("body = %q, want %q", got, "root;custom 404")
+	}
+}
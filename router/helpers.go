@@ -2,31 +2,47 @@ package router
 
 import "net/http"
 
+// clone returns a subgroup of g without middlewares of its own.
 func (g *Group) clone() *Group {
-	mws := make([]func(http.Handler) http.Handler, len(g.middlewares))
-	copy(mws, g.middlewares)
-
-	ng := &Group{
-		mux:         g.mux,
-		basePath:    g.basePath,
-		middlewares: mws,
-		root:        g.root,
-		rootCount:   g.rootCount,
+	return &Group{
+		mux:       g.mux,
+		basePath:  g.basePath,
+		root:      g.rootGroup(),
+		parent:    g,
+		timeout:   g.timeout,
+		bodyLimit: g.bodyLimit,
 	}
-	if ng.root == nil {
-		ng.root = g
-		ng.rootCount = len(g.middlewares)
+}
+
+// serveMux returns the mux routes are registered on and served from.
+func (g *Group) serveMux() *http.ServeMux {
+	root := g.rootGroup()
+	if mux := root.current.Load(); mux != nil {
+		return mux
 	}
-	return ng
+	return root.mux
 }
 
-func (g *Group) lockRoot() { g.routesLocked = true }
+// rootGroup returns the root group of g.
+func (g *Group) rootGroup() *Group {
+	if g.root != nil {
+		return g.root
+	}
+	return g
+}
 
 // statusRecorder is used to probe mux responses.
 type statusRecorder struct {
 	status int
+	header http.Header
+}
+
+func (r *statusRecorder) Header() http.Header {
+	if r.header == nil {
+		r.header = make(http.Header)
+	}
+	return r.header
 }
 
-func (r *statusRecorder) Header() http.Header       { return make(http.Header) }
 func (r *statusRecorder) Write([]byte) (int, error) { return 0, nil }
 func (r *statusRecorder) WriteHeader(status int)    { r.status = status }
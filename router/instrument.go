@@ -0,0 +1,27 @@
+package router
+
+import "net/http"
+
+// Instrument sets a hook on the root group that wraps each route when the
+// router is built, once per route, with the route's full pattern, e.g.
+// "GET /api/users/{id}". Metrics and tracing middleware can use it to label
+// requests by route instead of raw path, which keeps label cardinality
+// bounded. The wrapped handler runs inside the root middlewares and outside
+// those of the route's groups. It returns g and panics once the router is
+// built.
+//
+//	r.Instrument(func(route string, next http.Handler) http.Handler {
+//		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+//			start := time.Now()
+//			next.ServeHTTP(w, r)
+//			latency.WithLabelValues(route).Observe(time.Since(start).Seconds())
+//		})
+//	})
+func (g *Group) Instrument(fn func(route string, next http.Handler) http.Handler) *Group {
+	root := g.rootGroup()
+	if root.built.Load() {
+		panic("router: Instrument called after the router was built")
+	}
+	root.instrument = fn
+	return g
+}
@@ -0,0 +1,51 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestInstrument(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		wraps  = map[string]int{}
+		counts = map[string]int{}
+	)
+	root := New(http.NewServeMux())
+	api := root.Mount("/api").Use(writeBeforeMiddleware("api;"))
+	api.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("user")) })
+	root.Instrument(func(route string, next http.Handler) http.Handler {
+		mu.Lock()
+		wraps[route]++
+		mu.Unlock()
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("metrics;"))
+			next.ServeHTTP(w, r)
+			mu.Lock()
+			counts[route]++
+			mu.Unlock()
+		})
+	})
+
+	for _, id := range []string{"1", "2", "3"} {
+		rec := httptest.NewRecorder()
+		root.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/users/"+id, nil))
+		if got := rec.Body.String(); got != "metrics;api;user" {
+			t.Errorf("body = %q, want %q", got, "metrics;api;user")
+		}
+	}
+
+	const route = "GET /api/users/{id}"
+	if wraps[route] != 1 || counts[route] != 3 {
+		t.Errorf("wraps = %v, counts = %v, want 1 wrap and 3 requests for %q", wraps, counts, route)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Instrument after Build did not panic")
+		}
+	}()
+	root.Instrument(nil)
+}
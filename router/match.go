@@ -0,0 +1,72 @@
+package router
+
+import (
+	"net/http"
+	"strings"
+)
+
+// RouteInfo describes the route that handles a request.
+type RouteInfo struct {
+	// Pattern is the full registered pattern, e.g. "GET /api/users/{id}".
+	Pattern string
+
+	// Params holds the values of the pattern's wildcards.
+	Params map[string]string
+}
+
+// Match reports which registered route would handle a request with method
+// and path, without running any handler or middleware. It reports false if
+// the request would not reach a route: not found, method not allowed, or
+// redirected, taking the trailing slash policy into account. It is meant for
+// table-driven tests of the routing table:
+//
+//	route, ok := r.Match("GET", "/api/users/42")
+//	// route.Pattern == "GET /api/users/{id}", route.Params["id"] == "42"
+func (g *Group) Match(method, path string) (RouteInfo, bool) {
+	if method == "" {
+		method = http.MethodGet
+	}
+	req, err := http.NewRequest(method, path, nil)
+	if err != nil {
+		return RouteInfo{}, false
+	}
+	_, pattern := g.serveMux().Handler(req)
+	req, pattern, handler := g.rootGroup().slashRoute(req, pattern)
+	if handler != nil || !routeMatches(req.URL.Path, pattern) {
+		return RouteInfo{}, false
+	}
+	return RouteInfo{Pattern: pattern, Params: patternParams(pattern, req.URL.Path)}, true
+}
+
+// patternParams extracts the wildcard values of pattern from path, which
+// the pattern is known to match.
+func patternParams(pattern, path string) map[string]string {
+	if _, p, ok := strings.Cut(pattern, " "); ok {
+		pattern = p
+	}
+	if i := strings.IndexByte(pattern, '/'); i > 0 {
+		pattern = pattern[i:]
+	}
+	patSegs := strings.Split(strings.TrimPrefix(pattern, "/"), "/")
+	pathSegs := strings.Split(strings.TrimPrefix(path, "/"), "/")
+
+	params := make(map[string]string)
+	for i, seg := range patSegs {
+		if !strings.HasPrefix(seg, "{") || !strings.HasSuffix(seg, "}") || seg == "{$}" {
+			continue
+		}
+		name := seg[1 : len(seg)-1]
+		if rest, ok := strings.CutSuffix(name, "..."); ok {
+			if i < len(pathSegs) {
+				params[rest] = strings.Join(pathSegs[i:], "/")
+			} else {
+				params[rest] = ""
+			}
+			break
+		}
+		if i < len(pathSegs) {
+			params[name] = pathSegs[i]
+		}
+	}
+	return params
+}
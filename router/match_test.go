@@ -0,0 +1,52 @@
+package router
+
+import (
+	"maps"
+	"net/http"
+	"testing"
+)
+
+func TestMatch(t *testing.T) {
+	root := New(http.NewServeMux())
+	api := root.Mount("/api")
+	h := func(http.ResponseWriter, *http.Request) {}
+	api.Get("/users/{id}", h)
+	api.Post("/users", h)
+	api.Get("/files/{path...}", h)
+	api.Get("/docs/", h)
+	root.Get("/", h)
+
+	tests := []struct {
+		method, path string
+		ok           bool
+		pattern      string
+		params       map[string]string
+	}{
+		{"GET", "/api/users/42", true, "GET /api/users/{id}", map[string]string{"id": "42"}},
+		{"HEAD", "/api/users/42", true, "GET /api/users/{id}", map[string]string{"id": "42"}},
+		{"POST", "/api/users", true, "POST /api/users", map[string]string{}},
+		{"", "/api/files/a/b.txt", true, "GET /api/files/{path...}", map[string]string{"path": "a/b.txt"}},
+		{"GET", "/api/docs/intro", true, "GET /api/docs/", map[string]string{}},
+		{"GET", "/", true, "GET /{$}", map[string]string{}},
+		{"DELETE", "/api/users/42", false, "", nil},
+		{"GET", "/api/docs", false, "", nil},
+		{"GET", "/api/missing", false, "", nil},
+	}
+	for _, tt := range tests {
+		route, ok := root.Match(tt.method, tt.path)
+		if ok != tt.ok {
+			t.Errorf("Match(%s %s) ok = %v, want %v", tt.method, tt.path, ok, tt.ok)
+			continue
+		}
+		if route.Pattern != tt.pattern || !maps.Equal(route.Params, tt.params) {
+			t.Errorf("Match(%s %s) = %+v, want %s %v", tt.method, tt.path, route, tt.pattern, tt.params)
+		}
+	}
+
+	strict := New(http.NewServeMux())
+	strict.Get("/items", h)
+	strict.TrailingSlash(TrailingSlashMatch)
+	if route, ok := strict.Match("GET", "/items/"); !ok || route.Pattern != "GET /items" {
+		t.Errorf("Match with TrailingSlashMatch = %+v, %v", route, ok)
+	}
+}
@@ -0,0 +1,41 @@
+package router
+
+import "net/http"
+
+// Get registers handler for GET requests to path. Like a "GET path" pattern,
+// it also matches HEAD requests. Trailing middlewares apply only to this
+// route, as for Handle.
+func (g *Group) Get(path string, handler http.HandlerFunc, mws ...func(http.Handler) http.Handler) {
+	g.HandleFunc(http.MethodGet+" "+path, handler, mws...)
+}
+
+// Post registers handler for POST requests to path.
+func (g *Group) Post(path string, handler http.HandlerFunc, mws ...func(http.Handler) http.Handler) {
+	g.HandleFunc(http.MethodPost+" "+path, handler, mws...)
+}
+
+// Put registers handler for PUT requests to path.
+func (g *Group) Put(path string, handler http.HandlerFunc, mws ...func(http.Handler) http.Handler) {
+	g.HandleFunc(http.MethodPut+" "+path, handler, mws...)
+}
+
+// Patch registers handler for PATCH requests to path.
+func (g *Group) Patch(path string, handler http.HandlerFunc, mws ...func(http.Handler) http.Handler) {
+	g.HandleFunc(http.MethodPatch+" "+path, handler, mws...)
+}
+
+// Delete registers handler for DELETE requests to path.
+func (g *Group) Delete(path string, handler http.HandlerFunc, mws ...func(http.Handler) http.Handler) {
+	g.HandleFunc(http.MethodDelete+" "+path, handler, mws...)
+}
+
+// Options registers handler for OPTIONS requests to path.
+func (g *Group) Options(path string, handler http.HandlerFunc, mws ...func(http.Handler) http.Handler) {
+	g.HandleFunc(http.MethodOptions+" "+path, handler, mws...)
+}
+
+// Head registers handler for HEAD requests to path, taking precedence over
+// a Get handler for the same path.
+func (g *Group) Head(path string, handler http.HandlerFunc, mws ...func(http.Handler) http.Handler) {
+	g.HandleFunc(http.MethodHead+" "+path, handler, mws...)
+}
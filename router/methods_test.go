@@ -0,0 +1,55 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMethodHelpers(t *testing.T) {
+	mux := http.NewServeMux()
+	root := New(mux)
+	api := root.Mount("/api")
+
+	respond := func(body string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(body))
+		}
+	}
+	api.Get("/items", respond("get"))
+	api.Post("/items", respond("post"))
+	api.Put("/items/{id}", respond("put"))
+	api.Patch("/items/{id}", respond("patch"))
+	api.Delete("/items/{id}", respond("delete"), writeBeforeMiddleware("auth;"))
+	api.Options("/items", respond("options"))
+	api.Head("/status", respond("head"))
+	api.Get("/status", respond("get-status"))
+
+	tests := []struct {
+		method, path string
+		status       int
+		body         string
+	}{
+		{http.MethodGet, "/api/items", http.StatusOK, "get"},
+		{http.MethodHead, "/api/items", http.StatusOK, "get"},
+		{http.MethodPost, "/api/items", http.StatusOK, "post"},
+		{http.MethodPut, "/api/items/1", http.StatusOK, "put"},
+		{http.MethodPatch, "/api/items/1", http.StatusOK, "patch"},
+		{http.MethodDelete, "/api/items/1", http.StatusOK, "auth;delete"},
+		{http.MethodOptions, "/api/items", http.StatusOK, "options"},
+		{http.MethodHead, "/api/status", http.StatusOK, "head"},
+		{http.MethodGet, "/api/status", http.StatusOK, "get-status"},
+		{http.MethodDelete, "/api/items", http.StatusMethodNotAllowed, ""},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		root.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+
+		if rec.Code != tt.status {
+			t.Errorf("%s %s status = %d, want %d", tt.method, tt.path, rec.Code, tt.status)
+		}
+		if got := rec.Body.String(); tt.status == http.StatusOK && got != tt.body {
+			t.Errorf("%s %s body = %q, want %q", tt.method, tt.path, got, tt.body)
+		}
+	}
+}
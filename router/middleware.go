@@ -2,33 +2,22 @@ package router
 
 import "net/http"
 
-// Use appends middleware(s) to the group.
-func (g *Group) Use(mw func(http.Handler) http.Handler, more ...func(http.Handler) http.Handler) {
-	if g.routesLocked {
-		panic("router: Use called after routes were registered; add middleware before routes or use Group/With")
+// Use appends middleware(s) to the group and returns the group. Middleware
+// applies to all routes of the group and its subgroups, including routes
+// registered before the call. It panics once the router is built.
+func (g *Group) Use(mw func(http.Handler) http.Handler, more ...func(http.Handler) http.Handler) *Group {
+	if g.rootGroup().built.Load() {
+		panic("router: Use called after the router was built; add middleware before serving requests or calling Build")
 	}
 	g.middlewares = append(g.middlewares, mw)
 	g.middlewares = append(g.middlewares, more...)
+	return g
 }
 
 // With returns a new group with appended middleware(s).
 func (g *Group) With(mw func(http.Handler) http.Handler, more ...func(http.Handler) http.Handler) *Group {
-	newStack := make([]func(http.Handler) http.Handler, len(g.middlewares), len(g.middlewares)+len(more)+1)
-	copy(newStack, g.middlewares)
-	newStack = append(newStack, mw)
-	newStack = append(newStack, more...)
-
-	ng := &Group{
-		mux:         g.mux,
-		basePath:    g.basePath,
-		middlewares: newStack,
-		root:        g.root,
-		rootCount:   g.rootCount,
-	}
-	if ng.root == nil {
-		ng.root = g
-		ng.rootCount = len(g.middlewares)
-	}
+	ng := g.clone()
+	ng.middlewares = append([]func(http.Handler) http.Handler{mw}, more...)
 	return ng
 }
 
@@ -40,14 +29,39 @@ func Wrap(handler http.Handler, mw1 func(http.Handler) http.Handler, mws ...func
 	return mw1(handler)
 }
 
-// wrapMiddleware applies the group's middlewares.
+// wrapRoute applies per-route middlewares, the first one outermost. If one
+// of them is BodyLimit, the result is a *limitedRoute, so the group's body
+// limit gives way to the route's.
+func wrapRoute(handler http.Handler, mws []func(http.Handler) http.Handler) http.Handler {
+	limit := int64(-1)
+	if lr, ok := handler.(*limitedRoute); ok {
+		handler, limit = lr.Handler, lr.limit
+	}
+	for i := len(mws) - 1; i >= 0; i-- {
+		handler = mws[i](handler)
+		if bl, ok := handler.(*bodyLimitHandler); ok && limit < 0 {
+			limit = bl.n
+		}
+	}
+	if limit >= 0 {
+		return &limitedRoute{Handler: handler, limit: limit}
+	}
+	return handler
+}
+
+// wrapMiddleware applies the middlewares of the group and its parents, up
+// to but not including the root group, whose middlewares wrapGlobal applies.
 func (g *Group) wrapMiddleware(handler http.Handler) http.Handler {
-	if g.root == nil {
-		return handler
+	if _, ok := handler.(*limitedRoute); !ok && g.bodyLimit > 0 {
+		handler = limitBody(handler, g.bodyLimit)
+	}
+	if g.timeout > 0 {
+		handler = http.TimeoutHandler(handler, g.timeout, "")
 	}
-	start := min(g.rootCount, len(g.middlewares))
-	for i := len(g.middlewares) - 1; i >= start; i-- {
-		handler = g.middlewares[i](handler)
+	for ; g.parent != nil; g = g.parent {
+		for i := len(g.middlewares) - 1; i >= 0; i-- {
+			handler = g.middlewares[i](handler)
+		}
 	}
 	return handler
 }
@@ -0,0 +1,87 @@
+package router
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Module registers a set of routes on a group. Because a module only talks
+// to the group it is given, the same module can be applied to several root
+// groups, e.g. a public and an admin server listening on different ports,
+// each with its own middleware stack.
+type Module func(*Group)
+
+// Include applies the modules to g in order. Each module gets its own
+// subgroup, so middleware it adds with Use applies only to its own routes.
+func (g *Group) Include(modules ...Module) {
+	for _, m := range modules {
+		m(g.Group())
+	}
+}
+
+// Registry is a named collection of route modules that can be applied to
+// any number of groups.
+//
+//	modules := router.NewRegistry()
+//	modules.Register("health", healthRoutes)
+//	modules.Register("users", userRoutes)
+//	modules.Register("admin", adminRoutes)
+//
+//	public := router.New(http.NewServeMux())
+//	public.Use(middleware.RateLimit(rlCfg))
+//	modules.Apply(public, "health", "users")
+//
+//	admin := router.New(http.NewServeMux())
+//	admin.Use(basicAuth)
+//	modules.Apply(admin) // every module
+type Registry struct {
+	mu      sync.Mutex
+	names   []string
+	modules map[string]Module
+}
+
+// NewRegistry creates an empty module registry.
+func NewRegistry() *Registry {
+	return &Registry{modules: make(map[string]Module)}
+}
+
+// Register adds a module under name. It panics if name is already
+// registered, like http.ServeMux does for conflicting patterns.
+func (r *Registry) Register(name string, m Module) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.modules[name]; ok {
+		panic(fmt.Sprintf("router: module %q already registered", name))
+	}
+	r.names = append(r.names, name)
+	r.modules[name] = m
+}
+
+// Names returns the registered module names in registration order.
+func (r *Registry) Names() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.names...)
+}
+
+// Apply includes the named modules in g, in the given order. With no names
+// every module is applied in registration order. It panics if a name is not
+// registered.
+func (r *Registry) Apply(g *Group, names ...string) {
+	r.mu.Lock()
+	if len(names) == 0 {
+		names = r.names
+	}
+	modules := make([]Module, 0, len(names))
+	for _, name := range names {
+		m, ok := r.modules[name]
+		if !ok {
+			r.mu.Unlock()
+			panic(fmt.Sprintf("router: unknown module %q", name))
+		}
+		modules = append(modules, m)
+	}
+	r.mu.Unlock()
+
+	g.Include(modules...)
+}
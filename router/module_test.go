@@ -0,0 +1,97 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRegistry_ApplyToMultipleRoots(t *testing.T) {
+	modules := NewRegistry()
+	modules.Register("health", func(g *Group) {
+		g.HandleFunc("GET /health", func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("ok;"))
+		})
+	})
+	modules.Register("users", func(g *Group) {
+		api := g.Mount("/users")
+		api.Use(writeBeforeMiddleware("users;"))
+		api.HandleFunc("GET /list", func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("list;"))
+		})
+	})
+
+	public := New(http.NewServeMux())
+	public.Use(writeBeforeMiddleware("public;"))
+	modules.Apply(public)
+
+	admin := New(http.NewServeMux())
+	admin.Use(writeBeforeMiddleware("admin;"))
+	modules.Apply(admin, "health")
+
+	tests := []struct {
+		name string
+		root *Group
+		path string
+		body string
+	}{
+		{"public health", public, "/health", "public;ok;"},
+		{"public users", public, "/users/list", "public;users;list;"},
+		{"admin health", admin, "/health", "admin;ok;"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			tt.root.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if rec.Body.String() != tt.body {
+				t.Errorf("body = %q, want %q", rec.Body.String(), tt.body)
+			}
+		})
+	}
+
+	if _, pattern := admin.Handler(httptest.NewRequest(http.MethodGet, "/users/list", nil)); pattern != "" {
+		t.Errorf("users module registered on admin mux as %q", pattern)
+	}
+}
+
+func TestInclude_ModuleMiddlewareIsScoped(t *testing.T) {
+	root := New(http.NewServeMux())
+	root.Include(
+		func(g *Group) {
+			g.Use(writeBeforeMiddleware("a;"))
+			g.HandleFunc("/a", func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("A")) })
+		},
+		func(g *Group) {
+			g.HandleFunc("/b", func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("B")) })
+		},
+	)
+
+	for path, want := range map[string]string{"/a": "a;A", "/b": "B"} {
+		rec := httptest.NewRecorder()
+		root.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Body.String() != want {
+			t.Errorf("%s body = %q, want %q", path, rec.Body.String(), want)
+		}
+	}
+}
+
+func TestRegistry_Panics(t *testing.T) {
+	modules := NewRegistry()
+	modules.Register("a", func(*Group) {})
+
+	assertPanics := func(name string, fn func()) {
+		t.Helper()
+		defer func() {
+			if recover() == nil {
+				t.Errorf("%s: expected panic", name)
+			}
+		}()
+		fn()
+	}
+	assertPanics("duplicate", func() { modules.Register("a", func(*Group) {}) })
+	assertPanics("unknown", func() { modules.Apply(New(http.NewServeMux()), "missing") })
+
+	if names := modules.Names(); len(names) != 1 || names[0] != "a" {
+		t.Errorf("Names = %v, want [a]", names)
+	}
+}
@@ -0,0 +1,55 @@
+package router
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Deregister removes the route registered on g with pattern, as passed to
+// Handle or HandleFunc, and reports whether there was one. ServeMux cannot
+// remove patterns, so the remaining routes move to a new mux: from then on,
+// serve the router through a group rather than the mux passed to New.
+func (g *Group) Deregister(pattern string) bool {
+	candidates := []string{g.fullPattern(pattern)}
+	if strings.HasSuffix(pattern, "/") {
+		// Handle registers subtree patterns without normalizing "/"
+		if method, path, ok := strings.Cut(pattern, " "); ok {
+			candidates = append(candidates, method+" "+g.basePath+path)
+		} else {
+			candidates = append(candidates, g.basePath+pattern)
+		}
+	}
+
+	root := g.rootGroup()
+	root.mu.Lock()
+	defer root.mu.Unlock()
+
+	i := -1
+	for j, rt := range root.routes {
+		for _, c := range candidates {
+			if rt.pattern == c {
+				i = j
+			}
+		}
+	}
+	if i < 0 {
+		return false
+	}
+
+	delete(root.sites, root.routes[i].pattern)
+	root.routes = append(root.routes[:i:i], root.routes[i+1:]...)
+	mux := http.NewServeMux()
+	for _, rt := range root.routes {
+		mux.Handle(rt.pattern, rt)
+	}
+	root.current.Store(mux)
+	return true
+}
+
+// Replace registers handler for pattern like Handle, first removing the
+// route registered on g with the same pattern, if any, so plugins and tests
+// can override default routes.
+func (g *Group) Replace(pattern string, handler http.Handler, mws ...func(http.Handler) http.Handler) {
+	g.Deregister(pattern)
+	g.Handle(pattern, handler, mws...)
+}
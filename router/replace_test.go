@@ -0,0 +1,70 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestReplaceAndDeregister(t *testing.T) {
+	root := New(http.NewServeMux())
+	api := root.Mount("/api").With(writeBeforeMiddleware("api;"))
+	respond := func(body string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) { w.Write([]byte(body)) }
+	}
+	api.Get("/users", respond("default users"))
+	api.Get("/health", respond("ok"))
+	api.Handle("/static/", respond("static"))
+
+	api.Replace("GET /users", respond("plugin users"))
+	if !api.Deregister("/static/") {
+		t.Fatal("Deregister of a subtree route reported false")
+	}
+	if api.Deregister("GET /missing") {
+		t.Error("Deregister of an unknown route reported true")
+	}
+	api.Replace("GET /health", respond("plugin health"))
+
+	tests := []struct {
+		path   string
+		status int
+		body   string
+	}{
+		{"/api/users", http.StatusOK, "api;plugin users"},
+		{"/api/health", http.StatusOK, "api;plugin health"},
+		{"/api/static/app.js", http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		root.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if rec.Code != tt.status {
+			t.Errorf("GET %s status = %d, want %d", tt.path, rec.Code, tt.status)
+		}
+		if tt.body != "" && rec.Body.String() != tt.body {
+			t.Errorf("GET %s body = %q, want %q", tt.path, rec.Body.String(), tt.body)
+		}
+	}
+
+	// a removed pattern can be registered again without a conflict
+	api.Handle("/static/", respond("static again"))
+	if route, ok := root.Match("GET", "/api/static/x"); !ok || route.Pattern != "/api/static/" {
+		t.Errorf("Match after re-registering = %+v, %v", route, ok)
+	}
+}
+
+func TestReplace_ConcurrentWithDebug(t *testing.T) {
+	root := New(http.NewServeMux())
+	root.HandleFunc("GET /a", func(w http.ResponseWriter, r *http.Request) {})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range 100 {
+			root.Replace("GET /a", http.NotFoundHandler())
+		}
+	}()
+	for range 100 {
+		debugRoutes(root)
+	}
+	<-done
+}
@@ -5,50 +5,52 @@ import (
 	"strings"
 )
 
-// Handle registers a route with middlewares applied.
-func (g *Group) Handle(pattern string, handler http.Handler) {
-	g.lockRoot()
+// Handle registers a route with middlewares applied. Trailing middlewares
+// apply only to this route, inside the group's middlewares, the first one
+// outermost:
+//
+//	g.Handle("GET /admin", adminHandler, authMW, auditMW)
+func (g *Group) Handle(pattern string, handler http.Handler, mws ...func(http.Handler) http.Handler) {
+	handler = wrapRoute(handler, mws)
 
 	if strings.HasSuffix(pattern, "/") {
 		method, path, ok := strings.Cut(pattern, " ")
 		if ok {
 			full := method + " " + g.basePath + path
-			g.mux.Handle(full, g.wrapMiddleware(handler))
+			g.handle(full, handler)
 		} else {
 			full := g.basePath + pattern
-			g.mux.Handle(full, g.wrapMiddleware(handler))
+			g.handle(full, handler)
 		}
 		return
 	}
-	g.register(pattern, handler.ServeHTTP)
+	g.register(pattern, handler)
 }
 
-// HandleFunc registers a route handler function.
-func (g *Group) HandleFunc(pattern string, handler http.HandlerFunc) {
-	g.register(pattern, handler)
+// HandleFunc registers a route handler function. Trailing middlewares apply
+// only to this route, as for Handle.
+func (g *Group) HandleFunc(pattern string, handler http.HandlerFunc, mws ...func(http.Handler) http.Handler) {
+	g.register(pattern, wrapRoute(handler, mws))
 }
 
 // HandleFiles serves static files.
 func (g *Group) HandleFiles(pattern string, root http.FileSystem) {
-	g.lockRoot()
-
 	if !strings.HasSuffix(pattern, "/") {
 		pattern += "/"
 	}
 	full := g.basePath + pattern
 
 	if pattern == "/" && g.basePath == "" {
-		g.mux.Handle("/", g.wrapMiddleware(http.FileServer(root)))
+		g.handle("/", http.FileServer(root))
 		return
 	}
 
 	handler := http.StripPrefix(strings.TrimSuffix(full, "/"), http.FileServer(root))
-	g.mux.Handle(full, g.wrapMiddleware(handler))
+	g.handle(full, handler)
 }
 
 // HandleRoot registers a handler for the group's root without redirect.
 func (g *Group) HandleRoot(method string, handler http.Handler) {
-	g.lockRoot()
 	pattern := g.basePath
 	if pattern == "" {
 		pattern = "/"
@@ -56,12 +58,11 @@ func (g *Group) HandleRoot(method string, handler http.Handler) {
 	if method != "" {
 		pattern = method + " " + pattern
 	}
-	g.mux.Handle(pattern, g.wrapMiddleware(handler))
+	g.handle(pattern, handler)
 }
 
 // HandleRootFunc registers a root handler func.
 func (g *Group) HandleRootFunc(method string, handler http.HandlerFunc) {
-	g.lockRoot()
 	pattern := g.basePath
 	if pattern == "" {
 		pattern = "/"
@@ -69,17 +70,20 @@ func (g *Group) HandleRootFunc(method string, handler http.HandlerFunc) {
 	if method != "" {
 		pattern = method + " " + pattern
 	}
-	g.mux.HandleFunc(pattern, g.wrapMiddleware(handler).ServeHTTP)
+	g.handle(pattern, handler)
 }
 
 // Handler proxies to mux.Handler.
 func (g *Group) Handler(r *http.Request) (h http.Handler, pattern string) {
-	return g.mux.Handler(r)
+	return g.serveMux().Handler(r)
 }
 
-func (g *Group) register(pattern string, handler http.HandlerFunc) {
-	g.lockRoot()
+func (g *Group) register(pattern string, handler http.Handler) {
+	g.handle(g.fullPattern(pattern), handler)
+}
 
+// fullPattern returns the mux pattern registered by HandleFunc for pattern.
+func (g *Group) fullPattern(pattern string) string {
 	var path, method string
 	if m, p, ok := strings.Cut(pattern, " "); ok {
 		method, path = m, p
@@ -96,5 +100,5 @@ func (g *Group) register(pattern string, handler http.HandlerFunc) {
 			pattern = g.basePath + "/{$}"
 		}
 	}
-	g.mux.HandleFunc(pattern, g.wrapMiddleware(handler).ServeHTTP)
+	return pattern
 }
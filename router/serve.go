@@ -0,0 +1,173 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// ServeConfig configures Serve.
+type ServeConfig struct {
+	// ReadHeaderTimeout limits how long reading request headers may take.
+	// Default: 10s
+	ReadHeaderTimeout time.Duration
+
+	// ReadTimeout limits reading the whole request. Default: 0 (no limit)
+	ReadTimeout time.Duration
+
+	// WriteTimeout limits writing the response. It would cut off long-poll
+	// and SSE responses, so prefer WithTimeout on the groups that need one.
+	// Default: 0 (no limit)
+	WriteTimeout time.Duration
+
+	// IdleTimeout is how long keep-alive connections wait for the next
+	// request. Default: 120s
+	IdleTimeout time.Duration
+
+	// ShutdownTimeout limits how long in-flight requests are waited for
+	// after a shutdown signal, and how long the shutdown hooks may run.
+	// Default: 30s
+	ShutdownTimeout time.Duration
+
+	// Signals trigger a graceful shutdown. Default: SIGINT, SIGTERM
+	Signals []os.Signal
+
+	// OnShutdown hooks run in order once in-flight requests are drained,
+	// e.g. to close databases or flush telemetry. Their errors are returned
+	// by Serve.
+	OnShutdown []func(context.Context) error
+
+	// Listener, if set, is served instead of listening on addr.
+	Listener net.Listener
+
+	// Logger logs the server lifecycle and HTTP server errors.
+	// Default: slog.Default()
+	Logger *slog.Logger
+}
+
+// Serve builds the router and serves it on addr until ctx is done or one of
+// the configured signals arrives. It then stops accepting connections,
+// waits for in-flight requests to finish and runs the shutdown hooks. It
+// returns nil after a clean shutdown; otherwise the error of the listener,
+// the server, the drain or the hooks.
+//
+//	err := router.Serve(ctx, ":8080", r, router.ServeConfig{
+//		OnShutdown: []func(context.Context) error{
+//			func(ctx context.Context) error { return db.Close() },
+//		},
+//	})
+func Serve(ctx context.Context, addr string, g *Group, cfg ServeConfig) error {
+	cfg = cfg.withDefaults()
+	g.Build()
+	srv := cfg.server(addr, g)
+
+	ln := cfg.Listener
+	if ln == nil {
+		var err error
+		if ln, err = net.Listen("tcp", addr); err != nil {
+			return err
+		}
+	}
+	return run(ctx, cfg, []listener{{srv: srv, ln: ln}})
+}
+
+// withDefaults returns cfg with the documented defaults filled in.
+func (cfg ServeConfig) withDefaults() ServeConfig {
+	if cfg.ReadHeaderTimeout == 0 {
+		cfg.ReadHeaderTimeout = 10 * time.Second
+	}
+	if cfg.IdleTimeout == 0 {
+		cfg.IdleTimeout = 120 * time.Second
+	}
+	if cfg.ShutdownTimeout == 0 {
+		cfg.ShutdownTimeout = 30 * time.Second
+	}
+	if len(cfg.Signals) == 0 {
+		cfg.Signals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
+	return cfg
+}
+
+// server returns an http.Server for handler configured by cfg.
+func (cfg ServeConfig) server(addr string, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		ReadTimeout:       cfg.ReadTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+		ErrorLog:          slog.NewLogLogger(cfg.Logger.Handler(), slog.LevelError),
+	}
+}
+
+// listener is a server and the listener it serves, with TLS if tls is set.
+type listener struct {
+	srv *http.Server
+	ln  net.Listener
+	tls bool
+}
+
+// run serves the listeners until ctx is done, one of the configured signals
+// arrives or a server fails, then shuts all servers down as Serve describes.
+func run(ctx context.Context, cfg ServeConfig, listeners []listener) error {
+	ctx, stop := signal.NotifyContext(ctx, cfg.Signals...)
+	defer stop()
+
+	serveErr := make(chan error, len(listeners))
+	for _, l := range listeners {
+		go func() {
+			if l.tls {
+				serveErr <- l.srv.ServeTLS(l.ln, "", "")
+			} else {
+				serveErr <- l.srv.Serve(l.ln)
+			}
+		}()
+		cfg.Logger.Info("server started", "addr", l.ln.Addr().String(), "tls", l.tls)
+	}
+
+	var errs []error
+	pending := len(listeners)
+	select {
+	case err := <-serveErr:
+		pending--
+		if len(listeners) == 1 {
+			return err
+		}
+		errs = append(errs, err)
+	case <-ctx.Done():
+	}
+	stop()
+	cfg.Logger.Info("shutting down server", "timeout", cfg.ShutdownTimeout)
+
+	shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cfg.ShutdownTimeout)
+	defer cancel()
+
+	for _, l := range listeners {
+		errs = append(errs, l.srv.Shutdown(shutdownCtx))
+	}
+	for ; pending > 0; pending-- {
+		if err := <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+			errs = append(errs, err)
+		}
+	}
+	for _, hook := range cfg.OnShutdown {
+		errs = append(errs, hook(shutdownCtx))
+	}
+	err := errors.Join(errs...)
+	if err != nil {
+		cfg.Logger.Error("server shutdown failed", "error", err)
+	} else {
+		cfg.Logger.Info("server stopped")
+	}
+	return err
+}
@@ -0,0 +1,76 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestServeGracefulShutdown(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	g := New(http.NewServeMux())
+	g.Get("/slow", func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		w.Write([]byte("done"))
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var hooks []string
+	errHook := errors.New("close failed")
+	served := make(chan error, 1)
+	go func() {
+		served <- Serve(ctx, "", g, ServeConfig{
+			Listener: ln,
+			Logger:   slog.New(slog.DiscardHandler),
+			OnShutdown: []func(context.Context) error{
+				func(context.Context) error { hooks = append(hooks, "db"); return nil },
+				func(context.Context) error { hooks = append(hooks, "cache"); return errHook },
+			},
+		})
+	}()
+
+	body := make(chan string, 1)
+	go func() {
+		resp, err := http.Get("http://" + ln.Addr().String() + "/slow")
+		if err != nil {
+			body <- err.Error()
+			return
+		}
+		defer resp.Body.Close()
+		b, _ := io.ReadAll(resp.Body)
+		body <- string(b)
+	}()
+
+	<-started
+	cancel()
+	time.Sleep(20 * time.Millisecond)
+	select {
+	case err := <-served:
+		t.Fatalf("Serve returned with a request in flight: %v", err)
+	default:
+	}
+	close(release)
+
+	if got := <-body; got != "done" {
+		t.Errorf("in-flight request got %q, want done", got)
+	}
+	err = <-served
+	if !errors.Is(err, errHook) {
+		t.Errorf("Serve = %v, want the hook error", err)
+	}
+	if len(hooks) != 2 || hooks[0] != "db" || hooks[1] != "cache" {
+		t.Errorf("hooks ran as %v", hooks)
+	}
+}
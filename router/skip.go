@@ -0,0 +1,55 @@
+package router
+
+import (
+	"net/http"
+	"strings"
+)
+
+// UseExcept appends middleware that is bypassed for the routes registered
+// with any of patterns, given as for HandleFunc on g. A pattern without a
+// method skips the route for every method, so "/health" matches both
+// "/health" and "GET /health". It returns g.
+//
+//	api.UseExcept(authMiddleware, "/health", "GET /metrics")
+func (g *Group) UseExcept(mw func(http.Handler) http.Handler, patterns ...string) *Group {
+	full := make([]string, len(patterns))
+	for i, p := range patterns {
+		full[i] = g.fullPattern(p)
+	}
+	return g.UseUnless(mw, func(r *http.Request) bool {
+		for _, p := range full {
+			if r.Pattern == p || !strings.Contains(p, " ") && patternPath(r.Pattern) == p {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// UseUnless appends middleware that is bypassed for requests for which skip
+// returns true. r.Pattern holds the matched route's full pattern. It
+// returns g.
+//
+//	r.UseUnless(compress, func(r *http.Request) bool {
+//		return strings.HasPrefix(r.URL.Path, "/events")
+//	})
+func (g *Group) UseUnless(mw func(http.Handler) http.Handler, skip func(r *http.Request) bool) *Group {
+	return g.Use(func(next http.Handler) http.Handler {
+		wrapped := mw(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if skip(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			wrapped.ServeHTTP(w, r)
+		})
+	})
+}
+
+// patternPath returns pattern without its method.
+func patternPath(pattern string) string {
+	if _, p, ok := strings.Cut(pattern, " "); ok {
+		return p
+	}
+	return pattern
+}
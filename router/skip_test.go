@@ -0,0 +1,39 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestUseExceptAndUnless(t *testing.T) {
+	ok := func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("ok")) }
+	root := New(http.NewServeMux())
+	root.UseUnless(writeBeforeMiddleware("log;"), func(r *http.Request) bool {
+		return strings.HasPrefix(r.URL.Path, "/api/internal")
+	})
+	api := root.Mount("/api").UseExcept(writeBeforeMiddleware("auth;"), "/health", "GET /metrics")
+	api.Get("/health", ok)
+	api.Get("/metrics", ok)
+	api.Post("/metrics", ok)
+	api.Get("/users", ok)
+	api.Get("/internal/stats", ok)
+
+	tests := []struct {
+		method, path, body string
+	}{
+		{http.MethodGet, "/api/health", "log;ok"},
+		{http.MethodGet, "/api/metrics", "log;ok"},
+		{http.MethodPost, "/api/metrics", "log;auth;ok"},
+		{http.MethodGet, "/api/users", "log;auth;ok"},
+		{http.MethodGet, "/api/internal/stats", "auth;ok"},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		root.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+		if got := rec.Body.String(); got != tt.body {
+			t.Errorf("%s %s body = %q, want %q", tt.method, tt.path, got, tt.body)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package router
+
+import (
+	"net/http"
+	"strings"
+)
+
+// TrailingSlashPolicy decides how a request is handled when no route matches
+// its path as written, but one would with the trailing slash added or
+// removed.
+type TrailingSlashPolicy int
+
+const (
+	// TrailingSlashDefault keeps the ServeMux behavior: /foo is redirected
+	// to /foo/ if only the latter is registered; /foo/ is not found if only
+	// /foo is registered.
+	TrailingSlashDefault TrailingSlashPolicy = iota
+
+	// TrailingSlashRedirect redirects to the path with the trailing slash
+	// added or removed, whichever has a route. GET and HEAD requests get a
+	// 301, other methods a 308 so clients resend the body.
+	TrailingSlashRedirect
+
+	// TrailingSlashMatch serves the route of the other form directly, so
+	// /foo and /foo/ both match without a redirect.
+	TrailingSlashMatch
+
+	// TrailingSlashStrict never redirects: a path only matches routes
+	// registered with exactly its form, anything else is not found.
+	TrailingSlashStrict
+)
+
+// TrailingSlash sets the trailing slash policy for requests under the
+// group's base path. Policies are kept on the root group; for a request the
+// policy of the longest matching base path applies. It returns g.
+//
+//	api := r.Mount("/api").TrailingSlash(router.TrailingSlashMatch)
+func (g *Group) TrailingSlash(policy TrailingSlashPolicy) *Group {
+	root := g
+	if g.root != nil {
+		root = g.root
+	}
+	if root.slashPolicies == nil {
+		root.slashPolicies = make(map[string]TrailingSlashPolicy)
+	}
+	root.slashPolicies[g.basePath] = policy
+	return g
+}
+
+// slashPolicy returns the policy for path.
+func (g *Group) slashPolicy(path string) TrailingSlashPolicy {
+	policy, longest := TrailingSlashDefault, -1
+	for base, p := range g.slashPolicies {
+		if len(base) > longest && (path == base || strings.HasPrefix(path, strings.TrimSuffix(base, "/")+"/")) {
+			policy, longest = p, len(base)
+		}
+	}
+	return policy
+}
+
+// slashRoute applies the trailing slash policy to r, whose path resolved
+// to pattern. It returns the request and pattern to serve, or a non-nil
+// handler that answers the request instead.
+func (g *Group) slashRoute(r *http.Request, pattern string) (*http.Request, string, http.Handler) {
+	path := r.URL.Path
+	if path == "/" || len(g.slashPolicies) == 0 {
+		return r, pattern, nil
+	}
+	policy := g.slashPolicy(path)
+	if policy == TrailingSlashDefault || routeMatches(path, pattern) {
+		return r, pattern, nil
+	}
+
+	if policy == TrailingSlashStrict {
+		if pattern == "" {
+			// not a redirect; the mux decides between 404 and 405
+			return r, pattern, nil
+		}
+		if g.notFound != nil {
+			return r, "", g.notFound
+		}
+		return r, "", http.NotFoundHandler()
+	}
+
+	alt := strings.TrimSuffix(path, "/")
+	if alt == path {
+		alt += "/"
+	}
+	u := *r.URL
+	u.Path, u.RawPath = alt, ""
+	r2 := *r
+	r2.URL = &u
+	_, altPattern := g.serveMux().Handler(&r2)
+	if !routeMatches(alt, altPattern) {
+		return r, pattern, nil
+	}
+
+	if policy == TrailingSlashMatch {
+		return &r2, altPattern, nil
+	}
+	code := http.StatusPermanentRedirect
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		code = http.StatusMovedPermanently
+	}
+	return r, pattern, http.RedirectHandler(u.String(), code)
+}
+
+// routeMatches reports whether pattern, as returned by ServeMux.Handler for
+// path, is a route matching path itself rather than the target of the
+// ServeMux redirect from /foo to /foo/. A route can only match a path with
+// at least as many segments as the route has, while the redirect target has
+// one more segment than path.
+func routeMatches(path, pattern string) bool {
+	if pattern == "" {
+		return false
+	}
+	if _, p, ok := strings.Cut(pattern, " "); ok {
+		pattern = p
+	}
+	if i := strings.IndexByte(pattern, '/'); i > 0 {
+		pattern = pattern[i:] // strip the host
+	}
+	pattern = strings.TrimSuffix(pattern, "{$}")
+	return strings.Count(path, "/") >= strings.Count(pattern, "/")
+}
@@ -0,0 +1,84 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTrailingSlashPolicies(t *testing.T) {
+	newRouter := func(policy TrailingSlashPolicy) *Group {
+		root := New(http.NewServeMux())
+		api := root.Mount("/api")
+		api.TrailingSlash(policy)
+		respond := func(body string) http.HandlerFunc {
+			return func(w http.ResponseWriter, r *http.Request) { w.Write([]byte(body)) }
+		}
+		api.Get("/items", respond("items"))
+		api.Get("/docs/", respond("docs"))
+		api.Post("/orders", respond("orders"))
+		root.Get("/outside/", respond("outside"))
+		return root
+	}
+
+	tests := []struct {
+		name     string
+		policy   TrailingSlashPolicy
+		method   string
+		path     string
+		status   int
+		body     string
+		location string
+	}{
+		{"default adds slash", TrailingSlashDefault, "GET", "/api/docs", http.StatusTemporaryRedirect, "", "/api/docs/"},
+		{"default keeps 404", TrailingSlashDefault, "GET", "/api/items/", http.StatusNotFound, "", ""},
+		{"redirect removes slash", TrailingSlashRedirect, "GET", "/api/items/?q=1", http.StatusMovedPermanently, "", "/api/items?q=1"},
+		{"redirect adds slash", TrailingSlashRedirect, "GET", "/api/docs", http.StatusMovedPermanently, "", "/api/docs/"},
+		{"redirect keeps method", TrailingSlashRedirect, "POST", "/api/orders/", http.StatusPermanentRedirect, "", "/api/orders"},
+		{"redirect exact match", TrailingSlashRedirect, "GET", "/api/items", http.StatusOK, "items", ""},
+		{"match without slash", TrailingSlashMatch, "GET", "/api/docs", http.StatusOK, "docs", ""},
+		{"match with slash", TrailingSlashMatch, "GET", "/api/items/", http.StatusOK, "items", ""},
+		{"match unknown", TrailingSlashMatch, "GET", "/api/missing/", http.StatusNotFound, "", ""},
+		{"strict no redirect", TrailingSlashStrict, "GET", "/api/docs", http.StatusNotFound, "", ""},
+		{"strict subtree", TrailingSlashStrict, "GET", "/api/docs/intro", http.StatusOK, "docs", ""},
+		{"strict exact match", TrailingSlashStrict, "GET", "/api/docs/", http.StatusOK, "docs", ""},
+		{"policy limited to group", TrailingSlashStrict, "GET", "/outside", http.StatusTemporaryRedirect, "", "/outside/"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			newRouter(tt.policy).ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.status)
+			}
+			if tt.body != "" && rec.Body.String() != tt.body {
+				t.Errorf("body = %q, want %q", rec.Body.String(), tt.body)
+			}
+			if got := rec.Header().Get("Location"); got != tt.location {
+				t.Errorf("Location = %q, want %q", got, tt.location)
+			}
+		})
+	}
+}
+
+func TestRouteMatches(t *testing.T) {
+	tests := []struct {
+		path, pattern string
+		want          bool
+	}{
+		{"/foo", "GET /foo", true},
+		{"/foo", "/foo/", false},
+		{"/foo/bar", "/foo/", true},
+		{"/foo", "/{rest...}", true},
+		{"/a", "/a/{rest...}", false},
+		{"/api", "GET /api/{$}", false},
+		{"/foo", "example.com/foo", true},
+		{"/foo", "", false},
+	}
+	for _, tt := range tests {
+		if got := routeMatches(tt.path, tt.pattern); got != tt.want {
+			t.Errorf("routeMatches(%q, %q) = %v, want %v", tt.path, tt.pattern, got, tt.want)
+		}
+	}
+}
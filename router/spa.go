@@ -0,0 +1,89 @@
+package router
+
+import (
+	"net/http"
+	"path"
+	"strings"
+)
+
+// SPAConfig configures HandleSPA.
+type SPAConfig struct {
+	// Index is the file served for paths that are not files.
+	// Default: "index.html"
+	Index string
+
+	// IndexCacheControl is the Cache-Control header of the index file, which
+	// must be revalidated so clients pick up new builds.
+	// Default: "no-cache"
+	IndexCacheControl string
+
+	// AssetCacheControl is the Cache-Control header of fingerprinted assets.
+	// Default: "public, max-age=31536000, immutable"
+	AssetCacheControl string
+
+	// IsFingerprinted reports whether a file name contains a content hash,
+	// so the file never changes under that name. Other files get no
+	// Cache-Control header. Default: see IsFingerprinted.
+	IsFingerprinted func(name string) bool
+}
+
+// HandleSPA serves a single-page application build, e.g. from React or Vue,
+// under pattern. Existing files are served normally; other paths get the
+// index file so the client-side router can handle them. Missing paths with
+// a file extension, such as a stale /assets/app.js, are not found rather
+// than answered with the index.
+//
+//	r.HandleSPA("/", http.Dir("web/dist"), router.SPAConfig{})
+func (g *Group) HandleSPA(pattern string, root http.FileSystem, cfg SPAConfig) {
+	if cfg.Index == "" {
+		cfg.Index = "index.html"
+	}
+	if cfg.IndexCacheControl == "" {
+		cfg.IndexCacheControl = "no-cache"
+	}
+	if cfg.AssetCacheControl == "" {
+		cfg.AssetCacheControl = "public, max-age=31536000, immutable"
+	}
+	if cfg.IsFingerprinted == nil {
+		cfg.IsFingerprinted = IsFingerprinted
+	}
+
+	files := &fileServer{root: root, assetCache: cfg.AssetCacheControl, isAsset: cfg.IsFingerprinted}
+	index := "/" + cfg.Index
+	g.handleFiles(pattern, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := path.Clean("/" + r.URL.Path)
+		if files.serve(w, r, name) {
+			return
+		}
+		if path.Ext(name) != "" || !files.serveAs(w, r, index, cfg.IndexCacheControl) {
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+// IsFingerprinted reports whether a file name contains a content hash, as
+// added by common bundlers: a dot- or dash-separated segment of at least 8
+// letters, digits or '_' that contains a digit, followed by the extension,
+// as in main.3f9a1c2b.js or index-B4x_9kQz.css.
+func IsFingerprinted(name string) bool {
+	base := strings.TrimSuffix(name, path.Ext(name))
+	i := strings.LastIndexAny(base, ".-")
+	if i <= 0 {
+		return false
+	}
+	hash := base[i+1:]
+	if len(hash) < 8 {
+		return false
+	}
+	digit := false
+	for _, c := range hash {
+		switch {
+		case c >= '0' && c <= '9':
+			digit = true
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c == '_':
+		default:
+			return false
+		}
+	}
+	return digit
+}
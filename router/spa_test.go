@@ -0,0 +1,68 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+)
+
+func TestHandleSPA(t *testing.T) {
+	files := fstest.MapFS{
+		"index.html":                {Data: []byte("<app>")},
+		"favicon.ico":               {Data: []byte("icon")},
+		"assets/main.3f9a1c2b.js":   {Data: []byte("js")},
+		"assets/index-B4x_9kQz.css": {Data: []byte("css")},
+	}
+	root := New(http.NewServeMux())
+	root.Mount("/app").HandleSPA("/", http.FS(files), SPAConfig{})
+
+	tests := []struct {
+		method, path string
+		status       int
+		body         string
+		cacheControl string
+	}{
+		{"GET", "/app/", http.StatusOK, "<app>", "no-cache"},
+		{"GET", "/app/users/42", http.StatusOK, "<app>", "no-cache"},
+		{"GET", "/app/assets/main.3f9a1c2b.js", http.StatusOK, "js", "public, max-age=31536000, immutable"},
+		{"GET", "/app/assets/index-B4x_9kQz.css", http.StatusOK, "css", "public, max-age=31536000, immutable"},
+		{"GET", "/app/favicon.ico", http.StatusOK, "icon", ""},
+		{"GET", "/app/assets/", http.StatusOK, "<app>", "no-cache"},
+		{"GET", "/app/assets/missing.js", http.StatusNotFound, "", ""},
+		{"POST", "/app/users", http.StatusMethodNotAllowed, "", ""},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		root.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+		if rec.Code != tt.status {
+			t.Errorf("%s %s status = %d, want %d", tt.method, tt.path, rec.Code, tt.status)
+			continue
+		}
+		if tt.body != "" && rec.Body.String() != tt.body {
+			t.Errorf("%s %s body = %q, want %q", tt.method, tt.path, rec.Body.String(), tt.body)
+		}
+		if got := rec.Header().Get("Cache-Control"); tt.status == http.StatusOK && got != tt.cacheControl {
+			t.Errorf("%s %s Cache-Control = %q, want %q", tt.method, tt.path, got, tt.cacheControl)
+		}
+	}
+}
+
+func TestIsFingerprinted(t *testing.T) {
+	tests := map[string]bool{
+		"main.3f9a1c2b.js":    true,
+		"index-B4x_9kQz.css":  true,
+		"chunk.a1b2c3d4e5.js": true,
+		"app.js":              false,
+		"vendor.bundle.js":    false,
+		"jquery-3.7.1.min.js": false,
+		"favicon.ico":         false,
+		"logo-abcdefgh.svg":   false,
+		".3f9a1c2b3":          false,
+	}
+	for name, want := range tests {
+		if got := IsFingerprinted(name); got != want {
+			t.Errorf("IsFingerprinted(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
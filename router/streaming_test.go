@@ -0,0 +1,156 @@
+package router
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/en9inerd/go-pkgs/middleware"
+	"github.com/en9inerd/go-pkgs/observability/observabilitytest"
+)
+
+// streamingStack builds a router with every response-wrapping middleware
+// from the middleware package.
+func streamingStack(t *testing.T) *Group {
+	t.Helper()
+	discard := slog.New(slog.DiscardHandler)
+	obs, _, _ := observabilitytest.New()
+
+	r := New(http.NewServeMux())
+	r.Use(
+		middleware.RequestLogger(discard),
+		middleware.Recoverer(discard, false),
+		middleware.Logger(discard),
+		middleware.Observe(obs),
+	)
+	return r.Mount("/api")
+}
+
+func TestStreaming_SSEFlushesThroughStack(t *testing.T) {
+	// Coalesce must pass event streams through rather than buffer them
+	api := streamingStack(t).With(Coalesce(CoalesceConfig{}))
+	next := make(chan struct{})
+	api.HandleFunc("GET /events", func(w http.ResponseWriter, r *http.Request) {
+		f, ok := w.(http.Flusher)
+		if !ok {
+			t.Error("http.Flusher lost in the middleware stack")
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		for i := range 2 {
+			fmt.Fprintf(w, "data: %d\n\n", i)
+			f.Flush()
+			if i == 0 {
+				<-next // the client must see event 0 before event 1 is written
+			}
+		}
+	})
+
+	srv := httptest.NewServer(api.root)
+	defer srv.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/api/events", nil)
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	br := bufio.NewReader(resp.Body)
+	line, err := br.ReadString('\n')
+	if err != nil || line != "data: 0\n" {
+		t.Fatalf("first event = %q, %v", line, err)
+	}
+	close(next)
+
+	rest, _ := io.ReadAll(br)
+	if string(rest) != "\ndata: 1\n\n" {
+		t.Errorf("rest of stream = %q", rest)
+	}
+}
+
+func TestStreaming_HijackThroughStack(t *testing.T) {
+	api := streamingStack(t).With(Coalesce(CoalesceConfig{}))
+	api.HandleFunc("GET /ws", func(w http.ResponseWriter, r *http.Request) {
+		conn, rw, err := http.NewResponseController(w).Hijack()
+		if err != nil {
+			t.Errorf("Hijack: %v", err)
+			return
+		}
+		defer conn.Close()
+		rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: test\r\nConnection: Upgrade\r\n\r\nhello")
+		rw.Flush()
+	})
+
+	srv := httptest.NewServer(api.root)
+	defer srv.Close()
+
+	conn, err := net.Dial("tcp", srv.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	fmt.Fprintf(conn, "GET /api/ws HTTP/1.1\r\nHost: test\r\nUpgrade: test\r\nConnection: Upgrade\r\n\r\n")
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("status = %d, want 101", resp.StatusCode)
+	}
+	body, _ := io.ReadAll(br)
+	if string(body) != "hello" {
+		t.Errorf("upgraded stream = %q", body)
+	}
+}
+
+// readerFromWriter stands in for the server's response writer, whose
+// ReadFrom uses sendfile, and records that io.Copy reached it.
+type readerFromWriter struct {
+	*httptest.ResponseRecorder
+	readFrom bool
+}
+
+func (w *readerFromWriter) ReadFrom(src io.Reader) (int64, error) {
+	w.readFrom = true
+	return io.Copy(w.ResponseRecorder, src)
+}
+
+func TestStreaming_ReadFromThroughStack(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file.txt")
+	if err := os.WriteFile(path, []byte(strings.Repeat("x", 4096)), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	api := streamingStack(t)
+	api.HandleFunc("GET /file", func(w http.ResponseWriter, r *http.Request) {
+		f, err := os.Open(path)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer f.Close()
+		io.Copy(w, f)
+	})
+
+	w := &readerFromWriter{ResponseRecorder: httptest.NewRecorder()}
+	api.root.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/file", nil))
+
+	if !w.readFrom {
+		t.Error("io.Copy did not reach the underlying io.ReaderFrom")
+	}
+	if w.Body.Len() != 4096 {
+		t.Errorf("body length = %d, want 4096", w.Body.Len())
+	}
+}
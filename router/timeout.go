@@ -0,0 +1,30 @@
+package router
+
+import (
+	"net/http"
+	"time"
+)
+
+// WithTimeout returns a new group whose routes run with http.TimeoutHandler
+// semantics: the request context gets a deadline of d and, if the handler
+// has not responded by then, the client gets 503 Service Unavailable. The
+// timeout applies inside the group's middlewares. Responses are buffered
+// and cannot be flushed, so long-poll and SSE routes should opt out:
+// WithTimeout(0) returns a subgroup without a timeout.
+//
+//	api := r.Mount("/api").WithTimeout(5 * time.Second)
+//	api.Get("/users", listUsers)
+//	api.WithTimeout(0).Get("/events", streamEvents)
+func (g *Group) WithTimeout(d time.Duration) *Group {
+	ng := g.clone()
+	ng.timeout = d
+	return ng
+}
+
+// Timeout returns per-route middleware with the semantics of WithTimeout,
+// for passing after the handler to Handle and HandleFunc.
+func Timeout(d time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.TimeoutHandler(next, d, "")
+	}
+}
@@ -0,0 +1,52 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWithTimeout(t *testing.T) {
+	root := New(http.NewServeMux())
+	api := root.Mount("/api").WithTimeout(20 * time.Millisecond)
+
+	slow := func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+		w.Write([]byte("done"))
+	}
+	api.Get("/slow", slow)
+	api.Get("/fast", func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("fast")) })
+	api.WithTimeout(0).Get("/stream", func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := w.(http.Flusher); !ok {
+			http.Error(w, "not a flusher", http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte("stream"))
+	})
+	root.Get("/route", slow, Timeout(20*time.Millisecond))
+
+	tests := []struct {
+		path   string
+		status int
+		body   string
+	}{
+		{"/api/slow", http.StatusServiceUnavailable, ""},
+		{"/api/fast", http.StatusOK, "fast"},
+		{"/api/stream", http.StatusOK, "stream"},
+		{"/route", http.StatusServiceUnavailable, ""},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		root.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if rec.Code != tt.status {
+			t.Errorf("GET %s status = %d, want %d", tt.path, rec.Code, tt.status)
+		}
+		if tt.body != "" && rec.Body.String() != tt.body {
+			t.Errorf("GET %s body = %q, want %q", tt.path, rec.Body.String(), tt.body)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// TLSOptions configures ServeTLS.
+type TLSOptions struct {
+	// Domains are the host names certificates are requested for. Requests
+	// for other hosts fail the TLS handshake. Required unless Manager is set.
+	Domains []string
+
+	// CacheDir is the directory certificates and the ACME account key are
+	// kept in across restarts, so they are not requested again and again
+	// against the CA's rate limits.
+	// Default: "certs"
+	CacheDir string
+
+	// Email is the contact address given to the CA for expiry notices.
+	// Optional.
+	Email string
+
+	// Addr is the HTTPS address. Default: ":443"
+	Addr string
+
+	// HTTPAddr is the plain HTTP address, which answers ACME HTTP-01
+	// challenges and redirects everything else to HTTPS. Default: ":80"
+	HTTPAddr string
+
+	// Manager, if set, is used instead of one built from Domains, CacheDir
+	// and Email, e.g. to use the CA's staging directory while testing.
+	Manager *autocert.Manager
+
+	// HTTPListener, if set, is served instead of listening on HTTPAddr.
+	// Serve.Listener likewise replaces listening on Addr.
+	HTTPListener net.Listener
+
+	// Serve configures both servers: timeouts, signals, shutdown hooks and
+	// logging, as for Serve.
+	Serve ServeConfig
+}
+
+// ServeTLS is like Serve but serves g over HTTPS with certificates obtained
+// and renewed automatically from Let's Encrypt through ACME, and serves
+// plain HTTP on HTTPAddr to answer ACME challenges and redirect everything
+// else to HTTPS: 301 for GET and HEAD, 308 otherwise.
+//
+//	err := router.ServeTLS(ctx, r, router.TLSOptions{
+//		Domains:  []string{"example.com", "www.example.com"},
+//		CacheDir: "/var/lib/myapp/certs",
+//	})
+func ServeTLS(ctx context.Context, g *Group, opts TLSOptions) error {
+	if opts.Addr == "" {
+		opts.Addr = ":443"
+	}
+	if opts.HTTPAddr == "" {
+		opts.HTTPAddr = ":80"
+	}
+	if opts.CacheDir == "" {
+		opts.CacheDir = "certs"
+	}
+	m := opts.Manager
+	if m == nil {
+		if len(opts.Domains) == 0 {
+			return errors.New("router: ServeTLS needs Domains or a Manager")
+		}
+		m = &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(opts.Domains...),
+			Cache:      autocert.DirCache(opts.CacheDir),
+			Email:      opts.Email,
+		}
+	}
+	cfg := opts.Serve.withDefaults()
+
+	g.Build()
+	srv := cfg.server(opts.Addr, g)
+	srv.TLSConfig = m.TLSConfig()
+	httpSrv := cfg.server(opts.HTTPAddr, m.HTTPHandler(httpsRedirect(opts.Addr)))
+
+	ln, err := listen(cfg.Listener, opts.Addr)
+	if err != nil {
+		return err
+	}
+	httpLn, err := listen(opts.HTTPListener, opts.HTTPAddr)
+	if err != nil {
+		ln.Close()
+		return err
+	}
+	return run(ctx, cfg, []listener{{srv: srv, ln: ln, tls: true}, {srv: httpSrv, ln: httpLn}})
+}
+
+// listen returns ln, or a new TCP listener on addr if ln is nil.
+func listen(ln net.Listener, addr string) (net.Listener, error) {
+	if ln != nil {
+		return ln, nil
+	}
+	return net.Listen("tcp", addr)
+}
+
+// httpsRedirect redirects requests to the same URL over HTTPS on the port of
+// addr.
+func httpsRedirect(addr string) http.Handler {
+	_, port, _ := net.SplitHostPort(addr)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if port != "" && port != "443" {
+			host = net.JoinHostPort(host, port)
+		}
+		code := http.StatusMovedPermanently
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			code = http.StatusPermanentRedirect
+		}
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), code)
+	})
+}
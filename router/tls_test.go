@@ -0,0 +1,89 @@
+package router
+
+import (
+	"context"
+	"crypto/tls"
+	"log/slog"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/acme/autocert"
+)
+
+func TestServeTLS(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	httpLn, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	g := New(http.NewServeMux())
+	g.Get("/", func(w http.ResponseWriter, r *http.Request) {})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() {
+		served <- ServeTLS(ctx, g, TLSOptions{
+			Addr: ":8443",
+			Manager: &autocert.Manager{
+				Prompt:     autocert.AcceptTOS,
+				HostPolicy: autocert.HostWhitelist("example.com"),
+				Cache:      autocert.DirCache(t.TempDir()),
+			},
+			HTTPListener: httpLn,
+			Serve:        ServeConfig{Listener: ln, Logger: slog.New(slog.DiscardHandler)},
+		})
+	}()
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	tests := []struct {
+		method   string
+		status   int
+		location string
+	}{
+		{http.MethodGet, http.StatusMovedPermanently, "https://example.com:8443/users?page=2"},
+		{http.MethodPost, http.StatusPermanentRedirect, "https://example.com:8443/users?page=2"},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest(tt.method, "http://"+httpLn.Addr().String()+"/users?page=2", nil)
+		req.Host = "example.com"
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tt.status || resp.Header.Get("Location") != tt.location {
+			t.Errorf("%s redirect = %d %q, want %d %q", tt.method, resp.StatusCode, resp.Header.Get("Location"), tt.status, tt.location)
+		}
+	}
+
+	client.CloseIdleConnections()
+
+	// hosts outside the policy are refused before any ACME request
+	conn, err := tls.DialWithDialer(&net.Dialer{Timeout: time.Second}, "tcp", ln.Addr().String(), &tls.Config{ServerName: "other.test"})
+	if err == nil {
+		conn.Close()
+		t.Error("TLS handshake for a host outside Domains succeeded")
+	}
+
+	cancel()
+	select {
+	case err := <-served:
+		if err != nil {
+			t.Errorf("ServeTLS = %v, want nil after a clean shutdown", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("ServeTLS did not return after cancel")
+	}
+}
+
+func TestServeTLSNeedsDomains(t *testing.T) {
+	if err := ServeTLS(context.Background(), New(http.NewServeMux()), TLSOptions{}); err == nil {
+		t.Error("ServeTLS without Domains or Manager returned nil")
+	}
+}
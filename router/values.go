@@ -0,0 +1,37 @@
+package router
+
+import (
+	"context"
+	"net/http"
+)
+
+// UseValue adds middleware that stores value under key in the request
+// context of the group's routes, like context.WithValue. key should be of
+// an unexported type, as for context.WithValue.
+//
+//	type tenantKey struct{}
+//	acme := r.Mount("/acme")
+//	acme.UseValue(tenantKey{}, "acme")
+//
+// It returns g.
+func (g *Group) UseValue(key, value any) *Group {
+	return g.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), key, value)))
+		})
+	})
+}
+
+// UseValueFunc is like UseValue but computes the key and value for each
+// request, e.g. feature flags for the requesting user. A nil key stores
+// nothing. It returns g.
+func (g *Group) UseValueFunc(fn func(r *http.Request) (key, value any)) *Group {
+	return g.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if key, value := fn(r); key != nil {
+				r = r.WithContext(context.WithValue(r.Context(), key, value))
+			}
+			next.ServeHTTP(w, r)
+		})
+	})
+}
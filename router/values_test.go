@@ -0,0 +1,46 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type tenantKey struct{}
+
+type flagsKey struct{}
+
+func TestUseValue(t *testing.T) {
+	root := New(http.NewServeMux())
+	acme := root.Mount("/acme")
+	acme.UseValue(tenantKey{}, "acme")
+	acme.UseValueFunc(func(r *http.Request) (any, any) {
+		if r.URL.Query().Has("beta") {
+			return flagsKey{}, "beta"
+		}
+		return nil, nil
+	})
+
+	show := func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%v/%v", r.Context().Value(tenantKey{}), r.Context().Value(flagsKey{}))
+	}
+	acme.Get("/whoami", show)
+	root.Get("/whoami", show)
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/acme/whoami", "acme/<nil>"},
+		{"/acme/whoami?beta", "acme/beta"},
+		{"/whoami", "<nil>/<nil>"},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		root.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if got := rec.Body.String(); got != tt.want {
+			t.Errorf("GET %s = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package router
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"path"
+	"sync"
+)
+
+// ViewsConfig configures the templates rendered by HandleView.
+type ViewsConfig struct {
+	// FS holds the templates, e.g. an embed.FS or os.DirFS("templates").
+	FS fs.FS
+
+	// Layout is the template file every view is rendered through, e.g.
+	// "layout.tmpl" executing {{block "content" .}}, which the view files
+	// redefine. Empty renders the view file on its own.
+	Layout string
+
+	// Partials is a glob of templates parsed with every view, e.g.
+	// "partials/*.tmpl". Empty means none.
+	Partials string
+
+	// Funcs are made available to all templates.
+	Funcs template.FuncMap
+
+	// Reload parses the templates on every request instead of once per view,
+	// so edits show up without a restart. Meant for development.
+	Reload bool
+}
+
+// views renders the templates of a root group.
+type views struct {
+	cfg   ViewsConfig
+	mu    sync.Mutex
+	cache map[string]*template.Template
+}
+
+// Views sets the templates of the router for HandleView, on the root group,
+// and returns g.
+//
+//	//go:embed templates
+//	var templates embed.FS
+//
+//	sub, _ := fs.Sub(templates, "templates")
+//	r.Views(router.ViewsConfig{FS: sub, Layout: "layout.tmpl", Reload: dev})
+func (g *Group) Views(cfg ViewsConfig) *Group {
+	g.rootGroup().views = &views{cfg: cfg, cache: make(map[string]*template.Template)}
+	return g
+}
+
+// HandleView registers a route that renders the view file name, through the
+// layout if one is configured, with the value returned by data, which may be
+// nil for static pages. Errors from data and from rendering go to the
+// group's ErrorHandler; the page is rendered to a buffer first, so a failing
+// template never sends a partial response. Trailing middlewares apply only
+// to this route, as for Handle.
+//
+//	r.HandleView("GET /about", "about.tmpl", nil)
+//	r.HandleView("GET /users/{id}", "user.tmpl", func(r *http.Request) (any, error) {
+//		return store.User(r.PathValue("id"))
+//	})
+func (g *Group) HandleView(pattern, name string, data func(r *http.Request) (any, error), mws ...func(http.Handler) http.Handler) {
+	g.HandleFuncE(pattern, func(w http.ResponseWriter, r *http.Request) error {
+		v := g.rootGroup().views
+		if v == nil {
+			return errors.New("router: HandleView used without Views")
+		}
+		var value any
+		if data != nil {
+			var err error
+			if value, err = data(r); err != nil {
+				return err
+			}
+		}
+
+		var buf bytes.Buffer
+		if err := v.render(&buf, name, value); err != nil {
+			return err
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, err := buf.WriteTo(w)
+		return err
+	}, mws...)
+}
+
+// render executes the view name with data into buf.
+func (v *views) render(buf *bytes.Buffer, name string, data any) error {
+	t, err := v.template(name)
+	if err != nil {
+		return err
+	}
+	entry := path.Base(name)
+	if v.cfg.Layout != "" {
+		entry = path.Base(v.cfg.Layout)
+	}
+	return t.ExecuteTemplate(buf, entry, data)
+}
+
+// template returns the parsed view name, from the cache unless reloading.
+func (v *views) template(name string) (*template.Template, error) {
+	if !v.cfg.Reload {
+		v.mu.Lock()
+		defer v.mu.Unlock()
+		if t, ok := v.cache[name]; ok {
+			return t, nil
+		}
+	}
+
+	// the layout comes first so the view's block definitions override it
+	var patterns []string
+	if v.cfg.Layout != "" {
+		patterns = append(patterns, v.cfg.Layout)
+	}
+	if v.cfg.Partials != "" {
+		patterns = append(patterns, v.cfg.Partials)
+	}
+	patterns = append(patterns, name)
+	t, err := template.New(path.Base(patterns[0])).Funcs(v.cfg.Funcs).ParseFS(v.cfg.FS, patterns...)
+	if err != nil {
+		return nil, fmt.Errorf("router: parse view %q: %w", name, err)
+	}
+
+	if !v.cfg.Reload {
+		v.cache[name] = t
+	}
+	return t, nil
+}
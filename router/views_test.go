@@ -0,0 +1,93 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/en9inerd/go-pkgs/httperrors"
+)
+
+func TestHandleView(t *testing.T) {
+	fsys := fstest.MapFS{
+		"layout.tmpl":       {Data: []byte(`<main>{{template "nav"}}|{{block "content" .}}default{{end}}</main>`)},
+		"partials/nav.tmpl": {Data: []byte(`{{define "nav"}}nav{{end}}`)},
+		"about.tmpl":        {Data: []byte(`{{define "content"}}about{{end}}`)},
+		"user.tmpl":         {Data: []byte(`{{define "content"}}{{upper .}}{{end}}`)},
+		"broken.tmpl":       {Data: []byte(`{{define "content"}}{{.Missing.Field}}{{end}}`)},
+	}
+	root := New(http.NewServeMux()).Views(ViewsConfig{
+		FS:       fsys,
+		Layout:   "layout.tmpl",
+		Partials: "partials/*.tmpl",
+		Funcs:    map[string]any{"upper": strings.ToUpper},
+	})
+	root.HandleView("GET /about", "about.tmpl", nil)
+	root.HandleView("GET /users/{id}", "user.tmpl", func(r *http.Request) (any, error) {
+		if r.PathValue("id") == "0" {
+			return nil, httperrors.NewError(http.StatusNotFound, "user not found")
+		}
+		return "user " + r.PathValue("id"), nil
+	})
+	root.HandleView("GET /broken", "broken.tmpl", func(r *http.Request) (any, error) { return 42, nil })
+
+	tests := []struct {
+		path   string
+		status int
+		body   string
+	}{
+		{"/about", http.StatusOK, "<main>nav|about</main>"},
+		{"/users/7", http.StatusOK, "<main>nav|USER 7</main>"},
+		{"/users/0", http.StatusNotFound, "user not found"},
+		{"/broken", http.StatusInternalServerError, "Internal Server Error"},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		root.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if rec.Code != tt.status || !strings.Contains(rec.Body.String(), tt.body) {
+			t.Errorf("GET %s = %d %q, want %d containing %q", tt.path, rec.Code, rec.Body.String(), tt.status, tt.body)
+		}
+		if tt.status == http.StatusOK && rec.Header().Get("Content-Type") != "text/html; charset=utf-8" {
+			t.Errorf("GET %s Content-Type = %q", tt.path, rec.Header().Get("Content-Type"))
+		}
+	}
+}
+
+func TestHandleViewReload(t *testing.T) {
+	fsys := fstest.MapFS{"page.tmpl": {Data: []byte("v1")}}
+	get := func(root *Group) string {
+		rec := httptest.NewRecorder()
+		root.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		return rec.Body.String()
+	}
+
+	cached := New(http.NewServeMux()).Views(ViewsConfig{FS: fsys})
+	cached.HandleView("GET /{$}", "page.tmpl", nil)
+	reloading := New(http.NewServeMux()).Views(ViewsConfig{FS: fsys, Reload: true})
+	reloading.HandleView("GET /{$}", "page.tmpl", nil)
+	if get(cached) != "v1" || get(reloading) != "v1" {
+		t.Fatal("initial render differs from v1")
+	}
+
+	fsys["page.tmpl"] = &fstest.MapFile{Data: []byte("v2")}
+	if got := get(cached); got != "v1" {
+		t.Errorf("cached view = %q, want v1", got)
+	}
+	if got := get(reloading); got != "v2" {
+		t.Errorf("reloaded view = %q, want v2", got)
+	}
+}
+
+func TestHandleViewWithoutViews(t *testing.T) {
+	var got error
+	root := New(http.NewServeMux())
+	root.ErrorHandler(func(w http.ResponseWriter, r *http.Request, err error) { got = err })
+	root.HandleView("GET /about", "about.tmpl", nil)
+
+	root.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/about", nil))
+	if got == nil {
+		t.Error("rendering without Views did not report an error")
+	}
+}
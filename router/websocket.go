@@ -0,0 +1,82 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// HandleWebSocket registers a WebSocket endpoint; handler performs the
+// upgrade, e.g. with a websocket library's Upgrader or Accept. A pattern
+// without a method is registered for GET. Trailing middlewares apply only to
+// this route, as for Handle.
+//
+// The group's middlewares run for the upgrade request as for any route, so
+// authentication, logging and CORS apply, but wrappers that break hijacking
+// or outlive the handshake do not reach the handler:
+//   - the group's WithTimeout is not applied;
+//   - if a middleware replaced the http.ResponseWriter with one that cannot
+//     be hijacked, e.g. http.TimeoutHandler or a compressing writer, the
+//     handler gets the writer from before the middlewares;
+//   - the request context keeps the values set by middlewares but not their
+//     deadlines or cancellation, so a timeout middleware does not end the
+//     connection; once hijacked, closing it is up to the handler.
+//
+// Requests without WebSocket upgrade headers reach handler unchanged, so it
+// can reject them.
+func (g *Group) HandleWebSocket(pattern string, handler http.Handler, mws ...func(http.Handler) http.Handler) {
+	if !strings.Contains(pattern, " ") {
+		pattern = http.MethodGet + " " + pattern
+	}
+	ng := g.WithTimeout(0)
+	ng.websocket = true
+	ng.Handle(pattern, wrapRoute(upgradeHandler(handler), mws))
+}
+
+// upgradeKey is the context key of the response writer of a WebSocket
+// upgrade request before any middleware replaced it.
+type upgradeKey struct{}
+
+// withUpgradeWriter records w for upgradeHandler, unless an outer layer
+// already did.
+func withUpgradeWriter(w http.ResponseWriter, r *http.Request) *http.Request {
+	if !isWebSocketUpgrade(r) || r.Context().Value(upgradeKey{}) != nil {
+		return r
+	}
+	return r.WithContext(context.WithValue(r.Context(), upgradeKey{}, w))
+}
+
+// upgradeHandler hands handler the recorded writer when the current one
+// cannot be hijacked, and a context without middleware deadlines.
+func upgradeHandler(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		orig, ok := r.Context().Value(upgradeKey{}).(http.ResponseWriter)
+		if !ok {
+			handler.ServeHTTP(w, r)
+			return
+		}
+		if !canHijack(w) {
+			w = orig
+		}
+		handler.ServeHTTP(w, r.WithContext(context.WithoutCancel(r.Context())))
+	})
+}
+
+// canHijack reports whether the innermost writer reachable from w through
+// Unwrap can be hijacked.
+func canHijack(w http.ResponseWriter) bool {
+	for {
+		u, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			_, ok := w.(http.Hijacker)
+			return ok
+		}
+		w = u.Unwrap()
+	}
+}
+
+// isWebSocketUpgrade reports whether r asks to upgrade to WebSocket.
+func isWebSocketUpgrade(r *http.Request) bool {
+	return strings.EqualFold(r.Header.Get("Upgrade"), "websocket") &&
+		strings.Contains(strings.ToLower(r.Header.Get("Connection")), "upgrade")
+}
@@ -0,0 +1,81 @@
+package router
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// plainWriter hides the optional interfaces of the writer it wraps, like a
+// compression middleware that embeds the writer in its own struct.
+type plainWriter struct{ http.ResponseWriter }
+
+func TestHandleWebSocket(t *testing.T) {
+	type userKey struct{}
+	root := New(http.NewServeMux())
+	api := root.Mount("/api").WithTimeout(20 * time.Millisecond)
+	api.UseValue(userKey{}, "alice")
+	api.Use(Timeout(20*time.Millisecond), func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(plainWriter{w}, r)
+		})
+	})
+
+	api.HandleWebSocket("/ws", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Upgrade") == "" {
+			http.Error(w, "upgrade required", http.StatusUpgradeRequired)
+			return
+		}
+		conn, rw, err := http.NewResponseController(w).Hijack()
+		if err != nil {
+			t.Errorf("Hijack: %v", err)
+			return
+		}
+		defer conn.Close()
+		time.Sleep(50 * time.Millisecond)
+		rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n\r\n")
+		fmt.Fprintf(rw, "%v %v", r.Context().Value(userKey{}), r.Context().Err())
+		rw.Flush()
+	}))
+
+	// the timeout middleware writing its 503 after the hijack is logged
+	srv := httptest.NewUnstartedServer(root)
+	srv.Config.ErrorLog = log.New(io.Discard, "", 0)
+	srv.Start()
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/api/ws")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUpgradeRequired {
+		t.Errorf("plain GET status = %d, want 426", resp.StatusCode)
+	}
+
+	conn, err := net.Dial("tcp", srv.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	fmt.Fprintf(conn, "GET /api/ws HTTP/1.1\r\nHost: test\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n\r\n")
+
+	br := bufio.NewReader(conn)
+	resp, err = http.ReadResponse(br, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("status = %d, want 101", resp.StatusCode)
+	}
+	body, _ := io.ReadAll(br)
+	if string(body) != "alice <nil>" {
+		t.Errorf("upgraded stream = %q, want middleware value and no context error", body)
+	}
+}
@@ -3,6 +3,7 @@ package validator
 
 import (
 	"encoding/json"
+	"path/filepath"
 	"regexp"
 	"slices"
 	"strconv"
@@ -114,6 +115,49 @@ func MaxDuration(d, maxDuration time.Duration) bool { return d <= maxDuration }
 // MinDuration returns true if the duration is greater than or equal to minDuration.
 func MinDuration(d, minDuration time.Duration) bool { return d >= minDuration }
 
+/////////////////////////
+// File Validators
+/////////////////////////
+
+// MaxFileSize returns true if size is less than or equal to maxSize bytes.
+func MaxFileSize(size, maxSize int64) bool { return size <= maxSize }
+
+// PermittedMIMEType returns true if mimeType matches one of the permitted types.
+// Parameters such as charset are ignored and wildcards like "image/*" are supported.
+func PermittedMIMEType(mimeType string, permitted ...string) bool {
+	mediaType, _, _ := strings.Cut(mimeType, ";")
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	for _, p := range permitted {
+		p = strings.ToLower(p)
+		if p == mediaType || p == "*/*" {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(p, "/*"); ok && strings.HasPrefix(mediaType, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// PermittedFileExtension returns true if filename has one of the permitted
+// extensions. Comparison is case-insensitive and extensions may be given
+// with or without the leading dot.
+func PermittedFileExtension(filename string, permitted ...string) bool {
+	ext := strings.ToLower(filepath.Ext(filename))
+	if ext == "" {
+		return false
+	}
+	for _, p := range permitted {
+		if !strings.HasPrefix(p, ".") {
+			p = "." + p
+		}
+		if strings.ToLower(p) == ext {
+			return true
+		}
+	}
+	return false
+}
+
 /////////////////////////
 // Generic Helpers
 /////////////////////////
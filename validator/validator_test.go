@@ -121,3 +121,83 @@ func TestPermittedValue(t *testing.T) {
 		t.Errorf("expected int not to be permitted")
 	}
 }
+
+func TestFileValidators(t *testing.T) {
+	if !MaxFileSize(1024, 1024) || MaxFileSize(1025, 1024) {
+		t.Errorf("MaxFileSize failed")
+	}
+
+	if !PermittedMIMEType("image/png", "image/*") {
+		t.Errorf("expected wildcard MIME type to match")
+	}
+	if !PermittedMIMEType("text/plain; charset=utf-8", "application/json", "text/plain") {
+		t.Errorf("expected MIME type with parameters to match")
+	}
+	if PermittedMIMEType("application/pdf", "image/*", "text/plain") {
+		t.Errorf("expected MIME type not to be permitted")
+	}
+
+	if !PermittedFileExtension("photo.JPG", "jpg", ".png") {
+		t.Errorf("expected extension to be permitted")
+	}
+	if PermittedFileExtension("archive.tar.gz", ".zip") || PermittedFileExtension("noext", "txt") {
+		t.Errorf("expected extension not to be permitted")
+	}
+}
+
+// benchRequest is a typical request payload validated on a hot path.
+type benchRequest struct {
+	Name    string
+	Email   string
+	Age     int
+	Website string
+	Role    string
+}
+
+func (r benchRequest) Validate(v *Validator) {
+	v.CheckField(NotBlank(r.Name), "name", "must not be blank")
+	v.CheckField(MaxChars(r.Name, 100), "name", "must be at most 100 characters")
+	v.CheckField(IsEmail(r.Email), "email", "must be a valid email address")
+	v.CheckField(MinInt(r.Age, 18), "age", "must be at least 18")
+	v.CheckField(IsHTTPURL(r.Website), "website", "must be an http(s) URL")
+	v.CheckField(PermittedValue(r.Role, "admin", "user"), "role", "must be admin or user")
+}
+
+var validBenchRequest = benchRequest{
+	Name:    "Jane Doe",
+	Email:   "jane@example.com",
+	Age:     30,
+	Website: "https://example.com",
+	Role:    "user",
+}
+
+// Validation is explicit method calls rather than struct tags, so there is
+// no per-type reflection to cache; these benchmarks track its cost.
+func BenchmarkValidateRequest(b *testing.B) {
+	b.ReportAllocs()
+	for b.Loop() {
+		if err := ValidateRequest(validBenchRequest); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkValidateRequestWithValidator(b *testing.B) {
+	b.ReportAllocs()
+	var v Validator
+	for b.Loop() {
+		if err := ValidateRequestWithValidator(validBenchRequest, &v); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkValidateRequest_Invalid(b *testing.B) {
+	b.ReportAllocs()
+	req := benchRequest{Email: "nope", Website: "ftp://x", Role: "root"}
+	for b.Loop() {
+		if err := ValidateRequest(req); err == nil {
+			b.Fatal("expected validation error")
+		}
+	}
+}